@@ -8,7 +8,11 @@ import (
 	"github.com/ViBiOh/absto/pkg/absto"
 	"github.com/ViBiOh/auth/v3/pkg/cookie"
 	basicMemory "github.com/ViBiOh/auth/v3/pkg/store/memory"
+	"github.com/ViBiOh/fibr/pkg/album"
 	"github.com/ViBiOh/fibr/pkg/crud"
+	"github.com/ViBiOh/fibr/pkg/email"
+	"github.com/ViBiOh/fibr/pkg/inbox"
+	"github.com/ViBiOh/fibr/pkg/live"
 	"github.com/ViBiOh/fibr/pkg/metadata"
 	"github.com/ViBiOh/fibr/pkg/push"
 	"github.com/ViBiOh/fibr/pkg/sanitizer"
@@ -56,7 +60,11 @@ type configuration struct {
 	thumbnail *thumbnail.Config
 	webhook   *webhook.Config
 	share     *share.Config
+	album     *album.Config
 	push      *push.Config
+	email     *email.Config
+	inbox     *inbox.Config
+	live      *live.Config
 
 	disableAuth           bool
 	disableStorageTracing bool
@@ -92,7 +100,11 @@ func newConfig() configuration {
 		thumbnail: thumbnail.Flags(fs, "thumbnail"),
 		webhook:   webhook.Flags(fs, "webhook"),
 		share:     share.Flags(fs, "share"),
+		album:     album.Flags(fs, "album"),
 		push:      push.Flags(fs, "push"),
+		email:     email.Flags(fs, "smtp"),
+		inbox:     inbox.Flags(fs, "inbox"),
+		live:      live.Flags(fs, "live"),
 	}
 
 	flags.New("NoAuth", "Disable basic authentification").DocPrefix("auth").BoolVar(fs, &config.disableAuth, false, nil)
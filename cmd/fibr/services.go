@@ -9,8 +9,12 @@ import (
 
 	"github.com/ViBiOh/auth/v3/pkg/cookie"
 	basicMemory "github.com/ViBiOh/auth/v3/pkg/store/memory"
+	"github.com/ViBiOh/fibr/pkg/album"
 	"github.com/ViBiOh/fibr/pkg/crud"
+	"github.com/ViBiOh/fibr/pkg/email"
 	"github.com/ViBiOh/fibr/pkg/fibr"
+	"github.com/ViBiOh/fibr/pkg/inbox"
+	"github.com/ViBiOh/fibr/pkg/live"
 	"github.com/ViBiOh/fibr/pkg/metadata"
 	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/fibr/pkg/push"
@@ -34,9 +38,13 @@ type services struct {
 	renderer *renderer.Service
 
 	fibr          fibr.Service
+	crud          *crud.Service
 	eventBus      provider.EventBus
 	webhook       *webhook.Service
 	share         *share.Service
+	album         *album.Service
+	inbox         *inbox.Service
+	live          *live.Service
 	amqpThumbnail *amqphandler.Service
 	amqpExif      *amqphandler.Service
 	sanitizer     sanitizer.Service
@@ -56,17 +64,17 @@ func newServices(ctx context.Context, config configuration, clients clients, ada
 		return output, err
 	}
 
-	output.thumbnail, err = thumbnail.New(ctx, config.thumbnail, adapters.storage, clients.redis, clients.telemetry.MeterProvider(), clients.telemetry.TracerProvider(), clients.amqp)
+	output.metadata, err = metadata.New(ctx, config.metadata, adapters.storage, clients.telemetry.MeterProvider(), clients.telemetry.TracerProvider(), clients.amqp, clients.redis, adapters.exclusiveService)
 	if err != nil {
 		return output, err
 	}
 
-	output.renderer, err = renderer.New(ctx, config.renderer, content, fibr.FuncMap, clients.telemetry.MeterProvider(), clients.telemetry.TracerProvider())
+	output.thumbnail, err = thumbnail.New(ctx, config.thumbnail, adapters.storage, clients.redis, clients.telemetry.MeterProvider(), clients.telemetry.TracerProvider(), clients.amqp, output.metadata, output.eventBus.Push)
 	if err != nil {
 		return output, err
 	}
 
-	output.metadata, err = metadata.New(ctx, config.metadata, adapters.storage, clients.telemetry.MeterProvider(), clients.telemetry.TracerProvider(), clients.amqp, clients.redis, adapters.exclusiveService)
+	output.renderer, err = renderer.New(ctx, config.renderer, content, fibr.FuncMap, clients.telemetry.MeterProvider(), clients.telemetry.TracerProvider())
 	if err != nil {
 		return output, err
 	}
@@ -76,13 +84,22 @@ func newServices(ctx context.Context, config configuration, clients clients, ada
 		return output, err
 	}
 
-	output.webhook = webhook.New(config.webhook, adapters.storage, clients.telemetry.MeterProvider(), clients.redis, output.renderer, pushService, output.thumbnail, adapters.exclusiveService)
+	emailService, err := email.New(config.email)
+	if err != nil && !errors.Is(err, email.ErrNoConfig) {
+		return output, err
+	}
+
+	output.webhook = webhook.New(config.webhook, adapters.storage, clients.telemetry.MeterProvider(), clients.redis, output.renderer, pushService, emailService, output.thumbnail, adapters.exclusiveService)
 
 	output.share, err = share.New(config.share, clients.telemetry.TracerProvider(), adapters.storage, clients.redis, adapters.exclusiveService)
 	if err != nil {
 		return output, err
 	}
 
+	output.album = album.New(config.album, adapters.storage, clients.redis, adapters.exclusiveService)
+	output.inbox = inbox.New(config.inbox, adapters.storage, clients.redis, adapters.exclusiveService)
+	output.live = live.New(config.live, clients.redis)
+
 	output.amqpThumbnail, err = amqphandler.New(config.amqpThumbnail, clients.amqp, clients.telemetry.MeterProvider(), clients.telemetry.TracerProvider(), output.thumbnail.AMQPHandler)
 	if err != nil {
 		return output, err
@@ -95,11 +112,12 @@ func newServices(ctx context.Context, config configuration, clients clients, ada
 
 	searchService := search.New(adapters.filteredStorage, output.thumbnail, output.metadata, adapters.exclusiveService, clients.telemetry.TracerProvider())
 
-	crudService, err := crud.New(config.crud, adapters.storage, adapters.filteredStorage, output.renderer, output.share, output.webhook, output.thumbnail, output.metadata, searchService, pushService, output.eventBus.Push, clients.telemetry.TracerProvider())
+	crudService, err := crud.New(config.crud, adapters.storage, adapters.filteredStorage, output.renderer, output.share, output.webhook, output.album, output.thumbnail, output.metadata, searchService, pushService, output.inbox, output.live, output.eventBus.Push, adapters.exclusiveService, clients.telemetry.TracerProvider())
 	if err != nil {
 		return output, err
 	}
 
+	output.crud = crudService
 	output.sanitizer = sanitizer.New(config.sanitizer, adapters.filteredStorage, adapters.exclusiveService, crudService, output.eventBus.Push)
 
 	var middlewareService provider.Auth
@@ -119,8 +137,12 @@ func (s services) Start(adapters adapters, doneCtx, endCtx context.Context) {
 
 	go s.webhook.Start(endCtx)
 	go s.share.Start(endCtx)
+	go s.album.Start(endCtx)
+	go s.inbox.Start(endCtx)
+	go s.live.Start(endCtx)
+	go s.crud.Start(endCtx)
 
-	go s.eventBus.Start(endCtx, adapters.storage, []provider.Renamer{s.thumbnail.Rename, s.metadata.Rename}, s.share.EventConsumer, s.thumbnail.EventConsumer, s.metadata.EventConsumer, s.webhook.EventConsumer)
+	go s.eventBus.Start(endCtx, adapters.storage, []provider.Renamer{s.thumbnail.Rename, s.metadata.Rename, s.album.Rename}, s.share.EventConsumer, s.album.EventConsumer, s.thumbnail.EventConsumer, s.metadata.EventConsumer, s.webhook.EventConsumer, s.inbox.EventConsumer, s.live.EventConsumer)
 }
 
 func (s services) Close() {
@@ -130,6 +152,10 @@ func (s services) Close() {
 
 	<-s.webhook.Done()
 	<-s.share.Done()
+	<-s.album.Done()
+	<-s.inbox.Done()
+	<-s.live.Done()
+	<-s.crud.Done()
 }
 
 func newLoginService(basicConfig *basicMemory.Config) provider.Auth {
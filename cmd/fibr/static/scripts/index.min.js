@@ -1 +1 @@
-function isWebPCompatible(){const e="UklGRlIAAABXRUJQVlA4WAoAAAASAAAAAAAAAAAAQU5JTQYAAAD/////AABBTk1GJgAAAAAAAAAAAAAAAAAAAGQAAABWUDhMDQAAAC8AAAAQBxAREYiI/gcA";return new Promise((t,n)=>{const s=new Image;s.onload=()=>{s.width>0&&s.height>0?t():n()},s.onerror=n.bind(null,!0),s.src=`data:image/webp;base64,${e}`})}function appendChunk(e,t){const n=new Uint8Array(e.length+t.length);return n.set(e,0),n.set(t,e.length),n}function findIndexEscapeSequence(e,t){let n=0;for(let s=0;s<t.length;s++)if(t[s]===e[n]){if(n++,n===e.length)return s-(e.length-1)}else n!==0&&(n=0);return-1}async function*readChunk(e){const s=[28,23,4,28],o=e.body.getReader();let{value:i,done:a}=await o.read(),t=new Uint8Array(0),n;for(;;){if(a)break;for(t=appendChunk(t,i),n=findIndexEscapeSequence(s,t);n!==-1;)yield t.slice(0,n),t=t.slice(n+s.length),n=findIndexEscapeSequence(s,t);({value:i,done:a}=await o.read())}}function encode(e){const t=[];for(const n of e)t.push(String.fromCharCode(n));return btoa(t.join(""))}async function fetchThumbnail(){let e=document.location.search;e.includes("?")?(e.endsWith("&")||(e+="&"),e+="thumbnail"):e+="?thumbnail";const n=await fetch(e,{credentials:"same-origin"});if(n.status>=400)throw new Error("unable to load thumbnails");let t;typeof lazyLoadThumbnail!="undefined"&&lazyLoadThumbnail&&(t=new IntersectionObserver(async(e)=>{for(const s of e){if(!s.isIntersecting)continue;const n=s.target;if(window.webpHero){const t=await fetch(n.dataset.src,{credentials:"same-origin"}),s=await t.arrayBuffer(),e=new webpHero.WebpMachine;n.src=await e.decode(new Uint8Array(s)),e.clearCache()}else n.src=n.dataset.src;t.unobserve(n)}}));for await(const o of readChunk(n)){const i=o.findIndex(e=>e===44);if(i===-1){console.error("invalid line for thumbnail:",line);continue}const s=document.getElementById(`picture-${String.fromCharCode.apply(null,o.slice(0,i))}`);if(!s)continue;const e=new Image;e.src=`data:image/webp;base64,${encode(o.slice(i+1))}`,e.alt=s.dataset.alt,e.dataset.src=s.dataset.src,e.classList.add("thumbnail","full","block"),replaceContent(s,e),t!==0[0]&&t.observe(e)}}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;const t=new Intl.DateTimeFormat(navigator.language,{dateStyle:"medium",timeStyle:"short"});document.querySelectorAll(".date").forEach(e=>{e.innerHTML=t.format(new Date(e.innerHTML))})}),document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;if(typeof hasThumbnail=="undefined"||!hasThumbnail)return;const t=document.querySelectorAll("[data-thumbnail]");if(!t)return;t.forEach(e=>{replaceContent(e,generateThrobber(["throbber-white"]))});try{await isWebPCompatible()}catch{await resolveScript("https://unpkg.com/webp-hero@0.0.2/dist-cjs/webp-hero.bundle.js","sha512-DA6h9H5Sqn55/uVn4JI4aSPFnAWoCQYYDXUnvjOAMNVx11///hX4QaFbQt5yWsrIm9hSI5fLJYfRWt3KXneSXQ==","anonymous")}try{await fetchThumbnail()}catch(e){console.error(e)}if(window.webpHero){const e=new webpHero.WebpMachine;e.polyfillDocument(),e.clearCache()}},!1),document.addEventListener("readystatechange",e=>{if(e.target.readyState!=="complete")return;document.querySelectorAll("[data-confirm]").forEach(e=>{e.addEventListener("click",t=>{confirm(`Are you sure you want to delete ${e.dataset.confirm}?`)||t.preventDefault()})})}),document.addEventListener("readystatechange",e=>{if(e.target.readyState!=="complete")return;const t=document.getElementById("go-back");t&&(t.setAttribute("href",document.referrer),t.addEventListener("click",e=>(e.preventDefault(),window.addEventListener("popstate",()=>{window.location.reload(!0)}),history.back(),!1)))});async function fetchGeoJSON(e){const t=await fetch(e,{credentials:"same-origin"});if(t.status>=400)throw new Error("unable to load geojson");return t.status===204?null:t.json()}async function addStyle(e,t,n){return new Promise(s=>{const o=document.createElement("link");o.rel="stylesheet",o.href=e,o.onload=s,t&&(o.integrity=t,o.crossOrigin=n),document.querySelector("head").appendChild(o)})}async function loadLeaflet(){const e="1.9.4";await addStyle(`https://unpkg.com/leaflet@${e}/dist/leaflet.css`,"sha512-Zcn6bjR/8RZbLEpLIeOwNtzREBAJnUKESxces60Mpoj+2okopSAcSUIUOseddDm0cxnGQzxIR7vJgsLZbdLE3w==","anonymous"),await addStyle("https://unpkg.com/leaflet.markercluster@1.5.1/dist/MarkerCluster.Default.css","sha512-6ZCLMiYwTeli2rVh3XAPxy3YoR5fVxGdH/pz+KMCzRY2M65Emgkw00Yqmhh8qLGeYQ3LbVZGdmOX9KUjSKr0TA==","anonymous"),await resolveScript(`https://unpkg.com/leaflet@${e}/dist/leaflet.js`,"sha512-BwHfrr4c9kmRkLw6iXFdzcdWV/PGkVgiIyIWLLlTSXzWQzxuSg4DiQUCpauz/EWjgk5TYQqX/kvn9pG1NpYfqg==","anonymous"),await resolveScript("https://unpkg.com/leaflet.markercluster@1.5.1/dist/leaflet.markercluster.js","sha512-+Zr0llcuE/Ho6wXRYtlWypMyWSEMxrWJxrYgeAMDRSf1FF46gQ3PAVOVp5RHdxdzikZXuHZ0soHpqRkkPkI3KA==","anonymous")}let map;async function renderMap(e){if(map){map.invalidateSize();return}const t=document.getElementById("map-container"),n=generateThrobber(["map-throbber","throbber-white"]);t&&t.appendChild(n),await loadLeaflet(),map=L.map("map-container",{center:[46.227638,2.213749],zoom:5}),L.tileLayer("https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png",{maxZoom:19,attribution:'&copy; <a href="https://openstreetmap.org/copyright">OpenStreetMap contributors</a>'}).addTo(map);const s=await fetchGeoJSON(e);if(!s||!s.features)return;const o=L.markerClusterGroup({zoomToBoundsOnClick:!1}),i=[];s.features.map(e=>{const t=L.GeoJSON.coordsToLatLng(e.geometry.coordinates);i.push(t),o.addLayer(L.circleMarker(t).bindPopup(`<a href="${e.properties.url}?browser"><img src="${e.properties.url}?thumbnail" alt="Image thumbnail" class="thumbnail-img"></a><br><span>${e.properties.date}</span>`,{maxWidth:"auto",closeButton:!1,className:"thumbnail-popup"}))}),o.on("clusterclick",e=>{map.fitBounds(e.layer.getAllChildMarkers().map(e=>e.getLatLng()))}),i.length?(map.once("zoomend",()=>{t.removeChild(n)}),map.fitBounds(i)):t.removeChild(n),map.addLayer(o)}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;document.location.hash==="#map"?await renderMap(geoURL):window.addEventListener("popstate",async()=>{document.location.hash==="#map"&&await renderMap(geoURL)})});function resolveScript(e,t,n,s="text/javascript"){return new Promise((o,i)=>{const a=document.createElement("script");a.type=s,a.src=e,a.async=!0,a.onload=o.bind(null,!0),a.onerror=i.bind(null,!0),t&&(a.integrity=t,a.crossOrigin=n),document.querySelector("head").appendChild(a)})}window.onkeyup=e=>{switch(e.key){case"ArrowLeft":goToPrevious();break;case"ArrowRight":goToNext();break;case"Escape":goBack(e);break}};function goBack(e){const t=document.location.hash;if(t){if(typeof abort=="function"&&typeof aborter!="undefined"){abort(e);return}document.location.hash="",/success$/gim.test(t)&&window.location.reload(!0);return}if(typeof parentPage=="undefined")return;window.location.href=parentPage}function goToPrevious(){if(typeof previousFile=="undefined")return;window.location.href=previousFile}function goToNext(){if(typeof nextFile=="undefined")return;window.location.href=nextFile}function replaceContent(e,t){for(;e.firstChild;)e.removeChild(e.firstChild);t&&e.appendChild(t)}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;const t=document.getElementById("push-form-form");if(!t)return;const n=t.querySelector("button.bg-primary"),_=document.getElementById("push-url"),i=document.getElementById("push-form-button"),y=document.getElementById("push-form-method"),j=document.getElementById("push-form-id"),b=document.getElementById("worker-register"),s=document.getElementById("worker-register-wrapper");function l(e,t){const n=t.getKey?t.getKey(e):"";return n?btoa(String.fromCharCode.apply(null,new Uint8Array(n))):""}function u(e){for(var o="=".repeat((4-e.length%4)%4),i=(e+o).replace(/-/g,"+").replace(/_/g,"/"),n=window.atob(i),s=new Uint8Array(n.length),t=0;t<n.length;++t)s[t]=n.charCodeAt(t);return s}async function d(e){return l("p256dh",e)}async function h(e){return l("auth",e)}async function m(e){const n=await d(e),s=await h(e),t=await fetch("?push",{method:"POST",credentials:"same-origin",headers:{"Content-Type":"application/json"},body:JSON.stringify({endpoint:e.endpoint,publicKey:n,auth:s})});if(t.status>=400){const e=await t.text();throw new Error(`unable to register push: ${e}`)}}async function f(e,t){const s=await fetch(`?push&endpoint=${encodeURIComponent(t)}`,{method:"GET",credentials:"same-origin"});if(s.status>=400){const e=await s.text();throw new Error(`unable to register push: ${e}`)}const a=await s.json();a.id?(y.value="DELETE",j.value=a.id,n.innerHTML="Unsubscribe",i.querySelector("img").src="/svg/push-ring?fill=limegreen"):a.registered||(await e.unregister(),o())}async function p(){navigator.serviceWorker.register("/service-worker.js");const t=await c();let e=await r(t);return e||(e=await t.pushManager.subscribe({userVisibleOnly:!0,applicationServerKey:u(vapidKey)})),m(e),e}function g(){return!!vapidKey&&"serviceWorker"in navigator&&(!/iphone|ipad/i.test(navigator.userAgent)||window.navigator.standalone===!0)}async function v(){if(!navigator.serviceWorker.controller)return null;const e=generateThrobber(["throbber-white","padding"]);t.insertBefore(e,s);const n=new Promise(e=>{setTimeout(()=>{e(null)},3e3)}),o=await Promise.race([c(),n]);return t.removeChild(e),o}async function c(){const e=await navigator.serviceWorker.ready;return await e.update()}async function r(e){if(e)return await e.pushManager.getSubscription()}function a(e){_.value=e.endpoint,n.disabled=!1}function o(){s.classList.remove("hidden"),b.addEventListener("click",async()=>{a(await p()),s.classList.add("hidden")})}if(g()){i.classList.remove("hidden"),n.disabled=!0;const t=await v(),e=await r(t);e&&e.endpoint?(a(e),f(t,e.endpoint)):o()}});function generateThrobber(e=[]){const t=document.createElement("div");t.classList.add("throbber"),e.forEach(e=>t.classList.add(e));for(let e=1;e<4;e++){const n=document.createElement("div");n.classList.add("throbber-dot",`throbber-dot-${e}`),t.appendChild(n)}return t}let fileInput,uploadList,cancelButton;function eventNoop(e){e.preventDefault(),e.stopPropagation()}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;const t=document.getElementsByTagName("body")[0];t.addEventListener("dragover",eventNoop),t.addEventListener("dragleave",eventNoop),t.addEventListener("drop",e=>{eventNoop(e),window.location.hash="#upload-modal",fileInput&&(fileInput.files=e.dataTransfer.files,fileInput.dispatchEvent(new Event("change")))})});function bufferToHex(e){return Array.prototype.map.call(new Uint8Array(e),e=>`00${e.toString(16)}`.slice(-2)).join("")}async function sha(e){const t=await crypto.subtle.digest("SHA-256",new TextEncoder("utf-8").encode(e));return bufferToHex(t)}async function fileMessageId(e){const t=await sha(JSON.stringify({name:e.name,size:e.size,type:e.type,lastModified:e.lastModified}));return`upload-file-${t}`}function humanFileSize(e){return e<1024?e+"bytes":e<1048576?(e/1024).toFixed(0)+" KB":(e/1048576).toFixed(0)+" MB"}async function addUploadItem(e,t){const c=await fileMessageId(t),n=document.createElement("div");n.id=c,n.classList.add("flex","flex-center","margin","align-baseline");const o=document.createElement("div");o.classList.add("upload-item"),n.appendChild(o);const s=document.createElement("input");s.id=`${c}-filename`,s.classList.add("upload-name","full"),s.type="text",s.value=t.name,o.appendChild(s);const i=document.createElement("div");i.classList.add("full","flex","flex-center");const r=document.createElement("em");r.innerHTML=humanFileSize(t.size),r.style.width="8rem",i.appendChild(r);const a=document.createElement("progress");a.classList.add("flex-grow","margin-left"),a.max=100,a.value=0,i.appendChild(a),o.appendChild(i);const l=document.createElement("span");l.classList.add("upload-status"),n.appendChild(l),e.appendChild(n)}function getFiles(e){return[].filter.call(e.target,e=>e.nodeName.toLowerCase()==="input").reduce((e,t)=>(t.type==="file"?e.files=t.files:e[t.name]=t.value,e),{})}function getFilename(e,t){const n=document.getElementById(`${e}-filename`);return n&&n.value?n.value:t.name}function clearUploadStatus(e){if(!e)return;const t=e.querySelector(".upload-status");if(!t)return;t.classList.remove("danger"),t.classList.remove("success")}async function setUploadStatus(e,t,n,s,o){if(!e)return;const i=e.querySelector(".upload-status");if(!i)return;if(i.innerHTML=n,i.classList.add(s),o&&(i.title=o,String(o).includes("file already exists")&&!e.querySelector("#overwrite-"+t))){const r=e.querySelector(".upload-item");if(!r)return;const n=document.createElement("p");r.appendChild(n),n.classList.add("flex","no-margin");const o=document.createElement("input");n.appendChild(o),o.id="overwrite-"+t,o.type="checkbox",o.name="overwrite";const i=document.createElement("label");n.appendChild(i),i.htmlFor="overwrite-"+t,i.innerHTML="Overwrite";const s=document.createElement("input");n.appendChild(s),s.id="skip-"+t,s.type="checkbox",s.name="skip",s.classList.add("margin-left");const a=document.createElement("label");n.appendChild(a),a.htmlFor="skip-"+t,a.innerHTML="Skip"}}let uploadFile,aborter;const chunkSize=2*1024*1024;let currentUpload={};async function uploadFileByChunks(e,t,n,s,o){let a;if(e&&(a=e.querySelector("progress"),clearUploadStatus(e)),s.name!==currentUpload.filename){currentUpload.filename=s.name,currentUpload.chunks=[];for(let e=0;e<s.size;e+=chunkSize)currentUpload.chunks.push({content:s.slice(e,e+chunkSize),done:!1})}for(let e=0;e<currentUpload.chunks.length;e++){if(currentUpload.chunks[e].done)continue;typeof AbortController!="undefined"&&(aborter=new AbortController);const i=new FormData;i.append("method",t),i.append("overwrite",o),i.append("file",currentUpload.chunks[e].content,n);const r=await fetch("",{method:"POST",credentials:"same-origin",signal:aborter.signal,headers:{"X-Chunk-Upload":!0,"X-Chunk-Number":e+1,Accept:"text/plain"},body:i});if(r.status>=400)return Promise.reject(await r.text());currentUpload.chunks[e].done=!0,a&&(a.value=chunkSize*(e+1)/s.size*100)}const i=new FormData;i.append("method",t),i.append("filename",n),i.append("overwrite",o),i.append("size",s.size);const r=await fetch("",{method:"POST",credentials:"same-origin",headers:{"X-Chunk-Upload":!0,Accept:"text/plain"},body:i}),c=await r.text();return r.status>=400?Promise.reject(c):(currentUpload={},Promise.resolve(c))}async function uploadFileByXHR(e,t,n,s,o){let i;e&&(i=e.querySelector("progress"),clearUploadStatus(e));const a=new FormData;return a.append("method",t),a.append("size",s.size),a.append("overwrite",o),a.append("file",s,n),new Promise((e,t)=>{let n=new XMLHttpRequest;aborter=n,i&&n.upload.addEventListener("progress",e=>i.value=parseInt(e.loaded/e.total*100,10),!1),n.addEventListener("readystatechange",s=>{if(n.readyState===XMLHttpRequest.UNSENT){t(new Error("request aborted")),n=0[0];return}if(n.readyState!==XMLHttpRequest.DONE)return;n.status>=200&&n.status<400?(i&&(i.value=100),e(n.responseText),n=0[0]):(t(s),n=0[0])},!1),n.open("POST","",!0),n.setRequestHeader("Accept","text/plain"),n.send(a)})}function sliceFileList(e,t){const n=document.getElementById(e),s=new DataTransfer;for(;t<n.files.length;t++)s.items.add(n.files[t]);n.files=s.files}async function upload(e){e.preventDefault();const t=document.getElementById("upload-button");t&&(t.disabled=!0,replaceContent(t,generateThrobber(["throbber-white"]))),cancelButton&&(cancelButton.innerHTML="Cancel");const n=getFiles(e);let s=!0;for(let o=0;o<n.files.length;o++){const i=n.files[o],e=await fileMessageId(i),a=document.getElementById(e);try{const t=getFilename(e,i),s=document.getElementById("overwrite-"+e)?.checked;document.getElementById("skip-"+e)?.checked||(await uploadFile(a,n.method,t,i,s),await setUploadStatus(a,e,"✓","success"))}catch(n){sliceFileList("file",o),t&&(t.disabled=!1,t.innerHTML="Retry"),await setUploadStatus(a,e,"X","danger",n),s=!1,console.error(n);break}finally{aborter=0[0]}}return s?document.location.hash="#upload-success":cancelButton&&(cancelButton.innerHTML="Close"),!1}function abort(e){return e.preventDefault(),aborter&&(aborter.abort(),aborter=0[0],cancelButton&&(cancelButton.innerHTML="Close")),!1}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;if(typeof chunkUpload!="undefined"&&chunkUpload?uploadFile=uploadFileByChunks:uploadFile=uploadFileByXHR,fileInput=document.getElementById("file"),uploadList=document.getElementById("upload-list"),cancelButton=document.getElementById("upload-cancel"),fileInput){fileInput.classList.add("opacity"),fileInput.multiple=!0,fileInput.addEventListener("change",()=>{window.location.hash="#upload-modal",replaceContent(uploadList);for(const e of fileInput.files)addUploadItem(uploadList,e)});const e=document.getElementById("upload-button-link");e&&e.addEventListener("click",e=>{eventNoop(e),fileInput.click()})}const t=document.getElementById("file-label");t&&(t.classList.remove("hidden"),t.innerHTML="Choose files..."),uploadList&&uploadList.classList.remove("hidden"),cancelButton&&cancelButton.addEventListener("click",goBack);const n=document.getElementById("upload-form");n&&n.addEventListener("submit",upload)}),document.addEventListener("readystatechange",e=>{if(e.target.readyState!=="complete")return;const t=document.getElementById("webhook-url-label");if(!t)return;const n=document.getElementById("webhook-url-wrapper"),s=document.getElementById("webhook-url"),o=document.getElementById("telegram-chat-id");document.getElementById("webhook-kind-raw").addEventListener("change",e=>{e.target.value==="raw"&&(s.placeholder="https://website.com/fibr",t.innerHTML="URL",n.classList.remove("hidden"),o.classList.add("hidden"))}),document.getElementById("webhook-kind-discord").addEventListener("change",e=>{e.target.value==="discord"&&(s.placeholder="https://discord.com/api/webhooks/...",t.innerHTML="URL",n.classList.remove("hidden"),o.classList.add("hidden"))}),document.getElementById("webhook-kind-slack").addEventListener("change",e=>{e.target.value==="slack"&&(s.placeholder="https://hooks.slack.com/services/...",t.innerHTML="URL",n.classList.remove("hidden"),o.classList.add("hidden"))}),document.getElementById("webhook-kind-telegram").addEventListener("change",e=>{e.target.value==="telegram"&&(t.innerHTML="Token",s.placeholder="Bot token",n.classList.remove("hidden"),o.classList.remove("hidden"))})})
\ No newline at end of file
+function isWebPCompatible(){const e="UklGRlIAAABXRUJQVlA4WAoAAAASAAAAAAAAAAAAQU5JTQYAAAD/////AABBTk1GJgAAAAAAAAAAAAAAAAAAAGQAAABWUDhMDQAAAC8AAAAQBxAREYiI/gcA";return new Promise((t,n)=>{const s=new Image;s.onload=()=>{s.width>0&&s.height>0?t():n()},s.onerror=n.bind(null,!0),s.src=`data:image/webp;base64,${e}`})}function appendChunk(e,t){const n=new Uint8Array(e.length+t.length);return n.set(e,0),n.set(t,e.length),n}function findIndexEscapeSequence(e,t){let n=0;for(let s=0;s<t.length;s++)if(t[s]===e[n]){if(n++,n===e.length)return s-(e.length-1)}else n!==0&&(n=0);return-1}async function*readChunk(e){const s=[28,23,4,28],o=e.body.getReader();let{value:i,done:a}=await o.read(),t=new Uint8Array(0),n;for(;;){if(a)break;for(t=appendChunk(t,i),n=findIndexEscapeSequence(s,t);n!==-1;)yield t.slice(0,n),t=t.slice(n+s.length),n=findIndexEscapeSequence(s,t);({value:i,done:a}=await o.read())}}function encode(e){const t=[];for(const n of e)t.push(String.fromCharCode(n));return btoa(t.join(""))}async function fetchThumbnail(){let e=document.location.search;e.includes("?")?(e.endsWith("&")||(e+="&"),e+="thumbnail"):e+="?thumbnail";const n=await fetch(e,{credentials:"same-origin"});if(n.status>=400)throw new Error("unable to load thumbnails");let t;typeof lazyLoadThumbnail!="undefined"&&lazyLoadThumbnail&&(t=new IntersectionObserver(async(e)=>{for(const s of e){if(!s.isIntersecting)continue;const n=s.target;if(window.webpHero){const t=await fetch(n.dataset.src,{credentials:"same-origin"}),s=await t.arrayBuffer(),e=new webpHero.WebpMachine;n.src=await e.decode(new Uint8Array(s)),e.clearCache()}else n.src=n.dataset.src;t.unobserve(n)}}));for await(const o of readChunk(n)){const i=o.findIndex(e=>e===44);if(i===-1){console.error("invalid line for thumbnail:",line);continue}const s=document.getElementById(`picture-${String.fromCharCode.apply(null,o.slice(0,i))}`);if(!s)continue;const e=new Image;e.src=`data:image/webp;base64,${encode(o.slice(i+1))}`,e.alt=s.dataset.alt,e.dataset.src=s.dataset.src,e.classList.add("thumbnail","full","block"),replaceContent(s,e),t!==0[0]&&t.observe(e)}}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;const t=new Intl.DateTimeFormat(navigator.language,{dateStyle:"medium",timeStyle:"short"});document.querySelectorAll(".date").forEach(e=>{e.innerHTML=t.format(new Date(e.innerHTML))})}),document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;if(typeof hasThumbnail=="undefined"||!hasThumbnail)return;const t=document.querySelectorAll("[data-thumbnail]");if(!t)return;t.forEach(e=>{replaceContent(e,generateThrobber(["throbber-white"]))});try{await isWebPCompatible()}catch{await resolveScript("https://unpkg.com/webp-hero@0.0.2/dist-cjs/webp-hero.bundle.js","sha512-DA6h9H5Sqn55/uVn4JI4aSPFnAWoCQYYDXUnvjOAMNVx11///hX4QaFbQt5yWsrIm9hSI5fLJYfRWt3KXneSXQ==","anonymous")}try{await fetchThumbnail()}catch(e){console.error(e)}if(window.webpHero){const e=new webpHero.WebpMachine;e.polyfillDocument(),e.clearCache()}},!1),document.addEventListener("readystatechange",e=>{if(e.target.readyState!=="complete")return;document.querySelectorAll("[data-confirm]").forEach(e=>{e.addEventListener("click",t=>{confirm(`Are you sure you want to delete ${e.dataset.confirm}?`)||t.preventDefault()})})}),document.addEventListener("readystatechange",e=>{if(e.target.readyState!=="complete")return;const t=document.getElementById("go-back");t&&(t.setAttribute("href",document.referrer),t.addEventListener("click",e=>(e.preventDefault(),window.addEventListener("popstate",()=>{window.location.reload(!0)}),history.back(),!1)))});async function fetchGeoJSON(e){const t=await fetch(e,{credentials:"same-origin"});if(t.status>=400)throw new Error("unable to load geojson");return t.status===204?null:t.json()}async function addStyle(e,t,n){return new Promise(s=>{const o=document.createElement("link");o.rel="stylesheet",o.href=e,o.onload=s,t&&(o.integrity=t,o.crossOrigin=n),document.querySelector("head").appendChild(o)})}async function loadLeaflet(){const e="1.9.4";await addStyle(`https://unpkg.com/leaflet@${e}/dist/leaflet.css`,"sha512-Zcn6bjR/8RZbLEpLIeOwNtzREBAJnUKESxces60Mpoj+2okopSAcSUIUOseddDm0cxnGQzxIR7vJgsLZbdLE3w==","anonymous"),await addStyle("https://unpkg.com/leaflet.markercluster@1.5.1/dist/MarkerCluster.Default.css","sha512-6ZCLMiYwTeli2rVh3XAPxy3YoR5fVxGdH/pz+KMCzRY2M65Emgkw00Yqmhh8qLGeYQ3LbVZGdmOX9KUjSKr0TA==","anonymous"),await resolveScript(`https://unpkg.com/leaflet@${e}/dist/leaflet.js`,"sha512-BwHfrr4c9kmRkLw6iXFdzcdWV/PGkVgiIyIWLLlTSXzWQzxuSg4DiQUCpauz/EWjgk5TYQqX/kvn9pG1NpYfqg==","anonymous"),await resolveScript("https://unpkg.com/leaflet.markercluster@1.5.1/dist/leaflet.markercluster.js","sha512-+Zr0llcuE/Ho6wXRYtlWypMyWSEMxrWJxrYgeAMDRSf1FF46gQ3PAVOVp5RHdxdzikZXuHZ0soHpqRkkPkI3KA==","anonymous")}let map;async function renderMap(e){if(map){map.invalidateSize();return}const t=document.getElementById("map-container"),n=generateThrobber(["map-throbber","throbber-white"]);t&&t.appendChild(n),await loadLeaflet(),map=L.map("map-container",{center:[46.227638,2.213749],zoom:5}),L.tileLayer("https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png",{maxZoom:19,attribution:'&copy; <a href="https://openstreetmap.org/copyright">OpenStreetMap contributors</a>'}).addTo(map),document.dispatchEvent(new CustomEvent("map-ready",{detail:map}));const s=await fetchGeoJSON(e);if(!s||!s.features){t.removeChild(n);return}const o=L.markerClusterGroup({zoomToBoundsOnClick:!1}),i=[];s.features.map(e=>{if(e.geometry.type==="LineString"){const r=L.GeoJSON.coordsToLatLngs(e.geometry.coordinates),a=document.createElement("a");a.href=`${e.properties.url}?browser`,a.textContent=e.properties.name,i.push(...r),L.polyline(r,{color:"crimson",weight:3}).bindPopup(a).addTo(map);return}const t=L.GeoJSON.coordsToLatLng(e.geometry.coordinates);i.push(t),o.addLayer(L.circleMarker(t).bindPopup(`<a href="${e.properties.url}?browser"><img src="${e.properties.url}?thumbnail" alt="Image thumbnail" class="thumbnail-img"></a><br><span>${e.properties.date}</span>`,{maxWidth:"auto",closeButton:!1,className:"thumbnail-popup"}))}),o.on("clusterclick",e=>{map.fitBounds(e.layer.getAllChildMarkers().map(e=>e.getLatLng()))}),i.length?(map.once("zoomend",()=>{t.removeChild(n)}),map.fitBounds(i)):t.removeChild(n),map.addLayer(o)}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;document.location.hash==="#map"?await renderMap(geoURL):window.addEventListener("popstate",async()=>{document.location.hash==="#map"&&await renderMap(geoURL)})});function resolveScript(e,t,n,s="text/javascript"){return new Promise((o,i)=>{const a=document.createElement("script");a.type=s,a.src=e,a.async=!0,a.onload=o.bind(null,!0),a.onerror=i.bind(null,!0),t&&(a.integrity=t,a.crossOrigin=n),document.querySelector("head").appendChild(a)})}window.onkeyup=e=>{switch(e.key){case"ArrowLeft":goToPrevious();break;case"ArrowRight":goToNext();break;case"Escape":goBack(e);break}};function goBack(e){const t=document.location.hash;if(t){if(typeof abort=="function"&&typeof aborter!="undefined"){abort(e);return}document.location.hash="",/success$/gim.test(t)&&window.location.reload(!0);return}if(typeof parentPage=="undefined")return;window.location.href=parentPage}function goToPrevious(){if(typeof previousFile=="undefined")return;window.location.href=previousFile}function goToNext(){if(typeof nextFile=="undefined")return;window.location.href=nextFile}function replaceContent(e,t){for(;e.firstChild;)e.removeChild(e.firstChild);t&&e.appendChild(t)}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;const t=document.getElementById("push-form-form");if(!t)return;const n=t.querySelector("button.bg-primary"),_=document.getElementById("push-url"),i=document.getElementById("push-form-button"),y=document.getElementById("push-form-method"),j=document.getElementById("push-form-id"),b=document.getElementById("worker-register"),s=document.getElementById("worker-register-wrapper");function l(e,t){const n=t.getKey?t.getKey(e):"";return n?btoa(String.fromCharCode.apply(null,new Uint8Array(n))):""}function u(e){for(var o="=".repeat((4-e.length%4)%4),i=(e+o).replace(/-/g,"+").replace(/_/g,"/"),n=window.atob(i),s=new Uint8Array(n.length),t=0;t<n.length;++t)s[t]=n.charCodeAt(t);return s}async function d(e){return l("p256dh",e)}async function h(e){return l("auth",e)}async function m(e){const n=await d(e),s=await h(e),t=await fetch("?push",{method:"POST",credentials:"same-origin",headers:{"Content-Type":"application/json"},body:JSON.stringify({endpoint:e.endpoint,publicKey:n,auth:s})});if(t.status>=400){const e=await t.text();throw new Error(`unable to register push: ${e}`)}}async function f(e,t){const s=await fetch(`?push&endpoint=${encodeURIComponent(t)}`,{method:"GET",credentials:"same-origin"});if(s.status>=400){const e=await s.text();throw new Error(`unable to register push: ${e}`)}const a=await s.json();a.id?(y.value="DELETE",j.value=a.id,n.innerHTML="Unsubscribe",i.querySelector("img").src="/svg/push-ring?fill=limegreen"):a.registered||(await e.unregister(),o())}async function p(){navigator.serviceWorker.register("/service-worker.js");const t=await c();let e=await r(t);return e||(e=await t.pushManager.subscribe({userVisibleOnly:!0,applicationServerKey:u(vapidKey)})),m(e),e}function g(){return!!vapidKey&&"serviceWorker"in navigator&&(!/iphone|ipad/i.test(navigator.userAgent)||window.navigator.standalone===!0)}async function v(){if(!navigator.serviceWorker.controller)return null;const e=generateThrobber(["throbber-white","padding"]);t.insertBefore(e,s);const n=new Promise(e=>{setTimeout(()=>{e(null)},3e3)}),o=await Promise.race([c(),n]);return t.removeChild(e),o}async function c(){const e=await navigator.serviceWorker.ready;return await e.update()}async function r(e){if(e)return await e.pushManager.getSubscription()}function a(e){_.value=e.endpoint,n.disabled=!1}function o(){s.classList.remove("hidden"),b.addEventListener("click",async()=>{a(await p()),s.classList.add("hidden")})}if(g()){i.classList.remove("hidden"),n.disabled=!0;const t=await v(),e=await r(t);e&&e.endpoint?(a(e),f(t,e.endpoint)):o()}});function generateThrobber(e=[]){const t=document.createElement("div");t.classList.add("throbber"),e.forEach(e=>t.classList.add(e));for(let e=1;e<4;e++){const n=document.createElement("div");n.classList.add("throbber-dot",`throbber-dot-${e}`),t.appendChild(n)}return t}let fileInput,uploadList,cancelButton;function eventNoop(e){e.preventDefault(),e.stopPropagation()}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;const t=document.getElementsByTagName("body")[0];t.addEventListener("dragover",eventNoop),t.addEventListener("dragleave",eventNoop),t.addEventListener("drop",e=>{eventNoop(e),window.location.hash="#upload-modal",fileInput&&(fileInput.files=e.dataTransfer.files,fileInput.dispatchEvent(new Event("change")))})});function bufferToHex(e){return Array.prototype.map.call(new Uint8Array(e),e=>`00${e.toString(16)}`.slice(-2)).join("")}async function sha(e){const t=await crypto.subtle.digest("SHA-256",new TextEncoder("utf-8").encode(e));return bufferToHex(t)}async function fileMessageId(e){const t=await sha(JSON.stringify({name:e.name,size:e.size,type:e.type,lastModified:e.lastModified}));return`upload-file-${t}`}function humanFileSize(e){return e<1024?e+"bytes":e<1048576?(e/1024).toFixed(0)+" KB":(e/1048576).toFixed(0)+" MB"}async function addUploadItem(e,t){const c=await fileMessageId(t),n=document.createElement("div");n.id=c,n.classList.add("flex","flex-center","margin","align-baseline");const o=document.createElement("div");o.classList.add("upload-item"),n.appendChild(o);const s=document.createElement("input");s.id=`${c}-filename`,s.classList.add("upload-name","full"),s.type="text",s.value=t.name,o.appendChild(s);const i=document.createElement("div");i.classList.add("full","flex","flex-center");const r=document.createElement("em");r.innerHTML=humanFileSize(t.size),r.style.width="8rem",i.appendChild(r);const a=document.createElement("progress");a.classList.add("flex-grow","margin-left"),a.max=100,a.value=0,i.appendChild(a),o.appendChild(i);const l=document.createElement("span");l.classList.add("upload-status"),n.appendChild(l),e.appendChild(n)}function getFiles(e){return[].filter.call(e.target,e=>e.nodeName.toLowerCase()==="input").reduce((e,t)=>(t.type==="file"?e.files=t.files:e[t.name]=t.value,e),{})}function getFilename(e,t){const n=document.getElementById(`${e}-filename`);return n&&n.value?n.value:t.name}function clearUploadStatus(e){if(!e)return;const t=e.querySelector(".upload-status");if(!t)return;t.classList.remove("danger"),t.classList.remove("success")}async function setUploadStatus(e,t,n,s,o){if(!e)return;const i=e.querySelector(".upload-status");if(!i)return;if(i.innerHTML=n,i.classList.add(s),o&&(i.title=o,String(o).includes("file already exists")&&!e.querySelector("#overwrite-"+t))){const r=e.querySelector(".upload-item");if(!r)return;const n=document.createElement("p");r.appendChild(n),n.classList.add("flex","no-margin");const o=document.createElement("input");n.appendChild(o),o.id="overwrite-"+t,o.type="checkbox",o.name="overwrite";const i=document.createElement("label");n.appendChild(i),i.htmlFor="overwrite-"+t,i.innerHTML="Overwrite";const s=document.createElement("input");n.appendChild(s),s.id="skip-"+t,s.type="checkbox",s.name="skip",s.classList.add("margin-left");const a=document.createElement("label");n.appendChild(a),a.htmlFor="skip-"+t,a.innerHTML="Skip"}}let uploadFile,aborter;const chunkSize=2*1024*1024;let currentUpload={};async function uploadFileByChunks(e,t,n,s,o){let a;if(e&&(a=e.querySelector("progress"),clearUploadStatus(e)),s.name!==currentUpload.filename){currentUpload.filename=s.name,currentUpload.chunks=[];for(let e=0;e<s.size;e+=chunkSize)currentUpload.chunks.push({content:s.slice(e,e+chunkSize),done:!1})}for(let e=0;e<currentUpload.chunks.length;e++){if(currentUpload.chunks[e].done)continue;typeof AbortController!="undefined"&&(aborter=new AbortController);const i=new FormData;i.append("method",t),i.append("overwrite",o),i.append("file",currentUpload.chunks[e].content,n);const r=await fetch("",{method:"POST",credentials:"same-origin",signal:aborter.signal,headers:{"X-Chunk-Upload":!0,"X-Chunk-Number":e+1,Accept:"text/plain"},body:i});if(r.status>=400)return Promise.reject(await r.text());currentUpload.chunks[e].done=!0,a&&(a.value=chunkSize*(e+1)/s.size*100)}const i=new FormData;i.append("method",t),i.append("filename",n),i.append("overwrite",o),i.append("size",s.size);const r=await fetch("",{method:"POST",credentials:"same-origin",headers:{"X-Chunk-Upload":!0,Accept:"text/plain"},body:i}),c=await r.text();return r.status>=400?Promise.reject(c):(currentUpload={},Promise.resolve(c))}async function uploadFileByXHR(e,t,n,s,o){let i;e&&(i=e.querySelector("progress"),clearUploadStatus(e));const a=new FormData;return a.append("method",t),a.append("size",s.size),a.append("overwrite",o),a.append("file",s,n),new Promise((e,t)=>{let n=new XMLHttpRequest;aborter=n,i&&n.upload.addEventListener("progress",e=>i.value=parseInt(e.loaded/e.total*100,10),!1),n.addEventListener("readystatechange",s=>{if(n.readyState===XMLHttpRequest.UNSENT){t(new Error("request aborted")),n=0[0];return}if(n.readyState!==XMLHttpRequest.DONE)return;n.status>=200&&n.status<400?(i&&(i.value=100),e(n.responseText),n=0[0]):(t(s),n=0[0])},!1),n.open("POST","",!0),n.setRequestHeader("Accept","text/plain"),n.send(a)})}function sliceFileList(e,t){const n=document.getElementById(e),s=new DataTransfer;for(;t<n.files.length;t++)s.items.add(n.files[t]);n.files=s.files}async function upload(e){e.preventDefault();const t=document.getElementById("upload-button");t&&(t.disabled=!0,replaceContent(t,generateThrobber(["throbber-white"]))),cancelButton&&(cancelButton.innerHTML="Cancel");const n=getFiles(e);let s=!0;for(let o=0;o<n.files.length;o++){const i=n.files[o],e=await fileMessageId(i),a=document.getElementById(e);try{const t=getFilename(e,i),s=document.getElementById("overwrite-"+e)?.checked;document.getElementById("skip-"+e)?.checked||(await uploadFile(a,n.method,t,i,s),await setUploadStatus(a,e,"✓","success"))}catch(n){sliceFileList("file",o),t&&(t.disabled=!1,t.innerHTML="Retry"),await setUploadStatus(a,e,"X","danger",n),s=!1,console.error(n);break}finally{aborter=0[0]}}return s?document.location.hash="#upload-success":cancelButton&&(cancelButton.innerHTML="Close"),!1}function abort(e){return e.preventDefault(),aborter&&(aborter.abort(),aborter=0[0],cancelButton&&(cancelButton.innerHTML="Close")),!1}document.addEventListener("readystatechange",async e=>{if(e.target.readyState!=="complete")return;if(typeof chunkUpload!="undefined"&&chunkUpload?uploadFile=uploadFileByChunks:uploadFile=uploadFileByXHR,fileInput=document.getElementById("file"),uploadList=document.getElementById("upload-list"),cancelButton=document.getElementById("upload-cancel"),fileInput){fileInput.classList.add("opacity"),fileInput.multiple=!0,fileInput.addEventListener("change",()=>{window.location.hash="#upload-modal",replaceContent(uploadList);for(const e of fileInput.files)addUploadItem(uploadList,e)});const e=document.getElementById("upload-button-link");e&&e.addEventListener("click",e=>{eventNoop(e),fileInput.click()})}const t=document.getElementById("file-label");t&&(t.classList.remove("hidden"),t.innerHTML="Choose files..."),uploadList&&uploadList.classList.remove("hidden"),cancelButton&&cancelButton.addEventListener("click",goBack);const n=document.getElementById("upload-form");n&&n.addEventListener("submit",upload)}),document.addEventListener("readystatechange",e=>{if(e.target.readyState!=="complete")return;const t=document.getElementById("webhook-url-label");if(!t)return;const n=document.getElementById("webhook-url-wrapper"),s=document.getElementById("webhook-url"),o=document.getElementById("telegram-chat-id");document.getElementById("webhook-kind-raw").addEventListener("change",e=>{e.target.value==="raw"&&(s.placeholder="https://website.com/fibr",t.innerHTML="URL",n.classList.remove("hidden"),o.classList.add("hidden"))}),document.getElementById("webhook-kind-discord").addEventListener("change",e=>{e.target.value==="discord"&&(s.placeholder="https://discord.com/api/webhooks/...",t.innerHTML="URL",n.classList.remove("hidden"),o.classList.add("hidden"))}),document.getElementById("webhook-kind-slack").addEventListener("change",e=>{e.target.value==="slack"&&(s.placeholder="https://hooks.slack.com/services/...",t.innerHTML="URL",n.classList.remove("hidden"),o.classList.add("hidden"))}),document.getElementById("webhook-kind-telegram").addEventListener("change",e=>{e.target.value==="telegram"&&(t.innerHTML="Token",s.placeholder="Bot token",n.classList.remove("hidden"),o.classList.remove("hidden"))})})
\ No newline at end of file
@@ -81,8 +81,11 @@ async function renderMap(geoURL) {
       '&copy; <a href="https://openstreetmap.org/copyright">OpenStreetMap contributors</a>',
   }).addTo(map);
 
+  document.dispatchEvent(new CustomEvent("map-ready", { detail: map }));
+
   const geojson = await fetchGeoJSON(geoURL);
   if (!geojson || !geojson.features) {
+    container.removeChild(throbber);
     return;
   }
 
@@ -92,6 +95,20 @@ async function renderMap(geoURL) {
 
   const bounds = [];
   geojson.features.map((f) => {
+    if (f.geometry.type === "LineString") {
+      const coords = L.GeoJSON.coordsToLatLngs(f.geometry.coordinates);
+
+      const link = document.createElement("a");
+      link.href = `${f.properties.url}?browser`;
+      link.textContent = f.properties.name;
+
+      bounds.push(...coords);
+      L.polyline(coords, { color: "crimson", weight: 3 })
+        .bindPopup(link)
+        .addTo(map);
+      return;
+    }
+
     const coord = L.GeoJSON.coordsToLatLng(f.geometry.coordinates);
 
     bounds.push(coord);
@@ -11,7 +11,7 @@ self.addEventListener("push", async function (event) {
 
   return self.registration.showNotification(payload.title, {
     icon: "/images/favicon/favicon-32x32.png",
-    image: payload.image,
+    image: payload.image || undefined,
     body: payload.description,
     data: {
       url: payload.url,
@@ -20,6 +20,7 @@ require (
 	go.opentelemetry.io/otel/trace v1.45.0
 	go.uber.org/mock v0.6.0
 	golang.org/x/crypto v0.55.0
+	golang.org/x/image v0.25.0
 	golang.org/x/text v0.41.0
 )
 
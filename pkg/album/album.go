@@ -0,0 +1,117 @@
+package album
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+)
+
+var albumFilename = provider.MetadataDirectoryName + "/albums.json"
+
+type Service struct {
+	exclusive     exclusive.Service
+	storage       absto.Storage
+	redisClient   redis.Client
+	done          chan struct{}
+	albums        map[string]provider.Album
+	pubsubChannel string
+	mutex         sync.RWMutex
+}
+
+type Config struct {
+	PubsubChannel string
+}
+
+func Flags(fs *flag.FlagSet, prefix string) *Config {
+	var config Config
+
+	flags.New("PubSubChannel", "Channel name").Prefix(prefix).DocPrefix("album").StringVar(fs, &config.PubsubChannel, "fibr:albums-channel", nil)
+
+	return &config
+}
+
+func New(config *Config, storageService absto.Storage, redisClient redis.Client, exclusiveService exclusive.Service) *Service {
+	return &Service{
+		albums:        make(map[string]provider.Album),
+		done:          make(chan struct{}),
+		storage:       storageService,
+		exclusive:     exclusiveService,
+		redisClient:   redisClient,
+		pubsubChannel: config.PubsubChannel,
+	}
+}
+
+func (s *Service) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Service) Exclusive(ctx context.Context, name string, duration time.Duration, action func(ctx context.Context) error) error {
+	return s.exclusive.Execute(ctx, "fibr:mutex:album:"+name, duration, func(ctx context.Context) error {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if err := s.refresh(ctx); err != nil {
+			return fmt.Errorf("refresh albums: %w", err)
+		}
+
+		return action(ctx)
+	})
+}
+
+func (s *Service) Start(ctx context.Context) {
+	defer close(s.done)
+
+	if err := s.loadAlbums(ctx); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "refresh albums", slog.Any("error", err))
+		return
+	}
+
+	redis.SubscribeFor(ctx, s.redisClient, s.pubsubChannel, s.PubSubHandle)
+}
+
+func (s *Service) loadAlbums(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.refresh(ctx)
+}
+
+func (s *Service) refresh(ctx context.Context) error {
+	albums, err := provider.LoadJSON[map[string]provider.Album](ctx, s.storage, albumFilename)
+	if err != nil {
+		if !absto.IsNotExist(err) {
+			return err
+		}
+
+		if err := s.storage.Mkdir(ctx, provider.MetadataDirectoryName, absto.DirectoryPerm); err != nil {
+			return fmt.Errorf("create dir: %w", err)
+		}
+
+		return provider.SaveJSON(ctx, s.storage, albumFilename, &s.albums)
+	}
+
+	s.albums = albums
+
+	return nil
+}
+
+func (s *Service) save(ctx context.Context, album provider.Album) error {
+	if err := provider.SaveJSON(ctx, s.storage, albumFilename, s.albums); err != nil {
+		return fmt.Errorf("save albums: %w", err)
+	}
+
+	if err := s.redisClient.PublishJSON(ctx, s.pubsubChannel, album); err != nil {
+		return fmt.Errorf("publish album: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package album
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+var ErrNotFound = errors.New("album not found")
+
+func (s *Service) generateID() string {
+	for {
+		idSha := provider.Hash(provider.Identifier())[:8]
+
+		if _, ok := s.albums[idSha]; !ok {
+			return idSha
+		}
+	}
+}
+
+func (s *Service) List() []provider.Album {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	output := make([]provider.Album, 0, len(s.albums))
+	for _, album := range s.albums {
+		output = append(output, album)
+	}
+
+	slices.SortFunc(output, func(a, b provider.Album) int {
+		if compare := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); compare != 0 {
+			return compare
+		}
+
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return output
+}
+
+func (s *Service) Get(id string) provider.Album {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.albums[id]
+}
+
+func (s *Service) Create(ctx context.Context, name string) (string, error) {
+	var id string
+
+	return id, s.Exclusive(ctx, "create", exclusive.Duration, func(ctx context.Context) error {
+		id = s.generateID()
+
+		album := provider.Album{
+			ID:      id,
+			Name:    name,
+			Created: time.Now(),
+		}
+
+		s.albums[id] = album
+
+		return s.save(ctx, album)
+	})
+}
+
+func (s *Service) UpdateName(ctx context.Context, id, name string) error {
+	return s.update(ctx, id, func(album *provider.Album) bool {
+		album.Name = name
+		return true
+	})
+}
+
+func (s *Service) Delete(ctx context.Context, id string) error {
+	return s.Exclusive(ctx, id, exclusive.Duration, func(ctx context.Context) error {
+		if _, ok := s.albums[id]; !ok {
+			return ErrNotFound
+		}
+
+		delete(s.albums, id)
+
+		return s.save(ctx, provider.Album{ID: id})
+	})
+}
+
+func (s *Service) AddFiles(ctx context.Context, id string, items ...absto.Item) (int, error) {
+	var count int
+
+	return count, s.update(ctx, id, func(album *provider.Album) bool {
+		count = album.Add(items...)
+		return count != 0
+	})
+}
+
+func (s *Service) RemoveFiles(ctx context.Context, id string, ids ...string) (int, error) {
+	var count int
+
+	return count, s.update(ctx, id, func(album *provider.Album) bool {
+		count = album.Remove(ids...)
+		return count != 0
+	})
+}
+
+func (s *Service) update(ctx context.Context, id string, action func(*provider.Album) bool) error {
+	return s.Exclusive(ctx, id, exclusive.Duration, func(ctx context.Context) error {
+		album, ok := s.albums[id]
+		if !ok {
+			return ErrNotFound
+		}
+
+		if !action(&album) {
+			return nil
+		}
+
+		s.albums[id] = album
+
+		return s.save(ctx, album)
+	})
+}
+
+// updateAll applies the action on every album, under a single lock, and saves the changed ones
+func (s *Service) updateAll(ctx context.Context, name string, action func(*provider.Album) bool) error {
+	return s.Exclusive(ctx, name, exclusive.Duration, func(ctx context.Context) error {
+		var changed []provider.Album
+
+		for id, album := range s.albums {
+			if action(&album) {
+				s.albums[id] = album
+				changed = append(changed, album)
+			}
+		}
+
+		if len(changed) == 0 {
+			return nil
+		}
+
+		if err := provider.SaveJSON(ctx, s.storage, albumFilename, s.albums); err != nil {
+			return fmt.Errorf("save albums: %w", err)
+		}
+
+		for _, album := range changed {
+			if err := s.redisClient.PublishJSON(ctx, s.pubsubChannel, album); err != nil {
+				return fmt.Errorf("publish album: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
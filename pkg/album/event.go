@@ -0,0 +1,62 @@
+package album
+
+import (
+	"context"
+	"log/slog"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func (s *Service) EventConsumer(ctx context.Context, e provider.Event) {
+	switch e.Type {
+	case provider.RenameEvent:
+		if e.Item.IsDir() {
+			// Files of directories are renamed through the renamers on the event bus
+			return
+		}
+
+		if err := s.Rename(ctx, e.Item, *e.New); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "rename album file", slog.String("item", e.Item.Pathname), slog.Any("error", err))
+		}
+
+	case provider.DeleteEvent:
+		if err := s.deleteItem(ctx, e.Item); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "delete album file", slog.String("item", e.Item.Pathname), slog.Any("error", err))
+		}
+	}
+}
+
+// Rename keeps the albums' references up to date, it's a provider.Renamer
+func (s *Service) Rename(ctx context.Context, old, new absto.Item) error {
+	if !s.contains(old.ID) {
+		return nil
+	}
+
+	return s.updateAll(ctx, old.ID, func(album *provider.Album) bool {
+		return album.Rename(old, new)
+	})
+}
+
+func (s *Service) deleteItem(ctx context.Context, item absto.Item) error {
+	if !item.IsDir() && !s.contains(item.ID) {
+		return nil
+	}
+
+	return s.updateAll(ctx, item.ID, func(album *provider.Album) bool {
+		return album.RemoveItem(item)
+	})
+}
+
+func (s *Service) contains(id string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, album := range s.albums {
+		if _, ok := album.Find(id); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,24 @@
+package album
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func (s *Service) PubSubHandle(album provider.Album, err error) {
+	if err != nil {
+		slog.LogAttrs(context.Background(), slog.LevelError, "Album's PubSub", slog.Any("error", err))
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if album.Created.IsZero() {
+		delete(s.albums, album.ID)
+	} else {
+		s.albums[album.ID] = album
+	}
+}
@@ -0,0 +1,359 @@
+package crud
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/query"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	errEmptyAlbum    = errors.New("album name is empty")
+	errAlbumNotFound = errors.New("album not found")
+)
+
+// parseAlbumPath returns the album ID and the file name of a virtual album path, both empty for the album list
+func parseAlbumPath(pathname string) (id, name string, ok bool) {
+	if strings.TrimSuffix(pathname, "/")+"/" == provider.AlbumsPath {
+		return "", "", true
+	}
+
+	rest, ok := strings.CutPrefix(pathname, provider.AlbumsPath)
+	if !ok {
+		return "", "", false
+	}
+
+	id, name, _ = strings.Cut(rest, "/")
+	if len(id) == 0 || strings.Contains(name, "/") {
+		return "", "", false
+	}
+
+	return id, name, true
+}
+
+func (s *Service) handleAlbum(w http.ResponseWriter, r *http.Request, request provider.Request, id, name string, message renderer.Message) (renderer.Page, error) {
+	ctx := r.Context()
+
+	if len(id) == 0 {
+		if !request.Share.IsZero() {
+			return errorReturn(request, model.WrapNotFound(errAlbumNotFound))
+		}
+
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			s.renderer.Redirect(w, r, fmt.Sprintf("%s/", r.URL.Path), renderer.Message{})
+			return renderer.Page{}, nil
+		}
+
+		telemetry.SetRouteTag(ctx, "/albums")
+		return s.albumList(request, message), nil
+	}
+
+	album := s.album.Get(id)
+	if album.IsZero() || (!request.Share.IsZero() && request.Share.Path != album.Path()) {
+		return errorReturn(request, model.WrapNotFound(errAlbumNotFound))
+	}
+
+	canManage := request.CanEdit && request.Share.IsZero()
+
+	request.Album = album.ID
+	// Files come from several folders, editing is done in their own folder
+	request.CanEdit = false
+
+	if len(name) != 0 {
+		request.CanShare = false
+		request.CanWebhook = false
+
+		item, err := s.albumFile(ctx, album, name)
+		if err != nil {
+			return errorReturn(request, err)
+		}
+
+		return s.handleFile(w, r, request, item, message)
+	}
+
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		s.renderer.Redirect(w, r, fmt.Sprintf("%s/?d=%s", r.URL.Path, request.Display), renderer.Message{})
+		return renderer.Page{}, nil
+	}
+
+	files := s.albumFiles(ctx, album)
+
+	if query.GetBool(r, "geojson") {
+		telemetry.SetRouteTag(ctx, "/albums/geojsons")
+		s.serveGeoJSON(w, r, request, absto.Item{}, files)
+		return renderer.Page{}, nil
+	}
+
+	if query.GetBool(r, "thumbnail") {
+		telemetry.SetRouteTag(ctx, "/albums/thumbnails")
+		s.thumbnail.List(w, r, absto.Item{}, files)
+		return renderer.Page{}, nil
+	}
+
+	if query.GetBool(r, "download") {
+		telemetry.SetRouteTag(ctx, "/albums/downloads")
+		return errorReturn(request, s.downloadAlbum(w, r, request, album, files))
+	}
+
+	provider.SetPrefsCookie(w, request)
+
+	if request.IsStory() {
+		telemetry.SetRouteTag(ctx, "/albums/stories")
+
+		storyFiles := files[:0]
+		for _, file := range files {
+			if s.thumbnail.HasLargeThumbnail(ctx, file) {
+				storyFiles = append(storyFiles, file)
+			}
+		}
+
+		return s.story(r, request, absto.Item{}, storyFiles)
+	}
+
+	telemetry.SetRouteTag(ctx, "/albums/directory")
+
+	page := s.virtualFolder(ctx, request, message, files)
+	page.Content["Album"] = album
+	page.Content["CanManage"] = canManage
+
+	if canManage && request.CanShare {
+		page.Content["Shares"] = albumShares(s.share.List(), album)
+	}
+
+	return page, nil
+}
+
+func (s *Service) albumList(request provider.Request, message renderer.Message) renderer.Page {
+	return renderer.NewPage("albums", http.StatusOK, map[string]any{
+		"Paths":     getPathParts(request),
+		"Albums":    s.album.List(),
+		"Request":   request,
+		"Message":   message,
+		"AlbumList": true,
+		"CanManage": request.CanEdit,
+	})
+}
+
+// albumFiles resolves the files of the album, in the order they were added, skipping the missing ones
+func (s *Service) albumFiles(ctx context.Context, album provider.Album) []absto.Item {
+	files := make([]absto.Item, 0, len(album.Files))
+
+	for _, file := range album.Files {
+		item, err := s.storage.Stat(ctx, file.Pathname)
+		if err != nil {
+			if !absto.IsNotExist(err) {
+				slog.LogAttrs(ctx, slog.LevelError, "get album file", slog.String("album", album.ID), slog.String("item", file.Pathname), slog.Any("error", err))
+			}
+
+			continue
+		}
+
+		files = append(files, item)
+	}
+
+	return files
+}
+
+// albumFile resolves a file of the album from its name in the virtual folder, or one of its companions and subtitles living next to it
+func (s *Service) albumFile(ctx context.Context, album provider.Album, name string) (absto.Item, error) {
+	id, filename, ok := provider.ParseAlbumFileName(name)
+	if ok {
+		for _, file := range album.Files {
+			pathname := file.Pathname
+
+			if file.ID != id || path.Base(pathname) != filename {
+				if !provider.IsAlbumCompanion(file, filename) {
+					continue
+				}
+
+				if pathname = path.Join(path.Dir(file.Pathname), filename); absto.ID(pathname) != id {
+					continue
+				}
+			}
+
+			item, err := s.storage.Stat(ctx, pathname)
+			if err != nil {
+				if absto.IsNotExist(err) {
+					return item, model.WrapNotFound(err)
+				}
+
+				return item, model.WrapInternal(err)
+			}
+
+			return item, nil
+		}
+	}
+
+	return absto.Item{}, model.WrapNotFound(fmt.Errorf("no file `%s` in album `%s`", name, album.Name))
+}
+
+func (s *Service) albumPreviousAndNext(ctx context.Context, request provider.Request, item absto.Item) (previous, next provider.RenderItem) {
+	ctx, end := telemetry.StartSpan(ctx, s.tracer, "album_previous_next", trace.WithSpanKind(trace.SpanKindInternal))
+	defer end(nil)
+
+	previousItem, nextItem := getPreviousAndNext(item, s.albumFiles(ctx, s.album.Get(request.Album)))
+
+	if previousItem != nil {
+		previous = provider.StorageToRender(*previousItem, request)
+	}
+	if nextItem != nil {
+		next = provider.StorageToRender(*nextItem, request)
+	}
+
+	return previous, next
+}
+
+func (s *Service) downloadAlbum(w http.ResponseWriter, r *http.Request, request provider.Request, album provider.Album, files []absto.Item) error {
+	zipWriter := zip.NewWriter(w)
+
+	ctx := r.Context()
+
+	defer func() {
+		if closeErr := zipWriter.Close(); closeErr != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "close zip", slog.Any("error", closeErr))
+		}
+	}()
+
+	w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=%q", album.Name+".zip"))
+
+	// Names of files in album are prefixed by their ID, so files from different folders don't collide
+	return s.zipItems(ctx, ctx.Done(), request, zipWriter, files)
+}
+
+func albumShares(shares []provider.Share, album provider.Album) []provider.Share {
+	var output []provider.Share
+
+	for _, share := range shares {
+		if share.Path == album.Path() {
+			output = append(output, share)
+		}
+	}
+
+	return output
+}
+
+func (s *Service) handlePostAlbum(w http.ResponseWriter, r *http.Request, request provider.Request, method string) {
+	if !request.CanEdit || !request.Share.IsZero() {
+		s.error(w, r, request, model.WrapForbidden(ErrNotAuthorized))
+		return
+	}
+
+	ctx := r.Context()
+
+	if method == http.MethodPost {
+		name := strings.TrimSpace(r.FormValue("name"))
+		if len(name) == 0 {
+			s.error(w, r, request, model.WrapInvalid(errEmptyAlbum))
+			return
+		}
+
+		var items []absto.Item
+		if names := r.Form["names"]; len(names) != 0 {
+			var err error
+
+			if items, err = s.selectedFiles(ctx, request, names); err != nil {
+				s.error(w, r, request, err)
+				return
+			}
+		}
+
+		id, err := s.album.Create(ctx, name)
+		if err != nil {
+			s.error(w, r, request, model.WrapInternal(err))
+			return
+		}
+
+		if len(items) != 0 {
+			if _, err = s.album.AddFiles(ctx, id, items...); err != nil {
+				s.error(w, r, request, model.WrapInternal(err))
+				return
+			}
+		}
+
+		s.renderer.Redirect(w, r, provider.AlbumsPath+id+"/", renderer.NewSuccessMessage("Album %s successfully created with %d file(s)", name, len(items)))
+		return
+	}
+
+	album := s.album.Get(r.FormValue("id"))
+	if album.IsZero() {
+		s.error(w, r, request, model.WrapNotFound(errAlbumNotFound))
+		return
+	}
+
+	switch method {
+	case http.MethodPut:
+		items, err := s.selectedFiles(ctx, request, r.Form["names"])
+		if err != nil {
+			s.error(w, r, request, err)
+			return
+		}
+
+		count, err := s.album.AddFiles(ctx, album.ID, items...)
+		if err != nil {
+			s.error(w, r, request, model.WrapInternal(err))
+			return
+		}
+
+		redirection := fmt.Sprintf("?d=%s", request.Display)
+		if len(request.Item) != 0 {
+			redirection = "?browser"
+		}
+
+		s.renderer.Redirect(w, r, redirection, renderer.NewSuccessMessage("%d file(s) added to album %s", count, album.Name))
+
+	case http.MethodPatch:
+		name := strings.TrimSpace(r.FormValue("newName"))
+		if len(name) == 0 {
+			s.error(w, r, request, model.WrapInvalid(errEmptyAlbum))
+			return
+		}
+
+		if err := s.album.UpdateName(ctx, album.ID, name); err != nil {
+			s.error(w, r, request, model.WrapInternal(err))
+			return
+		}
+
+		s.renderer.Redirect(w, r, album.Path(), renderer.NewSuccessMessage("Album %s renamed to %s", album.Name, name))
+
+	case http.MethodDelete:
+		if ids := r.Form["ids"]; len(ids) != 0 {
+			count, err := s.album.RemoveFiles(ctx, album.ID, ids...)
+			if err != nil {
+				s.error(w, r, request, model.WrapInternal(err))
+				return
+			}
+
+			s.renderer.Redirect(w, r, fmt.Sprintf("%s?d=%s", album.Path(), request.Display), renderer.NewSuccessMessage("%d file(s) removed from album %s", count, album.Name))
+			return
+		}
+
+		for _, share := range albumShares(s.share.List(), album) {
+			if err := s.share.Delete(ctx, share.ID); err != nil {
+				s.error(w, r, request, model.WrapInternal(err))
+				return
+			}
+		}
+
+		if err := s.album.Delete(ctx, album.ID); err != nil {
+			s.error(w, r, request, model.WrapInternal(err))
+			return
+		}
+
+		s.renderer.Redirect(w, r, provider.AlbumsPath, renderer.NewSuccessMessage("Album %s successfully deleted", album.Name))
+
+	default:
+		s.error(w, r, request, model.WrapMethodNotAllowed(fmt.Errorf("unknown method `%s` for album", method)))
+	}
+}
@@ -0,0 +1,66 @@
+package crud
+
+import (
+	"testing"
+)
+
+func TestParseAlbumPath(t *testing.T) {
+	cases := map[string]struct {
+		pathname string
+		wantID   string
+		wantName string
+		wantOk   bool
+	}{
+		"list": {
+			"/albums/",
+			"",
+			"",
+			true,
+		},
+		"list without slash": {
+			"/albums",
+			"",
+			"",
+			true,
+		},
+		"album": {
+			"/albums/8000cafe/",
+			"8000cafe",
+			"",
+			true,
+		},
+		"album without slash": {
+			"/albums/8000cafe",
+			"8000cafe",
+			"",
+			true,
+		},
+		"file": {
+			"/albums/8000cafe/1234-rome.jpg",
+			"8000cafe",
+			"1234-rome.jpg",
+			true,
+		},
+		"nested": {
+			"/albums/8000cafe/travel/rome.jpg",
+			"",
+			"",
+			false,
+		},
+		"other": {
+			"/photos/albums/",
+			"",
+			"",
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			id, name, ok := parseAlbumPath(tc.pathname)
+			if id != tc.wantID || name != tc.wantName || ok != tc.wantOk {
+				t.Errorf("parseAlbumPath() = (`%s`, `%s`, %t), want (`%s`, `%s`, %t)", id, name, ok, tc.wantID, tc.wantName, tc.wantOk)
+			}
+		})
+	}
+}
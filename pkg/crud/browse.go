@@ -20,10 +20,12 @@ func (s *Service) browse(ctx context.Context, request provider.Request, item abs
 	defer end(nil)
 
 	var (
-		previous provider.RenderItem
-		next     provider.RenderItem
-		files    []absto.Item
-		metadata provider.Metadata
+		previous   provider.RenderItem
+		next       provider.RenderItem
+		files      []absto.Item
+		subtitles  []provider.Subtitle
+		companions []provider.Companion
+		metadata   provider.Metadata
 	)
 
 	wg := concurrent.NewLimiter(-1)
@@ -31,9 +33,23 @@ func (s *Service) browse(ctx context.Context, request provider.Request, item abs
 	if request.Share.IsZero() || !request.Share.File {
 		wg.Go(func() {
 			files, previous, next = s.getFilesPreviousAndNext(ctx, item, request)
+			if len(request.Album) != 0 {
+				previous, next = s.albumPreviousAndNext(ctx, request, item)
+			}
+
+			subtitles = provider.SubtitlesOf(item, files)
+			companions = provider.CompanionsOf(item, files)
 		})
 	} else {
 		files = []absto.Item{item}
+
+		wg.Go(func() {
+			var err error
+			subtitles, err = s.subtitlesOf(ctx, item)
+			if err != nil {
+				slog.LogAttrs(ctx, slog.LevelError, "list subtitles", slog.String("item", item.Pathname), slog.Any("error", err))
+			}
+		})
 	}
 
 	wg.Go(func() {
@@ -51,19 +67,32 @@ func (s *Service) browse(ctx context.Context, request provider.Request, item abs
 		renderItem.HasThumbnail = true
 	}
 
-	return renderer.NewPage("file", http.StatusOK, map[string]any{
-		"Paths":     getPathParts(request),
-		"File":      renderItem,
-		"Exif":      metadata,
-		"Cover":     s.getCover(ctx, request, files),
-		"HasStream": renderItem.IsVideo() && s.thumbnail.HasStream(ctx, item),
+	content := map[string]any{
+		"Paths":      getPathParts(request),
+		"File":       renderItem,
+		"Exif":       metadata,
+		"Cover":      s.getCover(ctx, request, files, nil),
+		"HasStream":  renderItem.IsVideo() && s.thumbnail.HasStream(ctx, item),
+		"HasSprite":  renderItem.IsVideo() && s.thumbnail.HasSprite(ctx, item),
+		"Subtitles":  subtitles,
+		"Companions": companions,
 
 		"Previous": previous,
 		"Next":     next,
 
 		"Request": request,
 		"Message": message,
-	}), nil
+	}
+
+	if metadata.PerceptualHash != 0 {
+		content["Similar"] = s.similarOf(ctx, request, item, metadata.PerceptualHash, files)
+	}
+
+	if request.CanEdit && request.Share.IsZero() {
+		content["Albums"] = s.album.List()
+	}
+
+	return renderer.NewPage("file", http.StatusOK, content), nil
 }
 
 func (s *Service) getFilesPreviousAndNext(ctx context.Context, item absto.Item, request provider.Request) (items []absto.Item, previous, next provider.RenderItem) {
@@ -79,7 +108,9 @@ func (s *Service) getFilesPreviousAndNext(ctx context.Context, item absto.Item,
 
 	sort.Sort(provider.ByHybridSort(items))
 
-	previousItem, nextItem := getPreviousAndNext(item, items)
+	navigableItems, _ := provider.GroupSubtitles(items)
+	navigableItems, _ = provider.GroupCompanions(navigableItems)
+	previousItem, nextItem := getPreviousAndNext(item, navigableItems)
 
 	if previousItem != nil {
 		previous = provider.StorageToRender(*previousItem, request)
@@ -3,14 +3,20 @@ package crud
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"net/http"
+	"time"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+	"github.com/ViBiOh/fibr/pkg/inbox"
+	"github.com/ViBiOh/fibr/pkg/live"
 	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/fibr/pkg/push"
 	"github.com/ViBiOh/fibr/pkg/search"
 	"github.com/ViBiOh/fibr/pkg/thumbnail"
 	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/cron"
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -28,19 +34,31 @@ type Service struct {
 	storage         absto.Storage
 	share           provider.ShareManager
 	webhook         provider.WebhookManager
+	album           provider.AlbumManager
 	metadata        provider.MetadataManager
 	searchService   search.Service
 	pushService     *push.Service
+	inbox           *inbox.Service
+	live            *live.Service
 	pushEvent       provider.EventProducer
 	temporaryFolder string
 	renderer        *renderer.Service
 	thumbnail       thumbnail.Service
+	exclusive       exclusive.Service
+	cron            *cron.Cron
+	done            chan struct{}
+	memoriesAt      string
+	memoriesZone    *time.Location
+	similarDistance int
 	chunkUpload     bool
 }
 
 type Config struct {
-	TemporaryFolder string
-	ChunkUpload     bool
+	TemporaryFolder  string
+	MemoriesAt       string
+	MemoriesTimezone string
+	SimilarDistance  int
+	ChunkUpload      bool
 }
 
 func Flags(fs *flag.FlagSet, prefix string) *Config {
@@ -48,11 +66,19 @@ func Flags(fs *flag.FlagSet, prefix string) *Config {
 
 	flags.New("ChunkUpload", "Use chunk upload in browser").Prefix(prefix).DocPrefix("crud").BoolVar(fs, &config.ChunkUpload, false, nil)
 	flags.New("TemporaryFolder", "Temporary folder for chunk upload").Prefix(prefix).DocPrefix("crud").StringVar(fs, &config.TemporaryFolder, "/tmp", nil)
+	flags.New("MemoriesAt", "Hour of the daily \"On this day\" digest, empty to disable").Prefix(prefix).DocPrefix("crud").StringVar(fs, &config.MemoriesAt, "08:00", nil)
+	flags.New("MemoriesTimezone", "Timezone of the \"On this day\" memories").Prefix(prefix).DocPrefix("crud").StringVar(fs, &config.MemoriesTimezone, "UTC", nil)
+	flags.New("SimilarDistance", "Max number of differing bits between the perceptual hashes of similar pictures").Prefix(prefix).DocPrefix("crud").IntVar(fs, &config.SimilarDistance, 10, nil)
 
 	return &config
 }
 
-func New(config *Config, storageService, filteredStorage absto.Storage, rendererService *renderer.Service, shareService provider.ShareManager, webhookService provider.WebhookManager, thumbnailService thumbnail.Service, exifService provider.MetadataManager, searchService search.Service, pushService *push.Service, eventProducer provider.EventProducer, tracerProvider trace.TracerProvider) (*Service, error) {
+func New(config *Config, storageService, filteredStorage absto.Storage, rendererService *renderer.Service, shareService provider.ShareManager, webhookService provider.WebhookManager, albumService provider.AlbumManager, thumbnailService thumbnail.Service, exifService provider.MetadataManager, searchService search.Service, pushService *push.Service, inboxService *inbox.Service, liveService *live.Service, eventProducer provider.EventProducer, exclusiveService exclusive.Service, tracerProvider trace.TracerProvider) (*Service, error) {
+	memoriesZone, err := time.LoadLocation(config.MemoriesTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("load memories timezone: %w", err)
+	}
+
 	service := &Service{
 		chunkUpload:     config.ChunkUpload,
 		temporaryFolder: config.TemporaryFolder,
@@ -64,8 +90,17 @@ func New(config *Config, storageService, filteredStorage absto.Storage, renderer
 		metadata:        exifService,
 		share:           shareService,
 		webhook:         webhookService,
+		album:           albumService,
 		searchService:   searchService,
 		pushService:     pushService,
+		inbox:           inboxService,
+		live:            liveService,
+		exclusive:       exclusiveService,
+		cron:            cron.New().WithTracerProvider(tracerProvider),
+		done:            make(chan struct{}),
+		memoriesAt:      config.MemoriesAt,
+		memoriesZone:    memoriesZone,
+		similarDistance: config.SimilarDistance,
 	}
 
 	if tracerProvider != nil {
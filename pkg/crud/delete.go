@@ -8,6 +8,7 @@ import (
 
 	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/query"
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
 )
 
@@ -45,11 +46,18 @@ func (s *Service) Delete(w http.ResponseWriter, r *http.Request, request provide
 	if info.IsDir() {
 		request = request.DeletePreference(pathname)
 		provider.SetPrefsCookie(w, request)
+	} else if !isSingle(r) {
+		s.deleteGroup(ctx, request, info)
 	}
 
 	go s.pushEvent(context.WithoutCancel(ctx), provider.NewDeleteEvent(ctx, request, info, s.renderer))
 
-	s.renderer.Redirect(w, r, fmt.Sprintf("?d=%s", request.Display), renderer.NewSuccessMessage("%s successfully deleted", info.Name()))
+	redirect := fmt.Sprintf("?d=%s", request.Display)
+	if query.GetBool(r, "similar") {
+		redirect = "?similar"
+	}
+
+	s.renderer.Redirect(w, r, redirect, renderer.NewSuccessMessage("%s successfully deleted", info.Name()))
 }
 
 func (s *Service) DeleteSavedSearch(w http.ResponseWriter, r *http.Request, request provider.Request) {
@@ -0,0 +1,145 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+)
+
+type eventSection struct {
+	ID    string
+	Title string
+	Names []string
+}
+
+// groupByEvent reorders the items with folders first then files by event, and returns the events keyed by the ID of their first file
+func groupByEvent(items []provider.RenderItem, clusters []provider.Cluster) ([]provider.RenderItem, map[string]eventSection) {
+	byID := make(map[string]provider.RenderItem, len(items))
+	output := make([]provider.RenderItem, 0, len(items))
+
+	for _, item := range items {
+		if item.IsDir() {
+			output = append(output, item)
+		} else {
+			byID[item.ID] = item
+		}
+	}
+
+	events := make(map[string]eventSection, len(clusters))
+
+	for _, cluster := range clusters {
+		section := eventSection{
+			ID:    cluster.ID(),
+			Title: cluster.Title(),
+			Names: make([]string, 0, len(cluster.Items)),
+		}
+
+		for _, file := range cluster.Items {
+			item, ok := byID[file.ID]
+			if !ok {
+				continue
+			}
+
+			output = append(output, item)
+			section.Names = append(section.Names, item.URL)
+		}
+
+		events[section.ID] = section
+	}
+
+	return output, events
+}
+
+// flattenEvents returns the files in the order of their events, and the events keyed by the ID of their first file
+func flattenEvents(clusters []provider.Cluster) ([]absto.Item, map[string]eventSection) {
+	var output []absto.Item
+	events := make(map[string]eventSection, len(clusters))
+
+	for _, cluster := range clusters {
+		output = append(output, cluster.Items...)
+		events[cluster.ID()] = eventSection{
+			ID:    cluster.ID(),
+			Title: cluster.Title(),
+		}
+	}
+
+	return output, events
+}
+
+// handlePostEvent moves the files of an event into a new folder
+func (s *Service) handlePostEvent(w http.ResponseWriter, r *http.Request, request provider.Request) {
+	if !request.CanEdit {
+		s.error(w, r, request, model.WrapForbidden(ErrNotAuthorized))
+		return
+	}
+
+	name, err := checkFormName(r, "name")
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	name, err = provider.SanitizeName(name, true)
+	if err != nil {
+		s.error(w, r, request, model.WrapInternal(err))
+		return
+	}
+
+	ctx := r.Context()
+
+	items, err := s.selectedFiles(ctx, request, r.Form["names"])
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	pathname := request.SubPath(name)
+
+	if _, err = s.checkFile(ctx, pathname, false); err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	if err = s.storage.Mkdir(ctx, pathname, absto.DirectoryPerm); err != nil {
+		s.error(w, r, request, model.WrapInternal(err))
+		return
+	}
+
+	count, err := s.moveToFolder(ctx, items, provider.Dirname(pathname))
+	if err != nil {
+		s.error(w, r, request, model.WrapInternal(err))
+		return
+	}
+
+	s.renderer.Redirect(w, r, fmt.Sprintf("%s/?d=%s", name, request.Display), renderer.NewSuccessMessage("%d file(s) successfully moved to %s", count, path.Base(pathname)))
+}
+
+func (s *Service) moveToFolder(ctx context.Context, items []absto.Item, folder string) (int, error) {
+	metadatas, err := s.metadata.GetAllMetadataFor(ctx, items...)
+	if err != nil {
+		return 0, fmt.Errorf("get metadatas: %w", err)
+	}
+
+	var count int
+	var errs []error
+
+	for _, item := range items {
+		newItem, err := s.DoRename(ctx, item.Pathname, folder+item.Name(), item)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("move `%s`: %w", item.Pathname, err))
+			continue
+		}
+
+		s.renameGroup(ctx, item, newItem, metadatas[item.ID].Tags)
+		count++
+	}
+
+	return count, errors.Join(errs...)
+}
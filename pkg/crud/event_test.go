@@ -0,0 +1,36 @@
+package crud
+
+import (
+	"reflect"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestGroupByEvent(t *testing.T) {
+	folder := provider.RenderItem{Item: absto.Item{ID: "1", NameValue: "folder", IsDirValue: true}, URL: "folder/"}
+	first := provider.RenderItem{Item: absto.Item{ID: "2", NameValue: "first.jpg"}, URL: "first.jpg"}
+	second := provider.RenderItem{Item: absto.Item{ID: "3", NameValue: "second.jpg"}, URL: "second.jpg"}
+	third := provider.RenderItem{Item: absto.Item{ID: "4", NameValue: "third.jpg"}, URL: "third.jpg"}
+
+	clusters := []provider.Cluster{
+		{Location: "Lyon", Items: []absto.Item{third.Item, first.Item}},
+		{Items: []absto.Item{second.Item}},
+	}
+
+	items, events := groupByEvent([]provider.RenderItem{first, second, folder, third}, clusters)
+
+	if want := []provider.RenderItem{folder, third, first, second}; !reflect.DeepEqual(items, want) {
+		t.Errorf("groupByEvent() = %+v, want %+v", items, want)
+	}
+
+	want := map[string]eventSection{
+		"4": {ID: "4", Title: "Lyon", Names: []string{"third.jpg", "first.jpg"}},
+		"3": {ID: "3", Title: "Without date", Names: []string{"second.jpg"}},
+	}
+
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("groupByEvent() = %+v, want %+v", events, want)
+	}
+}
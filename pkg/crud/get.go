@@ -34,12 +34,17 @@ func (s *Service) getWithMessage(w http.ResponseWriter, r *http.Request, request
 	item, err := s.storage.Stat(ctx, pathname)
 
 	if err != nil && absto.IsNotExist(err) && provider.StreamExtensions[filepath.Ext(pathname)] {
-		if request.Share.File {
-			// URL with /<share_id>/segment.ts will be the path `/path/of/shared/file/segment.ts`, so we need to remove two directories before appending segment
-			pathname = provider.Dirname(path.Dir(path.Dir(pathname))) + path.Base(pathname)
+		item, err = s.thumbnail.GetChunk(ctx, chunkPathname(request, pathname))
+	}
+
+	if err != nil && absto.IsNotExist(err) && !request.Share.File {
+		if tag, ok := parseTagPath(request.Path); ok {
+			return s.handleTag(w, r, request, tag, message)
 		}
 
-		item, err = s.thumbnail.GetChunk(ctx, pathname)
+		if id, name, ok := parseAlbumPath(request.Filepath()); ok {
+			return s.handleAlbum(w, r, request, id, name, message)
+		}
 	}
 
 	if err != nil {
@@ -61,6 +66,15 @@ func (s *Service) getWithMessage(w http.ResponseWriter, r *http.Request, request
 	return s.handleDir(w, r, request, item, message)
 }
 
+func chunkPathname(request provider.Request, pathname string) string {
+	if !request.Share.File {
+		return pathname
+	}
+
+	// URL with /<share_id>/variant/segment.ts will be the path `/path/of/shared/file/variant/segment.ts`, so we need to resolve what's after the file from its directory
+	return provider.Dirname(path.Dir(request.Share.Path)) + strings.TrimPrefix(strings.TrimPrefix(pathname, request.Share.Path), "/")
+}
+
 func (s *Service) handleFile(w http.ResponseWriter, r *http.Request, request provider.Request, item absto.Item, message renderer.Message) (renderer.Page, error) {
 	ctx := r.Context()
 
@@ -76,6 +90,24 @@ func (s *Service) handleFile(w http.ResponseWriter, r *http.Request, request pro
 		return renderer.Page{}, nil
 	}
 
+	if query.GetBool(r, "sprite") {
+		telemetry.SetRouteTag(ctx, "/sprite")
+		s.thumbnail.Sprite(w, r, item)
+		return renderer.Page{}, nil
+	}
+
+	if r.URL.Query().Has("subtitle") {
+		telemetry.SetRouteTag(ctx, "/subtitle")
+		s.serveSubtitle(w, r, item, r.URL.Query().Get("subtitle"))
+		return renderer.Page{}, nil
+	}
+
+	if query.GetBool(r, "previews") {
+		telemetry.SetRouteTag(ctx, "/previews")
+		s.thumbnail.SpriteTrack(w, r, item)
+		return renderer.Page{}, nil
+	}
+
 	if query.GetBool(r, "browser") {
 		telemetry.SetRouteTag(ctx, "/browse")
 		provider.SetPrefsCookie(w, request)
@@ -85,6 +117,18 @@ func (s *Service) handleFile(w http.ResponseWriter, r *http.Request, request pro
 		return s.browse(ctx, request, item, message)
 	}
 
+	if query.GetBool(r, "download") && !isSingle(r) {
+		members, err := s.groupOf(ctx, item)
+		if err != nil {
+			return errorReturn(request, err)
+		}
+
+		if len(members) != 0 {
+			telemetry.SetRouteTag(ctx, "/downloads")
+			return renderer.Page{}, s.downloadGroup(w, r, item, members)
+		}
+	}
+
 	telemetry.SetRouteTag(ctx, "/download")
 	return renderer.Page{}, s.serveFile(w, r, item)
 }
@@ -108,7 +152,11 @@ func (s *Service) serveFile(w http.ResponseWriter, r *http.Request, item absto.I
 	defer provider.LogClose(ctx, file, "crud.serveFile", item.Pathname)
 
 	w.Header().Add("Etag", etag)
-	w.Header().Add("Content-Disposition", fmt.Sprintf("inline; filename=%s", item.Name()))
+	w.Header().Add("Content-Disposition", fmt.Sprintf("inline; filename=%q", item.Name()))
+
+	if item.Extension == ".m3u8" {
+		w.Header().Add("Content-Type", "application/x-mpegURL")
+	}
 
 	http.ServeContent(w, r, item.Name(), item.Date, file)
 	return nil
@@ -121,11 +169,37 @@ func (s *Service) handleDir(w http.ResponseWriter, r *http.Request, request prov
 		return s.stats(r, request, message)
 	}
 
+	if query.GetBool(r, "timeline") {
+		return s.timeline(r, request, item, message)
+	}
+
+	if query.GetBool(r, "memories") {
+		return s.memories(r, request, item, message)
+	}
+
+	if query.GetBool(r, "similar") {
+		return s.similar(r, request, item, message)
+	}
+
+	if query.GetBool(r, "subscriptions") {
+		return s.subscriptions(r, request, message)
+	}
+
 	if query.GetBool(r, "push") {
 		s.handleGetPush(w, r, request)
 		return renderer.Page{}, nil
 	}
 
+	if query.GetBool(r, "inbox") {
+		return s.handleGetInbox(r, request, message)
+	}
+
+	if query.GetBool(r, "live") {
+		telemetry.SetRouteTag(ctx, "/live")
+		s.streamLive(w, r, request, item)
+		return renderer.Page{}, nil
+	}
+
 	items, err := s.listFiles(r, request, item)
 	if err != nil {
 		return errorReturn(request, err)
@@ -163,7 +237,7 @@ func (s *Service) handleDir(w http.ResponseWriter, r *http.Request, request prov
 	}
 
 	telemetry.SetRouteTag(ctx, "/directory")
-	return s.list(ctx, request, message, item, items)
+	return s.list(ctx, request, message, item, items, query.GetBool(r, "events"))
 }
 
 func (s *Service) listFiles(r *http.Request, request provider.Request, item absto.Item) (items []absto.Item, err error) {
@@ -223,7 +297,7 @@ func (s *Service) serveGeoJSON(w http.ResponseWriter, r *http.Request, request p
 	defer end(nil)
 
 	var hash string
-	if query.GetBool(r, "search") {
+	if query.GetBool(r, "search") || !item.IsDir() {
 		hash = s.exifHash(ctx, items)
 	} else if exifs, err := s.metadata.ListDir(ctx, item); err != nil {
 		slog.LogAttrs(ctx, slog.LevelError, "list exifs", slog.String("item", item.Pathname), slog.Any("error", err))
@@ -231,6 +305,10 @@ func (s *Service) serveGeoJSON(w http.ResponseWriter, r *http.Request, request p
 		hash = provider.RawHash(exifs)
 	}
 
+	if tracks := keepOnlyTracks(items); len(tracks) != 0 {
+		hash = provider.RawHash([]any{hash, tracks})
+	}
+
 	etag, ok := provider.EtagMatch(w, r, hash)
 	if ok {
 		return
@@ -301,6 +379,8 @@ func (s *Service) generateGeoJSON(ctx context.Context, w io.Writer, request prov
 		}
 	}
 
+	s.generateTrackFeatures(ctx, w, encoder, request, keepOnlyTracks(items), commaNeeded, isDone)
+
 	provider.SafeWrite(ctx, w, "]}")
 }
 
@@ -339,5 +419,10 @@ func (s *Service) exifHash(ctx context.Context, items []absto.Item) string {
 }
 
 func (s *Service) Get(w http.ResponseWriter, r *http.Request, request provider.Request) (renderer.Page, error) {
-	return s.getWithMessage(w, r, request, renderer.ParseMessage(r))
+	page, err := s.getWithMessage(w, r, request, renderer.ParseMessage(r))
+	if err == nil && page.Content != nil {
+		page.Content["InboxUnread"] = s.inboxUnread(r.Context(), request)
+	}
+
+	return page, err
 }
@@ -185,3 +185,61 @@ func TestDichotomicFind(t *testing.T) {
 		})
 	}
 }
+
+func TestChunkPathname(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		request  provider.Request
+		pathname string
+	}
+
+	cases := map[string]struct {
+		args args
+		want string
+	}{
+		"no share": {
+			args{
+				pathname: "/path/to/1234.ts",
+			},
+			"/path/to/1234.ts",
+		},
+		"directory share": {
+			args{
+				request: provider.Request{
+					Share: provider.Share{Path: "/path/"},
+				},
+				pathname: "/path/to/1234/720p_0.ts",
+			},
+			"/path/to/1234/720p_0.ts",
+		},
+		"file share": {
+			args{
+				request: provider.Request{
+					Share: provider.Share{Path: "/path/to/video.mp4", File: true},
+				},
+				pathname: "/path/to/video.mp4/1234.ts",
+			},
+			"/path/to/1234.ts",
+		},
+		"file share variant": {
+			args{
+				request: provider.Request{
+					Share: provider.Share{Path: "/path/to/video.mp4", File: true},
+				},
+				pathname: "/path/to/video.mp4/1234/720p.m3u8",
+			},
+			"/path/to/1234/720p.m3u8",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := chunkPathname(testCase.args.request, testCase.args.pathname); got != testCase.want {
+				t.Errorf("chunkPathname() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package crud
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/query"
+)
+
+type groupMember struct {
+	rename func(string) string
+	absto.Item
+	companion bool
+}
+
+// groupOf lists the files that follow the item on download, rename, move and delete: its subtitles, its companions and its XMP sidecar
+func (s *Service) groupOf(ctx context.Context, item absto.Item) ([]groupMember, error) {
+	if item.IsDir() {
+		return nil, nil
+	}
+
+	items, err := s.storage.List(ctx, item.Dir())
+	if err != nil {
+		return nil, fmt.Errorf("list siblings: %w", err)
+	}
+
+	var members []groupMember
+
+	var subtitles []provider.Subtitle
+	if !provider.HasNamesake(item, items) {
+		// Subtitles shared with a namesake video stay where they are for it
+		subtitles = provider.SubtitlesOf(item, items)
+	}
+
+	for _, subtitle := range subtitles {
+		members = append(members, groupMember{
+			Item: subtitle.Item,
+			rename: func(name string) string {
+				return provider.SubtitleName(name, subtitle)
+			},
+		})
+	}
+
+	for _, companion := range provider.CompanionsOf(item, items) {
+		members = append(members, groupMember{
+			Item: companion.Item,
+			rename: func(name string) string {
+				return provider.CompanionName(name, companion)
+			},
+			companion: true,
+		})
+	}
+
+	if sidecar, ok := provider.XMPSidecarOf(item, items); ok {
+		members = append(members, groupMember{
+			Item: sidecar,
+			rename: func(name string) string {
+				return provider.XMPSidecarName(item.Name(), name, sidecar)
+			},
+		})
+	}
+
+	return members, nil
+}
+
+func isSingle(r *http.Request) bool {
+	return query.GetBool(r, "single") || r.FormValue("single") == "true"
+}
+
+func (s *Service) renameGroup(ctx context.Context, oldItem, newItem absto.Item, tags []string) {
+	members, err := s.groupOf(ctx, oldItem)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "list group", slog.String("item", oldItem.Pathname), slog.Any("error", err))
+		return
+	}
+
+	for _, member := range members {
+		newPath := newItem.Dir() + member.rename(newItem.Name())
+
+		renamed := member.Item
+
+		if member.Pathname != newPath {
+			if renamed, err = s.DoRename(ctx, member.Pathname, newPath, member.Item); err != nil {
+				slog.LogAttrs(ctx, slog.LevelError, "rename group member", slog.String("item", member.Pathname), slog.Any("error", err))
+				continue
+			}
+		}
+
+		if !member.companion {
+			continue
+		}
+
+		if _, err = s.metadata.Update(ctx, renamed, provider.ReplaceTags(tags)); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "update tags of group member", slog.String("item", renamed.Pathname), slog.Any("error", err))
+		}
+	}
+}
+
+func (s *Service) deleteGroup(ctx context.Context, request provider.Request, item absto.Item) {
+	members, err := s.groupOf(ctx, item)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "list group", slog.String("item", item.Pathname), slog.Any("error", err))
+		return
+	}
+
+	for _, member := range members {
+		if err := s.storage.RemoveAll(ctx, member.Pathname); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "delete group member", slog.String("item", member.Pathname), slog.Any("error", err))
+			continue
+		}
+
+		go s.pushEvent(context.WithoutCancel(ctx), provider.NewDeleteEvent(ctx, request, member.Item, s.renderer))
+	}
+}
+
+func (s *Service) downloadGroup(w http.ResponseWriter, r *http.Request, item absto.Item, members []groupMember) error {
+	ctx := r.Context()
+	zipWriter := zip.NewWriter(w)
+
+	defer func() {
+		if closeErr := zipWriter.Close(); closeErr != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "close zip", slog.Any("error", closeErr))
+		}
+	}()
+
+	w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=%q", item.Name()+".zip"))
+
+	if err := s.addFileToZip(ctx, zipWriter, item, item.Name()); err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if err := s.addFileToZip(ctx, zipWriter, member.Item, member.Name()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
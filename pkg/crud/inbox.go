@@ -0,0 +1,137 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/inbox"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
+)
+
+func (s *Service) handleGetInbox(r *http.Request, request provider.Request, message renderer.Message) (renderer.Page, error) {
+	telemetry.SetRouteTag(r.Context(), "/inbox")
+
+	entries, err := s.inbox.Entries(r.Context(), inbox.User(request.Actor()))
+	if err != nil {
+		return errorReturn(request, model.WrapInternal(err))
+	}
+
+	views := make([]inbox.View, len(entries))
+	for index, entry := range entries {
+		views[index] = entry.View(request.Share)
+	}
+
+	return renderer.NewPage("inbox", http.StatusOK, map[string]any{
+		"Paths":   getPathParts(request),
+		"Request": request,
+		"Message": message,
+		"Entries": views,
+	}), nil
+}
+
+func (s *Service) handlePostInbox(w http.ResponseWriter, r *http.Request, request provider.Request, method string) {
+	ctx := r.Context()
+	user := inbox.User(request.Actor())
+
+	switch method {
+	case http.MethodPost, http.MethodDelete:
+		pathname, err := s.followable(ctx, request)
+		if err != nil {
+			s.error(w, r, request, err)
+			return
+		}
+
+		message := renderer.NewSuccessMessage("You're now following this folder")
+
+		if method == http.MethodPost {
+			err = s.inbox.Follow(ctx, user, pathname)
+		} else {
+			err = s.inbox.Unfollow(ctx, user, pathname)
+			message = renderer.NewSuccessMessage("You're no longer following this folder")
+		}
+
+		if err != nil {
+			s.error(w, r, request, model.WrapInternal(err))
+			return
+		}
+
+		s.inboxDone(w, r, request.AbsoluteURL("")+"?d="+string(request.Display), message)
+
+	case http.MethodPut:
+		if err := s.inbox.MarkRead(ctx, user, r.FormValue("id")); err != nil {
+			if errors.Is(err, inbox.ErrNotFound) {
+				s.error(w, r, request, model.WrapNotFound(err))
+			} else {
+				s.error(w, r, request, model.WrapInternal(err))
+			}
+
+			return
+		}
+
+		s.inboxDone(w, r, request.AbsoluteURL("")+"?inbox", renderer.NewSuccessMessage("Marked as read"))
+
+	default:
+		s.error(w, r, request, model.WrapMethodNotAllowed(fmt.Errorf("unknown inbox method `%s` for %s", method, r.URL.Path)))
+	}
+}
+
+func (s *Service) inboxDone(w http.ResponseWriter, r *http.Request, url string, message renderer.Message) {
+	if r.Header.Get("Accept") == "text/plain" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.renderer.Redirect(w, r, url, message)
+}
+
+// followable returns the pathname of the requested folder, share visitors can only follow the folders of their share
+func (s *Service) followable(ctx context.Context, request provider.Request) (string, error) {
+	if request.Share.File {
+		return "", model.WrapForbidden(ErrNotAuthorized)
+	}
+
+	info, err := s.storage.Stat(ctx, request.Filepath())
+	if err != nil {
+		if absto.IsNotExist(err) {
+			return "", model.WrapNotFound(err)
+		}
+
+		return "", model.WrapInternal(err)
+	}
+
+	if !info.IsDir() {
+		return "", model.WrapInvalid(errors.New("only folders can be followed"))
+	}
+
+	return provider.Dirname(request.Filepath()), nil
+}
+
+func (s *Service) isFollowing(ctx context.Context, request provider.Request) bool {
+	following, err := s.inbox.IsFollowing(ctx, inbox.User(request.Actor()), provider.Dirname(request.Filepath()))
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "inbox following", slog.String("item", request.Filepath()), slog.Any("error", err))
+	}
+
+	return following
+}
+
+// inboxUnread is the count displayed in the header of every page
+func (s *Service) inboxUnread(ctx context.Context, request provider.Request) int {
+	if request.Share.File {
+		return 0
+	}
+
+	count, err := s.inbox.Unread(ctx, inbox.User(request.Actor()))
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "inbox unread", slog.String("actor", request.Actor()), slog.Any("error", err))
+	}
+
+	return count
+}
@@ -13,6 +13,7 @@ import (
 	"syscall"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/gpx"
 	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/fibr/pkg/search"
 	"github.com/ViBiOh/fibr/pkg/thumbnail"
@@ -27,10 +28,13 @@ func listLogger(pathname string) *slog.Logger {
 	return slog.With("fn", "crud.list").With("item", pathname)
 }
 
-func (s *Service) list(ctx context.Context, request provider.Request, message renderer.Message, item absto.Item, files []absto.Item) (renderer.Page, error) {
+func (s *Service) list(ctx context.Context, request provider.Request, message renderer.Message, item absto.Item, files []absto.Item, events bool) (renderer.Page, error) {
 	ctx, end := telemetry.StartSpan(ctx, s.tracer, "list", trace.WithAttributes(attribute.String("item", item.Pathname)))
 	defer end(nil)
 
+	files, subtitles := provider.GroupSubtitles(files)
+	files, companions := provider.GroupCompanions(files)
+
 	wg := concurrent.NewLimiter(-1)
 
 	var directoryAggregate provider.Aggregate
@@ -53,6 +57,11 @@ func (s *Service) list(ctx context.Context, request provider.Request, message re
 		}
 	})
 
+	var tagNames []string
+	wg.Go(func() {
+		tagNames = s.tagNames(ctx, request)
+	})
+
 	var metadatas map[string]provider.Metadata
 	wg.Go(func() {
 		var err error
@@ -94,10 +103,19 @@ func (s *Service) list(ctx context.Context, request provider.Request, message re
 	wg.Wait()
 
 	items := make([]provider.RenderItem, len(files))
+	hasMap := len(directoryAggregate.Location) != 0
 
 	for index, item := range files {
+		if !hasMap && (metadatas[item.ID].Geocode.HasCoordinates() || (!item.IsDir() && item.Extension == gpx.Extension)) {
+			hasMap = true
+		}
+
 		renderItem := provider.StorageToRender(item, request)
 		renderItem.Tags = metadatas[item.ID].Tags
+		renderItem.Rating = metadatas[item.ID].Rating
+		renderItem.Favorite = metadatas[item.ID].Favorite
+		renderItem.Subtitles = subtitles[item.ID]
+		renderItem.Companions = companions[item.ID]
 
 		if item.IsDir() {
 			renderItem.Aggregate = aggregates[item.ID]
@@ -113,6 +131,11 @@ func (s *Service) list(ctx context.Context, request provider.Request, message re
 
 	<-savedSearchDone
 
+	var eventSections map[string]eventSection
+	if events {
+		items, eventSections = groupByEvent(items, s.metadata.Clusters(files, metadatas))
+	}
+
 	content := map[string]any{
 		"Paths":         getPathParts(request),
 		"Files":         items,
@@ -120,12 +143,17 @@ func (s *Service) list(ctx context.Context, request provider.Request, message re
 		"Cover":         cover,
 		"Request":       request,
 		"Message":       message,
-		"HasMap":        len(directoryAggregate.Location),
+		"HasMap":        hasMap,
 		"HasThumbnail":  hasThumbnail,
 		"HasStory":      hasStory,
 		"ThumbnailSize": thumbnail.SmallSize,
 		"ChunkUpload":   s.chunkUpload,
 		"VapidKey":      s.pushService.GetPublicKey(),
+		"TagNames":      tagNames,
+		"GroupEvents":   events,
+		"Events":        eventSections,
+		"Following":     s.isFollowing(ctx, request),
+		"LiveChanges":   true,
 	}
 
 	if request.CanShare {
@@ -134,6 +162,17 @@ func (s *Service) list(ctx context.Context, request provider.Request, message re
 
 	if request.CanWebhook {
 		content["Webhooks"] = s.webhook.List()
+
+		deliveries, err := s.webhook.Deliveries(ctx)
+		if err != nil {
+			listLogger(item.Pathname).ErrorContext(ctx, "list webhook deliveries", "error", err)
+		}
+
+		content["WebhookLogs"] = deliveries
+	}
+
+	if request.CanEdit && request.Share.IsZero() {
+		content["Albums"] = s.album.List()
 	}
 
 	return renderer.NewPage("files", http.StatusOK, content), nil
@@ -147,7 +186,7 @@ func (s *Service) enrichThumbnail(ctx context.Context, directoryAggregate provid
 
 		hasThumbnail = true
 
-		if cover.IsZero() || (len(directoryAggregate.Cover) != 0 && cover.Img.Name() != directoryAggregate.Cover) {
+		if isBetterCover(cover, item, directoryAggregate.Cover) {
 			cover = newCover(item, thumbnail.SmallSize)
 		}
 
@@ -0,0 +1,236 @@
+package crud
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/inbox"
+	"github.com/ViBiOh/fibr/pkg/live"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/fibr/pkg/thumbnail"
+	"github.com/ViBiOh/httputils/v4/pkg/httperror"
+	"github.com/ViBiOh/httputils/v4/pkg/query"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+)
+
+// liveTile is a change of the directory as streamed to the browser, with the rendered tile of the item while it's listed
+type liveTile struct {
+	Name   string `json:"name"`
+	New    string `json:"new,omitempty"`
+	Before string `json:"before,omitempty"`
+	HTML   string `json:"html,omitempty"`
+	Count  int    `json:"count"`
+}
+
+// streamLive sends, as server-sent events on a single stream per page, the unread count and the new entries of the user's inbox
+// and, with `changes`, the changes of the items in the directory. Paths are relative to the share, if any
+func (s *Service) streamLive(w http.ResponseWriter, r *http.Request, request provider.Request, item absto.Item) {
+	ctx := r.Context()
+	user := inbox.User(request.Actor())
+
+	notifications, unsubscribeInbox := s.inbox.Subscribe(user)
+	defer unsubscribeInbox()
+
+	// A nil channel is never ready, pages other than the listing only get the inbox
+	var changes <-chan live.Change
+
+	if query.GetBool(r, "changes") {
+		var unsubscribeLive func()
+
+		changes, unsubscribeLive = s.live.Subscribe(provider.Dirname(request.Filepath()))
+		defer unsubscribeLive()
+	}
+
+	count, err := s.inbox.Unread(ctx, user)
+	if err != nil {
+		httperror.InternalServerError(ctx, w, err)
+		return
+	}
+
+	stream, err := provider.NewEventStream(w)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "open live stream", slog.Any("error", err))
+		return
+	}
+
+	if err = stream.Send("unread", count); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(provider.SSEKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-s.inbox.Done():
+			return
+
+		case <-s.live.Done():
+			return
+
+		case <-keepAlive.C:
+			err = stream.KeepAlive()
+
+		case notification := <-notifications:
+			if notification.Entry != nil {
+				if err = stream.Send("entry", notification.Entry.View(request.Share)); err != nil {
+					return
+				}
+			}
+
+			err = stream.Send("unread", notification.Unread)
+
+		case change := <-changes:
+			tile, tileErr := s.liveTile(r, request, item, change)
+			if tileErr != nil {
+				slog.LogAttrs(ctx, slog.LevelError, "render live tile", slog.String("item", item.Pathname), slog.String("name", change.Name), slog.Any("error", tileErr))
+				continue
+			}
+
+			err = stream.Send(change.Type, tile)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// liveTile renders the tile of the changed item as it's listed, with the name of the tile it goes before. An item removed, or now grouped with another one, has no tile
+func (s *Service) liveTile(r *http.Request, request provider.Request, directory absto.Item, change live.Change) (liveTile, error) {
+	ctx := r.Context()
+
+	tile := liveTile{
+		Name: change.Name,
+		New:  change.New,
+	}
+
+	files, err := s.storage.List(ctx, request.Filepath())
+	if err != nil {
+		return tile, fmt.Errorf("list: %w", err)
+	}
+
+	sort.Sort(provider.ByHybridSort(files))
+
+	files, subtitles := provider.GroupSubtitles(files)
+	files, companions := provider.GroupCompanions(files)
+
+	tile.Count = len(files)
+
+	name := change.Name
+	if len(change.New) != 0 {
+		name = change.New
+	}
+
+	index := slices.IndexFunc(files, func(item absto.Item) bool {
+		return item.Name() == name
+	})
+	if index == -1 {
+		return tile, nil
+	}
+
+	if index+1 < len(files) {
+		tile.Before = files[index+1].Name()
+	}
+
+	item := files[index]
+
+	renderItem := provider.StorageToRender(item, request)
+	renderItem.Subtitles = subtitles[item.ID]
+	renderItem.Companions = companions[item.ID]
+
+	if err = s.enrichTile(ctx, directory, &renderItem); err != nil {
+		return tile, err
+	}
+
+	tile.HTML, err = s.renderTile(r, request, renderItem)
+
+	return tile, err
+}
+
+func (s *Service) enrichTile(ctx context.Context, directory absto.Item, item *provider.RenderItem) error {
+	metadatas, err := s.metadata.GetAllMetadataFor(ctx, item.Item)
+	if err != nil {
+		return fmt.Errorf("get metadata: %w", err)
+	}
+
+	item.Tags = metadatas[item.ID].Tags
+	item.Rating = metadatas[item.ID].Rating
+	item.Favorite = metadatas[item.ID].Favorite
+
+	if item.IsDir() {
+		aggregates, err := s.metadata.GetAllAggregateFor(ctx, item.Item)
+		if err != nil {
+			return fmt.Errorf("get aggregate: %w", err)
+		}
+
+		item.Aggregate = aggregates[item.ID]
+
+		return nil
+	}
+
+	directoryAggregate, err := s.metadata.GetAggregateFor(ctx, directory)
+	if err != nil && !absto.IsNotExist(err) {
+		return fmt.Errorf("get directory aggregate: %w", err)
+	}
+
+	item.IsCover = item.Name() == directoryAggregate.Cover
+	item.HasThumbnail = s.thumbnail.HasThumbnail(ctx, item.Item, thumbnail.SmallSize)
+
+	return nil
+}
+
+// renderTile renders the tile with the templates of the listing, so it's exactly the one of a page reload
+func (s *Service) renderTile(r *http.Request, request provider.Request, item provider.RenderItem) (string, error) {
+	var recorder tileRecorder
+
+	s.renderer.Serve(&recorder, r, renderer.NewPage("file-tile", http.StatusOK, map[string]any{
+		"Request": request,
+		"Files":   []provider.RenderItem{item},
+	}))
+
+	if recorder.status != http.StatusOK {
+		return "", fmt.Errorf("render tile: status %d", recorder.status)
+	}
+
+	return recorder.body.String(), nil
+}
+
+// tileRecorder keeps a rendered tile in memory, for being streamed
+type tileRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (tr *tileRecorder) Header() http.Header {
+	if tr.header == nil {
+		tr.header = make(http.Header)
+	}
+
+	return tr.header
+}
+
+func (tr *tileRecorder) Write(content []byte) (int, error) {
+	if tr.status == 0 {
+		tr.status = http.StatusOK
+	}
+
+	return tr.body.Write(content)
+}
+
+func (tr *tileRecorder) WriteHeader(status int) {
+	if tr.status == 0 {
+		tr.status = status
+	}
+}
@@ -0,0 +1,252 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/query"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
+)
+
+const memoriesDayLayout = "2006-01-02"
+
+var memoriesFilename = provider.MetadataDirectoryName + "/memories.json"
+
+type memoryYear struct {
+	Files []provider.StoryItem
+	Year  int
+	Ago   int
+}
+
+type memoryScope struct {
+	pathname  string
+	recursive bool
+}
+
+type memoriesState struct {
+	Sent string `json:"sent"`
+}
+
+func (s *Service) Done() <-chan struct{} {
+	return s.done
+}
+
+// Start sends once a day the memories of the folders having a webhook subscribed to them
+func (s *Service) Start(ctx context.Context) {
+	defer close(s.done)
+
+	if len(s.memoriesAt) == 0 {
+		return
+	}
+
+	memoriesCron := s.cron.Days().At(s.memoriesAt).In(s.memoriesZone.String()).OnError(func(ctx context.Context, err error) {
+		slog.LogAttrs(ctx, slog.LevelError, "send memories", slog.Any("error", err))
+	}).OnSignal(syscall.SIGUSR2)
+
+	if s.exclusive.Enabled() {
+		memoriesCron.Exclusive(s, "memories", time.Hour)
+	}
+
+	memoriesCron.Start(ctx, s.sendMemories)
+}
+
+func (s *Service) Exclusive(ctx context.Context, name string, duration time.Duration, action func(ctx context.Context) error) (bool, error) {
+	return s.exclusive.Try(ctx, "fibr:mutex:"+name, duration, action)
+}
+
+func (s *Service) memories(r *http.Request, request provider.Request, item absto.Item, message renderer.Message) (renderer.Page, error) {
+	ctx := r.Context()
+	telemetry.SetRouteTag(ctx, "/memories")
+
+	today := time.Now().In(s.memoriesZone)
+
+	files, err := s.memoryFiles(ctx, item, !query.GetBool(r, "flat"), today)
+	if err != nil {
+		return errorReturn(request, err)
+	}
+
+	return renderer.NewPage("memories", http.StatusOK, map[string]any{
+		"Paths":    getPathParts(request),
+		"Request":  request,
+		"Message":  message,
+		"Today":    today,
+		"Years":    s.memoryYears(ctx, request, files, today),
+		"Count":    len(files),
+		"Memories": true,
+	}), nil
+}
+
+func (s *Service) memoryFiles(ctx context.Context, dir absto.Item, recursive bool, today time.Time) ([]timelineFile, error) {
+	return s.datedFiles(ctx, dir, recursive, func(aggregate provider.Aggregate) bool {
+		return aggregateRemembers(aggregate, today)
+	}, func(date time.Time) bool {
+		return isMemory(date, today)
+	})
+}
+
+// isMemory checks if the date is the same day of a previous year. Memories of the 29th of February come back on the 28th in common years.
+func isMemory(date, today time.Time) bool {
+	if date.Year() >= today.Year() {
+		return false
+	}
+
+	if date.Month() == today.Month() && date.Day() == today.Day() {
+		return true
+	}
+
+	return date.Month() == time.February && date.Day() == 29 && today.Month() == time.February && today.Day() == 28 && !isLeapYear(today.Year())
+}
+
+func isLeapYear(year int) bool {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() == 366
+}
+
+// aggregateRemembers checks if the dates of the aggregate contain the same day of a previous year, with a day of margin for the time zones. An aggregate without dates may not be computed yet.
+func aggregateRemembers(aggregate provider.Aggregate, today time.Time) bool {
+	if aggregate.Start.IsZero() || aggregate.End.IsZero() {
+		return true
+	}
+
+	for year := aggregate.Start.Year(); year < today.Year() && year <= aggregate.End.Year(); year++ {
+		day := time.Date(year, today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+		if aggregate.End.After(day.AddDate(0, 0, -1)) && aggregate.Start.Before(day.AddDate(0, 0, 2)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Service) memoryYears(ctx context.Context, request provider.Request, files []timelineFile, today time.Time) []memoryYear {
+	var output []memoryYear
+
+	for _, file := range files {
+		year := file.metadata.Date.Year()
+
+		if len(output) == 0 || output[len(output)-1].Year != year {
+			output = append(output, memoryYear{Year: year, Ago: today.Year() - year})
+		}
+
+		current := &output[len(output)-1]
+		current.Files = append(current.Files, s.timelineStoryItem(ctx, request, file))
+	}
+
+	return output
+}
+
+func (s *Service) sendMemories(ctx context.Context) error {
+	today := time.Now().In(s.memoriesZone)
+	day := today.Format(memoriesDayLayout)
+
+	state, err := provider.LoadJSON[memoriesState](ctx, s.rawStorage, memoriesFilename)
+	if err != nil && !absto.IsNotExist(err) {
+		return fmt.Errorf("load memories state: %w", err)
+	}
+
+	if state.Sent == day {
+		return nil
+	}
+
+	for _, scope := range memoryScopes(s.webhook.List()) {
+		if err := s.sendMemory(ctx, scope, today); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "send memory", slog.String("item", scope.pathname), slog.Any("error", err))
+		}
+	}
+
+	if err := provider.SaveJSON(ctx, s.rawStorage, memoriesFilename, memoriesState{Sent: day}); err != nil {
+		return fmt.Errorf("save memories state: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) sendMemory(ctx context.Context, scope memoryScope, today time.Time) error {
+	item, err := s.storage.Stat(ctx, scope.pathname)
+	if err != nil {
+		return fmt.Errorf("stat: %w", err)
+	}
+
+	files, err := s.memoryFiles(ctx, item, scope.recursive, today)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	// Notification services load the cover without credentials, it's left out when no share makes it public
+	var coverURL string
+	if cover := bestMemory(files); s.thumbnail.CanHaveThumbnail(cover.item) {
+		if coverPath := s.publicSharePath(cover.item.Pathname); len(coverPath) != 0 {
+			coverURL = s.renderer.PublicURL(coverPath + "?thumbnail")
+		}
+	}
+
+	s.pushEvent(ctx, provider.NewMemoryEvent(ctx, item, scope.recursive, s.bestSharePath(item.Pathname), len(files), memoryYearsText(files), coverURL, s.renderer))
+
+	return nil
+}
+
+// memoryScopes lists the distinct folders of the webhooks subscribed to memories, so a folder is walked only once
+func memoryScopes(webhooks []provider.Webhook) []memoryScope {
+	var output []memoryScope
+
+	for _, webhook := range webhooks {
+		if !slices.Contains(webhook.Types, provider.MemoryEvent) {
+			continue
+		}
+
+		scope := memoryScope{pathname: webhook.Pathname, recursive: webhook.Recursive}
+		if !slices.Contains(output, scope) {
+			output = append(output, scope)
+		}
+	}
+
+	return output
+}
+
+// bestMemory picks the favorite and best rated file, the most recent on equality
+func bestMemory(files []timelineFile) timelineFile {
+	var output timelineFile
+
+	for index, file := range files {
+		if index == 0 || memoryRank(file.metadata) > memoryRank(output.metadata) {
+			output = file
+		}
+	}
+
+	return output
+}
+
+func memoryRank(metadata provider.Metadata) int {
+	rank := metadata.Rating
+	if metadata.Favorite {
+		rank += provider.MaxRating + 1
+	}
+
+	return rank
+}
+
+func memoryYearsText(files []timelineFile) string {
+	var years []string
+
+	for _, file := range files {
+		if year := strconv.Itoa(file.metadata.Date.Year()); !slices.Contains(years, year) {
+			years = append(years, year)
+		}
+	}
+
+	return strings.Join(years, ", ")
+}
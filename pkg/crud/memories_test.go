@@ -0,0 +1,132 @@
+package crud
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestIsMemory(t *testing.T) {
+	t.Parallel()
+
+	today := time.Date(2026, 7, 14, 9, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		date  time.Time
+		today time.Time
+		want  bool
+	}{
+		"same day": {
+			time.Date(2019, 7, 14, 18, 30, 0, 0, time.UTC),
+			today,
+			true,
+		},
+		"this year": {
+			time.Date(2026, 7, 14, 8, 0, 0, 0, time.UTC),
+			today,
+			false,
+		},
+		"other day": {
+			time.Date(2019, 7, 13, 18, 30, 0, 0, time.UTC),
+			today,
+			false,
+		},
+		"leap day on common year": {
+			time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC),
+			time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC),
+			true,
+		},
+		"leap day on leap year": {
+			time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC),
+			time.Date(2028, 2, 28, 9, 0, 0, 0, time.UTC),
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isMemory(tc.date, tc.today); got != tc.want {
+				t.Errorf("isMemory() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAggregateRemembers(t *testing.T) {
+	t.Parallel()
+
+	today := time.Date(2026, 7, 14, 9, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		aggregate provider.Aggregate
+		want      bool
+	}{
+		"not computed": {
+			provider.Aggregate{},
+			true,
+		},
+		"holidays": {
+			provider.Aggregate{Start: time.Date(2021, 7, 10, 0, 0, 0, 0, time.UTC), End: time.Date(2021, 7, 20, 0, 0, 0, 0, time.UTC)},
+			true,
+		},
+		"several years": {
+			provider.Aggregate{Start: time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)},
+			true,
+		},
+		"other month": {
+			provider.Aggregate{Start: time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2021, 8, 20, 0, 0, 0, 0, time.UTC)},
+			false,
+		},
+		"this year": {
+			provider.Aggregate{Start: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC)},
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := aggregateRemembers(tc.aggregate, today); got != tc.want {
+				t.Errorf("aggregateRemembers() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemoryScopes(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		webhooks []provider.Webhook
+		want     []memoryScope
+	}{
+		"empty": {
+			nil,
+			nil,
+		},
+		"subscribed": {
+			[]provider.Webhook{
+				{Pathname: "/photos/", Recursive: true, Types: []provider.EventType{provider.UploadEvent}},
+				{Pathname: "/photos/", Recursive: true, Types: []provider.EventType{provider.MemoryEvent}},
+				{Pathname: "/photos/", Recursive: true, Types: []provider.EventType{provider.UploadEvent, provider.MemoryEvent}},
+				{Pathname: "/photos/", Types: []provider.EventType{provider.MemoryEvent}},
+			},
+			[]memoryScope{{pathname: "/photos/", recursive: true}, {pathname: "/photos/"}},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := memoryScopes(tc.webhooks); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("memoryScopes() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+)
+
+var (
+	datetimeLocalLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+	errNothingToEdit = errors.New("nothing to edit")
+	errDateConflict  = errors.New("date can be set or shifted, not both")
+	errNoSelection   = errors.New("no file selected")
+)
+
+func (s *Service) handlePostMetadata(w http.ResponseWriter, r *http.Request, request provider.Request) {
+	if !request.CanEdit {
+		s.error(w, r, request, model.WrapForbidden(ErrNotAuthorized))
+		return
+	}
+
+	opts, err := parseMetadataEdit(r)
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	items, err := s.selectedFiles(ctx, request, r.Form["names"])
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	if err = s.metadata.Edit(ctx, items, opts...); err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	redirection := fmt.Sprintf("?d=%s", request.Display)
+	if len(items) == 1 {
+		redirection += "#" + items[0].ID
+	}
+
+	s.renderer.Redirect(w, r, redirection, renderer.NewSuccessMessage("Metadata of %d file(s) successfully edited", len(items)))
+}
+
+// selectedFiles resolves the files selected in a form, relative to the request
+func (s *Service) selectedFiles(ctx context.Context, request provider.Request, names []string) ([]absto.Item, error) {
+	if len(names) == 0 {
+		return nil, model.WrapInvalid(errNoSelection)
+	}
+
+	items := make([]absto.Item, 0, len(names))
+
+	for _, name := range names {
+		if len(name) == 0 || name == "/" {
+			return nil, model.WrapInvalid(ErrEmptyName)
+		}
+
+		item, err := s.storage.Stat(ctx, request.SubPath(name))
+		if err != nil {
+			return nil, err
+		}
+
+		if item.IsDir() {
+			return nil, model.WrapInvalid(fmt.Errorf("`%s` is a directory", name))
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// handlePostRating rates the file of the browse page
+func (s *Service) handlePostRating(w http.ResponseWriter, r *http.Request, request provider.Request) {
+	if !request.CanEdit {
+		s.error(w, r, request, model.WrapForbidden(ErrNotAuthorized))
+		return
+	}
+
+	opts, err := parseRatingEdit(r)
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	item, err := s.storage.Stat(ctx, request.Filepath())
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	if item.IsDir() {
+		s.error(w, r, request, model.WrapInvalid(fmt.Errorf("`%s` is a directory", item.Name())))
+		return
+	}
+
+	if _, err = s.metadata.Update(ctx, item, opts...); err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	s.renderer.Redirect(w, r, "?browser", renderer.NewSuccessMessage("Rating successfully edited"))
+}
+
+func parseRatingEdit(r *http.Request) ([]provider.MetadataAction, error) {
+	var opts []provider.MetadataAction
+
+	if rawRating := strings.TrimSpace(r.FormValue("rating")); len(rawRating) != 0 {
+		rating, err := strconv.Atoi(rawRating)
+		if err != nil {
+			return nil, model.WrapInvalid(fmt.Errorf("parse rating: %w", err))
+		}
+
+		if rating < 0 || rating > provider.MaxRating {
+			return nil, model.WrapInvalid(fmt.Errorf("rating `%d` is out of range", rating))
+		}
+
+		opts = append(opts, provider.ReplaceRating(rating))
+	}
+
+	if rawFavorite := strings.TrimSpace(r.FormValue("favorite")); len(rawFavorite) != 0 {
+		favorite, err := strconv.ParseBool(rawFavorite)
+		if err != nil {
+			return nil, model.WrapInvalid(fmt.Errorf("parse favorite: %w", err))
+		}
+
+		opts = append(opts, provider.ReplaceFavorite(favorite))
+	}
+
+	if len(opts) == 0 {
+		return nil, model.WrapInvalid(errNothingToEdit)
+	}
+
+	return opts, nil
+}
+
+func parseMetadataEdit(r *http.Request) ([]provider.MetadataAction, error) {
+	var opts []provider.MetadataAction
+
+	rawDate := strings.TrimSpace(r.FormValue("date"))
+	rawShift := strings.TrimSpace(r.FormValue("shift"))
+
+	switch {
+	case len(rawDate) != 0 && len(rawShift) != 0:
+		return nil, model.WrapInvalid(errDateConflict)
+
+	case len(rawDate) != 0:
+		date, err := parseDatetimeLocal(rawDate, strings.TrimSpace(r.FormValue("timezone")))
+		if err != nil {
+			return nil, model.WrapInvalid(err)
+		}
+
+		opts = append(opts, provider.ReplaceDate(date))
+
+	case len(rawShift) != 0:
+		offset, err := time.ParseDuration(rawShift)
+		if err != nil {
+			return nil, model.WrapInvalid(fmt.Errorf("parse shift: %w", err))
+		}
+
+		opts = append(opts, provider.ShiftDate(offset))
+	}
+
+	rawLatitude := strings.TrimSpace(r.FormValue("latitude"))
+	rawLongitude := strings.TrimSpace(r.FormValue("longitude"))
+
+	if len(rawLatitude) != 0 || len(rawLongitude) != 0 {
+		latitude, err := parseCoordinate(rawLatitude, 90)
+		if err != nil {
+			return nil, model.WrapInvalid(fmt.Errorf("parse latitude: %w", err))
+		}
+
+		longitude, err := parseCoordinate(rawLongitude, 180)
+		if err != nil {
+			return nil, model.WrapInvalid(fmt.Errorf("parse longitude: %w", err))
+		}
+
+		opts = append(opts, provider.ReplaceLocation(latitude, longitude))
+	}
+
+	if len(opts) == 0 {
+		return nil, model.WrapInvalid(errNothingToEdit)
+	}
+
+	return opts, nil
+}
+
+// parseDatetimeLocal parses the value of a datetime-local input, in the given IANA time zone or offset, UTC if empty
+func parseDatetimeLocal(value, zone string) (time.Time, error) {
+	location := time.UTC
+
+	if len(zone) != 0 {
+		if offset, err := time.Parse("-07:00", zone); err == nil {
+			location = offset.Location()
+		} else if location, err = time.LoadLocation(zone); err != nil {
+			return time.Time{}, fmt.Errorf("load timezone `%s`: %w", zone, err)
+		}
+	}
+
+	for _, layout := range datetimeLocalLayouts {
+		if date, err := time.ParseInLocation(layout, value, location); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("parse date `%s`", value)
+}
+
+func parseCoordinate(value string, limit float64) (float64, error) {
+	coordinate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if coordinate < -limit || coordinate > limit {
+		return 0, fmt.Errorf("`%s` is out of range", value)
+	}
+
+	return coordinate, nil
+}
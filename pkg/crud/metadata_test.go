@@ -0,0 +1,218 @@
+package crud
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	exas "github.com/ViBiOh/exas/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestParseMetadataEdit(t *testing.T) {
+	t.Parallel()
+
+	captured := time.Date(2024, 7, 14, 10, 0, 0, 0, time.UTC)
+	initial := provider.Metadata{
+		Exif: exas.Exif{
+			Date: captured,
+			Geocode: exas.Geocode{
+				Address:   map[string]string{"city": "Paris"},
+				Latitude:  48.8,
+				Longitude: 2.3,
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		form    url.Values
+		want    provider.Metadata
+		wantErr error
+	}{
+		"nothing": {
+			url.Values{"date": {" "}},
+			provider.Metadata{},
+			errNothingToEdit,
+		},
+		"conflict": {
+			url.Values{"date": {"2024-07-14T12:00"}, "shift": {"2h"}},
+			provider.Metadata{},
+			errDateConflict,
+		},
+		"set date with offset": {
+			url.Values{"date": {"2024-07-14T12:00:30"}, "timezone": {"+02:00"}},
+			provider.Metadata{
+				Exif: exas.Exif{
+					Date:    time.Date(2024, 7, 14, 10, 0, 30, 0, time.UTC),
+					Geocode: initial.Geocode,
+				},
+				ManualDate: true,
+			},
+			nil,
+		},
+		"set date with time zone": {
+			url.Values{"date": {"2024-01-14T12:00"}, "timezone": {"Europe/Paris"}},
+			provider.Metadata{
+				Exif: exas.Exif{
+					Date:    time.Date(2024, 1, 14, 11, 0, 0, 0, time.UTC),
+					Geocode: initial.Geocode,
+				},
+				ManualDate: true,
+			},
+			nil,
+		},
+		"shift and locate": {
+			url.Values{"shift": {"-1h30m"}, "latitude": {"45.5"}, "longitude": {"-73.56"}},
+			provider.Metadata{
+				Exif: exas.Exif{
+					Date: captured.Add(-90 * time.Minute),
+					Geocode: exas.Geocode{
+						Latitude:  45.5,
+						Longitude: -73.56,
+					},
+				},
+				ManualDate:     true,
+				ManualLocation: true,
+			},
+			nil,
+		},
+		"invalid shift": {
+			url.Values{"shift": {"yesterday"}},
+			provider.Metadata{},
+			errors.New("parse shift"),
+		},
+		"missing longitude": {
+			url.Values{"latitude": {"45.5"}},
+			provider.Metadata{},
+			errors.New("parse longitude"),
+		},
+		"out of range": {
+			url.Values{"latitude": {"95"}, "longitude": {"2"}},
+			provider.Metadata{},
+			errors.New("out of range"),
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.form.Encode()))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			opts, gotErr := parseMetadataEdit(request)
+
+			failed := false
+
+			switch {
+			case tc.wantErr == nil && gotErr != nil:
+				failed = true
+			case tc.wantErr != nil && gotErr == nil:
+				failed = true
+			case tc.wantErr != nil && !errors.Is(gotErr, tc.wantErr) && !strings.Contains(gotErr.Error(), tc.wantErr.Error()):
+				failed = true
+			}
+
+			if failed {
+				t.Errorf("parseMetadataEdit() = %v, want %v", gotErr, tc.wantErr)
+				return
+			}
+
+			if gotErr != nil {
+				return
+			}
+
+			got := initial
+			for _, opt := range opts {
+				got = opt(got)
+			}
+
+			got.Date = got.Date.UTC()
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseMetadataEdit() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseRatingEdit(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		form    url.Values
+		want    provider.Metadata
+		wantErr error
+	}{
+		"nothing": {
+			url.Values{"rating": {" "}},
+			provider.Metadata{},
+			errNothingToEdit,
+		},
+		"rate": {
+			url.Values{"rating": {"4"}},
+			provider.Metadata{Rating: 4, Favorite: true},
+			nil,
+		},
+		"unfavorite and unrate": {
+			url.Values{"rating": {"0"}, "favorite": {"false"}},
+			provider.Metadata{},
+			nil,
+		},
+		"out of range": {
+			url.Values{"rating": {"6"}},
+			provider.Metadata{},
+			errors.New("out of range"),
+		},
+		"invalid favorite": {
+			url.Values{"favorite": {"maybe"}},
+			provider.Metadata{},
+			errors.New("parse favorite"),
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.form.Encode()))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			opts, gotErr := parseRatingEdit(request)
+
+			failed := false
+
+			switch {
+			case tc.wantErr == nil && gotErr != nil:
+				failed = true
+			case tc.wantErr != nil && gotErr == nil:
+				failed = true
+			case tc.wantErr != nil && !errors.Is(gotErr, tc.wantErr) && !strings.Contains(gotErr.Error(), tc.wantErr.Error()):
+				failed = true
+			}
+
+			if failed {
+				t.Errorf("parseRatingEdit() = %v, want %v", gotErr, tc.wantErr)
+				return
+			}
+
+			if gotErr != nil {
+				return
+			}
+
+			got := provider.Metadata{Rating: 2, Favorite: true}
+			for _, opt := range opts {
+				got = opt(got)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseRatingEdit() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -26,13 +26,39 @@ func (c cover) IsZero() bool {
 	return c.Img.IsZero()
 }
 
-func (s *Service) getCover(ctx context.Context, request provider.Request, files []absto.Item) (output cover) {
-	for _, file := range files {
-		if s.thumbnail.HasThumbnail(ctx, file, thumbnail.SmallSize) {
-			output = newCover(provider.StorageToRender(file, request), thumbnail.SmallSize)
-			return output
+// getCover picks the first file having a thumbnail, favorites first
+func (s *Service) getCover(ctx context.Context, request provider.Request, files []absto.Item, metadatas map[string]provider.Metadata) (output cover) {
+	for _, favorite := range []bool{true, false} {
+		for _, file := range files {
+			if favorite && !metadatas[file.ID].Favorite {
+				continue
+			}
+
+			if s.thumbnail.HasThumbnail(ctx, file, thumbnail.SmallSize) {
+				return newCover(provider.StorageToRender(file, request), thumbnail.SmallSize)
+			}
 		}
 	}
 
 	return output
 }
+
+// isBetterCover ranks the cover chosen by the user first, then the favorites, then the first candidate
+func isBetterCover(current cover, candidate provider.RenderItem, chosen string) bool {
+	if current.IsZero() {
+		return true
+	}
+
+	return coverRank(candidate, chosen) > coverRank(current.Img, chosen)
+}
+
+func coverRank(item provider.RenderItem, chosen string) int {
+	switch {
+	case len(chosen) != 0 && item.Name() == chosen:
+		return 2
+	case item.Favorite:
+		return 1
+	default:
+		return 0
+	}
+}
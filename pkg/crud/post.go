@@ -94,10 +94,42 @@ func (s *Service) handleFormURLEncoded(w http.ResponseWriter, r *http.Request, r
 		telemetry.SetRouteTag(ctx, "/webhook")
 		s.handlePostWebhook(w, r, request, method)
 
+	case "push":
+		telemetry.SetRouteTag(ctx, "/push")
+		s.handlePostPush(w, r, request, method)
+
+	case "inbox":
+		telemetry.SetRouteTag(ctx, "/inbox")
+		s.handlePostInbox(w, r, request, method)
+
 	case "description":
 		telemetry.SetRouteTag(ctx, "/description")
 		s.handlePostDescription(w, r, request)
 
+	case "metadata":
+		telemetry.SetRouteTag(ctx, "/metadata")
+		s.handlePostMetadata(w, r, request)
+
+	case "geotag":
+		telemetry.SetRouteTag(ctx, "/geotag")
+		s.handlePostGeotag(w, r, request)
+
+	case "tag":
+		telemetry.SetRouteTag(ctx, "/tag")
+		s.handlePostTag(w, r, request, method)
+
+	case "album":
+		telemetry.SetRouteTag(ctx, "/album")
+		s.handlePostAlbum(w, r, request, method)
+
+	case "rating":
+		telemetry.SetRouteTag(ctx, "/rating")
+		s.handlePostRating(w, r, request)
+
+	case "event":
+		telemetry.SetRouteTag(ctx, "/event")
+		s.handlePostEvent(w, r, request)
+
 	default:
 		s.handlePost(w, r, request, method)
 	}
@@ -192,6 +224,10 @@ func (s *Service) handlePostWebhook(w http.ResponseWriter, r *http.Request, requ
 		s.createWebhook(w, r, request)
 	case http.MethodDelete:
 		s.deleteWebhook(w, r, request)
+	case http.MethodPatch:
+		s.fireWebhook(w, r, request)
+	case http.MethodPut:
+		s.rotateWebhook(w, r, request)
 	default:
 		s.error(w, r, request, model.WrapMethodNotAllowed(fmt.Errorf("unknown webhook method `%s` for %s", method, r.URL.Path)))
 	}
@@ -219,7 +255,12 @@ func (s *Service) handlePostDescription(w http.ResponseWriter, r *http.Request,
 
 	description := r.FormValue("description")
 
-	if _, err = s.metadata.Update(ctx, item, provider.ReplaceDescription(description)); err != nil {
+	opts := []provider.MetadataAction{provider.ReplaceDescription(description)}
+	if r.Form.Has("tags") {
+		opts = append(opts, provider.ReplaceTags(parseTags(r.Form.Get("tags"))))
+	}
+
+	if _, err = s.metadata.Update(ctx, item, opts...); err != nil {
 		s.error(w, r, request, err)
 		return
 	}
@@ -0,0 +1,215 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/fibr/pkg/push"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+)
+
+var (
+	pushEventTypes = []provider.EventType{provider.UploadEvent, provider.CreateDir, provider.RenameEvent, provider.DeleteEvent, provider.DescriptionEvent, provider.MemoryEvent}
+	pushWindows    = []pushWindow{{time.Minute * 5, "5 minutes"}, {time.Minute * 15, "15 minutes"}, {time.Minute * 30, "30 minutes"}, {time.Hour, "1 hour"}, {time.Hour * 3, "3 hours"}}
+)
+
+type pushWindow struct {
+	Duration time.Duration
+	Label    string
+}
+
+// pushDevice is a push subscription, with the folders it's notified of
+type pushDevice struct {
+	Subscription push.Subscription
+	Webhooks     []provider.Webhook
+}
+
+// pushDevices returns the subscriptions visible by the actor, the most recent first, with their webhooks sorted by path
+func pushDevices(subscriptions []push.Subscription, webhooks []provider.Webhook, actor string, admin bool) []pushDevice {
+	var output []pushDevice
+
+	for _, subscription := range subscriptions {
+		if !subscription.VisibleBy(actor, admin) {
+			continue
+		}
+
+		device := pushDevice{Subscription: subscription}
+
+		for _, webhook := range webhooks {
+			if webhook.Kind == provider.Push && webhook.URL == subscription.Endpoint {
+				device.Webhooks = append(device.Webhooks, webhook)
+			}
+		}
+
+		slices.SortFunc(device.Webhooks, func(a, b provider.Webhook) int {
+			return strings.Compare(a.Pathname, b.Pathname)
+		})
+
+		output = append(output, device)
+	}
+
+	slices.SortStableFunc(output, func(a, b pushDevice) int {
+		return b.Subscription.Created.Compare(a.Subscription.Created)
+	})
+
+	return output
+}
+
+func (s *Service) subscriptions(r *http.Request, request provider.Request, message renderer.Message) (renderer.Page, error) {
+	if s.pushService == nil {
+		return errorReturn(request, model.WrapNotFound(push.ErrNoConfig))
+	}
+
+	subscriptions, err := s.pushService.List(r.Context())
+	if err != nil {
+		return errorReturn(request, model.WrapInternal(err))
+	}
+
+	return renderer.NewPage("subscriptions", http.StatusOK, map[string]any{
+		"Paths":         getPathParts(request),
+		"Request":       request,
+		"Message":       message,
+		"Devices":       pushDevices(subscriptions, s.webhook.List(), request.Actor(), request.CanWebhook),
+		"EventTypes":    pushEventTypes,
+		"Windows":       pushWindows,
+		"DefaultWindow": provider.DefaultPushDebounce,
+	}), nil
+}
+
+func (s *Service) handlePostPush(w http.ResponseWriter, r *http.Request, request provider.Request, method string) {
+	if s.pushService == nil {
+		s.error(w, r, request, model.WrapNotFound(push.ErrNoConfig))
+		return
+	}
+
+	switch method {
+	case http.MethodPut:
+		s.updatePush(w, r, request)
+	case http.MethodDelete:
+		s.deletePush(w, r, request)
+	default:
+		s.error(w, r, request, model.WrapMethodNotAllowed(fmt.Errorf("unknown push method `%s` for %s", method, r.URL.Path)))
+	}
+}
+
+// updatePush changes the event types, the grouping window and the quiet hours of a folder notified to a device
+func (s *Service) updatePush(w http.ResponseWriter, r *http.Request, request provider.Request) {
+	ctx := r.Context()
+
+	webhook, err := s.ownedPushWebhook(ctx, request, r.FormValue("id"))
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	if webhook.Types, err = checkWebhookTypes(r); err != nil {
+		s.error(w, r, request, model.WrapInvalid(err))
+		return
+	}
+
+	webhook.Debounce = 0
+	webhook.Quiet = provider.WebhookQuietHours{}
+
+	if err = checkPushPreferences(r, &webhook); err != nil {
+		s.error(w, r, request, model.WrapInvalid(err))
+		return
+	}
+
+	if err = s.webhook.Update(ctx, webhook); err != nil {
+		s.error(w, r, request, model.WrapInternal(err))
+		return
+	}
+
+	s.pushDone(w, r, request, renderer.NewSuccessMessage("Preferences for %s saved", webhook.Pathname))
+}
+
+// deletePush unsubscribes the device from a folder when an id is given, or removes the device and all its folders
+func (s *Service) deletePush(w http.ResponseWriter, r *http.Request, request provider.Request) {
+	ctx := r.Context()
+
+	if id := r.FormValue("id"); len(id) != 0 {
+		webhook, err := s.ownedPushWebhook(ctx, request, id)
+		if err != nil {
+			s.error(w, r, request, err)
+			return
+		}
+
+		if err = s.webhook.Delete(ctx, webhook.ID); err != nil {
+			s.error(w, r, request, model.WrapInternal(err))
+			return
+		}
+
+		s.pushDone(w, r, request, renderer.NewSuccessMessage("Notifications for %s removed", webhook.Pathname))
+		return
+	}
+
+	subscription, err := s.ownedSubscription(ctx, request, r.FormValue("endpoint"))
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	if err = s.pushService.Delete(ctx, subscription.Endpoint); err != nil {
+		s.error(w, r, request, model.WrapInternal(err))
+		return
+	}
+
+	for _, webhook := range s.webhook.FindByURL(subscription.Endpoint) {
+		if webhook.Kind != provider.Push {
+			continue
+		}
+
+		if err = s.webhook.Delete(ctx, webhook.ID); err != nil {
+			s.error(w, r, request, model.WrapInternal(err))
+			return
+		}
+	}
+
+	s.pushDone(w, r, request, renderer.NewSuccessMessage("Device removed"))
+}
+
+func (s *Service) pushDone(w http.ResponseWriter, r *http.Request, request provider.Request, message renderer.Message) {
+	if r.Header.Get("Accept") == "text/plain" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.renderer.Redirect(w, r, request.AbsoluteURL("")+"?subscriptions", message)
+}
+
+func (s *Service) ownedPushWebhook(ctx context.Context, request provider.Request, id string) (provider.Webhook, error) {
+	webhook := s.webhook.Get(id)
+	if len(webhook.ID) == 0 || webhook.Kind != provider.Push {
+		return webhook, model.WrapNotFound(errors.New("push notification not found"))
+	}
+
+	if _, err := s.ownedSubscription(ctx, request, webhook.URL); err != nil {
+		return webhook, err
+	}
+
+	return webhook, nil
+}
+
+func (s *Service) ownedSubscription(ctx context.Context, request provider.Request, endpoint string) (push.Subscription, error) {
+	subscription, err := s.pushService.Find(ctx, endpoint)
+	if err != nil {
+		if errors.Is(err, push.ErrNotFound) {
+			return subscription, model.WrapNotFound(err)
+		}
+
+		return subscription, model.WrapInternal(err)
+	}
+
+	if !subscription.VisibleBy(request.Actor(), request.CanWebhook) {
+		return subscription, model.WrapForbidden(ErrNotAuthorized)
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,67 @@
+package crud
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/fibr/pkg/push"
+)
+
+func TestPushDevices(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 7, 17, 12, 0, 0, 0, time.UTC)
+
+	phone := push.Subscription{Endpoint: "https://push.local/phone", Owner: "user:alice", Device: "Firefox on Android", Created: now}
+	laptop := push.Subscription{Endpoint: "https://push.local/laptop", Owner: "user:alice", Device: "Firefox on Linux", Created: now.Add(-time.Hour)}
+	other := push.Subscription{Endpoint: "https://push.local/other", Owner: "user:bob", Created: now}
+	legacy := push.Subscription{Endpoint: "https://push.local/legacy"}
+
+	photos := provider.Webhook{ID: "1", Kind: provider.Push, URL: phone.Endpoint, Pathname: "/photos/"}
+	videos := provider.Webhook{ID: "2", Kind: provider.Push, URL: phone.Endpoint, Pathname: "/photos/videos/"}
+	documents := provider.Webhook{ID: "3", Kind: provider.Push, URL: laptop.Endpoint, Pathname: "/documents/"}
+	raw := provider.Webhook{ID: "4", Kind: provider.Raw, URL: phone.Endpoint, Pathname: "/"}
+
+	subscriptions := []push.Subscription{legacy, laptop, other, phone}
+	webhooks := []provider.Webhook{videos, documents, raw, photos}
+
+	cases := map[string]struct {
+		actor string
+		admin bool
+		want  []pushDevice
+	}{
+		"owner": {
+			"user:alice",
+			false,
+			[]pushDevice{
+				{Subscription: phone, Webhooks: []provider.Webhook{photos, videos}},
+				{Subscription: laptop, Webhooks: []provider.Webhook{documents}},
+			},
+		},
+		"admin": {
+			"user:bob",
+			true,
+			[]pushDevice{
+				{Subscription: other},
+				{Subscription: legacy},
+			},
+		},
+		"share": {
+			"share:a1b2c3d4",
+			false,
+			nil,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := pushDevices(subscriptions, webhooks, tc.actor, tc.admin); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("pushDevices() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -116,16 +116,21 @@ func (s *Service) Rename(w http.ResponseWriter, r *http.Request, request provide
 			}
 		}
 
-		var tags []string
-
-		if rawTags := r.Form.Get("tags"); len(rawTags) > 0 {
-			tags = strings.Split(rawTags, " ")
-		}
+		tags := parseTags(r.Form.Get("tags"))
 
 		if _, err = s.metadata.Update(ctx, newItem, provider.ReplaceTags(tags)); err != nil {
 			s.error(w, r, request, model.WrapInternal(err))
 			return
 		}
+
+		if !isSingle(r) {
+			groupItem := oldItem
+			if groupItem.IsZero() {
+				groupItem = newItem
+			}
+
+			s.renameGroup(ctx, groupItem, newItem, tags)
+		}
 	}
 
 	var message string
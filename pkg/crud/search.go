@@ -31,6 +31,8 @@ func (s *Service) search(r *http.Request, request provider.Request, item absto.I
 
 		metadata := metadatas[item.ID]
 		renderItem.Tags = metadata.Tags
+		renderItem.Rating = metadata.Rating
+		renderItem.Favorite = metadata.Favorite
 
 		if renderWithThumbnail && s.thumbnail.CanHaveThumbnail(item) && s.thumbnail.HasThumbnail(ctx, item, thumbnail.SmallSize) {
 			renderItem.HasThumbnail = true
@@ -46,7 +48,7 @@ func (s *Service) search(r *http.Request, request provider.Request, item absto.I
 	return renderer.NewPage("search", http.StatusOK, map[string]any{
 		"Paths":   getPathParts(request),
 		"Files":   items,
-		"Cover":   s.getCover(ctx, request, files),
+		"Cover":   s.getCover(ctx, request, files, metadatas),
 		"Search":  r.URL.Query(),
 		"Request": request,
 		"HasMap":  hasMap,
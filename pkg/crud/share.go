@@ -49,6 +49,32 @@ func (s *Service) bestSharePath(pathname string) string {
 	return ""
 }
 
+// publicSharePath gives the path of the item in the closest share without password, the only ones anyone can load
+func (s *Service) publicSharePath(pathname string) string {
+	var remainingPath string
+	var bestShare provider.Share
+
+	for _, share := range s.share.List() {
+		if len(share.Password) != 0 || !strings.HasPrefix(pathname, share.Path) {
+			continue
+		}
+
+		newRemainingPath := strings.TrimPrefix(pathname, share.Path)
+		if !bestShare.IsZero() && len(newRemainingPath) > len(remainingPath) {
+			continue
+		}
+
+		bestShare = share
+		remainingPath = newRemainingPath
+	}
+
+	if bestShare.IsZero() {
+		return ""
+	}
+
+	return provider.URL(remainingPath, "", bestShare)
+}
+
 func parseRights(value string) (edit, story bool, err error) {
 	switch value {
 	case "edit":
@@ -91,6 +117,16 @@ func (s *Service) createShare(w http.ResponseWriter, r *http.Request, request pr
 	ctx := r.Context()
 
 	info, err := s.storage.Stat(ctx, request.Filepath())
+	if err != nil && absto.IsNotExist(err) {
+		if id, name, ok := parseAlbumPath(request.Filepath()); ok && len(name) == 0 {
+			if album := s.album.Get(id); !album.IsZero() {
+				info, err = absto.Item{Pathname: album.Path(), NameValue: album.Name, IsDirValue: true}, nil
+				// Files of an album come from several folders, they are never editable through its share
+				edit = false
+			}
+		}
+	}
+
 	if err != nil {
 		if absto.IsNotExist(err) {
 			s.error(w, r, request, model.WrapNotFound(err))
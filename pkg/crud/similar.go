@@ -0,0 +1,171 @@
+package crud
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
+)
+
+// similar renders the groups of near-duplicate pictures of the folder
+func (s *Service) similar(r *http.Request, request provider.Request, item absto.Item, message renderer.Message) (renderer.Page, error) {
+	ctx := r.Context()
+	telemetry.SetRouteTag(ctx, "/similars")
+
+	items, err := s.storage.List(ctx, item.Pathname)
+	if err != nil {
+		return errorReturn(request, model.WrapInternal(err))
+	}
+
+	sort.Sort(provider.ByHybridSort(items))
+
+	items, _ = provider.GroupSubtitles(items)
+	items, _ = provider.GroupCompanions(items)
+
+	metadatas, err := s.metadata.GetAllMetadataFor(ctx, items...)
+	if err != nil {
+		return errorReturn(request, model.WrapInternal(err))
+	}
+
+	groups := similarGroups(items, metadatas, s.similarDistance)
+
+	var count int
+	renderGroups := make([][]provider.RenderItem, len(groups))
+
+	for index, group := range groups {
+		renderGroups[index] = s.similarRenderItems(request, group, metadatas)
+		count += len(group)
+	}
+
+	return renderer.NewPage("similar", http.StatusOK, map[string]any{
+		"Paths":   getPathParts(request),
+		"Request": request,
+		"Message": message,
+		"Groups":  renderGroups,
+		"Count":   count,
+	}), nil
+}
+
+// similarOf lists the pictures of the folder looking like the given one, closest first
+func (s *Service) similarOf(ctx context.Context, request provider.Request, item absto.Item, hash uint64, files []absto.Item) []provider.RenderItem {
+	if hash == 0 || len(files) == 0 {
+		return nil
+	}
+
+	files, _ = provider.GroupSubtitles(files)
+	files, _ = provider.GroupCompanions(files)
+
+	metadatas, err := s.metadata.GetAllMetadataFor(ctx, files...)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "list metadatas", slog.String("item", item.Pathname), slog.Any("error", err))
+		return nil
+	}
+
+	return s.similarRenderItems(request, similarTo(hash, item, files, metadatas, s.similarDistance), metadatas)
+}
+
+func (s *Service) similarRenderItems(request provider.Request, items []absto.Item, metadatas map[string]provider.Metadata) []provider.RenderItem {
+	output := make([]provider.RenderItem, len(items))
+
+	for index, item := range items {
+		renderItem := provider.StorageToRender(item, request)
+		renderItem.Rating = metadatas[item.ID].Rating
+		renderItem.Favorite = metadatas[item.ID].Favorite
+		renderItem.HasThumbnail = true
+
+		output[index] = renderItem
+	}
+
+	return output
+}
+
+// similarGroups links pictures whose hashes are within the distance, a group being every picture reachable from another one
+func similarGroups(items []absto.Item, metadatas map[string]provider.Metadata, distance int) [][]absto.Item {
+	var hashed []absto.Item
+
+	for _, item := range items {
+		if !item.IsDir() && metadatas[item.ID].PerceptualHash != 0 {
+			hashed = append(hashed, item)
+		}
+	}
+
+	parents := make([]int, len(hashed))
+	for index := range parents {
+		parents[index] = index
+	}
+
+	var root func(int) int
+	root = func(index int) int {
+		if parents[index] != index {
+			parents[index] = root(parents[index])
+		}
+
+		return parents[index]
+	}
+
+	for i := range hashed {
+		for j := i + 1; j < len(hashed); j++ {
+			if provider.HammingDistance(metadatas[hashed[i].ID].PerceptualHash, metadatas[hashed[j].ID].PerceptualHash) > distance {
+				continue
+			}
+
+			if first, second := root(i), root(j); first != second {
+				parents[max(first, second)] = min(first, second)
+			}
+		}
+	}
+
+	var groups [][]absto.Item
+	positions := make(map[int]int)
+
+	for index, item := range hashed {
+		group := root(index)
+
+		position, ok := positions[group]
+		if !ok {
+			position = len(groups)
+			positions[group] = position
+			groups = append(groups, nil)
+		}
+
+		groups[position] = append(groups[position], item)
+	}
+
+	var output [][]absto.Item
+
+	for _, group := range groups {
+		if len(group) > 1 {
+			output = append(output, group)
+		}
+	}
+
+	return output
+}
+
+// similarTo lists the pictures within the distance of the hash, closest first
+func similarTo(hash uint64, item absto.Item, items []absto.Item, metadatas map[string]provider.Metadata, distance int) []absto.Item {
+	var output []absto.Item
+
+	for _, other := range items {
+		if other.IsDir() || other.ID == item.ID {
+			continue
+		}
+
+		if otherHash := metadatas[other.ID].PerceptualHash; otherHash != 0 && provider.HammingDistance(hash, otherHash) <= distance {
+			output = append(output, other)
+		}
+	}
+
+	slices.SortStableFunc(output, func(a, b absto.Item) int {
+		return provider.HammingDistance(hash, metadatas[a.ID].PerceptualHash) - provider.HammingDistance(hash, metadatas[b.ID].PerceptualHash)
+	})
+
+	return output
+}
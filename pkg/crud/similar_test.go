@@ -0,0 +1,75 @@
+package crud
+
+import (
+	"reflect"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestSimilarGroups(t *testing.T) {
+	first := absto.Item{ID: "1", NameValue: "first.jpg"}
+	second := absto.Item{ID: "2", NameValue: "second.jpg"}
+	third := absto.Item{ID: "3", NameValue: "third.jpg"}
+	other := absto.Item{ID: "4", NameValue: "other.jpg"}
+	unhashed := absto.Item{ID: "5", NameValue: "unhashed.jpg"}
+	folder := absto.Item{ID: "6", NameValue: "folder", IsDirValue: true}
+
+	metadatas := map[string]provider.Metadata{
+		"1": {PerceptualHash: 0b0000_0001},
+		"2": {PerceptualHash: 0b0000_0011},
+		"3": {PerceptualHash: 0b0000_0111},
+		"4": {PerceptualHash: 0xffff_0000},
+	}
+
+	cases := map[string]struct {
+		items    []absto.Item
+		distance int
+		want     [][]absto.Item
+	}{
+		"empty": {
+			nil,
+			1,
+			nil,
+		},
+		"chained": {
+			[]absto.Item{folder, first, other, unhashed, third, second},
+			1,
+			[][]absto.Item{{first, third, second}},
+		},
+		"strict": {
+			[]absto.Item{first, second, third, other},
+			0,
+			nil,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := similarGroups(tc.items, metadatas, tc.distance); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("similarGroups() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSimilarTo(t *testing.T) {
+	item := absto.Item{ID: "1", NameValue: "item.jpg"}
+	far := absto.Item{ID: "2", NameValue: "far.jpg"}
+	near := absto.Item{ID: "3", NameValue: "close.jpg"}
+	other := absto.Item{ID: "4", NameValue: "other.jpg"}
+
+	metadatas := map[string]provider.Metadata{
+		"1": {PerceptualHash: 0b0000_0001},
+		"2": {PerceptualHash: 0b0000_0111},
+		"3": {PerceptualHash: 0b0000_0011},
+		"4": {PerceptualHash: 0xffff_0000},
+	}
+
+	want := []absto.Item{near, far}
+
+	if got := similarTo(0b0000_0001, item, []absto.Item{item, far, other, near}, metadatas, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("similarTo() = %+v, want %+v", got, want)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/fibr/pkg/thumbnail"
 	"github.com/ViBiOh/httputils/v4/pkg/concurrent"
+	"github.com/ViBiOh/httputils/v4/pkg/query"
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
 	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
@@ -26,6 +27,10 @@ func (s *Service) story(r *http.Request, request provider.Request, item absto.It
 
 	var directoryAggregate provider.Aggregate
 	wg.Go(func() {
+		if !item.IsDir() {
+			return
+		}
+
 		var err error
 
 		directoryAggregate, err = s.metadata.GetAggregateFor(ctx, item)
@@ -34,6 +39,11 @@ func (s *Service) story(r *http.Request, request provider.Request, item absto.It
 		}
 	})
 
+	var tagNames []string
+	wg.Go(func() {
+		tagNames = s.tagNames(ctx, request)
+	})
+
 	var exifs map[string]provider.Metadata
 	wg.Go(func() {
 		var err error
@@ -46,13 +56,23 @@ func (s *Service) story(r *http.Request, request provider.Request, item absto.It
 
 	wg.Wait()
 
-	for _, file := range files {
-		if cover.IsZero() || (len(directoryAggregate.Cover) != 0 && cover.Img.Name() != directoryAggregate.Cover) {
-			cover = newCover(provider.StorageToRender(file, request), thumbnail.SmallSize)
-		}
+	events := query.GetBool(r, "events")
 
+	var eventSections map[string]eventSection
+	if events {
+		files, eventSections = flattenEvents(s.metadata.Clusters(files, exifs))
+	}
+
+	for _, file := range files {
 		exif := exifs[file.ID]
 
+		renderItem := provider.StorageToRender(file, request)
+		renderItem.Favorite = exif.Favorite
+
+		if isBetterCover(cover, renderItem, directoryAggregate.Cover) {
+			cover = newCover(renderItem, thumbnail.SmallSize)
+		}
+
 		if !hasMap && exif.Geocode.HasCoordinates() {
 			hasMap = true
 		}
@@ -68,5 +88,8 @@ func (s *Service) story(r *http.Request, request provider.Request, item absto.It
 		"HasMap":        hasMap,
 		"ThumbnailSize": s.thumbnail.LargeThumbnailSize(),
 		"ChunkUpload":   s.chunkUpload,
+		"TagNames":      tagNames,
+		"GroupEvents":   events,
+		"Events":        eventSections,
 	}), nil
 }
@@ -0,0 +1,105 @@
+package crud
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/httperror"
+	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (s *Service) subtitlesOf(ctx context.Context, item absto.Item) ([]provider.Subtitle, error) {
+	if len(provider.VideoExtensions[item.Extension]) == 0 {
+		return nil, nil
+	}
+
+	items, err := s.storage.List(ctx, item.Dir())
+	if err != nil {
+		return nil, fmt.Errorf("list siblings: %w", err)
+	}
+
+	return provider.SubtitlesOf(item, items), nil
+}
+
+func (s *Service) serveSubtitle(w http.ResponseWriter, r *http.Request, item absto.Item, language string) {
+	ctx, end := telemetry.StartSpan(r.Context(), s.tracer, "subtitle", trace.WithSpanKind(trace.SpanKindInternal))
+	defer end(nil)
+
+	subtitles, err := s.subtitlesOf(ctx, item)
+	if err != nil {
+		httperror.InternalServerError(ctx, w, err)
+		return
+	}
+
+	for _, subtitle := range subtitles {
+		if subtitle.Language != language {
+			continue
+		}
+
+		reader, err := s.storage.ReadFrom(ctx, subtitle.Pathname)
+		if err != nil {
+			httperror.InternalServerError(ctx, w, err)
+			return
+		}
+
+		defer provider.LogClose(ctx, reader, "crud.serveSubtitle", subtitle.Pathname)
+
+		w.Header().Add("Content-Type", "text/vtt; charset=utf-8")
+
+		if subtitle.Extension == ".vtt" {
+			http.ServeContent(w, r, subtitle.Name(), subtitle.Date, reader)
+			return
+		}
+
+		if err := srtToVTT(w, reader); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "convert subtitle", slog.String("item", subtitle.Pathname), slog.Any("error", err))
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+// srtToVTT converts SubRip content to WebVTT: it adds the header and uses dot as the milliseconds separator of timings
+func srtToVTT(writer io.Writer, reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	output := bufio.NewWriter(writer)
+
+	if _, err := output.WriteString("WEBVTT\n\n"); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	first := true
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+
+		if strings.Contains(line, "-->") {
+			line = strings.ReplaceAll(line, ",", ".")
+		}
+
+		if _, err := output.WriteString(line + "\n"); err != nil {
+			return fmt.Errorf("write line: %w", err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+
+	return output.Flush()
+}
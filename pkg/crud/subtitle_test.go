@@ -0,0 +1,40 @@
+package crud
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSrtToVTT(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  string
+	}{
+		"empty": {
+			"",
+			"WEBVTT\n\n",
+		},
+		"cues": {
+			"\ufeff1\r\n00:00:01,000 --> 00:00:02,500\r\nHello, world\r\n\r\n2\r\n00:00:03,000 --> 00:00:04,000\r\nBye\r\n",
+			"WEBVTT\n\n1\n00:00:01.000 --> 00:00:02.500\nHello, world\n\n2\n00:00:03.000 --> 00:00:04.000\nBye\n",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			var output strings.Builder
+
+			if err := srtToVTT(&output, strings.NewReader(testCase.input)); err != nil {
+				t.Errorf("srtToVTT() = %s", err)
+			}
+
+			if got := output.String(); got != testCase.want {
+				t.Errorf("srtToVTT() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
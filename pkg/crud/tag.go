@@ -0,0 +1,287 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/fibr/pkg/thumbnail"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/query"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
+)
+
+var (
+	errEmptyTag   = errors.New("tag is empty")
+	errInvalidTag = errors.New("tag can't contain blank")
+)
+
+type tagCloudItem struct {
+	provider.TagCount
+	URL  string
+	Size int
+}
+
+// parseTagPath returns the tag of a virtual tag folder, empty for the tag cloud
+func parseTagPath(pathname string) (string, bool) {
+	if pathname+"/" == provider.TagsPath {
+		return "", true
+	}
+
+	tag, ok := strings.CutPrefix(pathname, provider.TagsPath)
+	if !ok {
+		return "", false
+	}
+
+	tag = strings.TrimSuffix(tag, "/")
+	if strings.Contains(tag, "/") {
+		return "", false
+	}
+
+	return tag, true
+}
+
+func parseTags(value string) []string {
+	return strings.Fields(value)
+}
+
+func checkTag(value string) (string, error) {
+	if len(value) == 0 {
+		return "", errEmptyTag
+	}
+
+	if strings.ContainsFunc(value, unicode.IsSpace) {
+		return "", errInvalidTag
+	}
+
+	return value, nil
+}
+
+func tagRoot(request provider.Request) string {
+	if request.Share.IsZero() {
+		return "/"
+	}
+
+	return request.Share.Path
+}
+
+func (s *Service) tagNames(ctx context.Context, request provider.Request) []string {
+	if !request.CanEdit {
+		return nil
+	}
+
+	index, err := s.metadata.TagIndex(ctx)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "get tag index", slog.Any("error", err))
+		return nil
+	}
+
+	return index.Names(tagRoot(request))
+}
+
+func (s *Service) handleTag(w http.ResponseWriter, r *http.Request, request provider.Request, tag string, message renderer.Message) (renderer.Page, error) {
+	ctx := r.Context()
+
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		s.renderer.Redirect(w, r, fmt.Sprintf("%s/?d=%s", r.URL.Path, request.Display), renderer.Message{})
+		return renderer.Page{}, nil
+	}
+
+	index, err := s.metadata.TagIndex(ctx)
+	if err != nil {
+		return errorReturn(request, model.WrapInternal(err))
+	}
+
+	if len(tag) == 0 {
+		telemetry.SetRouteTag(ctx, "/tags")
+		return s.tagCloud(request, message, index), nil
+	}
+
+	files := s.tagFiles(ctx, index.Pathnames(tag, tagRoot(request)))
+	if len(files) == 0 {
+		return errorReturn(request, model.WrapNotFound(fmt.Errorf("no file tagged with `%s`", tag)))
+	}
+
+	request.Tag = tag
+	// Files come from several folders, editing is done in their own folder
+	request.CanEdit = false
+
+	if query.GetBool(r, "geojson") {
+		telemetry.SetRouteTag(ctx, "/tags/geojsons")
+		s.serveGeoJSON(w, r, request, absto.Item{}, files)
+		return renderer.Page{}, nil
+	}
+
+	if query.GetBool(r, "thumbnail") {
+		telemetry.SetRouteTag(ctx, "/tags/thumbnails")
+		s.thumbnail.List(w, r, absto.Item{}, files)
+		return renderer.Page{}, nil
+	}
+
+	provider.SetPrefsCookie(w, request)
+
+	if request.IsStory() {
+		telemetry.SetRouteTag(ctx, "/tags/stories")
+
+		storyFiles := files[:0]
+		for _, file := range files {
+			if s.thumbnail.HasLargeThumbnail(ctx, file) {
+				storyFiles = append(storyFiles, file)
+			}
+		}
+
+		return s.story(r, request, absto.Item{}, storyFiles)
+	}
+
+	telemetry.SetRouteTag(ctx, "/tags/directory")
+	return s.virtualFolder(ctx, request, message, files), nil
+}
+
+func (s *Service) tagFiles(ctx context.Context, pathnames []string) []absto.Item {
+	files := make([]absto.Item, 0, len(pathnames))
+
+	for _, pathname := range pathnames {
+		item, err := s.storage.Stat(ctx, pathname)
+		if err != nil {
+			if !absto.IsNotExist(err) {
+				slog.LogAttrs(ctx, slog.LevelError, "get tagged file", slog.String("item", pathname), slog.Any("error", err))
+			}
+
+			continue
+		}
+
+		files = append(files, item)
+	}
+
+	sort.Sort(provider.ByHybridSort(files))
+
+	return files
+}
+
+func (s *Service) tagCloud(request provider.Request, message renderer.Message, index provider.TagIndex) renderer.Page {
+	counts := index.Counts(tagRoot(request))
+
+	var highest int
+	for _, count := range counts {
+		highest = max(highest, count.Count)
+	}
+
+	items := make([]tagCloudItem, len(counts))
+	for i, count := range counts {
+		items[i] = tagCloudItem{
+			TagCount: count,
+			URL:      request.TagURL(count.Name),
+			Size:     1 + 4*count.Count/highest,
+		}
+	}
+
+	return renderer.NewPage("tags", http.StatusOK, map[string]any{
+		"Paths":     getPathParts(request),
+		"Tags":      items,
+		"Request":   request,
+		"Message":   message,
+		"TagNames":  index.Names(tagRoot(request)),
+		"TagCloud":  true,
+		"CanManage": request.CanEdit && request.Share.IsZero(),
+	})
+}
+
+// virtualFolder renders files coming from several folders, for a tag or an album
+func (s *Service) virtualFolder(ctx context.Context, request provider.Request, message renderer.Message, files []absto.Item) renderer.Page {
+	metadatas, err := s.metadata.GetAllMetadataFor(ctx, files...)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "list metadatas", slog.String("item", request.Filepath()), slog.Any("error", err))
+	}
+
+	items := make([]provider.RenderItem, len(files))
+	var hasMap bool
+
+	renderWithThumbnail := request.Display == provider.GridDisplay
+
+	for i, item := range files {
+		renderItem := provider.StorageToRender(item, request)
+
+		metadata := metadatas[item.ID]
+		renderItem.Tags = metadata.Tags
+		renderItem.Rating = metadata.Rating
+		renderItem.Favorite = metadata.Favorite
+
+		if renderWithThumbnail && s.thumbnail.CanHaveThumbnail(item) && s.thumbnail.HasThumbnail(ctx, item, thumbnail.SmallSize) {
+			renderItem.HasThumbnail = true
+		}
+
+		items[i] = renderItem
+
+		if !hasMap && metadata.Geocode.HasCoordinates() {
+			hasMap = true
+		}
+	}
+
+	return renderer.NewPage("search", http.StatusOK, map[string]any{
+		"Paths":   getPathParts(request),
+		"Files":   items,
+		"Cover":   s.getCover(ctx, request, files, metadatas),
+		"Search":  url.Values{},
+		"Request": request,
+		"Message": message,
+		"HasMap":  hasMap,
+	})
+}
+
+func (s *Service) handlePostTag(w http.ResponseWriter, r *http.Request, request provider.Request, method string) {
+	if !request.CanEdit || !request.Share.IsZero() {
+		s.error(w, r, request, model.WrapForbidden(ErrNotAuthorized))
+		return
+	}
+
+	ctx := r.Context()
+
+	tag, err := checkTag(strings.TrimSpace(r.FormValue("name")))
+	if err != nil {
+		s.error(w, r, request, model.WrapInvalid(err))
+		return
+	}
+
+	var message renderer.Message
+
+	switch method {
+	case http.MethodPatch:
+		newTag, err := checkTag(strings.TrimSpace(r.FormValue("newName")))
+		if err != nil {
+			s.error(w, r, request, model.WrapInvalid(err))
+			return
+		}
+
+		count, err := s.metadata.RenameTag(ctx, tag, newTag)
+		if err != nil {
+			s.error(w, r, request, model.WrapInternal(err))
+			return
+		}
+
+		message = renderer.NewSuccessMessage("Tag %s renamed to %s on %d file(s)", tag, newTag, count)
+
+	case http.MethodDelete:
+		count, err := s.metadata.DeleteTag(ctx, tag)
+		if err != nil {
+			s.error(w, r, request, model.WrapInternal(err))
+			return
+		}
+
+		message = renderer.NewSuccessMessage("Tag %s removed from %d file(s)", tag, count)
+
+	default:
+		s.error(w, r, request, model.WrapMethodNotAllowed(fmt.Errorf("unknown method `%s` for tag", method)))
+		return
+	}
+
+	s.renderer.Redirect(w, r, provider.TagsPath, message)
+}
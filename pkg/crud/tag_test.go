@@ -0,0 +1,54 @@
+package crud
+
+import (
+	"testing"
+)
+
+func TestParseTagPath(t *testing.T) {
+	cases := map[string]struct {
+		pathname string
+		want     string
+		wantOk   bool
+	}{
+		"cloud": {
+			"/tags/",
+			"",
+			true,
+		},
+		"cloud without slash": {
+			"/tags",
+			"",
+			true,
+		},
+		"tag": {
+			"/tags/holidays/",
+			"holidays",
+			true,
+		},
+		"tag without slash": {
+			"/tags/holidays",
+			"holidays",
+			true,
+		},
+		"nested": {
+			"/tags/holidays/rome.jpg",
+			"",
+			false,
+		},
+		"other": {
+			"/photos/tags/",
+			"",
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotOk := parseTagPath(tc.pathname)
+
+			if got != tc.want || gotOk != tc.wantOk {
+				t.Errorf("parseTagPath() = (`%s`, %t), want (`%s`, %t)", got, gotOk, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/fibr/pkg/thumbnail"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+	"github.com/ViBiOh/httputils/v4/pkg/telemetry"
+)
+
+const timelinePageSize = 100
+
+var errInvalidPage = errors.New("page must be a positive number")
+
+type timelineFile struct {
+	item     absto.Item
+	metadata provider.Metadata
+}
+
+type timelineYear struct {
+	Months []timelineMonth
+	Year   int
+	Count  int
+}
+
+type timelineMonth struct {
+	Month time.Time
+	ID    string
+	Count int
+}
+
+type timelineDay struct {
+	Day       time.Time
+	Files     []provider.StoryItem
+	Continued bool
+}
+
+// timeline renders the photos and videos of the whole subtree by capture date: the overview lists the months, each month is then loaded page by page
+func (s *Service) timeline(r *http.Request, request provider.Request, item absto.Item, message renderer.Message) (renderer.Page, error) {
+	ctx := r.Context()
+
+	rawMonth := r.URL.Query().Get("month")
+	if len(rawMonth) == 0 {
+		telemetry.SetRouteTag(ctx, "/timelines")
+
+		months, err := s.timelineMonths(ctx, item)
+		if err != nil {
+			return errorReturn(request, err)
+		}
+
+		years, count := timelineYears(months)
+
+		return renderer.NewPage("timeline", http.StatusOK, map[string]any{
+			"Paths":    getPathParts(request),
+			"Request":  request,
+			"Message":  message,
+			"Years":    years,
+			"Count":    count,
+			"Timeline": true,
+		}), nil
+	}
+
+	telemetry.SetRouteTag(ctx, "/timelines/month")
+
+	month, err := time.Parse(provider.MonthLayout, rawMonth)
+	if err != nil {
+		return errorReturn(request, model.WrapInvalid(fmt.Errorf("parse month: %w", err)))
+	}
+
+	page, err := parsePage(r.URL.Query().Get("page"))
+	if err != nil {
+		return errorReturn(request, model.WrapInvalid(err))
+	}
+
+	files, err := s.timelineFiles(ctx, item, month)
+	if err != nil {
+		return errorReturn(request, err)
+	}
+
+	start := min(page*timelinePageSize, len(files))
+	end := min(start+timelinePageSize, len(files))
+
+	var nextPage int
+	if end < len(files) {
+		nextPage = page + 1
+	}
+
+	return renderer.NewPage("timeline-page", http.StatusOK, map[string]any{
+		"Request":  request,
+		"Month":    rawMonth,
+		"Days":     s.timelineDays(ctx, request, files, start, end),
+		"NextPage": nextPage,
+	}), nil
+}
+
+func parsePage(value string) (int, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	page, err := strconv.Atoi(value)
+	if err != nil || page < 0 {
+		return 0, errInvalidPage
+	}
+
+	return page, nil
+}
+
+// timelineMonths counts the dated photos and videos of the subtree by month, from the aggregates of its directories. Only the files of directories without counts yet have their metadata loaded.
+func (s *Service) timelineMonths(ctx context.Context, dir absto.Item) (map[string]int, error) {
+	directories, items, err := s.mediaItems(ctx, dir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := s.metadata.GetAllAggregateFor(ctx, directories...)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "list aggregates", slog.String("item", dir.Pathname), slog.Any("error", err))
+	}
+
+	months := make(map[string]int)
+	counted := make(map[string]bool, len(directories))
+
+	for _, directory := range directories {
+		counts := aggregates[directory.ID].Months
+		if counts == nil {
+			continue
+		}
+
+		counted[provider.Dirname(directory.Pathname)] = true
+
+		for month, count := range counts {
+			months[month] += count
+		}
+	}
+
+	var remaining []absto.Item
+	for _, item := range items {
+		if !counted[item.Dir()] {
+			remaining = append(remaining, item)
+		}
+	}
+
+	if len(remaining) == 0 {
+		return months, nil
+	}
+
+	metadatas, err := s.metadata.GetAllMetadataFor(ctx, remaining...)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "list metadatas", slog.String("item", dir.Pathname), slog.Any("error", err))
+	}
+
+	for _, item := range remaining {
+		if date := metadatas[item.ID].Date; !date.IsZero() {
+			months[date.Format(provider.MonthLayout)]++
+		}
+	}
+
+	return months, nil
+}
+
+// timelineFiles lists the dated photos and videos of the subtree taken during the month, most recent first. Directories without files of the month are skipped without loading their metadata.
+func (s *Service) timelineFiles(ctx context.Context, dir absto.Item, month time.Time) ([]timelineFile, error) {
+	monthID := month.Format(provider.MonthLayout)
+
+	return s.datedFiles(ctx, dir, true, func(aggregate provider.Aggregate) bool {
+		if aggregate.Months != nil {
+			return aggregate.Months[monthID] != 0
+		}
+
+		return aggregateCovers(aggregate, month)
+	}, func(date time.Time) bool {
+		return date.Format(provider.MonthLayout) == monthID
+	})
+}
+
+// datedFiles lists the dated photos and videos of the directory, most recent first. Directories whose aggregate isn't covered are skipped without loading their metadata, then files are kept on their capture date.
+func (s *Service) datedFiles(ctx context.Context, dir absto.Item, recursive bool, covers func(provider.Aggregate) bool, keep func(time.Time) bool) ([]timelineFile, error) {
+	directories, items, err := s.mediaItems(ctx, dir, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	if covers != nil {
+		items = s.keepCoveredItems(ctx, directories, items, covers)
+	}
+
+	metadatas, err := s.metadata.GetAllMetadataFor(ctx, items...)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "list metadatas", slog.String("item", dir.Pathname), slog.Any("error", err))
+	}
+
+	files := make([]timelineFile, 0, len(items))
+
+	for _, item := range items {
+		metadata := metadatas[item.ID]
+		if metadata.Date.IsZero() {
+			continue
+		}
+
+		if keep != nil && !keep(metadata.Date) {
+			continue
+		}
+
+		files = append(files, timelineFile{item: item, metadata: metadata})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].metadata.Date.After(files[j].metadata.Date)
+	})
+
+	return files, nil
+}
+
+func (s *Service) mediaItems(ctx context.Context, dir absto.Item, recursive bool) (directories, items []absto.Item, err error) {
+	if !recursive {
+		content, err := s.storage.List(ctx, dir.Pathname)
+		if err != nil {
+			return nil, nil, fmt.Errorf("list: %w", err)
+		}
+
+		for _, item := range content {
+			if provider.IsMedia(item) {
+				items = append(items, item)
+			}
+		}
+
+		return []absto.Item{dir}, items, nil
+	}
+
+	err = s.storage.Walk(ctx, dir.Pathname, func(item absto.Item) error {
+		switch {
+		case item.IsDir():
+			directories = append(directories, item)
+		case provider.IsMedia(item):
+			items = append(items, item)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("walk: %w", err)
+	}
+
+	return directories, items, nil
+}
+
+func (s *Service) keepCoveredItems(ctx context.Context, directories, items []absto.Item, covers func(provider.Aggregate) bool) []absto.Item {
+	aggregates, err := s.metadata.GetAllAggregateFor(ctx, directories...)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "list aggregates", slog.Any("error", err))
+		return items
+	}
+
+	covered := make(map[string]bool, len(directories))
+	for _, directory := range directories {
+		covered[provider.Dirname(directory.Pathname)] = covers(aggregates[directory.ID])
+	}
+
+	output := items[:0]
+
+	for _, item := range items {
+		if isCovered, ok := covered[item.Dir()]; !ok || isCovered {
+			output = append(output, item)
+		}
+	}
+
+	return output
+}
+
+// aggregateCovers checks if the dates of the aggregate overlap the month, with a day of margin for the time zones. An aggregate without dates covers everything, it may not be computed yet.
+func aggregateCovers(aggregate provider.Aggregate, month time.Time) bool {
+	if aggregate.Start.IsZero() || aggregate.End.IsZero() {
+		return true
+	}
+
+	start := month.AddDate(0, 0, -1)
+	end := month.AddDate(0, 1, 1)
+
+	return aggregate.End.After(start) && aggregate.Start.Before(end)
+}
+
+// timelineYears groups the counts by year, most recent first, and gives the total
+func timelineYears(months map[string]int) ([]timelineYear, int) {
+	ids := make([]string, 0, len(months))
+	for id, count := range months {
+		if count > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+	var output []timelineYear
+	var total int
+
+	for _, id := range ids {
+		month, err := time.Parse(provider.MonthLayout, id)
+		if err != nil {
+			continue
+		}
+
+		if len(output) == 0 || output[len(output)-1].Year != month.Year() {
+			output = append(output, timelineYear{Year: month.Year()})
+		}
+
+		year := &output[len(output)-1]
+		year.Months = append(year.Months, timelineMonth{ID: id, Month: month, Count: months[id]})
+		year.Count += months[id]
+		total += months[id]
+	}
+
+	return output, total
+}
+
+// timelineDays groups a page of files by day, the first day is flagged as continued if it started in the previous page
+func (s *Service) timelineDays(ctx context.Context, request provider.Request, files []timelineFile, start, end int) []timelineDay {
+	var output []timelineDay
+
+	for index := start; index < end; index++ {
+		file := files[index]
+		date := file.metadata.Date
+
+		if len(output) == 0 || !sameDay(output[len(output)-1].Day, date) {
+			output = append(output, timelineDay{
+				Day:       date,
+				Continued: len(output) == 0 && index > 0 && sameDay(files[index-1].metadata.Date, date),
+			})
+		}
+
+		day := &output[len(output)-1]
+		day.Files = append(day.Files, s.timelineStoryItem(ctx, request, file))
+	}
+
+	return output
+}
+
+func (s *Service) timelineStoryItem(ctx context.Context, request provider.Request, file timelineFile) provider.StoryItem {
+	storyItem := provider.StorageToStory(file.item, request, file.metadata)
+	storyItem.Rating = file.metadata.Rating
+	storyItem.Favorite = file.metadata.Favorite
+	storyItem.HasThumbnail = s.thumbnail.CanHaveThumbnail(file.item) && s.thumbnail.HasThumbnail(ctx, file.item, thumbnail.SmallSize)
+
+	return storyItem
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
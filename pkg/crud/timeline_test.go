@@ -0,0 +1,90 @@
+package crud
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestTimelineYears(t *testing.T) {
+	t.Parallel()
+
+	july := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		months    map[string]int
+		want      []timelineYear
+		wantCount int
+	}{
+		"empty": {
+			nil,
+			nil,
+			0,
+		},
+		"grouped": {
+			map[string]int{"2023-12": 1, "2024-06": 1, "2024-07": 2, "2024-01": 0},
+			[]timelineYear{
+				{Year: 2024, Count: 3, Months: []timelineMonth{{ID: "2024-07", Month: july, Count: 2}, {ID: "2024-06", Month: june, Count: 1}}},
+				{Year: 2023, Count: 1, Months: []timelineMonth{{ID: "2023-12", Month: december, Count: 1}}},
+			},
+			4,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, gotCount := timelineYears(tc.months)
+			if !reflect.DeepEqual(got, tc.want) || gotCount != tc.wantCount {
+				t.Errorf("timelineYears() = (%+v, %d), want (%+v, %d)", got, gotCount, tc.want, tc.wantCount)
+			}
+		})
+	}
+}
+
+func TestAggregateCovers(t *testing.T) {
+	t.Parallel()
+
+	month := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		aggregate provider.Aggregate
+		want      bool
+	}{
+		"not computed": {
+			provider.Aggregate{},
+			true,
+		},
+		"inside": {
+			provider.Aggregate{Start: time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 7, 12, 0, 0, 0, 0, time.UTC)},
+			true,
+		},
+		"overlapping": {
+			provider.Aggregate{Start: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			true,
+		},
+		"before": {
+			provider.Aggregate{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+			false,
+		},
+		"after": {
+			provider.Aggregate{Start: time.Date(2024, 8, 10, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)},
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := aggregateCovers(tc.aggregate, month); got != tc.want {
+				t.Errorf("aggregateCovers() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package crud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/geo"
+	"github.com/ViBiOh/fibr/pkg/gpx"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/renderer"
+)
+
+func keepOnlyTracks(items []absto.Item) []absto.Item {
+	var output []absto.Item
+
+	for _, item := range items {
+		if !item.IsDir() && item.Extension == gpx.Extension {
+			output = append(output, item)
+		}
+	}
+
+	return output
+}
+
+func (s *Service) readTrack(ctx context.Context, item absto.Item) (gpx.Track, error) {
+	reader, err := s.storage.ReadFrom(ctx, item.Pathname)
+	if err != nil {
+		return gpx.Track{}, fmt.Errorf("open: %w", err)
+	}
+
+	defer provider.LogClose(ctx, reader, "crud.readTrack", item.Pathname)
+
+	return gpx.Parse(io.LimitReader(reader, gpx.MaxSize))
+}
+
+// generateTrackFeatures writes a line for each segment of the tracks, after the points already written
+func (s *Service) generateTrackFeatures(ctx context.Context, w io.Writer, encoder *json.Encoder, request provider.Request, tracks []absto.Item, commaNeeded bool, isDone func() bool) {
+	for _, item := range tracks {
+		if isDone() {
+			return
+		}
+
+		track, err := s.readTrack(ctx, item)
+		if err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "read track", slog.String("item", item.Pathname), slog.Any("error", err))
+			continue
+		}
+
+		name := track.Name
+		if len(name) == 0 {
+			name = item.Name()
+		}
+
+		for _, segment := range track.Segments {
+			if len(segment) < 2 {
+				continue
+			}
+
+			positions := make([]geo.Position, len(segment))
+			for index, point := range segment {
+				positions[index] = geo.NewPosition(point.Longitude, point.Latitude)
+			}
+
+			if commaNeeded {
+				provider.DoneWriter(ctx, isDone, w, ",")
+			} else {
+				commaNeeded = true
+			}
+
+			line := geo.NewLineString(positions)
+
+			if err := encoder.Encode(geo.NewFeature(&line, map[string]any{"url": request.RelativeURL(item), "name": name})); err != nil {
+				slog.LogAttrs(ctx, slog.LevelError, "encode track", slog.String("item", item.Pathname), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+func (s *Service) handlePostGeotag(w http.ResponseWriter, r *http.Request, request provider.Request) {
+	if !request.CanEdit {
+		s.error(w, r, request, model.WrapForbidden(ErrNotAuthorized))
+		return
+	}
+
+	name, err := checkFormName(r, "name")
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	var offset time.Duration
+	if rawOffset := strings.TrimSpace(r.FormValue("offset")); len(rawOffset) != 0 {
+		if offset, err = time.ParseDuration(rawOffset); err != nil {
+			s.error(w, r, request, model.WrapInvalid(fmt.Errorf("parse offset: %w", err)))
+			return
+		}
+	}
+
+	ctx := r.Context()
+
+	item, err := s.storage.Stat(ctx, request.SubPath(name))
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	if item.IsDir() || item.Extension != gpx.Extension {
+		s.error(w, r, request, model.WrapInvalid(fmt.Errorf("`%s` is not a GPX track", name)))
+		return
+	}
+
+	count, err := s.metadata.Geotag(ctx, item, offset)
+	if err != nil {
+		s.error(w, r, request, err)
+		return
+	}
+
+	s.renderer.Redirect(w, r, fmt.Sprintf("?d=%s", request.Display), renderer.NewSuccessMessage("%d file(s) geotagged with %s", count, item.Name()))
+}
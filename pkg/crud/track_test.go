@@ -0,0 +1,68 @@
+package crud
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	exas "github.com/ViBiOh/exas/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/mocks"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"go.uber.org/mock/gomock"
+)
+
+type trackReader struct {
+	*bytes.Reader
+}
+
+func (trackReader) Close() error {
+	return nil
+}
+
+func TestGenerateGeoJSONWithTracks(t *testing.T) {
+	picture := absto.Item{ID: "1234", NameValue: "picture.jpg", Pathname: "/hike/picture.jpg", Extension: ".jpg"}
+	track := absto.Item{ID: "5678", NameValue: "hike.gpx", Pathname: "/hike/hike.gpx", Extension: ".gpx"}
+
+	cases := map[string]struct {
+		content string
+		want    string
+	}{
+		"invalid track": {
+			"<gpx>",
+			`{"type":"FeatureCollection","features":[{"properties":{"date":"Sunday, 14-Jul-24 08:05:00 UTC","url":"picture.jpg"},"geometry":{"type":"Point","coordinates":[6.000000,45.000000]},"type":"Feature"}
+]}`,
+		},
+		"track": {
+			`<gpx><trk><trkseg><trkpt lat="45" lon="6"/><trkpt lat="45.1" lon="6.2"/></trkseg><trkseg><trkpt lat="46" lon="7"/></trkseg></trk></gpx>`,
+			`{"type":"FeatureCollection","features":[{"properties":{"date":"Sunday, 14-Jul-24 08:05:00 UTC","url":"picture.jpg"},"geometry":{"type":"Point","coordinates":[6.000000,45.000000]},"type":"Feature"}
+,{"properties":{"name":"hike.gpx","url":"hike.gpx"},"geometry":{"type":"LineString","coordinates":[[6.000000,45.000000],[6.200000,45.100000]]},"type":"Feature"}
+]}`,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			mockStorage := mocks.NewStorage(ctrl)
+			mockStorage.EXPECT().ReadFrom(gomock.Any(), track.Pathname).Return(trackReader{bytes.NewReader([]byte(tc.content))}, nil)
+
+			instance := Service{
+				storage: mockStorage,
+			}
+
+			var output strings.Builder
+
+			instance.generateGeoJSON(context.TODO(), &output, provider.Request{Path: "/hike/"}, []absto.Item{picture, track}, map[string]provider.Metadata{
+				picture.ID: {Exif: exas.Exif{Date: time.Date(2024, 7, 14, 8, 5, 0, 0, time.UTC), Geocode: exas.Geocode{Latitude: 45, Longitude: 6}}},
+			})
+
+			if got := output.String(); got != tc.want {
+				t.Errorf("generateGeoJSON() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
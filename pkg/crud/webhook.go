@@ -4,7 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/mail"
 	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
 	"github.com/ViBiOh/fibr/pkg/provider"
@@ -12,10 +18,22 @@ import (
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
 )
 
+const (
+	webhookSecretHeader = "X-Webhook-Secret"
+	maxSecretGrace      = time.Hour * 24 * 30
+	maxDebounce         = time.Hour * 24
+)
+
+var webhookMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodGet, http.MethodDelete}
+
 func generateTelegramURL(botToken, chatID string) string {
 	return fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", url.PathEscape(botToken), url.QueryEscape(chatID))
 }
 
+func generateMatrixURL(homeserver, roomID string) string {
+	return fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message", homeserver, url.PathEscape(roomID))
+}
+
 func (s *Service) createWebhook(w http.ResponseWriter, r *http.Request, request provider.Request) {
 	var err error
 	err = r.ParseForm()
@@ -24,19 +42,27 @@ func (s *Service) createWebhook(w http.ResponseWriter, r *http.Request, request
 		return
 	}
 
-	recursive, kind, webhookURL, eventTypes, err := checkWebhookForm(r)
+	webhook, preview, err := checkWebhookForm(r, s.renderer.PublicURL(request.AbsoluteURL("sunset.jpg")))
 	if err != nil {
 		s.error(w, r, request, err)
 		return
 	}
 
-	if !request.CanWebhook && kind != provider.Push {
+	if !request.CanWebhook && webhook.Kind != provider.Push {
 		s.error(w, r, request, model.WrapForbidden(ErrNotAuthorized))
 		return
 	}
 
 	ctx := r.Context()
 
+	if r.Form.Get("preview") == "true" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		provider.SafeWrite(ctx, w, preview)
+
+		return
+	}
+
 	info, err := s.storage.Stat(ctx, request.Filepath())
 	if err != nil {
 		if absto.IsNotExist(err) {
@@ -53,79 +79,352 @@ func (s *Service) createWebhook(w http.ResponseWriter, r *http.Request, request
 		return
 	}
 
-	id, err := s.webhook.Create(ctx, info.Pathname, recursive, kind, webhookURL, eventTypes)
+	webhook.Pathname = info.Pathname
+
+	id, secret, err := s.webhook.Create(ctx, webhook)
 	if err != nil {
 		s.error(w, r, request, model.WrapInternal(err))
 		return
 	}
 
 	if r.Header.Get("Accept") == "text/plain" {
+		if len(secret) != 0 {
+			w.Header().Set(webhookSecretHeader, secret)
+		}
+
 		w.WriteHeader(http.StatusCreated)
 		provider.SafeWrite(ctx, w, id)
 
 		return
 	}
 
-	if kind == provider.Push {
+	if webhook.Kind == provider.Push {
 		s.renderer.Redirect(w, r, fmt.Sprintf("%s?d=%s", request.AbsoluteURL(""), request.Display), renderer.NewSuccessMessage("Push notification registered!"))
 		return
 	}
 
+	if len(secret) != 0 {
+		s.serveWebhookSecret(w, r, request, "Webhook successfully created", id, secret)
+		return
+	}
+
 	s.renderer.Redirect(w, r, fmt.Sprintf("%s?d=%s#webhook-list", request.AbsoluteURL(""), request.Display), renderer.NewSuccessMessage("Webhook successfully created with ID: %s", id))
 }
 
-func checkWebhookForm(r *http.Request) (recursive bool, kind provider.WebhookKind, webhookURL string, eventTypes []provider.EventType, err error) {
-	recursive, err = getFormBool(r.Form.Get("recursive"))
+// rotateWebhook generates a new signing secret, the previous one still being sent during the grace period
+func (s *Service) rotateWebhook(w http.ResponseWriter, r *http.Request, request provider.Request) {
+	if !request.CanWebhook {
+		s.error(w, r, request, model.WrapForbidden(ErrNotAuthorized))
+		return
+	}
+
+	webhook := s.webhook.Get(r.FormValue("id"))
+	if len(webhook.ID) == 0 {
+		s.error(w, r, request, model.WrapNotFound(errors.New("webhook not found")))
+		return
+	}
+
+	if !webhook.Signed() {
+		s.error(w, r, request, model.WrapInvalid(fmt.Errorf("webhook of kind `%s` is not signed", webhook.Kind)))
+		return
+	}
+
+	var grace time.Duration
+
+	if rawGrace := r.FormValue("grace"); len(rawGrace) != 0 {
+		var err error
+
+		if grace, err = time.ParseDuration(rawGrace); err != nil || grace < 0 || grace > maxSecretGrace {
+			s.error(w, r, request, model.WrapInvalid(fmt.Errorf("invalid grace period `%s`", rawGrace)))
+			return
+		}
+	}
+
+	ctx := r.Context()
+
+	secret, err := s.webhook.Rotate(ctx, webhook.ID, grace)
+	if err != nil {
+		s.error(w, r, request, model.WrapInternal(err))
+		return
+	}
+
+	if r.Header.Get("Accept") == "text/plain" {
+		w.WriteHeader(http.StatusOK)
+		provider.SafeWrite(ctx, w, secret)
+
+		return
+	}
+
+	s.serveWebhookSecret(w, r, request, "Signing secret rotated", webhook.ID, secret)
+}
+
+// serveWebhookSecret renders the secret in the response body, a redirect would put it in the URL, kept by the history and the logs
+func (s *Service) serveWebhookSecret(w http.ResponseWriter, r *http.Request, request provider.Request, title, id, secret string) {
+	s.renderer.Serve(w, r, renderer.NewPage("webhook-secret", http.StatusOK, map[string]any{
+		"Request": request,
+		"Title":   title,
+		"ID":      id,
+		"Secret":  secret,
+	}))
+}
+
+// checkWebhookCredentials builds the URL and the authentication headers of the self-hosted services from their own fields
+func checkWebhookCredentials(r *http.Request, webhook *provider.Webhook) error {
+	server := strings.TrimSuffix(webhook.URL, "/")
+
+	switch webhook.Kind {
+	case provider.Matrix:
+		room, token := strings.TrimSpace(r.Form.Get("matrix-room")), strings.TrimSpace(r.Form.Get("matrix-token"))
+		if len(room) == 0 || len(token) == 0 {
+			return errors.New("room ID and access token are required")
+		}
+
+		webhook.URL = generateMatrixURL(server, room)
+		webhook.Headers = map[string]string{"Authorization": "Bearer " + token}
+
+	case provider.Ntfy:
+		topic := strings.TrimSpace(r.Form.Get("ntfy-topic"))
+		if len(topic) == 0 || strings.Contains(topic, "/") {
+			return errors.New("a topic without slash is required")
+		}
+
+		webhook.URL = server + "/" + topic
+
+		if token := strings.TrimSpace(r.Form.Get("ntfy-token")); len(token) != 0 {
+			webhook.Headers = map[string]string{"Authorization": "Bearer " + token}
+		}
+
+	case provider.Gotify:
+		token := strings.TrimSpace(r.Form.Get("gotify-token"))
+		if len(token) == 0 {
+			return errors.New("application token is required")
+		}
+
+		webhook.URL = server + "/message"
+		webhook.Headers = map[string]string{"X-Gotify-Key": token}
+
+	case provider.Email:
+		address, err := mail.ParseAddress(webhook.URL)
+		if err != nil {
+			return fmt.Errorf("parse email address: %w", err)
+		}
+
+		webhook.URL = address.Address
+
+		if webhook.Digest = r.Form.Get("email-digest"); len(webhook.Digest) != 0 && !slices.Contains(provider.WebhookDigestValues, webhook.Digest) {
+			return fmt.Errorf("invalid digest `%s`", webhook.Digest)
+		}
+	}
+
+	return nil
+}
+
+// checkWebhookFilter reads the optional filter, globs and ignored actors being comma separated and the minimum size in kilobytes
+func checkWebhookFilter(r *http.Request) (provider.WebhookFilter, error) {
+	filter := provider.WebhookFilter{
+		Include:       splitFormList(r.Form.Get("filter-include")),
+		Exclude:       splitFormList(r.Form.Get("filter-exclude")),
+		IgnoredUsers:  splitFormList(r.Form.Get("filter-ignored-users")),
+		IgnoredShares: splitFormList(r.Form.Get("filter-ignored-shares")),
+	}
+
+	for _, pattern := range append(slices.Clone(filter.Include), filter.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return filter, fmt.Errorf("invalid glob `%s`: %w", pattern, err)
+		}
+	}
+
+	for _, family := range r.Form["filter-mimes"] {
+		if len(family) == 0 {
+			continue
+		}
+
+		if !slices.Contains(provider.MimeFamilies, family) {
+			return filter, fmt.Errorf("invalid mime family `%s`", family)
+		}
+
+		filter.Mimes = append(filter.Mimes, family)
+	}
+
+	if rawSize := strings.TrimSpace(r.Form.Get("filter-min-size")); len(rawSize) != 0 {
+		size, err := strconv.ParseInt(rawSize, 10, 64)
+		if err != nil || size < 0 {
+			return filter, fmt.Errorf("invalid minimum size `%s`", rawSize)
+		}
+
+		filter.MinSize = size << 10
+	}
+
+	return filter, nil
+}
+
+func splitFormList(value string) []string {
+	var output []string
+
+	for part := range strings.SplitSeq(value, ",") {
+		if part = strings.TrimSpace(part); len(part) != 0 {
+			output = append(output, part)
+		}
+	}
+
+	return output
+}
+
+// checkWebhookForm validates the webhook from the form, rendering the body of a templated one against a sample event for previewing it
+func checkWebhookForm(r *http.Request, sampleURL string) (provider.Webhook, string, error) {
+	var webhook provider.Webhook
+	var err error
+
+	webhook.Recursive, err = getFormBool(r.Form.Get("recursive"))
 	if err != nil {
-		err = model.WrapInvalid(err)
-		return recursive, kind, webhookURL, eventTypes, err
+		return webhook, "", model.WrapInvalid(err)
 	}
 
-	kind, err = provider.ParseWebhookKind(r.Form.Get("kind"))
+	webhook.Kind, err = provider.ParseWebhookKind(r.Form.Get("kind"))
 	if err != nil {
-		err = model.WrapInvalid(fmt.Errorf("parse kind: %w", err))
-		return recursive, kind, webhookURL, eventTypes, err
+		return webhook, "", model.WrapInvalid(fmt.Errorf("parse kind: %w", err))
 	}
 
-	webhookURL = r.Form.Get("url")
-	if len(webhookURL) == 0 {
-		err = model.WrapInvalid(errors.New("url or token is required"))
-		return recursive, kind, webhookURL, eventTypes, err
+	webhook.URL = r.Form.Get("url")
+	if len(webhook.URL) == 0 {
+		return webhook, "", model.WrapInvalid(errors.New("url or token is required"))
 	}
 
-	if kind == provider.Telegram {
+	if webhook.Kind == provider.Telegram {
 		chatID := r.Form.Get("chat-id")
 		if len(chatID) == 0 {
-			err = model.WrapInvalid(errors.New("chat ID is required"))
-			return recursive, kind, webhookURL, eventTypes, err
+			return webhook, "", model.WrapInvalid(errors.New("chat ID is required"))
 		}
 
-		webhookURL = generateTelegramURL(webhookURL, chatID)
-	} else {
-		if _, err = url.Parse(webhookURL); err != nil {
-			err = model.WrapInvalid(fmt.Errorf("parse url: %w", err))
-			return recursive, kind, webhookURL, eventTypes, err
+		webhook.URL = generateTelegramURL(webhook.URL, chatID)
+	} else if _, err = url.Parse(webhook.URL); err != nil {
+		return webhook, "", model.WrapInvalid(fmt.Errorf("parse url: %w", err))
+	} else if err = checkWebhookCredentials(r, &webhook); err != nil {
+		return webhook, "", model.WrapInvalid(err)
+	}
+
+	if webhook.Types, err = checkWebhookTypes(r); err != nil {
+		return webhook, "", model.WrapInvalid(err)
+	}
+
+	if webhook.Filter, err = checkWebhookFilter(r); err != nil {
+		return webhook, "", model.WrapInvalid(err)
+	}
+
+	if webhook.Kind == provider.Push {
+		if err = checkPushPreferences(r, &webhook); err != nil {
+			return webhook, "", model.WrapInvalid(err)
 		}
 	}
 
+	if webhook.Kind != provider.Template {
+		return webhook, "", nil
+	}
+
+	preview, err := checkWebhookTemplate(r, &webhook, sampleURL)
+
+	return webhook, preview, err
+}
+
+func checkWebhookTypes(r *http.Request) ([]provider.EventType, error) {
 	rawEventTypes := r.Form["types"]
 	if len(rawEventTypes) == 0 {
-		err = model.WrapInvalid(errors.New("at least one event type has to be chosen"))
-		return recursive, kind, webhookURL, eventTypes, err
+		return nil, errors.New("at least one event type has to be chosen")
 	}
 
-	eventTypes = make([]provider.EventType, len(rawEventTypes))
+	types := make([]provider.EventType, len(rawEventTypes))
 	for i, rawEventType := range rawEventTypes {
-		var eType provider.EventType
-		eType, err = provider.ParseEventType(rawEventType)
+		eventType, err := provider.ParseEventType(rawEventType)
 		if err != nil {
-			err = model.WrapInvalid(err)
-			return recursive, kind, webhookURL, eventTypes, err
+			return nil, err
+		}
+
+		types[i] = eventType
+	}
+
+	return types, nil
+}
+
+// checkPushPreferences reads the grouping window and the quiet hours of a push notification, given as `15:04` in the browser's timezone
+func checkPushPreferences(r *http.Request, webhook *provider.Webhook) error {
+	if rawDebounce := r.Form.Get("debounce"); len(rawDebounce) != 0 {
+		debounce, err := time.ParseDuration(rawDebounce)
+		if err != nil || debounce < time.Minute || debounce > maxDebounce {
+			return fmt.Errorf("invalid grouping window `%s`", rawDebounce)
 		}
-		eventTypes[i] = eType
+
+		webhook.Debounce = debounce
+	}
+
+	rawStart, rawEnd := r.Form.Get("quiet-start"), r.Form.Get("quiet-end")
+	if len(rawStart) == 0 && len(rawEnd) == 0 {
+		return nil
+	}
+
+	start, err := time.Parse("15:04", rawStart)
+	if err != nil {
+		return fmt.Errorf("invalid start of quiet hours `%s`", rawStart)
+	}
+
+	end, err := time.Parse("15:04", rawEnd)
+	if err != nil {
+		return fmt.Errorf("invalid end of quiet hours `%s`", rawEnd)
 	}
 
-	return recursive, kind, webhookURL, eventTypes, err
+	timezone := r.Form.Get("quiet-timezone")
+	if _, err = time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("invalid timezone `%s`", timezone)
+	}
+
+	webhook.Quiet = provider.WebhookQuietHours{
+		Timezone: timezone,
+		Start:    start.Hour()*60 + start.Minute(),
+		End:      end.Hour()*60 + end.Minute(),
+	}
+
+	return nil
+}
+
+func checkWebhookTemplate(r *http.Request, webhook *provider.Webhook, sampleURL string) (string, error) {
+	webhook.Method = strings.ToUpper(strings.TrimSpace(r.Form.Get("template-method")))
+	if len(webhook.Method) == 0 {
+		webhook.Method = http.MethodPost
+	}
+
+	if !slices.Contains(webhookMethods, webhook.Method) {
+		return "", model.WrapInvalid(fmt.Errorf("method `%s` is not supported", webhook.Method))
+	}
+
+	for _, line := range strings.Split(r.Form.Get("template-headers"), "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || len(strings.TrimSpace(name)) == 0 {
+			return "", model.WrapInvalid(fmt.Errorf("header `%s` is not in the `Name: value` form", strings.TrimSpace(line)))
+		}
+
+		if webhook.Headers == nil {
+			webhook.Headers = make(map[string]string)
+		}
+
+		webhook.Headers[http.CanonicalHeaderKey(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+
+	webhook.Template = r.Form.Get("template-body")
+	if len(strings.TrimSpace(webhook.Template)) == 0 {
+		return "", model.WrapInvalid(errors.New("template is required"))
+	}
+
+	sample := provider.SampleEvent(sampleURL)
+
+	preview, err := provider.RenderWebhookTemplate(webhook.Template, provider.NewWebhookTemplateData(sample, sample.GetURL()+"?thumbnail"))
+	if err != nil {
+		return "", model.WrapInvalid(fmt.Errorf("template: %w", err))
+	}
+
+	return preview, nil
 }
 
 func (s *Service) deleteWebhook(w http.ResponseWriter, r *http.Request, request provider.Request) {
@@ -160,3 +459,50 @@ func (s *Service) deleteWebhook(w http.ResponseWriter, r *http.Request, request
 
 	s.renderer.Redirect(w, r, fmt.Sprintf("%s?d=%s#webhook-list", request.AbsoluteURL(""), request.Display), renderer.NewSuccessMessage("Webhook with id %s successfully deleted", webhook.ID))
 }
+
+// fireWebhook replays a past delivery of the webhook if one is given, or sends it a test event
+func (s *Service) fireWebhook(w http.ResponseWriter, r *http.Request, request provider.Request) {
+	if !request.CanWebhook {
+		s.error(w, r, request, model.WrapForbidden(ErrNotAuthorized))
+		return
+	}
+
+	webhook := s.webhook.Get(r.FormValue("id"))
+	if len(webhook.ID) == 0 {
+		s.error(w, r, request, model.WrapNotFound(errors.New("webhook not found")))
+		return
+	}
+
+	ctx := r.Context()
+
+	var delivery provider.WebhookDelivery
+	var err error
+
+	if deliveryID := r.FormValue("delivery"); len(deliveryID) != 0 {
+		delivery, err = s.webhook.Replay(ctx, webhook.ID, deliveryID)
+	} else {
+		var item absto.Item
+
+		item, err = s.storage.Stat(ctx, webhook.Pathname)
+		if err != nil {
+			s.error(w, r, request, model.WrapNotFound(err))
+			return
+		}
+
+		delivery, err = s.webhook.Fire(ctx, webhook.ID, provider.NewTestEvent(ctx, request, item, webhook.Types[0], s.renderer))
+	}
+
+	if err != nil {
+		s.error(w, r, request, model.WrapInternal(err))
+		return
+	}
+
+	redirect := fmt.Sprintf("%s?d=%s#webhook-list", request.AbsoluteURL(""), request.Display)
+
+	if delivery.Failed() {
+		s.renderer.Redirect(w, r, redirect, renderer.NewErrorMessage("Delivery failed after %d attempt(s): %s", delivery.Attempts, delivery.Error))
+		return
+	}
+
+	s.renderer.Redirect(w, r, redirect, renderer.NewSuccessMessage("Delivered with status %d in %s", delivery.Status, delivery.Latency.Round(time.Millisecond)))
+}
@@ -0,0 +1,282 @@
+package crud
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestCheckWebhookForm(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		form        url.Values
+		want        provider.Webhook
+		wantPreview string
+		wantErr     error
+	}{
+		"raw": {
+			url.Values{"kind": {"raw"}, "url": {"https://website.com/fibr"}, "types": {"upload", "delete"}},
+			provider.Webhook{
+				Kind:  provider.Raw,
+				URL:   "https://website.com/fibr",
+				Types: []provider.EventType{provider.UploadEvent, provider.DeleteEvent},
+			},
+			"",
+			nil,
+		},
+		"no type": {
+			url.Values{"kind": {"raw"}, "url": {"https://website.com/fibr"}},
+			provider.Webhook{},
+			"",
+			errors.New("at least one event type"),
+		},
+		"matrix": {
+			url.Values{"kind": {"matrix"}, "url": {"https://matrix.org/"}, "types": {"upload"}, "matrix-room": {"!abc:matrix.org"}, "matrix-token": {"secret"}},
+			provider.Webhook{
+				Kind:    provider.Matrix,
+				URL:     "https://matrix.org/_matrix/client/v3/rooms/%21abc:matrix.org/send/m.room.message",
+				Types:   []provider.EventType{provider.UploadEvent},
+				Headers: map[string]string{"Authorization": "Bearer secret"},
+			},
+			"",
+			nil,
+		},
+		"matrix without token": {
+			url.Values{"kind": {"matrix"}, "url": {"https://matrix.org"}, "types": {"upload"}, "matrix-room": {"!abc:matrix.org"}},
+			provider.Webhook{},
+			"",
+			errors.New("access token are required"),
+		},
+		"ntfy public": {
+			url.Values{"kind": {"ntfy"}, "url": {"https://ntfy.sh"}, "types": {"upload"}, "ntfy-topic": {"fibr"}},
+			provider.Webhook{
+				Kind:  provider.Ntfy,
+				URL:   "https://ntfy.sh/fibr",
+				Types: []provider.EventType{provider.UploadEvent},
+			},
+			"",
+			nil,
+		},
+		"ntfy invalid topic": {
+			url.Values{"kind": {"ntfy"}, "url": {"https://ntfy.sh"}, "types": {"upload"}, "ntfy-topic": {"a/b"}},
+			provider.Webhook{},
+			"",
+			errors.New("topic without slash"),
+		},
+		"gotify": {
+			url.Values{"kind": {"gotify"}, "url": {"https://gotify.local"}, "types": {"upload"}, "gotify-token": {"secret"}},
+			provider.Webhook{
+				Kind:    provider.Gotify,
+				URL:     "https://gotify.local/message",
+				Types:   []provider.EventType{provider.UploadEvent},
+				Headers: map[string]string{"X-Gotify-Key": "secret"},
+			},
+			"",
+			nil,
+		},
+		"email digest": {
+			url.Values{"kind": {"email"}, "url": {"Alice <alice@example.com>"}, "types": {"upload"}, "email-digest": {"daily"}},
+			provider.Webhook{
+				Kind:   provider.Email,
+				URL:    "alice@example.com",
+				Types:  []provider.EventType{provider.UploadEvent},
+				Digest: "daily",
+			},
+			"",
+			nil,
+		},
+		"email invalid address": {
+			url.Values{"kind": {"email"}, "url": {"alice"}, "types": {"upload"}},
+			provider.Webhook{},
+			"",
+			errors.New("parse email address"),
+		},
+		"email invalid digest": {
+			url.Values{"kind": {"email"}, "url": {"alice@example.com"}, "types": {"upload"}, "email-digest": {"monthly"}},
+			provider.Webhook{},
+			"",
+			errors.New("invalid digest"),
+		},
+		"push debounce": {
+			url.Values{"kind": {"push"}, "url": {"https://push.local/abc"}, "types": {"upload"}, "debounce": {"1h"}},
+			provider.Webhook{
+				Kind:     provider.Push,
+				URL:      "https://push.local/abc",
+				Types:    []provider.EventType{provider.UploadEvent},
+				Debounce: time.Hour,
+			},
+			"",
+			nil,
+		},
+		"push debounce too short": {
+			url.Values{"kind": {"push"}, "url": {"https://push.local/abc"}, "types": {"upload"}, "debounce": {"10s"}},
+			provider.Webhook{},
+			"",
+			errors.New("invalid grouping window `10s`"),
+		},
+		"push quiet hours": {
+			url.Values{"kind": {"push"}, "url": {"https://push.local/abc"}, "types": {"upload", "rename"}, "quiet-start": {"22:30"}, "quiet-end": {"07:00"}, "quiet-timezone": {"Europe/Paris"}},
+			provider.Webhook{
+				Kind:  provider.Push,
+				URL:   "https://push.local/abc",
+				Types: []provider.EventType{provider.UploadEvent, provider.RenameEvent},
+				Quiet: provider.WebhookQuietHours{Timezone: "Europe/Paris", Start: 22*60 + 30, End: 7 * 60},
+			},
+			"",
+			nil,
+		},
+		"push quiet hours without end": {
+			url.Values{"kind": {"push"}, "url": {"https://push.local/abc"}, "types": {"upload"}, "quiet-start": {"22:30"}},
+			provider.Webhook{},
+			"",
+			errors.New("invalid end of quiet hours ``"),
+		},
+		"filter": {
+			url.Values{
+				"kind":                 {"raw"},
+				"url":                  {"https://website.com/fibr"},
+				"types":                {"upload"},
+				"filter-include":       {"*.jpg, /photos/raw/*"},
+				"filter-exclude":       {" .DS_Store ,,"},
+				"filter-mimes":         {"", "image", "video"},
+				"filter-min-size":      {"512"},
+				"filter-ignored-users": {"admin"},
+			},
+			provider.Webhook{
+				Kind:  provider.Raw,
+				URL:   "https://website.com/fibr",
+				Types: []provider.EventType{provider.UploadEvent},
+				Filter: provider.WebhookFilter{
+					Include:      []string{"*.jpg", "/photos/raw/*"},
+					Exclude:      []string{".DS_Store"},
+					Mimes:        []string{"image", "video"},
+					IgnoredUsers: []string{"admin"},
+					MinSize:      512 << 10,
+				},
+			},
+			"",
+			nil,
+		},
+		"filter invalid glob": {
+			url.Values{"kind": {"raw"}, "url": {"https://website.com/fibr"}, "types": {"upload"}, "filter-exclude": {"[a-"}},
+			provider.Webhook{},
+			"",
+			errors.New("invalid glob `[a-`"),
+		},
+		"filter invalid mime": {
+			url.Values{"kind": {"raw"}, "url": {"https://website.com/fibr"}, "types": {"upload"}, "filter-mimes": {"movie"}},
+			provider.Webhook{},
+			"",
+			errors.New("invalid mime family `movie`"),
+		},
+		"filter invalid size": {
+			url.Values{"kind": {"raw"}, "url": {"https://website.com/fibr"}, "types": {"upload"}, "filter-min-size": {"-1"}},
+			provider.Webhook{},
+			"",
+			errors.New("invalid minimum size"),
+		},
+		"template": {
+			url.Values{
+				"kind":             {"template"},
+				"url":              {"https://website.com/fibr"},
+				"types":            {"upload"},
+				"template-method":  {"put"},
+				"template-headers": {"content-type: text/plain\r\n\r\nX-Token: secret"},
+				"template-body":    {"{{ .Name }} {{ .ThumbnailURL }}"},
+			},
+			provider.Webhook{
+				Kind:     provider.Template,
+				URL:      "https://website.com/fibr",
+				Types:    []provider.EventType{provider.UploadEvent},
+				Method:   http.MethodPut,
+				Headers:  map[string]string{"Content-Type": "text/plain", "X-Token": "secret"},
+				Template: "{{ .Name }} {{ .ThumbnailURL }}",
+			},
+			"sunset.jpg https://fibr.local/sunset.jpg?thumbnail",
+			nil,
+		},
+		"template default method": {
+			url.Values{"kind": {"template"}, "url": {"https://website.com/fibr"}, "types": {"upload"}, "template-body": {"{{ .Type }}"}},
+			provider.Webhook{
+				Kind:     provider.Template,
+				URL:      "https://website.com/fibr",
+				Types:    []provider.EventType{provider.UploadEvent},
+				Method:   http.MethodPost,
+				Template: "{{ .Type }}",
+			},
+			"upload",
+			nil,
+		},
+		"template invalid method": {
+			url.Values{"kind": {"template"}, "url": {"https://website.com/fibr"}, "types": {"upload"}, "template-method": {"TRACE"}, "template-body": {"{{ .Type }}"}},
+			provider.Webhook{},
+			"",
+			errors.New("method `TRACE` is not supported"),
+		},
+		"template invalid header": {
+			url.Values{"kind": {"template"}, "url": {"https://website.com/fibr"}, "types": {"upload"}, "template-headers": {"X-Token"}, "template-body": {"{{ .Type }}"}},
+			provider.Webhook{},
+			"",
+			errors.New("`Name: value`"),
+		},
+		"template missing body": {
+			url.Values{"kind": {"template"}, "url": {"https://website.com/fibr"}, "types": {"upload"}},
+			provider.Webhook{},
+			"",
+			errors.New("template is required"),
+		},
+		"template unknown field": {
+			url.Values{"kind": {"template"}, "url": {"https://website.com/fibr"}, "types": {"upload"}, "template-body": {"{{ .Filename }}"}},
+			provider.Webhook{},
+			"",
+			errors.New("template: execute"),
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.form.Encode()))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			if err := request.ParseForm(); err != nil {
+				t.Fatalf("parse form: %s", err)
+			}
+
+			got, gotPreview, gotErr := checkWebhookForm(request, "https://fibr.local/sunset.jpg")
+
+			failed := false
+
+			switch {
+			case tc.wantErr == nil && gotErr != nil:
+				failed = true
+			case tc.wantErr != nil && gotErr == nil:
+				failed = true
+			case tc.wantErr != nil && !strings.Contains(gotErr.Error(), tc.wantErr.Error()):
+				failed = true
+			}
+
+			if failed {
+				t.Errorf("checkWebhookForm() = %v, want %v", gotErr, tc.wantErr)
+				return
+			}
+
+			if gotErr != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(got, tc.want) || gotPreview != tc.wantPreview {
+				t.Errorf("checkWebhookForm() = (%+v, `%s`), want (%+v, `%s`)", got, gotPreview, tc.want, tc.wantPreview)
+			}
+		})
+	}
+}
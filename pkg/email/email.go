@@ -0,0 +1,149 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/ViBiOh/flags"
+)
+
+var ErrNoConfig = errors.New("smtp host not set")
+
+type Service struct {
+	auth     smtp.Auth
+	host     string
+	address  string
+	from     string
+	timeout  time.Duration
+	insecure bool
+}
+
+type Config struct {
+	Host     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+	Port     uint
+	Insecure bool
+}
+
+func Flags(fs *flag.FlagSet, prefix string, overrides ...flags.Override) *Config {
+	var config Config
+
+	flags.New("Host", "SMTP host, empty to disable emails").Prefix(prefix).DocPrefix("smtp").StringVar(fs, &config.Host, "", overrides)
+	flags.New("Port", "SMTP port").Prefix(prefix).DocPrefix("smtp").UintVar(fs, &config.Port, 587, overrides)
+	flags.New("Username", "SMTP username").Prefix(prefix).DocPrefix("smtp").StringVar(fs, &config.Username, "", overrides)
+	flags.New("Password", "SMTP password").Prefix(prefix).DocPrefix("smtp").StringVar(fs, &config.Password, "", overrides)
+	flags.New("From", "Sender of the emails").Prefix(prefix).DocPrefix("smtp").StringVar(fs, &config.From, "fibr@localhost", overrides)
+	flags.New("Timeout", "Timeout for sending an email").Prefix(prefix).DocPrefix("smtp").DurationVar(fs, &config.Timeout, time.Second*30, overrides)
+	flags.New("Insecure", "Skip verification of the server's certificate").Prefix(prefix).DocPrefix("smtp").BoolVar(fs, &config.Insecure, false, overrides)
+
+	return &config
+}
+
+func New(config *Config) (*Service, error) {
+	if len(config.Host) == 0 {
+		return nil, ErrNoConfig
+	}
+
+	service := &Service{
+		host:     config.Host,
+		address:  net.JoinHostPort(config.Host, strconv.FormatUint(uint64(config.Port), 10)),
+		from:     config.From,
+		timeout:  config.Timeout,
+		insecure: config.Insecure,
+	}
+
+	if len(config.Username) != 0 {
+		service.auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	return service, nil
+}
+
+func (s *Service) Enabled() bool {
+	return s != nil
+}
+
+// Send delivers the message, upgrading the connection with STARTTLS when the server offers it
+func (s *Service) Send(ctx context.Context, message Message) error {
+	if s == nil {
+		return ErrNoConfig
+	}
+
+	if len(message.To) == 0 {
+		return errors.New("no recipient")
+	}
+
+	content, err := message.encode(s.from, time.Now())
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("client: %w", err)
+	}
+
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: s.host, InsecureSkipVerify: s.insecure}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if s.auth != nil {
+		if err = client.Auth(s.auth); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err = client.Mail(s.from); err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+
+	for _, recipient := range message.To {
+		if err = client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("rcpt `%s`: %w", recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+
+	if _, err = writer.Write(content); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("close data: %w", err)
+	}
+
+	return client.Quit()
+}
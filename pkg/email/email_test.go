@@ -0,0 +1,174 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sinkMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpSink accepts a single SMTP session without TLS nor authentication, and records the mail
+func smtpSink(t *testing.T) (string, <-chan sinkMail) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	output := make(chan sinkMail, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		_ = text.PrintfLine("220 sink ESMTP")
+
+		var received sinkMail
+
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+
+			command := strings.ToUpper(line)
+
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				_ = text.PrintfLine("250 sink")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				received.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				_ = text.PrintfLine("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				received.to = append(received.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				_ = text.PrintfLine("250 OK")
+			case command == "DATA":
+				_ = text.PrintfLine("354 Go ahead")
+
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+
+				received.data = string(data)
+				_ = text.PrintfLine("250 OK")
+			case command == "QUIT":
+				_ = text.PrintfLine("221 Bye")
+				output <- received
+
+				return
+			default:
+				_ = text.PrintfLine("502 Unknown")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), output
+}
+
+func TestSend(t *testing.T) {
+	address, received := smtpSink(t)
+
+	host, port, _ := net.SplitHostPort(address)
+
+	portValue, _ := strconv.ParseUint(port, 10, 16)
+
+	instance, err := New(&Config{Host: host, Port: uint(portValue), From: "fibr@localhost", Timeout: time.Second * 5})
+	if err != nil {
+		t.Fatalf("New() = %s", err)
+	}
+
+	message := Message{
+		To:      []string{"alice@localhost"},
+		Subject: "[fibr] photos: 💾 A file has been uploaded",
+		Text:    "A file has been uploaded",
+		HTML:    `<p>A file has been uploaded</p><img src="cid:thumbnail@fibr">`,
+		Inlines: []Inline{{ContentID: "thumbnail@fibr", ContentType: "image/webp", Content: []byte("RIFF....WEBP")}},
+	}
+
+	if err = instance.Send(context.Background(), message); err != nil {
+		t.Fatalf("Send() = %s", err)
+	}
+
+	got := <-received
+
+	if got.from != "fibr@localhost" || len(got.to) != 1 || got.to[0] != "alice@localhost" {
+		t.Errorf("Send() = from `%s` to %v, want from `fibr@localhost` to alice@localhost", got.from, got.to)
+	}
+
+	parsed, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(got.data)))
+	if err != nil {
+		t.Fatalf("read message: %s", err)
+	}
+
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject")); subject != message.Subject {
+		t.Errorf("Send() subject = `%s`, want `%s`", subject, message.Subject)
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if mediaType != "multipart/related" {
+		t.Fatalf("Send() content type = `%s`, want multipart/related", mediaType)
+	}
+
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+
+	alternative, err := parts.NextPart()
+	if err != nil {
+		t.Fatalf("read alternative: %s", err)
+	}
+
+	_, alternativeParams, _ := mime.ParseMediaType(alternative.Header.Get("Content-Type"))
+	alternatives := multipart.NewReader(alternative, alternativeParams["boundary"])
+
+	for _, want := range []string{message.Text, message.HTML} {
+		part, err := alternatives.NextRawPart()
+		if err != nil {
+			t.Fatalf("read alternative part: %s", err)
+		}
+
+		content, _ := io.ReadAll(quotedprintable.NewReader(part))
+		if string(content) != want {
+			t.Errorf("Send() part = `%s`, want `%s`", content, want)
+		}
+	}
+
+	inline, err := parts.NextPart()
+	if err != nil {
+		t.Fatalf("read inline: %s", err)
+	}
+
+	if inline.Header.Get("Content-Id") != "<thumbnail@fibr>" || inline.Header.Get("Content-Type") != "image/webp" {
+		t.Errorf("Send() inline = %v, want the thumbnail", inline.Header)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(&Config{}); err != ErrNoConfig {
+		t.Errorf("New() = %v, want %s", err, ErrNoConfig)
+	}
+}
@@ -0,0 +1,124 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const base64LineLength = 76
+
+// Inline is a file embedded in the HTML body, referenced with `cid:<ContentID>`
+type Inline struct {
+	ContentID   string
+	ContentType string
+	Content     []byte
+}
+
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+	To      []string
+	Inlines []Inline
+}
+
+// encode writes the message as a multipart/related holding the text and HTML alternatives, then the inline files
+func (m Message) encode(from string, now time.Time) ([]byte, error) {
+	var output bytes.Buffer
+
+	related := multipart.NewWriter(&output)
+
+	fmt.Fprintf(&output, "From: %s\r\n", from)
+	fmt.Fprintf(&output, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&output, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&output, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&output, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&output, "Content-Type: multipart/related; boundary=%q\r\n\r\n", related.Boundary())
+
+	var alternativeContent bytes.Buffer
+	alternative := multipart.NewWriter(&alternativeContent)
+
+	if err := writeQuotedPrintable(alternative, "text/plain; charset=utf-8", m.Text); err != nil {
+		return nil, fmt.Errorf("text: %w", err)
+	}
+
+	if err := writeQuotedPrintable(alternative, "text/html; charset=utf-8", m.HTML); err != nil {
+		return nil, fmt.Errorf("html: %w", err)
+	}
+
+	if err := alternative.Close(); err != nil {
+		return nil, fmt.Errorf("close alternative: %w", err)
+	}
+
+	part, err := related.CreatePart(textproto.MIMEHeader{"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=%q", alternative.Boundary())}})
+	if err != nil {
+		return nil, fmt.Errorf("create alternative: %w", err)
+	}
+
+	if _, err = part.Write(alternativeContent.Bytes()); err != nil {
+		return nil, fmt.Errorf("write alternative: %w", err)
+	}
+
+	for _, inline := range m.Inlines {
+		if err = writeInline(related, inline); err != nil {
+			return nil, fmt.Errorf("inline `%s`: %w", inline.ContentID, err)
+		}
+	}
+
+	if err = related.Close(); err != nil {
+		return nil, fmt.Errorf("close related: %w", err)
+	}
+
+	return output.Bytes(), nil
+}
+
+func writeQuotedPrintable(writer *multipart.Writer, contentType, content string) error {
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return fmt.Errorf("create part: %w", err)
+	}
+
+	encoder := quotedprintable.NewWriter(part)
+
+	if _, err = io.WriteString(encoder, content); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return encoder.Close()
+}
+
+func writeInline(writer *multipart.Writer, inline Inline) error {
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {inline.ContentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {"inline"},
+		"Content-Id":                {"<" + inline.ContentID + ">"},
+	})
+	if err != nil {
+		return fmt.Errorf("create part: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(inline.Content)
+
+	for len(encoded) > 0 {
+		line := encoded[:min(len(encoded), base64LineLength)]
+		encoded = encoded[len(line):]
+
+		if _, err = io.WriteString(part, line+"\r\n"); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+
+	return nil
+}
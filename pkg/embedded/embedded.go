@@ -0,0 +1,202 @@
+package embedded
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	exas "github.com/ViBiOh/exas/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+// maxBlockSize caps what is read for a single metadata block, we don't need embedded previews or maker notes
+const maxBlockSize = 4 << 20
+
+var (
+	ErrUnsupported = errors.New("unsupported format")
+
+	errInvalid = errors.New("invalid content")
+)
+
+type format int
+
+const (
+	formatJPEG format = iota + 1
+	formatPNG
+	formatTIFF
+	formatHEIF
+)
+
+var formats = map[string]format{
+	".jpg":  formatJPEG,
+	".jpeg": formatJPEG,
+	".png":  formatPNG,
+	".tif":  formatTIFF,
+	".tiff": formatTIFF,
+	".dng":  formatTIFF,
+	".cr2":  formatTIFF,
+	".nef":  formatTIFF,
+	".arw":  formatTIFF,
+	".orf":  formatTIFF,
+	".rw2":  formatTIFF,
+	".pef":  formatTIFF,
+	".heic": formatHEIF,
+	".heif": formatHEIF,
+}
+
+func Supported(extension string) bool {
+	return formats[extension] != 0
+}
+
+// Extract reads EXIF, XMP and IPTC metadata embedded in the given content, without any external tool
+func Extract(reader io.ReaderAt, size int64, extension string) (provider.Metadata, error) {
+	var content extraction
+	var err error
+
+	switch formats[extension] {
+	case formatJPEG:
+		err = content.readJPEG(reader, size)
+	case formatPNG:
+		err = content.readPNG(reader, size)
+	case formatTIFF:
+		err = content.readTIFF(reader, size)
+	case formatHEIF:
+		err = content.readHEIF(reader, size)
+	default:
+		return provider.Metadata{}, ErrUnsupported
+	}
+
+	if err != nil {
+		return provider.Metadata{}, err
+	}
+
+	return content.metadata(), nil
+}
+
+type extraction struct {
+	exif     exas.Exif
+	xmp      XMP
+	keywords []string
+	caption  string
+	rating   int
+	width    uint32
+	height   uint32
+}
+
+func (e *extraction) set(key string, value any) {
+	if e.exif.Data == nil {
+		e.exif.Data = make(map[string]any)
+	}
+
+	e.exif.Data[key] = value
+}
+
+func (e *extraction) setDimensions(width, height uint32) {
+	if width == 0 || height == 0 {
+		return
+	}
+
+	e.width = width
+	e.height = height
+}
+
+func (e *extraction) readXMP(reader io.Reader) error {
+	xmp, err := ParseXMP(reader)
+	if err != nil {
+		return fmt.Errorf("xmp: %w", err)
+	}
+
+	e.xmp = xmp
+
+	return nil
+}
+
+// readXMPBlock ignores the padding that writers leave after the packet
+func (e *extraction) readXMPBlock(content []byte) error {
+	return e.readXMP(bytes.NewReader(bytes.TrimRight(content, "\x00")))
+}
+
+func (e *extraction) metadata() provider.Metadata {
+	if e.width != 0 {
+		e.set("ImageWidth", int(e.width))
+		e.set("ImageHeight", int(e.height))
+	}
+
+	if e.exif.Date.IsZero() && !e.xmp.Date.IsZero() {
+		e.exif.Date = e.xmp.Date
+	}
+
+	if e.xmp.Rating != 0 {
+		e.rating = e.xmp.Rating
+	}
+
+	if e.rating != 0 {
+		e.set("Rating", e.rating)
+	}
+
+	output := provider.Metadata{
+		Exif:        e.exif,
+		Description: e.xmp.Description,
+		Rating:      e.rating,
+	}
+
+	if len(output.Description) == 0 {
+		output.Description = e.caption
+	}
+
+	for _, tag := range append(slices.Clone(e.xmp.Tags), e.keywords...) {
+		if !slices.Contains(output.Tags, tag) {
+			output.Tags = append(output.Tags, tag)
+		}
+	}
+
+	return output
+}
+
+func readAt(reader io.ReaderAt, offset int64, length int64) ([]byte, error) {
+	if length < 0 || length > maxBlockSize {
+		return nil, fmt.Errorf("block of %d bytes: %w", length, errInvalid)
+	}
+
+	output := make([]byte, length)
+
+	if read, err := reader.ReadAt(output, offset); read != len(output) {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func parseDate(value, offset string) (time.Time, bool) {
+	location := time.UTC
+
+	if len(offset) != 0 {
+		if zone, err := time.Parse("-07:00", strings.TrimSpace(offset)); err == nil {
+			location = zone.Location()
+		}
+	}
+
+	date, err := time.ParseInLocation("2006:01:02 15:04:05", strings.TrimSpace(value), location)
+	if err != nil || date.Year() < 1800 {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+func formatExposure(value float64) string {
+	if value > 0 && value < 1 {
+		return fmt.Sprintf("1/%d", int(math.Round(1/value)))
+	}
+
+	return fmt.Sprintf("%g", value)
+}
@@ -0,0 +1,372 @@
+package embedded
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	exas "github.com/ViBiOh/exas/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const testXMP = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+  <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+    <rdf:Description xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:CreateDate="2021-05-06T07:08:09">
+      <dc:subject>
+        <rdf:Bag>
+          <rdf:li>holidays</rdf:li>
+          <rdf:li>beach</rdf:li>
+        </rdf:Bag>
+      </dc:subject>
+      <dc:description>
+        <rdf:Alt>
+          <rdf:li xml:lang="fr-FR">Coucher de soleil</rdf:li>
+          <rdf:li xml:lang="x-default">Sunset</rdf:li>
+        </rdf:Alt>
+      </dc:description>
+    </rdf:Description>
+  </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+type testEntry struct {
+	tag   uint16
+	kind  uint16
+	count uint32
+	value []byte
+}
+
+func asciiEntry(tag uint16, value string) testEntry {
+	return testEntry{tag: tag, kind: 2, count: uint32(len(value) + 1), value: append([]byte(value), 0)}
+}
+
+func shortEntry(tag, value uint16) testEntry {
+	return testEntry{tag: tag, kind: 3, count: 1, value: binary.LittleEndian.AppendUint16(nil, value)}
+}
+
+func longEntry(tag uint16, value uint32) testEntry {
+	return testEntry{tag: tag, kind: 4, count: 1, value: binary.LittleEndian.AppendUint32(nil, value)}
+}
+
+func rationalEntry(tag uint16, values ...uint32) testEntry {
+	var content []byte
+	for _, value := range values {
+		content = binary.LittleEndian.AppendUint32(content, value)
+	}
+
+	return testEntry{tag: tag, kind: 5, count: uint32(len(values) / 2), value: content}
+}
+
+func ifdLength(entries []testEntry) int {
+	length := 6 + 12*len(entries)
+
+	for _, entry := range entries {
+		if len(entry.value) > 4 {
+			length += len(entry.value) + len(entry.value)%2
+		}
+	}
+
+	return length
+}
+
+func writeIFD(buffer *bytes.Buffer, entries []testEntry) {
+	order := binary.LittleEndian
+	extra := buffer.Len() + 6 + 12*len(entries)
+
+	var values []byte
+
+	buffer.Write(order.AppendUint16(nil, uint16(len(entries))))
+
+	for _, entry := range entries {
+		buffer.Write(order.AppendUint16(nil, entry.tag))
+		buffer.Write(order.AppendUint16(nil, entry.kind))
+		buffer.Write(order.AppendUint32(nil, entry.count))
+
+		if len(entry.value) <= 4 {
+			buffer.Write(append(entry.value, make([]byte, 4-len(entry.value))...))
+			continue
+		}
+
+		buffer.Write(order.AppendUint32(nil, uint32(extra+len(values))))
+		values = append(values, entry.value...)
+
+		if len(entry.value)%2 == 1 {
+			values = append(values, 0)
+		}
+	}
+
+	buffer.Write(order.AppendUint32(nil, 0))
+	buffer.Write(values)
+}
+
+func tiffFixture() []byte {
+	exif := []testEntry{
+		rationalEntry(tagExposureTime, 1, 250),
+		rationalEntry(tagFNumber, 18, 10),
+		shortEntry(tagISO, 200),
+		asciiEntry(tagDateTimeOriginal, "2022:07:14 18:30:00"),
+		asciiEntry(tagOffsetTimeOriginal, "+02:00"),
+		rationalEntry(tagFocalLength, 42, 10),
+	}
+
+	gps := []testEntry{
+		asciiEntry(tagLatitudeRef, "N"),
+		rationalEntry(tagLatitude, 48, 1, 51, 1, 36, 1),
+		asciiEntry(tagLongitudeRef, "W"),
+		rationalEntry(tagLongitude, 2, 1, 21, 1, 0, 1),
+	}
+
+	main := []testEntry{
+		asciiEntry(tagMake, "Apple"),
+		asciiEntry(tagModel, "iPhone 12"),
+		shortEntry(tagOrientation, 6),
+		longEntry(tagImageWidth, 160),
+		longEntry(tagImageHeight, 120),
+	}
+
+	exifOffset := 8 + ifdLength(main) + 24
+	main = append(main, longEntry(tagExifIFD, uint32(exifOffset)), longEntry(tagGPSIFD, uint32(exifOffset+ifdLength(exif))))
+
+	var buffer bytes.Buffer
+	buffer.WriteString("II")
+	buffer.Write(binary.LittleEndian.AppendUint16(nil, 0x2a))
+	buffer.Write(binary.LittleEndian.AppendUint32(nil, 8))
+
+	writeIFD(&buffer, main)
+	writeIFD(&buffer, exif)
+	writeIFD(&buffer, gps)
+
+	return buffer.Bytes()
+}
+
+func jpegSegment(marker byte, payload ...[]byte) []byte {
+	content := bytes.Join(payload, nil)
+
+	return append([]byte{0xff, marker, byte((len(content) + 2) >> 8), byte(len(content) + 2)}, content...)
+}
+
+func iptcFixture() []byte {
+	dataset := func(number byte, value string) []byte {
+		return append([]byte{0x1c, 2, number, 0, byte(len(value))}, value...)
+	}
+
+	iptc := bytes.Join([][]byte{dataset(25, "family"), dataset(25, "beach"), dataset(120, "Caption")}, nil)
+
+	return bytes.Join([][]byte{[]byte("8BIM"), {0x04, 0x04, 0, 0}, binary.BigEndian.AppendUint32(nil, uint32(len(iptc))), iptc}, nil)
+}
+
+func jpegFixture() []byte {
+	frame := []byte{8, 0x0b, 0xb8, 0x0f, 0xa0, 3}
+
+	return bytes.Join([][]byte{
+		{0xff, 0xd8},
+		jpegSegment(0xe1, exifPrefix, tiffFixture()),
+		jpegSegment(0xe1, xmpPrefix, []byte(testXMP), []byte{0, 0}),
+		jpegSegment(0xed, photoshopPrefix, iptcFixture()),
+		jpegSegment(0xc0, frame),
+		jpegSegment(0xda, []byte{0}),
+		{0xff, 0xd9},
+	}, nil)
+}
+
+func pngChunk(kind string, content ...[]byte) []byte {
+	data := bytes.Join(content, nil)
+
+	return bytes.Join([][]byte{binary.BigEndian.AppendUint32(nil, uint32(len(data))), []byte(kind), data, {0, 0, 0, 0}}, nil)
+}
+
+func pngFixture() []byte {
+	return bytes.Join([][]byte{
+		pngSignature,
+		pngChunk("IHDR", binary.BigEndian.AppendUint32(nil, 640), binary.BigEndian.AppendUint32(nil, 480), []byte{8, 2, 0, 0, 0}),
+		pngChunk("eXIf", tiffFixture()),
+		pngChunk("iTXt", []byte(pngXMPKeyword), []byte{0, 0, 0, 0, 0}, []byte(testXMP)),
+		pngChunk("IEND"),
+	}, nil)
+}
+
+func isoBox(kind string, content ...[]byte) []byte {
+	data := bytes.Join(content, nil)
+
+	return bytes.Join([][]byte{binary.BigEndian.AppendUint32(nil, uint32(len(data)+8)), []byte(kind), data}, nil)
+}
+
+func heifFixture() []byte {
+	exif := append([]byte{0, 0, 0, 6}, append(exifPrefix, tiffFixture()...)...)
+	ftyp := isoBox("ftyp", []byte("heic"), []byte{0, 0, 0, 0}, []byte("mif1heic"))
+
+	meta := func(offset uint32) []byte {
+		return isoBox("meta", []byte{0, 0, 0, 0},
+			isoBox("iinf", []byte{0, 0, 0, 0, 0, 2},
+				isoBox("infe", []byte{2, 0, 0, 0, 0, 1, 0, 0}, []byte("hvc1"), []byte{0}),
+				isoBox("infe", []byte{2, 0, 0, 0, 0, 2, 0, 0}, []byte("Exif"), []byte{0}),
+			),
+			isoBox("iloc", []byte{1, 0, 0, 0, 0x44, 0x00, 0, 1, 0, 2, 0, 0, 0, 0, 0, 1}, binary.BigEndian.AppendUint32(nil, offset), binary.BigEndian.AppendUint32(nil, uint32(len(exif)))),
+			isoBox("iprp",
+				isoBox("ipco",
+					isoBox("ispe", []byte{0, 0, 0, 0}, binary.BigEndian.AppendUint32(nil, 512), binary.BigEndian.AppendUint32(nil, 512)),
+					isoBox("ispe", []byte{0, 0, 0, 0}, binary.BigEndian.AppendUint32(nil, 4032), binary.BigEndian.AppendUint32(nil, 3024)),
+				),
+			),
+		)
+	}
+
+	offset := uint32(len(ftyp) + len(meta(0)) + 8)
+
+	return bytes.Join([][]byte{ftyp, meta(offset), isoBox("mdat", exif)}, nil)
+}
+
+func exifData(width, height int) map[string]any {
+	return map[string]any{
+		"Make":             "Apple",
+		"Model":            "iPhone 12",
+		"Orientation":      6,
+		"ISO":              200,
+		"Aperture":         1.8,
+		"FocalLength":      "4.2 mm",
+		"ExposureTime":     "1/250",
+		"DateTimeOriginal": "2022:07:14 18:30:00",
+		"GPSPosition":      "48.860000, -2.350000",
+		"ImageWidth":       width,
+		"ImageHeight":      height,
+	}
+}
+
+func TestExtract(t *testing.T) {
+	date := time.Date(2022, 7, 14, 18, 30, 0, 0, time.FixedZone("", 2*60*60))
+	geocode := exas.Geocode{Latitude: 48.86, Longitude: -2.35}
+
+	cases := map[string]struct {
+		content   []byte
+		extension string
+		want      provider.Metadata
+		wantErr   error
+	}{
+		"unsupported": {
+			[]byte("GIF89a"),
+			".gif",
+			provider.Metadata{},
+			ErrUnsupported,
+		},
+		"invalid": {
+			[]byte("not a jpeg"),
+			".jpg",
+			provider.Metadata{},
+			errInvalid,
+		},
+		"tiff": {
+			tiffFixture(),
+			".dng",
+			provider.Metadata{
+				Exif: exas.Exif{Date: date, Geocode: geocode, Data: exifData(160, 120)},
+			},
+			nil,
+		},
+		"jpeg": {
+			jpegFixture(),
+			".jpg",
+			provider.Metadata{
+				Description: "Sunset",
+				Tags:        []string{"holidays", "beach", "family"},
+				Exif:        exas.Exif{Date: date, Geocode: geocode, Data: exifData(4000, 3000)},
+			},
+			nil,
+		},
+		"png": {
+			pngFixture(),
+			".png",
+			provider.Metadata{
+				Description: "Sunset",
+				Tags:        []string{"holidays", "beach"},
+				Exif:        exas.Exif{Date: date, Geocode: geocode, Data: exifData(640, 480)},
+			},
+			nil,
+		},
+		"heif": {
+			heifFixture(),
+			".heic",
+			provider.Metadata{
+				Exif: exas.Exif{Date: date, Geocode: geocode, Data: exifData(4032, 3024)},
+			},
+			nil,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotErr := Extract(bytes.NewReader(tc.content), int64(len(tc.content)), tc.extension)
+
+			failed := false
+
+			switch {
+			case tc.wantErr == nil && gotErr != nil:
+				failed = true
+			case tc.wantErr != nil && !errors.Is(gotErr, tc.wantErr):
+				failed = true
+			case !got.Date.Equal(tc.want.Date):
+				failed = true
+			case !reflect.DeepEqual(got.Data, tc.want.Data), !reflect.DeepEqual(got.Geocode, tc.want.Geocode):
+				failed = true
+			case got.Description != tc.want.Description, !reflect.DeepEqual(got.Tags, tc.want.Tags):
+				failed = true
+			}
+
+			if failed {
+				t.Errorf("Extract() = (%+v, `%s`), want (%+v, `%s`)", got, gotErr, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseXMP(t *testing.T) {
+	cases := map[string]struct {
+		input   string
+		want    XMP
+		wantErr bool
+	}{
+		"full": {
+			testXMP,
+			XMP{
+				Date:        time.Date(2021, 5, 6, 7, 8, 9, 0, time.UTC),
+				Description: "Sunset",
+				Tags:        []string{"holidays", "beach"},
+			},
+			false,
+		},
+		"original date wins": {
+			`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description xmlns:exif="http://ns.adobe.com/exif/1.0/" xmlns:xmp="http://ns.adobe.com/xap/1.0/"><exif:DateTimeOriginal>2020-01-02T03:04:05+01:00</exif:DateTimeOriginal><xmp:CreateDate>2021-01-01T00:00:00</xmp:CreateDate></rdf:Description></rdf:RDF>`,
+			XMP{
+				Date: time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC),
+			},
+			false,
+		},
+		"invalid": {
+			`<rdf:RDF><rdf:Description>`,
+			XMP{},
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotErr := ParseXMP(bytes.NewReader([]byte(tc.input)))
+
+			failed := false
+
+			switch {
+			case tc.wantErr != (gotErr != nil):
+				failed = true
+			case !got.Date.Equal(tc.want.Date), got.Description != tc.want.Description, !reflect.DeepEqual(got.Tags, tc.want.Tags):
+				failed = true
+			}
+
+			if failed {
+				t.Errorf("ParseXMP() = (%+v, `%s`), want (%+v, %t)", got, gotErr, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
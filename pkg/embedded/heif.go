@@ -0,0 +1,403 @@
+package embedded
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	xmpContentType = "application/rdf+xml"
+
+	// maxItems and maxExtents bound the item locations of a crafted file, a picture has a few dozens of items made of a few extents
+	maxItems   = 4096
+	maxExtents = 64
+)
+
+type box struct {
+	kind   string
+	offset int64
+	size   int64
+}
+
+type itemInfo struct {
+	kind        string
+	contentType string
+}
+
+type extent struct {
+	offset int64
+	length int64
+}
+
+func readBoxes(reader io.ReaderAt, offset, end int64) ([]box, error) {
+	var output []box
+
+	for offset+8 <= end {
+		header, err := readAt(reader, offset, 8)
+		if err != nil {
+			return nil, fmt.Errorf("read box: %w", err)
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		kind := string(header[4:8])
+		headerSize := int64(8)
+
+		switch size {
+		case 0:
+			size = end - offset
+		case 1:
+			largeSize, err := readAt(reader, offset+8, 8)
+			if err != nil {
+				return nil, fmt.Errorf("read box size: %w", err)
+			}
+
+			size = int64(binary.BigEndian.Uint64(largeSize))
+			headerSize = 16
+		}
+
+		if size < headerSize || size > end-offset {
+			return nil, fmt.Errorf("box `%s` size: %w", kind, errInvalid)
+		}
+
+		output = append(output, box{kind: kind, offset: offset + headerSize, size: size - headerSize})
+		offset += size
+	}
+
+	return output, nil
+}
+
+func findBox(boxes []box, kind string) (box, bool) {
+	for _, item := range boxes {
+		if item.kind == kind {
+			return item, true
+		}
+	}
+
+	return box{}, false
+}
+
+func (e *extraction) readHEIF(reader io.ReaderAt, size int64) error {
+	boxes, err := readBoxes(reader, 0, size)
+	if err != nil {
+		return fmt.Errorf("heif: %w", err)
+	}
+
+	meta, ok := findBox(boxes, "meta")
+	if !ok || meta.size < 4 {
+		return nil
+	}
+
+	// meta is a full box, children are after version and flags
+	if boxes, err = readBoxes(reader, meta.offset+4, meta.offset+meta.size); err != nil {
+		return fmt.Errorf("heif: meta: %w", err)
+	}
+
+	infos, err := readChildPayload(reader, boxes, "iinf", parseItemInfos)
+	if err != nil {
+		return fmt.Errorf("heif: %w", err)
+	}
+
+	locations, err := readChildPayload(reader, boxes, "iloc", func(content []byte) (map[uint32][]extent, error) {
+		return parseItemLocations(content, size)
+	})
+	if err != nil {
+		return fmt.Errorf("heif: %w", err)
+	}
+
+	for id, info := range infos {
+		if info.kind != "Exif" && (info.kind != "mime" || info.contentType != xmpContentType) {
+			continue
+		}
+
+		data, err := readExtents(reader, locations[id])
+		if err != nil {
+			return fmt.Errorf("heif: read item `%s`: %w", info.kind, err)
+		}
+
+		if err = e.readItem(info, data); err != nil {
+			return fmt.Errorf("heif: %w", err)
+		}
+	}
+
+	e.readImageSize(reader, boxes)
+
+	return nil
+}
+
+func (e *extraction) readItem(info itemInfo, data []byte) error {
+	if info.kind != "Exif" {
+		return e.readXMPBlock(data)
+	}
+
+	// Exif item starts with the offset of the TIFF header
+	if len(data) < 4 {
+		return fmt.Errorf("exif item: %w", errInvalid)
+	}
+
+	start := int64(binary.BigEndian.Uint32(data[:4])) + 4
+	if start > int64(len(data)) {
+		return fmt.Errorf("exif item offset: %w", errInvalid)
+	}
+
+	return e.readTIFF(bytes.NewReader(data[start:]), int64(len(data))-start)
+}
+
+// readImageSize uses the biggest spatial extent declared, the primary image is the biggest one, thumbnails and tiles aren't
+func (e *extraction) readImageSize(reader io.ReaderAt, boxes []box) {
+	properties, ok := findBox(boxes, "iprp")
+	if !ok {
+		return
+	}
+
+	children, err := readBoxes(reader, properties.offset, properties.offset+properties.size)
+	if err != nil {
+		return
+	}
+
+	container, ok := findBox(children, "ipco")
+	if !ok {
+		return
+	}
+
+	if children, err = readBoxes(reader, container.offset, container.offset+container.size); err != nil {
+		return
+	}
+
+	var width, height uint32
+
+	for _, child := range children {
+		if child.kind != "ispe" || child.size < 12 {
+			continue
+		}
+
+		content, err := readAt(reader, child.offset+4, 8)
+		if err != nil {
+			return
+		}
+
+		if candidate := binary.BigEndian.Uint32(content[:4]); candidate > width {
+			width, height = candidate, binary.BigEndian.Uint32(content[4:8])
+		}
+	}
+
+	e.setDimensions(width, height)
+}
+
+func readChildPayload[T any](reader io.ReaderAt, boxes []box, kind string, parser func([]byte) (T, error)) (T, error) {
+	var output T
+
+	child, ok := findBox(boxes, kind)
+	if !ok {
+		return output, nil
+	}
+
+	content, err := readAt(reader, child.offset, child.size)
+	if err != nil {
+		return output, fmt.Errorf("read `%s`: %w", kind, err)
+	}
+
+	if output, err = parser(content); err != nil {
+		return output, fmt.Errorf("parse `%s`: %w", kind, err)
+	}
+
+	return output, nil
+}
+
+func parseItemInfos(content []byte) (map[uint32]itemInfo, error) {
+	if len(content) < 6 {
+		return nil, errInvalid
+	}
+
+	start := int64(6)
+	if content[0] != 0 {
+		start = 8
+	}
+
+	boxes, err := readBoxes(bytes.NewReader(content), start, int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(map[uint32]itemInfo)
+
+	for _, child := range boxes {
+		if child.kind != "infe" {
+			continue
+		}
+
+		entry := content[child.offset : child.offset+child.size]
+
+		// Item type is only available since version 2
+		if len(entry) < 12 || entry[0] < 2 {
+			continue
+		}
+
+		var id uint32
+		position := 4
+
+		if entry[0] == 2 {
+			id = uint32(binary.BigEndian.Uint16(entry[position:]))
+			position += 2
+		} else {
+			id = binary.BigEndian.Uint32(entry[position:])
+			position += 4
+		}
+
+		position += 2 // protection index
+
+		if position+4 > len(entry) {
+			continue
+		}
+
+		info := itemInfo{kind: string(entry[position : position+4])}
+
+		if info.kind == "mime" {
+			_, rest, _ := bytes.Cut(entry[position+4:], []byte{0}) // item name
+			contentType, _, _ := bytes.Cut(rest, []byte{0})
+			info.contentType = string(contentType)
+		}
+
+		output[id] = info
+	}
+
+	return output, nil
+}
+
+type cursor struct {
+	content  []byte
+	position int
+	overflow bool
+}
+
+func (c *cursor) read(size int) uint64 {
+	if c.position+size > len(c.content) {
+		c.overflow = true
+		return 0
+	}
+
+	var output uint64
+	for _, value := range c.content[c.position : c.position+size] {
+		output = output<<8 | uint64(value)
+	}
+
+	c.position += size
+
+	return output
+}
+
+// validFieldSize checks the size in bytes of an item location field, the only ones allowed by the spec
+func validFieldSize(size int) bool {
+	return size == 0 || size == 4 || size == 8
+}
+
+// parseItemLocations reads the extents of the items located in the file, of given size. Extents of a zero length size stand for the whole file and aren't supported
+func parseItemLocations(content []byte, fileSize int64) (map[uint32][]extent, error) {
+	reader := cursor{content: content}
+
+	version := reader.read(1)
+	reader.read(3) // flags
+
+	sizes := reader.read(1)
+	offsetSize, lengthSize := int(sizes>>4), int(sizes&0x0f)
+
+	sizes = reader.read(1)
+	baseOffsetSize, indexSize := int(sizes>>4), int(sizes&0x0f)
+
+	if version == 0 {
+		indexSize = 0
+	}
+
+	if lengthSize == 0 || !validFieldSize(offsetSize) || !validFieldSize(lengthSize) || !validFieldSize(baseOffsetSize) || !validFieldSize(indexSize) {
+		return nil, fmt.Errorf("field sizes: %w", errInvalid)
+	}
+
+	var count uint64
+	if version < 2 {
+		count = reader.read(2)
+	} else {
+		count = reader.read(4)
+	}
+
+	if count > maxItems {
+		return nil, fmt.Errorf("%d items: %w", count, errInvalid)
+	}
+
+	output := make(map[uint32][]extent)
+
+	var total int64
+
+	for range count {
+		var id uint32
+		if version < 2 {
+			id = uint32(reader.read(2))
+		} else {
+			id = uint32(reader.read(4))
+		}
+
+		var method uint64
+		if version > 0 {
+			method = reader.read(2) & 0x0f
+		}
+
+		reader.read(2) // data reference index
+		baseOffset := int64(reader.read(baseOffsetSize))
+
+		extentCount := reader.read(2)
+		if extentCount > maxExtents {
+			return nil, fmt.Errorf("%d extents: %w", extentCount, errInvalid)
+		}
+
+		for range extentCount {
+			reader.read(indexSize)
+			offset := int64(reader.read(offsetSize))
+			length := int64(reader.read(lengthSize))
+
+			if reader.overflow {
+				return nil, errInvalid
+			}
+
+			// Only data located in the file is supported
+			if method != 0 {
+				continue
+			}
+
+			// Values are read unsigned, negative ones are overflows
+			if baseOffset < 0 || offset < 0 || length < 0 || baseOffset > fileSize || offset > fileSize-baseOffset || length > fileSize-baseOffset-offset {
+				return nil, fmt.Errorf("extent out of file: %w", errInvalid)
+			}
+
+			if total += length; total > fileSize {
+				return nil, fmt.Errorf("extents larger than file: %w", errInvalid)
+			}
+
+			output[id] = append(output[id], extent{offset: baseOffset + offset, length: length})
+		}
+
+		if reader.overflow {
+			return nil, errInvalid
+		}
+	}
+
+	return output, nil
+}
+
+func readExtents(reader io.ReaderAt, extents []extent) ([]byte, error) {
+	var output []byte
+
+	for _, item := range extents {
+		if int64(len(output))+item.length > maxBlockSize {
+			return nil, fmt.Errorf("item too large: %w", errInvalid)
+		}
+
+		content, err := readAt(reader, item.offset, item.length)
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, content...)
+	}
+
+	return output, nil
+}
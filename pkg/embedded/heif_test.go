@@ -0,0 +1,92 @@
+package embedded
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// itemLocations builds a version 1 iloc payload, with 4 bytes offsets and lengths, of items made of the given extents
+func itemLocations(sizes byte, items ...[]extent) []byte {
+	output := []byte{1, 0, 0, 0, sizes, 0}
+	output = binary.BigEndian.AppendUint16(output, uint16(len(items)))
+
+	for index, extents := range items {
+		output = binary.BigEndian.AppendUint16(output, uint16(index+1))
+		output = append(output, 0, 0, 0, 0) // construction method and data reference index
+		output = binary.BigEndian.AppendUint16(output, uint16(len(extents)))
+
+		for _, item := range extents {
+			output = binary.BigEndian.AppendUint32(output, uint32(item.offset))
+			output = binary.BigEndian.AppendUint32(output, uint32(item.length))
+		}
+	}
+
+	return output
+}
+
+func TestParseItemLocations(t *testing.T) {
+	manyItems := make([][]extent, maxItems+1)
+	manyExtents := make([]extent, maxExtents+1)
+
+	cases := map[string]struct {
+		content []byte
+		want    map[uint32][]extent
+		wantErr error
+	}{
+		"valid": {
+			itemLocations(0x44, []extent{{offset: 10, length: 20}}, []extent{{offset: 30, length: 5}, {offset: 40, length: 5}}),
+			map[uint32][]extent{
+				1: {{offset: 10, length: 20}},
+				2: {{offset: 30, length: 5}, {offset: 40, length: 5}},
+			},
+			nil,
+		},
+		"zero length size": {
+			[]byte{1, 0, 0, 0, 0x00, 0, 0xff, 0xff},
+			nil,
+			errInvalid,
+		},
+		"unknown field size": {
+			itemLocations(0x43, []extent{{offset: 10, length: 20}}),
+			nil,
+			errInvalid,
+		},
+		"too many items": {
+			itemLocations(0x44, manyItems...),
+			nil,
+			errInvalid,
+		},
+		"too many extents": {
+			itemLocations(0x44, manyExtents),
+			nil,
+			errInvalid,
+		},
+		"out of file": {
+			itemLocations(0x44, []extent{{offset: 90, length: 20}}),
+			nil,
+			errInvalid,
+		},
+		"larger than file": {
+			itemLocations(0x44, []extent{{offset: 0, length: 60}}, []extent{{offset: 0, length: 60}}),
+			nil,
+			errInvalid,
+		},
+		"truncated": {
+			itemLocations(0x44, []extent{{offset: 10, length: 20}})[:16],
+			nil,
+			errInvalid,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotErr := parseItemLocations(tc.content, 100)
+
+			if !errors.Is(gotErr, tc.wantErr) || (tc.wantErr == nil && !reflect.DeepEqual(got, tc.want)) {
+				t.Errorf("parseItemLocations() = (%+v, `%v`), want (%+v, `%v`)", got, gotErr, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package embedded
+
+import (
+	"encoding/binary"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	iptcResource    = 0x0404
+	iptcApplication = 2
+	iptcKeywords    = 25
+	iptcCaption     = 120
+)
+
+// readPhotoshop walks the image resource blocks of a Photoshop segment, looking for the IPTC one
+func (e *extraction) readPhotoshop(content []byte) {
+	for len(content) >= 12 && string(content[:4]) == "8BIM" {
+		id := binary.BigEndian.Uint16(content[4:6])
+
+		// Name is a pascal string padded to an even size
+		nameLength := int(content[6]) + 1
+		nameLength += nameLength % 2
+
+		start := 6 + nameLength + 4
+		if start > len(content) {
+			return
+		}
+
+		size := int(binary.BigEndian.Uint32(content[start-4 : start]))
+		if size > len(content)-start {
+			return
+		}
+
+		if id == iptcResource {
+			e.readIPTC(content[start : start+size])
+		}
+
+		content = content[min(start+size+size%2, len(content)):]
+	}
+}
+
+func (e *extraction) readIPTC(content []byte) {
+	for len(content) >= 5 && content[0] == 0x1c {
+		record, dataset := content[1], content[2]
+
+		size := int(binary.BigEndian.Uint16(content[3:5]))
+		if size&0x8000 != 0 || 5+size > len(content) {
+			// Extended datasets are never used for keywords or caption
+			return
+		}
+
+		value := strings.TrimSpace(iptcString(content[5 : 5+size]))
+		content = content[5+size:]
+
+		if record != iptcApplication || len(value) == 0 {
+			continue
+		}
+
+		switch dataset {
+		case iptcKeywords:
+			e.keywords = append(e.keywords, value)
+		case iptcCaption:
+			e.caption = value
+		}
+	}
+}
+
+// iptcString decodes the value as UTF-8, or as Latin-1 that older tools write without declaring it
+func iptcString(value []byte) string {
+	if utf8.Valid(value) {
+		return string(value)
+	}
+
+	output := make([]rune, len(value))
+	for index, char := range value {
+		output[index] = rune(char)
+	}
+
+	return string(output)
+}
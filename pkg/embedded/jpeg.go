@@ -0,0 +1,100 @@
+package embedded
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+var (
+	exifPrefix      = []byte("Exif\x00\x00")
+	xmpPrefix       = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	photoshopPrefix = []byte("Photoshop 3.0\x00")
+)
+
+func (e *extraction) readJPEG(reader io.ReaderAt, size int64) error {
+	header, err := readAt(reader, 0, 2)
+	if err != nil {
+		return fmt.Errorf("jpeg: read header: %w", err)
+	}
+
+	if header[0] != 0xff || header[1] != 0xd8 {
+		return fmt.Errorf("jpeg: start of image: %w", errInvalid)
+	}
+
+	for offset := int64(2); offset+4 <= size; {
+		header, err = readAt(reader, offset, 4)
+		if err != nil {
+			return fmt.Errorf("jpeg: read marker: %w", err)
+		}
+
+		if header[0] != 0xff {
+			return fmt.Errorf("jpeg: marker at %d: %w", offset, errInvalid)
+		}
+
+		marker := header[1]
+
+		switch {
+		case marker == 0xff:
+			offset++
+			continue
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd8):
+			offset += 2
+			continue
+		case marker == 0xd9 || marker == 0xda:
+			// Metadata are all before the start of scan
+			return nil
+		}
+
+		length := int64(binary.BigEndian.Uint16(header[2:4]))
+		if length < 2 {
+			return fmt.Errorf("jpeg: segment length at %d: %w", offset, errInvalid)
+		}
+
+		switch {
+		case marker == 0xe1 || marker == 0xed:
+			payload, err := readAt(reader, offset+4, length-2)
+			if err != nil {
+				return fmt.Errorf("jpeg: read segment: %w", err)
+			}
+
+			if err = e.readSegment(marker, payload); err != nil {
+				return fmt.Errorf("jpeg: %w", err)
+			}
+
+		case isStartOfFrame(marker) && length >= 7:
+			frame, err := readAt(reader, offset+4, 5)
+			if err != nil {
+				return fmt.Errorf("jpeg: read frame: %w", err)
+			}
+
+			e.setDimensions(uint32(binary.BigEndian.Uint16(frame[3:5])), uint32(binary.BigEndian.Uint16(frame[1:3])))
+		}
+
+		offset += 2 + length
+	}
+
+	return nil
+}
+
+func (e *extraction) readSegment(marker byte, payload []byte) error {
+	switch {
+	case marker == 0xe1 && bytes.HasPrefix(payload, exifPrefix):
+		content := payload[len(exifPrefix):]
+		return e.readTIFF(bytes.NewReader(content), int64(len(content)))
+
+	case marker == 0xe1 && bytes.HasPrefix(payload, xmpPrefix):
+		return e.readXMPBlock(payload[len(xmpPrefix):])
+
+	case marker == 0xed && bytes.HasPrefix(payload, photoshopPrefix):
+		e.readPhotoshop(payload[len(photoshopPrefix):])
+	}
+
+	return nil
+}
+
+// isStartOfFrame excludes DHT, JPG and DAC markers that share the SOF range
+func isStartOfFrame(marker byte) bool {
+	return marker >= 0xc0 && marker <= 0xcf && marker != 0xc4 && marker != 0xc8 && marker != 0xcc
+}
@@ -0,0 +1,93 @@
+package embedded
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const pngXMPKeyword = "XML:com.adobe.xmp"
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+func (e *extraction) readPNG(reader io.ReaderAt, size int64) error {
+	signature, err := readAt(reader, 0, int64(len(pngSignature)))
+	if err != nil {
+		return fmt.Errorf("png: read signature: %w", err)
+	}
+
+	if !bytes.Equal(signature, pngSignature) {
+		return fmt.Errorf("png: signature: %w", errInvalid)
+	}
+
+	for offset := int64(len(pngSignature)); offset+12 <= size; {
+		header, err := readAt(reader, offset, 8)
+		if err != nil {
+			return fmt.Errorf("png: read chunk: %w", err)
+		}
+
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		kind := string(header[4:8])
+
+		if offset+12+length > size {
+			return fmt.Errorf("png: chunk `%s` length: %w", kind, errInvalid)
+		}
+
+		switch kind {
+		case "IHDR", "eXIf", "iTXt":
+			data, err := readAt(reader, offset+8, length)
+			if err != nil {
+				return fmt.Errorf("png: read `%s`: %w", kind, err)
+			}
+
+			if err = e.readChunk(kind, data); err != nil {
+				return fmt.Errorf("png: %w", err)
+			}
+
+		case "IEND":
+			return nil
+		}
+
+		offset += 12 + length
+	}
+
+	return nil
+}
+
+func (e *extraction) readChunk(kind string, data []byte) error {
+	switch kind {
+	case "IHDR":
+		if len(data) >= 8 {
+			e.setDimensions(binary.BigEndian.Uint32(data[:4]), binary.BigEndian.Uint32(data[4:8]))
+		}
+
+	case "eXIf":
+		return e.readTIFF(bytes.NewReader(data), int64(len(data)))
+
+	case "iTXt":
+		keyword, content, _ := bytes.Cut(data, []byte{0})
+		if string(keyword) != pngXMPKeyword || len(content) < 2 {
+			return nil
+		}
+
+		compressed := content[0] == 1
+
+		_, content, _ = bytes.Cut(content[2:], []byte{0}) // language tag
+		_, content, _ = bytes.Cut(content, []byte{0})     // translated keyword
+
+		if !compressed {
+			return e.readXMPBlock(content)
+		}
+
+		reader, err := zlib.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("inflate xmp: %w", err)
+		}
+
+		return e.readXMP(io.LimitReader(reader, maxBlockSize))
+	}
+
+	return nil
+}
@@ -0,0 +1,326 @@
+package embedded
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const maxEntries = 1024
+
+const (
+	tagImageWidth  uint16 = 0x0100
+	tagImageHeight uint16 = 0x0101
+	tagMake        uint16 = 0x010f
+	tagModel       uint16 = 0x0110
+	tagOrientation uint16 = 0x0112
+	tagDateTime    uint16 = 0x0132
+	tagRating      uint16 = 0x4746
+	tagXMP         uint16 = 0x02bc
+	tagIPTC        uint16 = 0x83bb
+	tagExifIFD     uint16 = 0x8769
+	tagGPSIFD      uint16 = 0x8825
+
+	tagExposureTime       uint16 = 0x829a
+	tagFNumber            uint16 = 0x829d
+	tagISO                uint16 = 0x8827
+	tagDateTimeOriginal   uint16 = 0x9003
+	tagDateTimeDigitized  uint16 = 0x9004
+	tagOffsetTimeOriginal uint16 = 0x9011
+	tagFocalLength        uint16 = 0x920a
+	tagPixelWidth         uint16 = 0xa002
+	tagPixelHeight        uint16 = 0xa003
+	tagLensModel          uint16 = 0xa434
+
+	tagLatitudeRef  uint16 = 0x0001
+	tagLatitude     uint16 = 0x0002
+	tagLongitudeRef uint16 = 0x0003
+	tagLongitude    uint16 = 0x0004
+)
+
+var (
+	typeSizes = map[uint16]int64{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8}
+
+	mainTags = map[uint16]bool{tagImageWidth: true, tagImageHeight: true, tagMake: true, tagModel: true, tagOrientation: true, tagDateTime: true, tagRating: true, tagXMP: true, tagIPTC: true, tagExifIFD: true, tagGPSIFD: true}
+	exifTags = map[uint16]bool{tagExposureTime: true, tagFNumber: true, tagISO: true, tagDateTimeOriginal: true, tagDateTimeDigitized: true, tagOffsetTimeOriginal: true, tagFocalLength: true, tagPixelWidth: true, tagPixelHeight: true, tagLensModel: true}
+	gpsTags  = map[uint16]bool{tagLatitudeRef: true, tagLatitude: true, tagLongitudeRef: true, tagLongitude: true}
+)
+
+type entry struct {
+	order binary.ByteOrder
+	value []byte
+	kind  uint16
+}
+
+func (e entry) String() string {
+	value, _, _ := bytes.Cut(e.value, []byte{0})
+
+	return strings.TrimSpace(string(value))
+}
+
+func (e entry) uint(index int) (uint32, bool) {
+	size := typeSizes[e.kind]
+	start := int64(index) * size
+
+	if size == 0 || start+size > int64(len(e.value)) {
+		return 0, false
+	}
+
+	switch e.kind {
+	case 1, 7:
+		return uint32(e.value[start]), true
+	case 3:
+		return uint32(e.order.Uint16(e.value[start:])), true
+	case 4:
+		return e.order.Uint32(e.value[start:]), true
+	default:
+		return 0, false
+	}
+}
+
+func (e entry) rational(index int) (float64, bool) {
+	start := index * 8
+
+	if (e.kind != 5 && e.kind != 10) || start+8 > len(e.value) {
+		return 0, false
+	}
+
+	numerator, denominator := float64(e.order.Uint32(e.value[start:])), float64(e.order.Uint32(e.value[start+4:]))
+	if e.kind == 10 {
+		numerator, denominator = float64(int32(e.order.Uint32(e.value[start:]))), float64(int32(e.order.Uint32(e.value[start+4:])))
+	}
+
+	if denominator == 0 {
+		return 0, false
+	}
+
+	return numerator / denominator, true
+}
+
+type tiff struct {
+	reader io.ReaderAt
+	order  binary.ByteOrder
+	size   int64
+}
+
+func newTIFF(reader io.ReaderAt, size int64) (tiff, int64, error) {
+	header, err := readAt(reader, 0, 8)
+	if err != nil {
+		return tiff{}, 0, fmt.Errorf("read header: %w", err)
+	}
+
+	output := tiff{reader: reader, size: size}
+
+	switch string(header[:2]) {
+	case "II":
+		output.order = binary.LittleEndian
+	case "MM":
+		output.order = binary.BigEndian
+	default:
+		return output, 0, fmt.Errorf("byte order: %w", errInvalid)
+	}
+
+	// 42 is the TIFF magic, Olympus and Panasonic raw files use their own
+	switch output.order.Uint16(header[2:4]) {
+	case 0x2a, 0x4f52, 0x5352, 0x55:
+	default:
+		return output, 0, fmt.Errorf("magic number: %w", errInvalid)
+	}
+
+	return output, int64(output.order.Uint32(header[4:8])), nil
+}
+
+func (t tiff) readIFD(offset int64, wanted map[uint16]bool) (map[uint16]entry, error) {
+	if offset < 8 || offset+2 > t.size {
+		return nil, fmt.Errorf("ifd offset %d: %w", offset, errInvalid)
+	}
+
+	header, err := readAt(t.reader, offset, 2)
+	if err != nil {
+		return nil, fmt.Errorf("read ifd header: %w", err)
+	}
+
+	count := int64(t.order.Uint16(header))
+	if count > maxEntries {
+		return nil, fmt.Errorf("ifd of %d entries: %w", count, errInvalid)
+	}
+
+	raw, err := readAt(t.reader, offset+2, count*12)
+	if err != nil {
+		return nil, fmt.Errorf("read ifd: %w", err)
+	}
+
+	output := make(map[uint16]entry)
+
+	for index := range count {
+		content := raw[index*12 : (index+1)*12]
+
+		tag := t.order.Uint16(content[0:2])
+		if !wanted[tag] {
+			continue
+		}
+
+		kind := t.order.Uint16(content[2:4])
+		length := typeSizes[kind] * int64(t.order.Uint32(content[4:8]))
+
+		if length == 0 {
+			continue
+		}
+
+		value := content[8 : 8+min(length, 4)]
+		if length > 4 {
+			if value, err = readAt(t.reader, int64(t.order.Uint32(content[8:12])), length); err != nil {
+				continue
+			}
+		}
+
+		output[tag] = entry{order: t.order, kind: kind, value: value}
+	}
+
+	return output, nil
+}
+
+func (t tiff) readSubIFD(parent map[uint16]entry, tag uint16, wanted map[uint16]bool) map[uint16]entry {
+	pointer, ok := parent[tag]
+	if !ok {
+		return nil
+	}
+
+	offset, ok := pointer.uint(0)
+	if !ok {
+		return nil
+	}
+
+	output, err := t.readIFD(int64(offset), wanted)
+	if err != nil {
+		return nil
+	}
+
+	return output
+}
+
+func (e *extraction) readTIFF(reader io.ReaderAt, size int64) error {
+	file, offset, err := newTIFF(reader, size)
+	if err != nil {
+		return fmt.Errorf("tiff: %w", err)
+	}
+
+	main, err := file.readIFD(offset, mainTags)
+	if err != nil {
+		return fmt.Errorf("tiff: %w", err)
+	}
+
+	exif := file.readSubIFD(main, tagExifIFD, exifTags)
+	gps := file.readSubIFD(main, tagGPSIFD, gpsTags)
+
+	e.fillCamera(main, exif)
+	e.fillDate(main, exif)
+	e.fillLocation(gps)
+
+	if value, ok := main[tagXMP]; ok {
+		if err := e.readXMPBlock(value.value); err != nil {
+			return err
+		}
+	}
+
+	if value, ok := main[tagIPTC]; ok {
+		e.readIPTC(value.value)
+	}
+
+	return nil
+}
+
+func (e *extraction) fillCamera(main, exif map[uint16]entry) {
+	for tag, name := range map[uint16]string{tagMake: "Make", tagModel: "Model"} {
+		if value := main[tag].String(); len(value) != 0 {
+			e.set(name, value)
+		}
+	}
+
+	if value := exif[tagLensModel].String(); len(value) != 0 {
+		e.set("LensModel", value)
+	}
+
+	if value, ok := main[tagOrientation].uint(0); ok {
+		e.set("Orientation", int(value))
+	}
+
+	// Rejected pictures are rated 0xffff, they are considered as unrated
+	if value, ok := main[tagRating].uint(0); ok && value <= provider.MaxRating {
+		e.rating = int(value)
+	}
+
+	if value, ok := exif[tagISO].uint(0); ok {
+		e.set("ISO", int(value))
+	}
+
+	if value, ok := exif[tagFNumber].rational(0); ok {
+		e.set("Aperture", math.Round(value*10)/10)
+	}
+
+	if value, ok := exif[tagFocalLength].rational(0); ok {
+		e.set("FocalLength", fmt.Sprintf("%g mm", math.Round(value*10)/10))
+	}
+
+	if value, ok := exif[tagExposureTime].rational(0); ok {
+		e.set("ExposureTime", formatExposure(value))
+	}
+
+	width, widthOk := exif[tagPixelWidth].uint(0)
+	height, heightOk := exif[tagPixelHeight].uint(0)
+
+	if !widthOk || !heightOk {
+		width, widthOk = main[tagImageWidth].uint(0)
+		height, heightOk = main[tagImageHeight].uint(0)
+	}
+
+	if widthOk && heightOk && e.width == 0 {
+		e.setDimensions(width, height)
+	}
+}
+
+func (e *extraction) fillDate(main, exif map[uint16]entry) {
+	for _, value := range []string{exif[tagDateTimeOriginal].String(), exif[tagDateTimeDigitized].String(), main[tagDateTime].String()} {
+		if date, ok := parseDate(value, exif[tagOffsetTimeOriginal].String()); ok {
+			e.exif.Date = date
+			e.set("DateTimeOriginal", value)
+
+			return
+		}
+	}
+}
+
+func (e *extraction) fillLocation(gps map[uint16]entry) {
+	latitude, latitudeOk := coordinate(gps[tagLatitude], gps[tagLatitudeRef].String(), "S")
+	longitude, longitudeOk := coordinate(gps[tagLongitude], gps[tagLongitudeRef].String(), "W")
+
+	if !latitudeOk || !longitudeOk {
+		return
+	}
+
+	e.exif.Geocode.Latitude = latitude
+	e.exif.Geocode.Longitude = longitude
+	e.set("GPSPosition", fmt.Sprintf("%.6f, %.6f", latitude, longitude))
+}
+
+func coordinate(value entry, reference, negative string) (float64, bool) {
+	degrees, ok := value.rational(0)
+	if !ok {
+		return 0, false
+	}
+
+	minutes, _ := value.rational(1)
+	seconds, _ := value.rational(2)
+
+	output := degrees + minutes/60 + seconds/3600
+	if strings.EqualFold(reference, negative) {
+		output = -output
+	}
+
+	return output, true
+}
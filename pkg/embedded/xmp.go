@@ -0,0 +1,158 @@
+package embedded
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	rdfNamespace       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	xmlNamespace       = "http://www.w3.org/XML/1998/namespace"
+	dcNamespace        = "http://purl.org/dc/elements/1.1/"
+	xmpNamespace       = "http://ns.adobe.com/xap/1.0/"
+	exifNamespace      = "http://ns.adobe.com/exif/1.0/"
+	photoshopNamespace = "http://ns.adobe.com/photoshop/1.0/"
+
+	defaultLanguage = "x-default"
+)
+
+var xmpDateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04Z07:00", "2006-01-02T15:04", "2006-01-02"}
+
+// XMP holds the properties of an XMP packet that fibr understands
+type XMP struct {
+	Date        time.Time
+	Description string
+	Tags        []string
+	Rating      int
+}
+
+func ParseXMP(reader io.Reader) (XMP, error) {
+	var output XMP
+
+	decoder := xml.NewDecoder(reader)
+
+	var property xml.Name
+	var language string
+	var text strings.Builder
+	var inDescription, hasItems bool
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return output, nil
+		}
+
+		if err != nil {
+			return output, fmt.Errorf("decode: %w", err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch {
+			case token.Name.Space == rdfNamespace && token.Name.Local == "Description":
+				inDescription = true
+
+				for _, attr := range token.Attr {
+					output.setProperty(attr.Name, attr.Value)
+				}
+
+			case token.Name.Space == rdfNamespace && token.Name.Local == "li":
+				hasItems = true
+				language = ""
+				text.Reset()
+
+				for _, attr := range token.Attr {
+					if attr.Name.Space == xmlNamespace && attr.Name.Local == "lang" {
+						language = attr.Value
+					}
+				}
+
+			case inDescription && len(property.Local) == 0 && token.Name.Space != rdfNamespace:
+				property = token.Name
+				hasItems = false
+				text.Reset()
+			}
+
+		case xml.CharData:
+			if len(property.Local) != 0 {
+				text.Write(token)
+			}
+
+		case xml.EndElement:
+			switch {
+			case token.Name.Space == rdfNamespace && token.Name.Local == "li":
+				output.addItem(property, strings.TrimSpace(text.String()), language)
+				text.Reset()
+
+			case token.Name.Space == rdfNamespace && token.Name.Local == "Description":
+				inDescription = false
+
+			case token.Name == property:
+				if !hasItems {
+					output.setProperty(property, strings.TrimSpace(text.String()))
+				}
+
+				property = xml.Name{}
+			}
+		}
+	}
+}
+
+func (x *XMP) setProperty(name xml.Name, value string) {
+	if len(value) == 0 {
+		return
+	}
+
+	switch {
+	case name.Space == exifNamespace && name.Local == "DateTimeOriginal",
+		name.Space == photoshopNamespace && name.Local == "DateCreated",
+		name.Space == xmpNamespace && name.Local == "CreateDate":
+
+		// The original capture date has precedence over the others
+		if !x.Date.IsZero() && name.Local != "DateTimeOriginal" {
+			return
+		}
+
+		if date, ok := parseXMPDate(value); ok {
+			x.Date = date
+		}
+
+	case name.Space == xmpNamespace && name.Local == "Rating":
+		// Rejected pictures are rated -1, they are considered as unrated
+		if rating, err := strconv.ParseFloat(value, 64); err == nil && rating > 0 {
+			x.Rating = int(math.Round(rating))
+		}
+	}
+}
+
+func (x *XMP) addItem(name xml.Name, value, language string) {
+	if len(value) == 0 || name.Space != dcNamespace {
+		return
+	}
+
+	switch name.Local {
+	case "subject":
+		x.Tags = append(x.Tags, value)
+
+	case "description":
+		if len(x.Description) == 0 || language == defaultLanguage {
+			x.Description = value
+		}
+	}
+}
+
+func parseXMPDate(value string) (time.Time, bool) {
+	for _, layout := range xmpDateLayouts {
+		if date, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
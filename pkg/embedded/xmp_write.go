@@ -0,0 +1,289 @@
+package embedded
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const emptyXMP = `<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:dc="http://purl.org/dc/elements/1.1/">
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>
+`
+
+type edit struct {
+	start       int64
+	end         int64
+	replacement []byte
+}
+
+// xmpLayout locates what WriteXMP needs to rewrite in a packet, without decoding the rest
+type xmpLayout struct {
+	properties      []edit
+	description     edit
+	rating          edit
+	rdfPrefix       string
+	dcPrefix        string
+	xmpPrefix       string
+	ratingPrefix    string
+	selfClosing     bool
+	dcInScope       bool
+	xmpInScope      bool
+	hasContainer    bool
+	ratingAttribute bool
+	ratingElement   bool
+}
+
+// WriteXMP replaces the keywords, the description and the rating of the given XMP packet, keeping all other properties untouched. An empty content creates a new packet.
+func WriteXMP(content []byte, tags []string, description string, rating int) ([]byte, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		content = []byte(emptyXMP)
+	}
+
+	layout, err := parseLayout(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if !layout.hasContainer {
+		return nil, fmt.Errorf("no rdf:Description: %w", errInvalid)
+	}
+
+	if len(layout.dcPrefix) == 0 {
+		layout.dcPrefix = "dc"
+	}
+
+	var properties bytes.Buffer
+	writeProperties(&properties, layout.rdfPrefix, layout.dcPrefix, tags, description)
+
+	edits := layout.properties
+	value := strconv.Itoa(rating)
+
+	original := content[layout.description.start:layout.description.end]
+	startTag := slices.Clone(original)
+
+	if layout.ratingAttribute {
+		startTag = ratingAttribute(layout.ratingPrefix).ReplaceAll(startTag, []byte(`${1}"`+value+`"`))
+	}
+
+	var attributes []string
+
+	if !layout.dcInScope {
+		attributes = append(attributes, fmt.Sprintf(` xmlns:%s="%s"`, layout.dcPrefix, dcNamespace))
+	}
+
+	if rating != 0 && !layout.ratingAttribute && !layout.ratingElement {
+		if !layout.xmpInScope {
+			layout.xmpPrefix = "xmp"
+			attributes = append(attributes, fmt.Sprintf(` xmlns:%s="%s"`, layout.xmpPrefix, xmpNamespace))
+		}
+
+		attributes = append(attributes, fmt.Sprintf(` %s:Rating="%s"`, layout.xmpPrefix, value))
+	}
+
+	if layout.selfClosing || len(attributes) != 0 {
+		startTag = bytes.TrimRight(bytes.TrimSuffix(startTag, []byte(">")), " \t\r\n/")
+
+		for _, attribute := range attributes {
+			startTag = append(startTag, attribute...)
+		}
+
+		startTag = append(startTag, '>')
+	}
+
+	switch {
+	case layout.selfClosing || !layout.dcInScope:
+		layout.description.replacement = append(startTag, properties.Bytes()...)
+
+		if layout.selfClosing {
+			layout.description.replacement = fmt.Appendf(layout.description.replacement, "\n  </%s:Description>", layout.rdfPrefix)
+		}
+
+		edits = append([]edit{layout.description}, edits...)
+
+	default:
+		if len(edits) != 0 {
+			edits[0].replacement = properties.Bytes()
+		} else {
+			edits = []edit{{start: layout.description.end, end: layout.description.end, replacement: properties.Bytes()}}
+		}
+
+		if !bytes.Equal(startTag, original) {
+			layout.description.replacement = startTag
+			edits = append(edits, layout.description)
+		}
+	}
+
+	if layout.ratingElement {
+		layout.rating.replacement = []byte(value)
+		edits = append(edits, layout.rating)
+	}
+
+	slices.SortStableFunc(edits, func(a, b edit) int {
+		return cmp.Compare(a.start, b.start)
+	})
+
+	var output bytes.Buffer
+	var position int64
+
+	for _, change := range edits {
+		output.Write(content[position:change.start])
+		output.Write(change.replacement)
+		position = change.end
+	}
+
+	output.Write(content[position:])
+
+	return output.Bytes(), nil
+}
+
+func parseLayout(content []byte) (xmpLayout, error) {
+	var output xmpLayout
+	var scopes []map[string]string
+	var depth, descriptionDepth, propertyDepth, ratingDepth int
+	var property edit
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+
+	for {
+		start := decoder.InputOffset()
+
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			return output, nil
+		}
+
+		if err != nil {
+			return output, fmt.Errorf("decode: %w", err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+
+			scope := make(map[string]string)
+			for _, attr := range token.Attr {
+				if attr.Name.Space == "xmlns" {
+					scope[attr.Name.Local] = attr.Value
+				}
+			}
+
+			scopes = append(scopes, scope)
+			namespace := resolve(scopes, token.Name.Space)
+
+			switch {
+			case !output.hasContainer && namespace == rdfNamespace && token.Name.Local == "Description":
+				output.hasContainer = true
+				output.rdfPrefix = token.Name.Space
+				output.description = edit{start: start, end: decoder.InputOffset()}
+				output.selfClosing = bytes.HasSuffix(content[start:decoder.InputOffset()], []byte("/>"))
+				output.dcPrefix, output.dcInScope = prefixOf(scopes, dcNamespace)
+				output.xmpPrefix, output.xmpInScope = prefixOf(scopes, xmpNamespace)
+				descriptionDepth = depth
+
+				for _, attr := range token.Attr {
+					if attr.Name.Local == "Rating" && resolve(scopes, attr.Name.Space) == xmpNamespace {
+						output.ratingAttribute = true
+						output.ratingPrefix = attr.Name.Space
+					}
+				}
+
+			case descriptionDepth != 0 && depth == descriptionDepth+1 && namespace == xmpNamespace && token.Name.Local == "Rating":
+				output.ratingElement = true
+				output.rating = edit{start: decoder.InputOffset()}
+				ratingDepth = depth
+
+			case descriptionDepth != 0 && depth == descriptionDepth+1 && namespace == dcNamespace && (token.Name.Local == "subject" || token.Name.Local == "description"):
+				// Leading whitespaces are removed with the property, to keep the layout stable across rewrites
+				for start > 0 && strings.IndexByte(" \t\r\n", content[start-1]) != -1 {
+					start--
+				}
+
+				property = edit{start: start}
+				propertyDepth = depth
+			}
+
+		case xml.EndElement:
+			if ratingDepth != 0 && depth == ratingDepth {
+				output.rating.end = start
+				ratingDepth = 0
+			}
+
+			if propertyDepth != 0 && depth == propertyDepth {
+				property.end = decoder.InputOffset()
+				output.properties = append(output.properties, property)
+				propertyDepth = 0
+			}
+
+			if depth == descriptionDepth {
+				// Only the first description is rewritten
+				descriptionDepth = -1
+			}
+
+			scopes = scopes[:len(scopes)-1]
+			depth--
+		}
+	}
+}
+
+func ratingAttribute(prefix string) *regexp.Regexp {
+	return regexp.MustCompile(`(\s` + regexp.QuoteMeta(prefix) + `:Rating\s*=\s*)("[^"]*"|'[^']*')`)
+}
+
+func resolve(scopes []map[string]string, prefix string) string {
+	for index := len(scopes) - 1; index >= 0; index-- {
+		if namespace, ok := scopes[index][prefix]; ok {
+			return namespace
+		}
+	}
+
+	return prefix
+}
+
+func prefixOf(scopes []map[string]string, namespace string) (string, bool) {
+	for index := len(scopes) - 1; index >= 0; index-- {
+		for prefix, value := range scopes[index] {
+			if value == namespace && resolve(scopes, prefix) == namespace {
+				return prefix, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func writeProperties(output *bytes.Buffer, rdfPrefix, dcPrefix string, tags []string, description string) {
+	if len(description) != 0 {
+		fmt.Fprintf(output, "\n   <%[1]s:description>\n    <%[2]s:Alt>\n     <%[2]s:li xml:lang=\"%[3]s\">%[4]s</%[2]s:li>\n    </%[2]s:Alt>\n   </%[1]s:description>", dcPrefix, rdfPrefix, defaultLanguage, escape(description))
+	}
+
+	if len(tags) != 0 {
+		fmt.Fprintf(output, "\n   <%s:subject>\n    <%s:Bag>", dcPrefix, rdfPrefix)
+
+		for _, tag := range tags {
+			fmt.Fprintf(output, "\n     <%[1]s:li>%[2]s</%[1]s:li>", rdfPrefix, escape(tag))
+		}
+
+		fmt.Fprintf(output, "\n    </%s:Bag>\n   </%s:subject>", rdfPrefix, dcPrefix)
+	}
+}
+
+func escape(value string) string {
+	var output strings.Builder
+	_ = xml.EscapeText(&output, []byte(value))
+
+	return output.String()
+}
@@ -0,0 +1,184 @@
+package embedded
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const darktableXMP = `<?xml version="1.0" encoding="UTF-8"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/" x:xmptk="XMP Core 4.4.0-Exiv2">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:darktable="http://darktable.sf.net/"
+   xmp:Rating="3"
+   darktable:xmp_version="5">
+   <dc:subject>
+    <rdf:Bag>
+     <rdf:li>old</rdf:li>
+    </rdf:Bag>
+   </dc:subject>
+   <darktable:history>
+    <rdf:Seq/>
+   </darktable:history>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+`
+
+func TestWriteXMP(t *testing.T) {
+	cases := map[string]struct {
+		content     string
+		tags        []string
+		description string
+		rating      int
+		want        string
+	}{
+		"replace": {
+			darktableXMP,
+			[]string{"holidays", "sea & sun"},
+			"Sunset",
+			3,
+			`<?xml version="1.0" encoding="UTF-8"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/" x:xmptk="XMP Core 4.4.0-Exiv2">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:darktable="http://darktable.sf.net/"
+   xmp:Rating="3"
+   darktable:xmp_version="5">
+   <dc:description>
+    <rdf:Alt>
+     <rdf:li xml:lang="x-default">Sunset</rdf:li>
+    </rdf:Alt>
+   </dc:description>
+   <dc:subject>
+    <rdf:Bag>
+     <rdf:li>holidays</rdf:li>
+     <rdf:li>sea &amp; sun</rdf:li>
+    </rdf:Bag>
+   </dc:subject>
+   <darktable:history>
+    <rdf:Seq/>
+   </darktable:history>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+`,
+		},
+		"self closing without dc": {
+			`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:Rating="1"/></rdf:RDF></x:xmpmeta>`,
+			[]string{"beach"},
+			"",
+			1,
+			`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:Rating="1" xmlns:dc="http://purl.org/dc/elements/1.1/">
+   <dc:subject>
+    <rdf:Bag>
+     <rdf:li>beach</rdf:li>
+    </rdf:Bag>
+   </dc:subject>
+  </rdf:Description></rdf:RDF></x:xmpmeta>`,
+		},
+		"remove": {
+			darktableXMP,
+			nil,
+			"",
+			3,
+			`<?xml version="1.0" encoding="UTF-8"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/" x:xmptk="XMP Core 4.4.0-Exiv2">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:darktable="http://darktable.sf.net/"
+   xmp:Rating="3"
+   darktable:xmp_version="5">
+   <darktable:history>
+    <rdf:Seq/>
+   </darktable:history>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+`,
+		},
+		"rate": {
+			darktableXMP,
+			[]string{"old"},
+			"",
+			5,
+			`<?xml version="1.0" encoding="UTF-8"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/" x:xmptk="XMP Core 4.4.0-Exiv2">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:darktable="http://darktable.sf.net/"
+   xmp:Rating="5"
+   darktable:xmp_version="5">
+   <dc:subject>
+    <rdf:Bag>
+     <rdf:li>old</rdf:li>
+    </rdf:Bag>
+   </dc:subject>
+   <darktable:history>
+    <rdf:Seq/>
+   </darktable:history>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+`,
+		},
+		"rating element": {
+			`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description xmlns:dc="http://purl.org/dc/elements/1.1/"><xap:Rating xmlns:xap="http://ns.adobe.com/xap/1.0/">2</xap:Rating></rdf:Description></rdf:RDF></x:xmpmeta>`,
+			nil,
+			"",
+			0,
+			`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description xmlns:dc="http://purl.org/dc/elements/1.1/"><xap:Rating xmlns:xap="http://ns.adobe.com/xap/1.0/">0</xap:Rating></rdf:Description></rdf:RDF></x:xmpmeta>`,
+		},
+		"new rating": {
+			`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/"></rdf:Description></rdf:RDF></x:xmpmeta>`,
+			nil,
+			"",
+			4,
+			`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:Rating="4"></rdf:Description></rdf:RDF></x:xmpmeta>`,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, err := WriteXMP([]byte(tc.content), tc.tags, tc.description, tc.rating)
+			if err != nil {
+				t.Errorf("WriteXMP() = %s", err)
+			}
+
+			if string(got) != tc.want {
+				t.Errorf("WriteXMP() = `%s`, want `%s`", got, tc.want)
+			}
+
+			again, err := WriteXMP(got, tc.tags, tc.description, tc.rating)
+			if err != nil || !bytes.Equal(again, got) {
+				t.Errorf("WriteXMP() is not stable: `%s`", again)
+			}
+		})
+	}
+}
+
+func TestWriteXMPRoundTrip(t *testing.T) {
+	content, err := WriteXMP(nil, []string{"holidays", "beach"}, "Sunset <3", 4)
+	if err != nil {
+		t.Fatalf("WriteXMP() = %s", err)
+	}
+
+	got, err := ParseXMP(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseXMP() = %s", err)
+	}
+
+	want := XMP{Description: "Sunset <3", Tags: []string{"holidays", "beach"}, Rating: 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseXMP(WriteXMP()) = %+v, want %+v", got, want)
+	}
+}
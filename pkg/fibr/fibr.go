@@ -97,6 +97,8 @@ func (s Service) parseRequest(w http.ResponseWriter, r *http.Request) (provider.
 		return request, convertAuthenticationError(err)
 	}
 
+	request.Login = login
+
 	if shouldUpdateCookie {
 		s.cookie.Set(ctx, w, authCookieName, provider.User{Login: login, Password: password})
 	}
@@ -305,6 +305,7 @@ func TestParseRequest(t *testing.T) {
 			provider.Request{
 				Path:       "/",
 				Item:       "guest",
+				Login:      "guest",
 				Display:    provider.DefaultDisplay,
 				CanEdit:    false,
 				CanShare:   false,
@@ -320,6 +321,7 @@ func TestParseRequest(t *testing.T) {
 			provider.Request{
 				Path:       "/",
 				Item:       "admin",
+				Login:      "admin",
 				Display:    provider.DefaultDisplay,
 				CanEdit:    true,
 				CanShare:   true,
@@ -35,6 +35,17 @@ var FuncMap = template.FuncMap{
 	"contains": func(arr []string, value string) bool {
 		return slices.Contains(arr, value)
 	},
+	"ratings": func() []int {
+		output := make([]int, provider.MaxRating)
+		for index := range output {
+			output[index] = index + 1
+		}
+
+		return output
+	},
+	"stars": func(rating int) string {
+		return strings.Repeat("★", rating)
+	},
 	"iconFromExtension": func(file provider.RenderItem) string {
 		switch {
 		case provider.ArchiveExtensions[file.Extension]:
@@ -96,6 +107,8 @@ func (s Service) TemplateFunc(w http.ResponseWriter, r *http.Request) (renderer.
 		return renderer.NewPage("", 0, content), err
 	}
 
+	r = r.WithContext(provider.WithActor(r.Context(), request.Actor()))
+
 	switch r.Method {
 	case http.MethodGet:
 		return s.crud.Get(w, r, request)
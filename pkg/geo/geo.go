@@ -11,6 +11,8 @@ const (
 	TypeFeature Type = "Feature"
 	// TypePoint as defined in https://datatracker.ietf.org/doc/html/rfc7946#section-1.4
 	TypePoint Type = "Point"
+	// TypeLineString as defined in https://datatracker.ietf.org/doc/html/rfc7946#section-1.4
+	TypeLineString Type = "LineString"
 )
 
 type Feature struct {
@@ -39,6 +41,18 @@ func NewPoint(position Position) Point {
 	}
 }
 
+type LineString struct {
+	Type        Type       `json:"type"`
+	Coordinates []Position `json:"coordinates"`
+}
+
+func NewLineString(positions []Position) LineString {
+	return LineString{
+		Type:        TypeLineString,
+		Coordinates: positions,
+	}
+}
+
 type Position struct {
 	Longitude float64
 	Latitude  float64
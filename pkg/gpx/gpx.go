@@ -0,0 +1,134 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	Extension = ".gpx"
+
+	// MaxSize is the max size read from a GPX file, a day of recording every second is ~10MB
+	MaxSize = 32 << 20
+)
+
+type Point struct {
+	Date      time.Time
+	Latitude  float64
+	Longitude float64
+}
+
+type Track struct {
+	Name     string
+	Segments [][]Point
+
+	// timed contains all the points with a date, sorted chronologically
+	timed []Point
+}
+
+type document struct {
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Segments []struct {
+			Points []struct {
+				Time      string  `xml:"time"`
+				Latitude  float64 `xml:"lat,attr"`
+				Longitude float64 `xml:"lon,attr"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// Parse reads all the tracks of a GPX file as a single one, waypoints and routes are ignored
+func Parse(reader io.Reader) (Track, error) {
+	var content document
+
+	if err := xml.NewDecoder(reader).Decode(&content); err != nil {
+		return Track{}, fmt.Errorf("decode: %w", err)
+	}
+
+	var output Track
+
+	for _, track := range content.Tracks {
+		if len(output.Name) == 0 {
+			output.Name = strings.TrimSpace(track.Name)
+		}
+
+		for _, segment := range track.Segments {
+			points := make([]Point, 0, len(segment.Points))
+
+			for _, raw := range segment.Points {
+				point := Point{Latitude: raw.Latitude, Longitude: raw.Longitude}
+
+				if value := strings.TrimSpace(raw.Time); len(value) != 0 {
+					date, err := time.Parse(time.RFC3339, value)
+					if err != nil {
+						return Track{}, fmt.Errorf("parse time `%s`: %w", value, err)
+					}
+
+					point.Date = date
+					output.timed = append(output.timed, point)
+				}
+
+				points = append(points, point)
+			}
+
+			if len(points) != 0 {
+				output.Segments = append(output.Segments, points)
+			}
+		}
+	}
+
+	slices.SortStableFunc(output.timed, func(a, b Point) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return output, nil
+}
+
+// Locate finds the position at the given date, interpolated between the surrounding points. When the track has a hole around the date, the nearest point is used if it's within the max gap.
+func (t Track) Locate(date time.Time, maxGap time.Duration) (float64, float64, bool) {
+	index, found := slices.BinarySearchFunc(t.timed, date, func(point Point, target time.Time) int {
+		return point.Date.Compare(target)
+	})
+
+	if found {
+		return t.timed[index].Latitude, t.timed[index].Longitude, true
+	}
+
+	var previous, next *Point
+
+	if index > 0 {
+		previous = &t.timed[index-1]
+	}
+
+	if index < len(t.timed) {
+		next = &t.timed[index]
+	}
+
+	if previous != nil && next != nil && next.Date.Sub(previous.Date) <= maxGap {
+		ratio := float64(date.Sub(previous.Date)) / float64(next.Date.Sub(previous.Date))
+
+		return previous.Latitude + (next.Latitude-previous.Latitude)*ratio, previous.Longitude + (next.Longitude-previous.Longitude)*ratio, true
+	}
+
+	var nearest *Point
+
+	if previous != nil && date.Sub(previous.Date) <= maxGap {
+		nearest = previous
+	}
+
+	if next != nil && next.Date.Sub(date) <= maxGap && (nearest == nil || next.Date.Sub(date) < date.Sub(nearest.Date)) {
+		nearest = next
+	}
+
+	if nearest == nil {
+		return 0, 0, false
+	}
+
+	return nearest.Latitude, nearest.Longitude, true
+}
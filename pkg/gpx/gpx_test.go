@@ -0,0 +1,146 @@
+package gpx
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="10" lon="10"><name>Summit</name></wpt>
+  <trk>
+    <name> Morning hike </name>
+    <trkseg>
+      <trkpt lat="45.0" lon="6.0"><ele>1000</ele><time>2024-07-14T08:00:00Z</time></trkpt>
+      <trkpt lat="45.1" lon="6.2"><ele>1100</ele><time>2024-07-14T08:10:00Z</time></trkpt>
+      <trkpt lat="45.2" lon="6.4"></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="46.0" lon="7.0"><time>2024-07-14T12:00:00.500+02:00</time></trkpt>
+      <trkpt lat="46.1" lon="7.1"><time>2024-07-14T12:05:00+02:00</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		content      string
+		wantName     string
+		wantSegments []int
+		wantErr      bool
+	}{
+		"invalid": {
+			"<gpx><trk>",
+			"",
+			nil,
+			true,
+		},
+		"invalid time": {
+			`<gpx><trk><trkseg><trkpt lat="1" lon="1"><time>yesterday</time></trkpt></trkseg></trk></gpx>`,
+			"",
+			nil,
+			true,
+		},
+		"valid": {
+			testGPX,
+			"Morning hike",
+			[]int{3, 2},
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotErr := Parse(strings.NewReader(tc.content))
+
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("Parse() error = %v, want %t", gotErr, tc.wantErr)
+				return
+			}
+
+			if got.Name != tc.wantName {
+				t.Errorf("Parse() name = `%s`, want `%s`", got.Name, tc.wantName)
+			}
+
+			if len(got.Segments) != len(tc.wantSegments) {
+				t.Errorf("Parse() segments = %d, want %d", len(got.Segments), len(tc.wantSegments))
+				return
+			}
+
+			for index, segment := range got.Segments {
+				if len(segment) != tc.wantSegments[index] {
+					t.Errorf("Parse() segment %d = %d points, want %d", index, len(segment), tc.wantSegments[index])
+				}
+			}
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	track, err := Parse(strings.NewReader(testGPX))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		date          time.Time
+		wantLatitude  float64
+		wantLongitude float64
+		wantOk        bool
+	}{
+		"exact": {
+			time.Date(2024, 7, 14, 8, 10, 0, 0, time.UTC),
+			45.1,
+			6.2,
+			true,
+		},
+		"interpolated": {
+			time.Date(2024, 7, 14, 8, 5, 0, 0, time.UTC),
+			45.05,
+			6.1,
+			true,
+		},
+		"before start": {
+			time.Date(2024, 7, 14, 7, 58, 0, 0, time.UTC),
+			45,
+			6,
+			true,
+		},
+		"hole": {
+			time.Date(2024, 7, 14, 8, 12, 0, 0, time.UTC),
+			45.1,
+			6.2,
+			true,
+		},
+		"other time zone": {
+			time.Date(2024, 7, 14, 10, 2, 30, 250_000_000, time.UTC),
+			46.05,
+			7.05,
+			true,
+		},
+		"too far": {
+			time.Date(2024, 7, 14, 9, 0, 0, 0, time.UTC),
+			0,
+			0,
+			false,
+		},
+		"after end": {
+			time.Date(2024, 7, 15, 9, 0, 0, 0, time.UTC),
+			0,
+			0,
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			latitude, longitude, ok := track.Locate(tc.date, 15*time.Minute)
+
+			if ok != tc.wantOk || math.Abs(latitude-tc.wantLatitude) > 1e-9 || math.Abs(longitude-tc.wantLongitude) > 1e-9 {
+				t.Errorf("Locate() = (%f, %f, %t), want (%f, %f, %t)", latitude, longitude, ok, tc.wantLatitude, tc.wantLongitude, tc.wantOk)
+			}
+		})
+	}
+}
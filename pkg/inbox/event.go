@@ -0,0 +1,56 @@
+package inbox
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func (s *Service) EventConsumer(ctx context.Context, event provider.Event) {
+	if !slices.Contains(eventTypes, event.Type) {
+		return
+	}
+
+	follows, err := s.follows(ctx)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "inbox follows", slog.String("item", event.Item.Pathname), slog.Any("error", err))
+		return
+	}
+
+	entry := newEntry(event)
+
+	for _, user := range follows.Recipients(event) {
+		if err := s.add(ctx, user, scoped(entry, follows[user])); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "add inbox entry", slog.String("user", user), slog.String("item", event.Item.Pathname), slog.Any("error", err))
+		}
+	}
+
+	if !event.Item.IsDir() {
+		return
+	}
+
+	var update func(Follows) bool
+
+	switch event.Type {
+	case provider.RenameEvent:
+		if event.New == nil {
+			return
+		}
+
+		update = func(follows Follows) bool {
+			return follows.Rename(provider.Dirname(event.Item.Pathname), provider.Dirname(event.New.Pathname))
+		}
+	case provider.DeleteEvent:
+		update = func(follows Follows) bool {
+			return follows.Remove(provider.Dirname(event.Item.Pathname))
+		}
+	default:
+		return
+	}
+
+	if err := s.updateFollows(ctx, update); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "update inbox follows", slog.String("item", event.Item.Pathname), slog.Any("error", err))
+	}
+}
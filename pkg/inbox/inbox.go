@@ -0,0 +1,64 @@
+package inbox
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"sync"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+)
+
+const listenerBuffer = 8
+
+type Service struct {
+	exclusive exclusive.Service
+	storage   absto.Storage
+	broker    *provider.Broker[Notification]
+	mutex     sync.Mutex
+}
+
+type Config struct {
+	PubsubChannel string
+}
+
+func Flags(fs *flag.FlagSet, prefix string) *Config {
+	var config Config
+
+	flags.New("PubSubChannel", "Channel name").Prefix(prefix).DocPrefix("inbox").StringVar(fs, &config.PubsubChannel, "fibr:inbox-channel", nil)
+
+	return &config
+}
+
+func New(config *Config, storageService absto.Storage, redisClient redis.Client, exclusiveService exclusive.Service) *Service {
+	return &Service{
+		storage:   storageService,
+		exclusive: exclusiveService,
+		broker: provider.NewBroker("inbox", redisClient, config.PubsubChannel, listenerBuffer, func(notification Notification) string {
+			return notification.User
+		}),
+	}
+}
+
+func (s *Service) Done() <-chan struct{} {
+	return s.broker.Done()
+}
+
+func (s *Service) Start(ctx context.Context) {
+	s.broker.Start(ctx)
+}
+
+// Subscribe registers a listener of the user's notifications, until the returned func is called
+func (s *Service) Subscribe(user string) (<-chan Notification, func()) {
+	return s.broker.Subscribe(user)
+}
+
+func (s *Service) notify(ctx context.Context, notification Notification) {
+	if err := s.broker.Notify(ctx, notification); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "publish inbox notification", slog.String("user", notification.User), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,255 @@
+package inbox
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const (
+	anonymous  = "anonymous"
+	maxEntries = 100
+)
+
+var eventTypes = []provider.EventType{provider.UploadEvent, provider.CreateDir, provider.RenameEvent, provider.DeleteEvent, provider.DescriptionEvent}
+
+// Follows are the folders followed, by user
+type Follows map[string][]string
+
+type Entry struct {
+	Time     time.Time          `json:"time"`
+	ID       string             `json:"id"`
+	Pathname string             `json:"pathname"`
+	New      string             `json:"new,omitempty"`
+	Actor    string             `json:"actor,omitempty"`
+	Type     provider.EventType `json:"type"`
+	Dir      bool               `json:"dir,omitempty"`
+	Read     bool               `json:"read,omitempty"`
+}
+
+// Notification is sent to the open streams of a user, with the entry that has just been added, if any
+type Notification struct {
+	Entry  *Entry `json:"entry,omitempty"`
+	User   string `json:"user"`
+	Unread int    `json:"unread"`
+}
+
+// View is an entry as sent to the browser, without the paths out of the share
+type View struct {
+	Time time.Time `json:"time"`
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Name string    `json:"name"`
+	By   string    `json:"by"`
+	URL  string    `json:"url,omitempty"`
+	Read bool      `json:"read,omitempty"`
+}
+
+// User is the key of an actor's inbox, share visitors have their own
+func User(actor string) string {
+	if len(actor) == 0 {
+		return anonymous
+	}
+
+	return actor
+}
+
+func (e Entry) Name() string {
+	if len(e.New) != 0 {
+		return path.Base(e.New)
+	}
+
+	return path.Base(e.Pathname)
+}
+
+// By is the human name of who made the change
+func (e Entry) By() string {
+	switch {
+	case strings.HasPrefix(e.Actor, "user:"):
+		return strings.TrimPrefix(e.Actor, "user:")
+	case strings.HasPrefix(e.Actor, "share:"):
+		return "a share visitor"
+	default:
+		return "someone"
+	}
+}
+
+// Folder is the path of the folder containing the entry, empty if it's out of the share
+func (e Entry) Folder(share provider.Share) string {
+	pathname := e.Pathname
+	if len(e.New) != 0 {
+		pathname = e.New
+	}
+
+	folder := provider.Dirname(path.Dir(strings.TrimSuffix(pathname, "/")))
+
+	if share.IsZero() {
+		return folder
+	}
+
+	if !strings.HasPrefix(folder, share.Path) {
+		return ""
+	}
+
+	return provider.Dirname("/" + share.ID + "/" + strings.TrimPrefix(folder, share.Path))
+}
+
+func (e Entry) View(share provider.Share) View {
+	return View{
+		ID:   e.ID,
+		Time: e.Time,
+		Type: e.Type.String(),
+		Name: e.Name(),
+		By:   e.By(),
+		URL:  e.Folder(share),
+		Read: e.Read,
+	}
+}
+
+func (f Follows) IsFollowing(user, pathname string) bool {
+	return slices.Contains(f[user], pathname)
+}
+
+// Recipients returns the users following a folder containing the item of the event, the actor excepted
+func (f Follows) Recipients(event provider.Event) []string {
+	var output []string
+
+	for user, folders := range f {
+		if user == event.Actor {
+			continue
+		}
+
+		for _, folder := range folders {
+			if contains(folder, event.Item.Pathname) || (event.New != nil && contains(folder, event.New.Pathname)) {
+				output = append(output, user)
+				break
+			}
+		}
+	}
+
+	slices.Sort(output)
+
+	return output
+}
+
+// Rename moves the follows of a renamed folder, and of its subfolders, to their new path
+func (f Follows) Rename(old, new string) bool {
+	var changed bool
+
+	for user, folders := range f {
+		for index, folder := range folders {
+			if strings.HasPrefix(folder, old) {
+				folders[index] = new + strings.TrimPrefix(folder, old)
+				changed = true
+			}
+		}
+
+		f[user] = folders
+	}
+
+	return changed
+}
+
+// Remove drops the follows of a deleted folder, and of its subfolders
+func (f Follows) Remove(pathname string) bool {
+	var changed bool
+
+	for user, folders := range f {
+		remaining := slices.DeleteFunc(folders, func(folder string) bool {
+			return strings.HasPrefix(folder, pathname)
+		})
+
+		if len(remaining) != len(folders) {
+			changed = true
+		}
+
+		if len(remaining) == 0 {
+			delete(f, user)
+		} else {
+			f[user] = remaining
+		}
+	}
+
+	return changed
+}
+
+func newEntry(event provider.Event) Entry {
+	entry := Entry{
+		ID:       provider.Identifier(),
+		Time:     event.Time,
+		Type:     event.Type,
+		Pathname: event.Item.Pathname,
+		Actor:    event.Actor,
+		Dir:      event.Item.IsDir(),
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	if event.New != nil {
+		entry.New = event.New.Pathname
+	}
+
+	return entry
+}
+
+// scoped hides the side of a rename that is out of the followed folders, so share visitors never learn about paths outside of their share
+func scoped(entry Entry, folders []string) Entry {
+	if len(entry.New) == 0 {
+		return entry
+	}
+
+	var oldVisible, newVisible bool
+
+	for _, folder := range folders {
+		oldVisible = oldVisible || contains(folder, entry.Pathname)
+		newVisible = newVisible || contains(folder, entry.New)
+	}
+
+	switch {
+	case !newVisible:
+		entry.Type = provider.DeleteEvent
+		entry.New = ""
+	case !oldVisible:
+		entry.Type = provider.UploadEvent
+		if entry.Dir {
+			entry.Type = provider.CreateDir
+		}
+
+		entry.Pathname = entry.New
+		entry.New = ""
+	}
+
+	return entry
+}
+
+// prepend adds the entry as the most recent, keeping at most maxEntries
+func prepend(entries []Entry, entry Entry) []Entry {
+	entries = append([]Entry{entry}, entries...)
+
+	if len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+
+	return entries
+}
+
+func unread(entries []Entry) int {
+	var count int
+
+	for _, entry := range entries {
+		if !entry.Read {
+			count++
+		}
+	}
+
+	return count
+}
+
+func contains(folder, pathname string) bool {
+	return pathname != folder && strings.HasPrefix(pathname, folder)
+}
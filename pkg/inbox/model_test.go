@@ -0,0 +1,205 @@
+package inbox
+
+import (
+	"reflect"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestRecipients(t *testing.T) {
+	t.Parallel()
+
+	follows := Follows{
+		"user:bob":       {"/photos/"},
+		"user:alice":     {"/photos/2024/", "/docs/"},
+		"share:abcdef12": {"/photos/2024/summer/"},
+	}
+
+	cases := map[string]struct {
+		event provider.Event
+		want  []string
+	}{
+		"nobody": {
+			provider.Event{Item: absto.Item{Pathname: "/videos/cat.mp4"}},
+			nil,
+		},
+		"followed folder itself": {
+			provider.Event{Item: absto.Item{Pathname: "/photos/", IsDirValue: true}},
+			nil,
+		},
+		"subfolders": {
+			provider.Event{Item: absto.Item{Pathname: "/photos/2024/summer/beach.jpg"}},
+			[]string{"share:abcdef12", "user:alice", "user:bob"},
+		},
+		"actor excepted": {
+			provider.Event{Item: absto.Item{Pathname: "/photos/2024/beach.jpg"}, Actor: "user:alice"},
+			[]string{"user:bob"},
+		},
+		"renamed into": {
+			provider.Event{Item: absto.Item{Pathname: "/videos/cat.mp4"}, New: &absto.Item{Pathname: "/docs/cat.mp4"}},
+			[]string{"user:alice"},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := follows.Recipients(tc.event); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Recipients() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScoped(t *testing.T) {
+	t.Parallel()
+
+	folders := []string{"/photos/summer/"}
+
+	cases := map[string]struct {
+		entry Entry
+		want  Entry
+	}{
+		"no rename": {
+			Entry{Type: provider.UploadEvent, Pathname: "/photos/summer/beach.jpg"},
+			Entry{Type: provider.UploadEvent, Pathname: "/photos/summer/beach.jpg"},
+		},
+		"rename inside": {
+			Entry{Type: provider.RenameEvent, Pathname: "/photos/summer/beach.jpg", New: "/photos/summer/sea.jpg"},
+			Entry{Type: provider.RenameEvent, Pathname: "/photos/summer/beach.jpg", New: "/photos/summer/sea.jpg"},
+		},
+		"moved out": {
+			Entry{Type: provider.RenameEvent, Pathname: "/photos/summer/beach.jpg", New: "/private/beach.jpg"},
+			Entry{Type: provider.DeleteEvent, Pathname: "/photos/summer/beach.jpg"},
+		},
+		"moved in": {
+			Entry{Type: provider.RenameEvent, Pathname: "/private/trip", New: "/photos/summer/trip", Dir: true},
+			Entry{Type: provider.CreateDir, Pathname: "/photos/summer/trip", Dir: true},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := scoped(tc.entry, folders); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("scoped() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFolder(t *testing.T) {
+	t.Parallel()
+
+	share := provider.Share{ID: "abcdef12", Path: "/photos/summer/"}
+
+	cases := map[string]struct {
+		entry Entry
+		share provider.Share
+		want  string
+	}{
+		"file": {
+			Entry{Pathname: "/photos/summer/beach.jpg"},
+			provider.Share{},
+			"/photos/summer/",
+		},
+		"directory": {
+			Entry{Pathname: "/photos/summer/trip/", Dir: true},
+			provider.Share{},
+			"/photos/summer/",
+		},
+		"renamed": {
+			Entry{Pathname: "/photos/summer/beach.jpg", New: "/photos/winter/beach.jpg"},
+			provider.Share{},
+			"/photos/winter/",
+		},
+		"share": {
+			Entry{Pathname: "/photos/summer/trip/beach.jpg"},
+			share,
+			"/abcdef12/trip/",
+		},
+		"share root": {
+			Entry{Pathname: "/photos/summer/beach.jpg"},
+			share,
+			"/abcdef12/",
+		},
+		"out of share": {
+			Entry{Pathname: "/photos/beach.jpg"},
+			share,
+			"",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.entry.Folder(tc.share); got != tc.want {
+				t.Errorf("Folder() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenameRemove(t *testing.T) {
+	t.Parallel()
+
+	follows := Follows{
+		"user:bob":   {"/photos/", "/photos/2024/"},
+		"user:alice": {"/photos/2024/summer/"},
+	}
+
+	if !follows.Rename("/photos/2024/", "/archives/2024/") {
+		t.Error("Rename() = false, want true")
+	}
+
+	want := Follows{
+		"user:bob":   {"/photos/", "/archives/2024/"},
+		"user:alice": {"/archives/2024/summer/"},
+	}
+
+	if !reflect.DeepEqual(follows, want) {
+		t.Errorf("Rename() = %v, want %v", follows, want)
+	}
+
+	if !follows.Remove("/archives/") {
+		t.Error("Remove() = false, want true")
+	}
+
+	want = Follows{
+		"user:bob": {"/photos/"},
+	}
+
+	if !reflect.DeepEqual(follows, want) {
+		t.Errorf("Remove() = %v, want %v", follows, want)
+	}
+
+	if follows.Remove("/videos/") {
+		t.Error("Remove() = true, want false")
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	t.Parallel()
+
+	var entries []Entry
+	for index := range maxEntries + 5 {
+		entries = prepend(entries, Entry{ID: string(rune('a' + index%26)), Read: index%2 == 0})
+	}
+
+	if len(entries) != maxEntries {
+		t.Errorf("prepend() = %d entries, want %d", len(entries), maxEntries)
+	}
+
+	if got := entries[0].ID; got != string(rune('a'+(maxEntries+4)%26)) {
+		t.Errorf("prepend() first = `%s`, want the last added", got)
+	}
+
+	if got := unread(entries); got != maxEntries/2 {
+		t.Errorf("unread() = %d, want %d", got, maxEntries/2)
+	}
+}
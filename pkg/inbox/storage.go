@@ -0,0 +1,194 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+var (
+	inboxDirectory  = provider.MetadataDirectoryName + "/inbox/"
+	followsFilename = inboxDirectory + "follows.json"
+
+	ErrNotFound = errors.New("inbox entry not found")
+)
+
+func (s *Service) IsFollowing(ctx context.Context, user, pathname string) (bool, error) {
+	follows, err := s.follows(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return follows.IsFollowing(user, pathname), nil
+}
+
+func (s *Service) Follow(ctx context.Context, user, pathname string) error {
+	return s.updateFollows(ctx, func(follows Follows) bool {
+		if follows.IsFollowing(user, pathname) {
+			return false
+		}
+
+		follows[user] = append(follows[user], pathname)
+		slices.Sort(follows[user])
+
+		return true
+	})
+}
+
+func (s *Service) Unfollow(ctx context.Context, user, pathname string) error {
+	return s.updateFollows(ctx, func(follows Follows) bool {
+		index := slices.Index(follows[user], pathname)
+		if index == -1 {
+			return false
+		}
+
+		follows[user] = slices.Delete(follows[user], index, index+1)
+		if len(follows[user]) == 0 {
+			delete(follows, user)
+		}
+
+		return true
+	})
+}
+
+// Entries returns the inbox of the user, the most recent first
+func (s *Service) Entries(ctx context.Context, user string) ([]Entry, error) {
+	return s.entries(ctx, user)
+}
+
+func (s *Service) Unread(ctx context.Context, user string) (int, error) {
+	entries, err := s.entries(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+
+	return unread(entries), nil
+}
+
+// MarkRead marks the entry of given id as read, or every entry if the id is empty
+func (s *Service) MarkRead(ctx context.Context, user, id string) error {
+	var count int
+
+	err := s.updateEntries(ctx, user, func(entries []Entry) ([]Entry, error) {
+		found := len(id) == 0
+
+		for index := range entries {
+			if len(id) == 0 || entries[index].ID == id {
+				entries[index].Read = true
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, ErrNotFound
+		}
+
+		count = unread(entries)
+
+		return entries, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, Notification{User: user, Unread: count})
+
+	return nil
+}
+
+func (s *Service) add(ctx context.Context, user string, entry Entry) error {
+	var count int
+
+	err := s.updateEntries(ctx, user, func(entries []Entry) ([]Entry, error) {
+		entries = prepend(entries, entry)
+		count = unread(entries)
+
+		return entries, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, Notification{User: user, Entry: &entry, Unread: count})
+
+	return nil
+}
+
+func (s *Service) updateFollows(ctx context.Context, update func(Follows) bool) error {
+	return s.exclusive.Execute(ctx, "fibr:mutex:inbox:follows", exclusive.Duration, func(ctx context.Context) error {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		follows, err := s.follows(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !update(follows) {
+			return nil
+		}
+
+		return s.save(ctx, followsFilename, follows)
+	})
+}
+
+func (s *Service) updateEntries(ctx context.Context, user string, update func([]Entry) ([]Entry, error)) error {
+	return s.exclusive.Execute(ctx, "fibr:mutex:inbox:"+provider.Hash(user), exclusive.Duration, func(ctx context.Context) error {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		entries, err := s.entries(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		if entries, err = update(entries); err != nil {
+			return err
+		}
+
+		return s.save(ctx, entriesFilename(user), entries)
+	})
+}
+
+func (s *Service) follows(ctx context.Context) (Follows, error) {
+	follows, err := provider.LoadJSON[Follows](ctx, s.storage, followsFilename)
+	if err != nil && !absto.IsNotExist(err) {
+		return nil, fmt.Errorf("load follows: %w", err)
+	}
+
+	if follows == nil {
+		follows = make(Follows)
+	}
+
+	return follows, nil
+}
+
+func (s *Service) entries(ctx context.Context, user string) ([]Entry, error) {
+	entries, err := provider.LoadJSON[[]Entry](ctx, s.storage, entriesFilename(user))
+	if err != nil && !absto.IsNotExist(err) {
+		return nil, fmt.Errorf("load entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (s *Service) save(ctx context.Context, filename string, content any) error {
+	if err := s.storage.Mkdir(ctx, inboxDirectory, absto.DirectoryPerm); err != nil {
+		return fmt.Errorf("create dir: %w", err)
+	}
+
+	if err := provider.SaveJSON(ctx, s.storage, filename, content); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+
+	return nil
+}
+
+func entriesFilename(user string) string {
+	return inboxDirectory + provider.Hash(user) + ".json"
+}
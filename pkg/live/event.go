@@ -0,0 +1,13 @@
+package live
+
+import (
+	"context"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func (s *Service) EventConsumer(ctx context.Context, event provider.Event) {
+	for _, change := range changes(event) {
+		s.notify(ctx, change)
+	}
+}
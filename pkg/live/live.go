@@ -0,0 +1,56 @@
+package live
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/flags"
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+)
+
+const listenerBuffer = 32
+
+type Service struct {
+	broker *provider.Broker[Change]
+}
+
+type Config struct {
+	PubsubChannel string
+}
+
+func Flags(fs *flag.FlagSet, prefix string) *Config {
+	var config Config
+
+	flags.New("PubSubChannel", "Channel name").Prefix(prefix).DocPrefix("live").StringVar(fs, &config.PubsubChannel, "fibr:live-channel", nil)
+
+	return &config
+}
+
+func New(config *Config, redisClient redis.Client) *Service {
+	return &Service{
+		broker: provider.NewBroker("live", redisClient, config.PubsubChannel, listenerBuffer, func(change Change) string {
+			return change.Directory
+		}),
+	}
+}
+
+func (s *Service) Done() <-chan struct{} {
+	return s.broker.Done()
+}
+
+func (s *Service) Start(ctx context.Context) {
+	s.broker.Start(ctx)
+}
+
+// Subscribe registers a listener of the changes in the directory, until the returned func is called
+func (s *Service) Subscribe(directory string) (<-chan Change, func()) {
+	return s.broker.Subscribe(directory)
+}
+
+func (s *Service) notify(ctx context.Context, change Change) {
+	if err := s.broker.Notify(ctx, change); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "publish live change", slog.String("directory", change.Directory), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,61 @@
+package live
+
+import (
+	"path"
+	"strings"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const (
+	Add       = "add"
+	Remove    = "remove"
+	Rename    = "rename"
+	Thumbnail = "thumbnail"
+)
+
+// Change is what happened to an item of a directory. Only names are streamed, the directory is already known by the listener and may be outside its share
+type Change struct {
+	Directory string `json:"directory"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	New       string `json:"new,omitempty"`
+}
+
+// changes maps an event to the changes of the directories containing its items, a move across directories removes from one and adds to the other
+func changes(event provider.Event) []Change {
+	switch event.Type {
+	case provider.UploadEvent, provider.CreateDir:
+		return []Change{changeOf(Add, event.Item.Pathname)}
+	case provider.DeleteEvent:
+		return []Change{changeOf(Remove, event.Item.Pathname)}
+	case provider.ThumbnailEvent:
+		return []Change{changeOf(Thumbnail, event.Item.Pathname)}
+	case provider.RenameEvent:
+		if event.New == nil {
+			return nil
+		}
+
+		old, renamed := changeOf(Remove, event.Item.Pathname), changeOf(Add, event.New.Pathname)
+		if old.Directory != renamed.Directory {
+			return []Change{old, renamed}
+		}
+
+		old.Type = Rename
+		old.New = renamed.Name
+
+		return []Change{old}
+	default:
+		return nil
+	}
+}
+
+func changeOf(changeType, pathname string) Change {
+	pathname = strings.TrimSuffix(pathname, "/")
+
+	return Change{
+		Directory: provider.Dirname(path.Dir(pathname)),
+		Type:      changeType,
+		Name:      path.Base(pathname),
+	}
+}
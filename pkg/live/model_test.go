@@ -0,0 +1,57 @@
+package live
+
+import (
+	"reflect"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		event provider.Event
+		want  []Change
+	}{
+		"upload": {
+			provider.Event{Type: provider.UploadEvent, Item: absto.Item{Pathname: "/photos/beach.jpg"}},
+			[]Change{{Directory: "/photos/", Type: Add, Name: "beach.jpg"}},
+		},
+		"create": {
+			provider.Event{Type: provider.CreateDir, Item: absto.Item{Pathname: "/photos/summer/", IsDirValue: true}},
+			[]Change{{Directory: "/photos/", Type: Add, Name: "summer"}},
+		},
+		"delete at root": {
+			provider.Event{Type: provider.DeleteEvent, Item: absto.Item{Pathname: "/beach.jpg"}},
+			[]Change{{Directory: "/", Type: Remove, Name: "beach.jpg"}},
+		},
+		"thumbnail": {
+			provider.Event{Type: provider.ThumbnailEvent, Item: absto.Item{Pathname: "/photos/beach.jpg"}},
+			[]Change{{Directory: "/photos/", Type: Thumbnail, Name: "beach.jpg"}},
+		},
+		"rename": {
+			provider.Event{Type: provider.RenameEvent, Item: absto.Item{Pathname: "/photos/beach.jpg"}, New: &absto.Item{Pathname: "/photos/sea.jpg"}},
+			[]Change{{Directory: "/photos/", Type: Rename, Name: "beach.jpg", New: "sea.jpg"}},
+		},
+		"move": {
+			provider.Event{Type: provider.RenameEvent, Item: absto.Item{Pathname: "/photos/beach.jpg"}, New: &absto.Item{Pathname: "/archives/beach.jpg"}},
+			[]Change{{Directory: "/photos/", Type: Remove, Name: "beach.jpg"}, {Directory: "/archives/", Type: Add, Name: "beach.jpg"}},
+		},
+		"ignored": {
+			provider.Event{Type: provider.AccessEvent, Item: absto.Item{Pathname: "/photos/beach.jpg"}},
+			nil,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := changes(tc.event); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("changes() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
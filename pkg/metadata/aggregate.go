@@ -134,11 +134,18 @@ func (s *Service) computeAndSaveAggregate(ctx context.Context, dir absto.Item) e
 	}
 
 	directoryAggregate := newAggregate()
+	months := make(map[string]int)
 	var minDate, maxDate time.Time
 
-	for _, exifData := range metadatas {
+	for _, item := range fileItems {
+		exifData := metadatas[item.ID]
+
 		if !exifData.Date.IsZero() {
 			minDate, maxDate = aggregateDate(minDate, maxDate, exifData.Date)
+
+			if provider.IsMedia(item) && item.Dir() == provider.Dirname(dir.Pathname) {
+				months[exifData.Date.Format(provider.MonthLayout)]++
+			}
 		}
 
 		if exifData.Geocode.HasAddress() {
@@ -151,6 +158,7 @@ func (s *Service) computeAndSaveAggregate(ctx context.Context, dir absto.Item) e
 		Location: directoryAggregate.value(),
 		Start:    minDate,
 		End:      maxDate,
+		Months:   months,
 	})
 }
 
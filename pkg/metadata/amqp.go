@@ -28,7 +28,7 @@ func (s *Service) AMQPHandler(ctx context.Context, message amqp.Delivery) error
 		return nil
 	}
 
-	metadata, err := s.Update(ctx, resp.Item, provider.ReplaceExif(resp.Exif))
+	metadata, err := s.Update(ctx, resp.Item, provider.ReplaceExif(resp.Exif), provider.DefaultRating(provider.ExifRating(resp.Exif)))
 	if err != nil {
 		return fmt.Errorf("update: %w", err)
 	}
@@ -0,0 +1,120 @@
+package metadata
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	exas "github.com/ViBiOh/exas/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const earthRadius = 6371.0
+
+// Clusters groups the files into events, from their capture date and location
+func (s *Service) Clusters(items []absto.Item, metadatas map[string]provider.Metadata) []provider.Cluster {
+	return clusters(items, metadatas, s.clusterGap, s.clusterDistance)
+}
+
+type clusterBuilder struct {
+	provider.Cluster
+	aggregate locationAggregate
+	last      exas.Geocode
+}
+
+func (b *clusterBuilder) add(item absto.Item, metadata provider.Metadata) {
+	if len(b.Items) == 0 {
+		b.Start = metadata.Date
+	}
+
+	b.Items = append(b.Items, item)
+	b.End = metadata.Date
+
+	if metadata.Geocode.HasCoordinates() {
+		b.last = metadata.Geocode
+	}
+
+	if metadata.Geocode.HasAddress() {
+		b.aggregate.ingest(metadata.Geocode)
+	}
+}
+
+func (b *clusterBuilder) accept(metadata provider.Metadata, gap time.Duration, distance float64) bool {
+	if len(b.Items) == 0 {
+		return true
+	}
+
+	if metadata.Date.Sub(b.End) > gap {
+		return false
+	}
+
+	if !b.last.HasCoordinates() || !metadata.Geocode.HasCoordinates() {
+		return true
+	}
+
+	return haversine(b.last, metadata.Geocode) <= distance
+}
+
+func (b *clusterBuilder) build() provider.Cluster {
+	b.Location = b.aggregate.value()
+
+	return b.Cluster
+}
+
+func clusters(items []absto.Item, metadatas map[string]provider.Metadata, gap time.Duration, distance float64) []provider.Cluster {
+	var dated, undated []absto.Item
+
+	for _, item := range items {
+		if item.IsDir() {
+			continue
+		}
+
+		if metadatas[item.ID].Date.IsZero() {
+			undated = append(undated, item)
+		} else {
+			dated = append(dated, item)
+		}
+	}
+
+	slices.SortStableFunc(dated, func(a, b absto.Item) int {
+		return metadatas[a.ID].Date.Compare(metadatas[b.ID].Date)
+	})
+
+	var output []provider.Cluster
+
+	current := clusterBuilder{aggregate: newAggregate()}
+
+	for _, item := range dated {
+		metadata := metadatas[item.ID]
+
+		if !current.accept(metadata, gap, distance) {
+			output = append(output, current.build())
+			current = clusterBuilder{aggregate: newAggregate()}
+		}
+
+		current.add(item, metadata)
+	}
+
+	if len(current.Items) != 0 {
+		output = append(output, current.build())
+	}
+
+	if len(undated) != 0 {
+		output = append(output, provider.Cluster{Items: undated})
+	}
+
+	return output
+}
+
+// haversine computes the great-circle distance, in kilometers, between two points
+func haversine(from, to exas.Geocode) float64 {
+	fromLatitude := from.Latitude * math.Pi / 180
+	toLatitude := to.Latitude * math.Pi / 180
+	deltaLatitude := toLatitude - fromLatitude
+	deltaLongitude := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLatitude/2)*math.Sin(deltaLatitude/2) + math.Cos(fromLatitude)*math.Cos(toLatitude)*math.Sin(deltaLongitude/2)*math.Sin(deltaLongitude/2)
+
+	return 2 * earthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	exas "github.com/ViBiOh/exas/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestClusters(t *testing.T) {
+	day := time.Date(2024, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+	lyon := exas.Geocode{Latitude: 45.76, Longitude: 4.83, Address: map[string]string{"city": "Lyon", "country": "France"}}
+	villeurbanne := exas.Geocode{Latitude: 45.77, Longitude: 4.88, Address: map[string]string{"city": "Lyon", "country": "France"}}
+	paris := exas.Geocode{Latitude: 48.85, Longitude: 2.35, Address: map[string]string{"city": "Paris", "country": "France"}}
+
+	first := absto.Item{ID: "1", NameValue: "first.jpg"}
+	second := absto.Item{ID: "2", NameValue: "second.jpg"}
+	third := absto.Item{ID: "3", NameValue: "third.jpg"}
+	undated := absto.Item{ID: "4", NameValue: "undated.jpg"}
+	folder := absto.Item{ID: "5", NameValue: "folder", IsDirValue: true}
+
+	type args struct {
+		items     []absto.Item
+		metadatas map[string]provider.Metadata
+	}
+
+	cases := map[string]struct {
+		args args
+		want []provider.Cluster
+	}{
+		"empty": {
+			args{},
+			nil,
+		},
+		"same event": {
+			args{
+				items: []absto.Item{second, folder, first},
+				metadatas: map[string]provider.Metadata{
+					"1": {Exif: exas.Exif{Date: day, Geocode: lyon}},
+					"2": {Exif: exas.Exif{Date: day.Add(2 * time.Hour), Geocode: villeurbanne}},
+				},
+			},
+			[]provider.Cluster{
+				{Start: day, End: day.Add(2 * time.Hour), Location: "Lyon", Items: []absto.Item{first, second}},
+			},
+		},
+		"time gap": {
+			args{
+				items: []absto.Item{first, second},
+				metadatas: map[string]provider.Metadata{
+					"1": {Exif: exas.Exif{Date: day}},
+					"2": {Exif: exas.Exif{Date: day.Add(24 * time.Hour)}},
+				},
+			},
+			[]provider.Cluster{
+				{Start: day, End: day, Items: []absto.Item{first}},
+				{Start: day.Add(24 * time.Hour), End: day.Add(24 * time.Hour), Items: []absto.Item{second}},
+			},
+		},
+		"distance": {
+			args{
+				items: []absto.Item{first, second, third, undated},
+				metadatas: map[string]provider.Metadata{
+					"1": {Exif: exas.Exif{Date: day, Geocode: lyon}},
+					"2": {Exif: exas.Exif{Date: day.Add(time.Hour)}},
+					"3": {Exif: exas.Exif{Date: day.Add(2 * time.Hour), Geocode: paris}},
+				},
+			},
+			[]provider.Cluster{
+				{Start: day, End: day.Add(time.Hour), Location: "Lyon", Items: []absto.Item{first, second}},
+				{Start: day.Add(2 * time.Hour), End: day.Add(2 * time.Hour), Location: "Paris", Items: []absto.Item{third}},
+				{Items: []absto.Item{undated}},
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := clusters(tc.args.items, tc.args.metadatas, 6*time.Hour, 50); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("clusters() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHaversine(t *testing.T) {
+	got := haversine(exas.Geocode{Latitude: 45.76, Longitude: 4.83}, exas.Geocode{Latitude: 48.85, Longitude: 2.35})
+
+	if got < 385 || got > 395 {
+		t.Errorf("haversine() = %f, want about 390", got)
+	}
+}
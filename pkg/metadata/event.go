@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/gpx"
 	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/httputils/v4/pkg/cache"
 )
@@ -29,6 +30,8 @@ func (s *Service) EventConsumer(ctx context.Context, e provider.Event) {
 		}
 
 	case provider.RenameEvent:
+		s.moveInTagIndex(ctx, e.Item, *e.New)
+
 		if e.Item.IsDir() {
 			// Dir are handled on the event bus
 			return
@@ -93,22 +96,60 @@ func (s *Service) handleStartEvent(ctx context.Context, event provider.Event) er
 		return nil
 	}
 
+	if item.Extension == provider.XMPExtension || item.Extension == gpx.Extension {
+		// Sidecars are imported with the files they describe, tracks are applied when files are processed
+		return nil
+	}
+
 	return s.handleUploadEvent(ctx, item, false)
 }
 
 func (s *Service) handleUploadEvent(ctx context.Context, item absto.Item, aggregate bool) error {
+	if item.Extension == provider.XMPExtension {
+		return s.handleSidecarEvent(ctx, item)
+	}
+
+	if item.Extension == gpx.Extension {
+		count, err := s.Geotag(ctx, item, s.gpxOffset)
+		if err != nil {
+			return fmt.Errorf("geotag: %w", err)
+		}
+
+		slog.LogAttrs(ctx, slog.LevelInfo, "geotagged from track", slog.String("item", item.Pathname), slog.Int("count", count))
+
+		return nil
+	}
+
+	if err := s.importSidecar(ctx, item); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "import sidecar", slog.String("item", item.Pathname), slog.Any("error", err))
+	}
+
 	if !s.CanHaveExif(item) {
 		slog.LogAttrs(ctx, slog.LevelDebug, "can't have exif", slog.String("item", item.Pathname))
 		return nil
 	}
 
-	if s.amqpClient != nil {
-		return s.publishExifRequest(ctx, item)
+	var metadata provider.Metadata
+	var err error
+
+	if s.amqpClient != nil && s.canUseExas(item) {
+		if s.local != localPrimary || !s.canUseLocal(item) {
+			return s.publishExifRequest(ctx, item)
+		}
+
+		if metadata, err = s.extractLocal(ctx, item); err != nil || !metadata.HasData() {
+			if err != nil {
+				slog.LogAttrs(ctx, slog.LevelWarn, "local extraction failed, publishing to exas", slog.String("item", item.Pathname), slog.Any("error", err))
+			}
+
+			return s.publishExifRequest(ctx, item)
+		}
+	} else if metadata, err = s.extractMetadata(ctx, item); err != nil {
+		return fmt.Errorf("extract metadata: %w", err)
 	}
 
-	metadata, err := s.extractAndSaveExif(ctx, item)
-	if err != nil {
-		return fmt.Errorf("extract and save exif: %w", err)
+	if metadata, err = s.saveExtracted(ctx, item, metadata); err != nil {
+		return fmt.Errorf("save metadata: %w", err)
 	}
 
 	if metadata.IsZero() {
@@ -119,6 +160,11 @@ func (s *Service) handleUploadEvent(ctx context.Context, item absto.Item, aggreg
 }
 
 func (s *Service) processMetadata(ctx context.Context, item absto.Item, exif provider.Metadata, aggregate bool) error {
+	exif, err := s.geotagFromTracks(ctx, item, exif)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "geotag from tracks", slog.String("item", item.Pathname), slog.Any("error", err))
+	}
+
 	if err := s.updateDate(ctx, item, exif); err != nil {
 		return fmt.Errorf("update date: %w", err)
 	}
@@ -169,6 +215,10 @@ func (s *Service) delete(ctx context.Context, item absto.Item) error {
 		return fmt.Errorf("cache: %w", err)
 	}
 
+	if err := s.editTagIndex(ctx, func(index provider.TagIndex) bool { return index.Remove(item.Pathname) }); err != nil {
+		return fmt.Errorf("tag index: %w", err)
+	}
+
 	if !item.IsDir() {
 		if err := s.aggregate(ctx, item); err != nil {
 			return fmt.Errorf("aggregate directory: %w", err)
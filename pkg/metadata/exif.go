@@ -8,9 +8,12 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
 	exas "github.com/ViBiOh/exas/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/embedded"
 	"github.com/ViBiOh/fibr/pkg/exclusive"
 	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/flags"
@@ -25,6 +28,12 @@ import (
 
 var errInvalidItemType = errors.New("invalid item type")
 
+const (
+	localDisabled = "disabled"
+	localPrimary  = "primary"
+	localFallback = "fallback"
+)
+
 type Service struct {
 	tracer          trace.Tracer
 	storage         absto.Storage
@@ -38,12 +47,23 @@ type Service struct {
 	exclusive   exclusive.Service
 	redisClient redis.Client
 
+	tagMutex *sync.Mutex
+
 	amqpClient     *amqpclient.Client
 	amqpExchange   string
 	amqpRoutingKey string
 
 	exifRequest request.Request
 
+	local        string
+	sidecarWrite bool
+
+	gpxOffset time.Duration
+	gpxMaxGap time.Duration
+
+	clusterGap      time.Duration
+	clusterDistance float64
+
 	maxSize      int64
 	directAccess bool
 }
@@ -56,6 +76,15 @@ type Config struct {
 	AmqpExchange   string
 	AmqpRoutingKey string
 
+	Local        string
+	SidecarWrite bool
+
+	GpxOffset time.Duration
+	GpxMaxGap time.Duration
+
+	ClusterGap      time.Duration
+	ClusterDistance float64
+
 	MaxSize      int64
 	DirectAccess bool
 }
@@ -70,6 +99,16 @@ func Flags(fs *flag.FlagSet, prefix string) *Config {
 	flags.New("DirectAccess", "Use Exas with direct access to filesystem (no large file upload, send a GET request, Basic Auth recommended)").Prefix(prefix).DocPrefix("exif").BoolVar(fs, &config.DirectAccess, false, nil)
 	flags.New("MaxSize", "Max file size (in bytes) for extracting exif (0 to no limit). Not used if DirectAccess enabled.").Prefix(prefix).DocPrefix("exif").Int64Var(fs, &config.MaxSize, 1024*1024*200, nil)
 
+	flags.New("Local", "Extract embedded metadata without exas: disabled, primary (exas only if nothing found) or fallback (if exas is unavailable or fails)").Prefix(prefix).DocPrefix("exif").StringVar(fs, &config.Local, localFallback, nil)
+
+	flags.New("SidecarWrite", "Write tags, description and rating back to XMP sidecars").Prefix(prefix).DocPrefix("exif").BoolVar(fs, &config.SidecarWrite, false, nil)
+
+	flags.New("GpxOffset", "Camera clock offset to the time of GPX tracks, e.g. 2h if the camera is set two hours ahead of UTC").Prefix(prefix).DocPrefix("exif").DurationVar(fs, &config.GpxOffset, 0, nil)
+	flags.New("GpxMaxGap", "Max duration between a picture and the GPX track points to geotag it").Prefix(prefix).DocPrefix("exif").DurationVar(fs, &config.GpxMaxGap, 5*time.Minute, nil)
+
+	flags.New("ClusterGap", "Max duration between two pictures of the same event").Prefix(prefix).DocPrefix("exif").DurationVar(fs, &config.ClusterGap, 6*time.Hour, nil)
+	flags.New("ClusterDistance", "Max distance (in kilometers) between two pictures of the same event").Prefix(prefix).DocPrefix("exif").Float64Var(fs, &config.ClusterDistance, 50, nil)
+
 	flags.New("AmqpExchange", "AMQP Exchange Name").Prefix(prefix).DocPrefix("exif").StringVar(fs, &config.AmqpExchange, "fibr", nil)
 	flags.New("AmqpRoutingKey", "AMQP Routing Key for exif").Prefix(prefix).DocPrefix("exif").StringVar(fs, &config.AmqpRoutingKey, "exif_input", nil)
 
@@ -77,6 +116,12 @@ func Flags(fs *flag.FlagSet, prefix string) *Config {
 }
 
 func New(ctx context.Context, config *Config, storageService absto.Storage, meterProvider metric.MeterProvider, traceProvider trace.TracerProvider, amqpClient *amqpclient.Client, redisClient redis.Client, exclusiveService exclusive.Service) (*Service, error) {
+	switch config.Local {
+	case localDisabled, localPrimary, localFallback:
+	default:
+		return nil, fmt.Errorf("invalid local extraction mode `%s`", config.Local)
+	}
+
 	var amqpExchange string
 
 	if amqpClient != nil {
@@ -91,6 +136,13 @@ func New(ctx context.Context, config *Config, storageService absto.Storage, mete
 		exifRequest:  request.New().URL(config.ExifURL).BasicAuth(config.ExifUser, config.ExifPass),
 		directAccess: config.DirectAccess,
 		maxSize:      config.MaxSize,
+		local:        config.Local,
+		sidecarWrite: config.SidecarWrite,
+		gpxOffset:    config.GpxOffset,
+		gpxMaxGap:    config.GpxMaxGap,
+
+		clusterGap:      config.ClusterGap,
+		clusterDistance: config.ClusterDistance,
 
 		redisClient: redisClient,
 
@@ -100,6 +152,7 @@ func New(ctx context.Context, config *Config, storageService absto.Storage, mete
 
 		tracer:    traceProvider.Tracer("exif"),
 		exclusive: exclusiveService,
+		tagMutex:  &sync.Mutex{},
 		storage:   storageService,
 		listStorage: storageService.WithIgnoreFn(func(item absto.Item) bool {
 			return !strings.HasSuffix(item.Name(), ".json")
@@ -158,16 +211,58 @@ func (s *Service) ListDir(ctx context.Context, item absto.Item) ([]absto.Item, e
 }
 
 func (s *Service) enabled() bool {
-	return !s.exifRequest.IsZero()
+	return !s.exifRequest.IsZero() || s.localEnabled()
+}
+
+func (s *Service) localEnabled() bool {
+	return s.local == localPrimary || s.local == localFallback
+}
+
+func (s *Service) saveExtracted(ctx context.Context, item absto.Item, extracted provider.Metadata) (provider.Metadata, error) {
+	return s.Update(ctx, item, provider.ReplaceExif(extracted.Exif), provider.MergeTags(extracted.Tags), provider.DefaultDescription(extracted.Description), provider.DefaultRating(extracted.Rating))
 }
 
-func (s *Service) extractAndSaveExif(ctx context.Context, item absto.Item) (provider.Metadata, error) {
+func (s *Service) extractMetadata(ctx context.Context, item absto.Item) (provider.Metadata, error) {
+	useExas := s.canUseExas(item)
+
+	if s.canUseLocal(item) && (s.local == localPrimary || !useExas) {
+		metadata, err := s.extractLocal(ctx, item)
+		if !useExas || (err == nil && metadata.HasData()) {
+			return metadata, err
+		}
+
+		if err != nil {
+			slog.LogAttrs(ctx, slog.LevelWarn, "local extraction failed, trying exas", slog.String("item", item.Pathname), slog.Any("error", err))
+		}
+	}
+
 	exif, err := s.extractExif(ctx, item)
+	if err != nil && s.local == localFallback && s.canUseLocal(item) {
+		slog.LogAttrs(ctx, slog.LevelWarn, "exas extraction failed, falling back to local", slog.String("item", item.Pathname), slog.Any("error", err))
+
+		return s.extractLocal(ctx, item)
+	}
+
+	return provider.Metadata{Exif: exif, Rating: provider.ExifRating(exif)}, err
+}
+
+func (s *Service) extractLocal(ctx context.Context, item absto.Item) (provider.Metadata, error) {
+	s.increaseExif(ctx, "local")
+
+	reader, err := s.storage.ReadFrom(ctx, item.Pathname)
 	if err != nil {
-		return provider.Metadata{}, fmt.Errorf("extract exif: %w", err)
+		return provider.Metadata{}, fmt.Errorf("open: %w", err)
+	}
+
+	defer provider.LogClose(ctx, reader, "metadata.extractLocal", item.Pathname)
+
+	metadata, err := embedded.Extract(reader, item.Size(), item.Extension)
+	if err != nil {
+		s.increaseExif(ctx, "error")
+		return metadata, fmt.Errorf("extract embedded: %w", err)
 	}
 
-	return s.Update(ctx, item, provider.ReplaceExif(exif))
+	return metadata, nil
 }
 
 func (s *Service) extractExif(ctx context.Context, item absto.Item) (exif exas.Exif, err error) {
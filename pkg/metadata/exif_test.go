@@ -13,7 +13,7 @@ func TestFlags(t *testing.T) {
 		want string
 	}{
 		"simple": {
-			"Usage of simple:\n  -amqpExchange string\n    \t[exif] AMQP Exchange Name ${SIMPLE_AMQP_EXCHANGE} (default \"fibr\")\n  -amqpRoutingKey string\n    \t[exif] AMQP Routing Key for exif ${SIMPLE_AMQP_ROUTING_KEY} (default \"exif_input\")\n  -directAccess\n    \t[exif] Use Exas with direct access to filesystem (no large file upload, send a GET request, Basic Auth recommended) ${SIMPLE_DIRECT_ACCESS}\n  -maxSize int\n    \t[exif] Max file size (in bytes) for extracting exif (0 to no limit). Not used if DirectAccess enabled. ${SIMPLE_MAX_SIZE} (default 209715200)\n  -password string\n    \t[exif] Exif Tool URL Basic Password ${SIMPLE_PASSWORD}\n  -uRL string\n    \t[exif] Exif Tool URL (exas) ${SIMPLE_URL} (default \"http://exas:1080\")\n  -user string\n    \t[exif] Exif Tool URL Basic User ${SIMPLE_USER}\n",
+			"Usage of simple:\n  -amqpExchange string\n    \t[exif] AMQP Exchange Name ${SIMPLE_AMQP_EXCHANGE} (default \"fibr\")\n  -amqpRoutingKey string\n    \t[exif] AMQP Routing Key for exif ${SIMPLE_AMQP_ROUTING_KEY} (default \"exif_input\")\n  -clusterDistance float\n    \t[exif] Max distance (in kilometers) between two pictures of the same event ${SIMPLE_CLUSTER_DISTANCE} (default 50)\n  -clusterGap duration\n    \t[exif] Max duration between two pictures of the same event ${SIMPLE_CLUSTER_GAP} (default 6h0m0s)\n  -directAccess\n    \t[exif] Use Exas with direct access to filesystem (no large file upload, send a GET request, Basic Auth recommended) ${SIMPLE_DIRECT_ACCESS}\n  -gpxMaxGap duration\n    \t[exif] Max duration between a picture and the GPX track points to geotag it ${SIMPLE_GPX_MAX_GAP} (default 5m0s)\n  -gpxOffset duration\n    \t[exif] Camera clock offset to the time of GPX tracks, e.g. 2h if the camera is set two hours ahead of UTC ${SIMPLE_GPX_OFFSET}\n  -local string\n    \t[exif] Extract embedded metadata without exas: disabled, primary (exas only if nothing found) or fallback (if exas is unavailable or fails) ${SIMPLE_LOCAL} (default \"fallback\")\n  -maxSize int\n    \t[exif] Max file size (in bytes) for extracting exif (0 to no limit). Not used if DirectAccess enabled. ${SIMPLE_MAX_SIZE} (default 209715200)\n  -password string\n    \t[exif] Exif Tool URL Basic Password ${SIMPLE_PASSWORD}\n  -sidecarWrite\n    \t[exif] Write tags, description and rating back to XMP sidecars ${SIMPLE_SIDECAR_WRITE}\n  -uRL string\n    \t[exif] Exif Tool URL (exas) ${SIMPLE_URL} (default \"http://exas:1080\")\n  -user string\n    \t[exif] Exif Tool URL Basic User ${SIMPLE_USER}\n",
 		},
 	}
 
@@ -50,6 +50,18 @@ func TestEnabled(t *testing.T) {
 			},
 			true,
 		},
+		"local only": {
+			Service{
+				local: localFallback,
+			},
+			true,
+		},
+		"local disabled": {
+			Service{
+				local: localDisabled,
+			},
+			false,
+		},
 	}
 
 	for intention, tc := range cases {
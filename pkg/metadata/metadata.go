@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
 	"github.com/ViBiOh/fibr/pkg/exclusive"
@@ -11,9 +12,14 @@ import (
 )
 
 func (s *Service) Update(ctx context.Context, item absto.Item, opts ...provider.MetadataAction) (provider.Metadata, error) {
-	var output provider.Metadata
+	return s.update(ctx, item, true, opts...)
+}
+
+// update applies the actions under the lock of the item. The XMP sidecar is written once the lock is released, unless the change comes from it
+func (s *Service) update(ctx context.Context, item absto.Item, exportSidecar bool, opts ...provider.MetadataAction) (provider.Metadata, error) {
+	var before, output provider.Metadata
 
-	return output, s.exclusive.Execute(ctx, "fibr:mutex:"+item.ID, exclusive.Duration, func(ctx context.Context) error {
+	err := s.exclusive.Execute(ctx, "fibr:mutex:"+item.ID, exclusive.Duration, func(ctx context.Context) error {
 		var err error
 
 		metadata, err := s.GetMetadataFor(ctx, item)
@@ -21,6 +27,9 @@ func (s *Service) Update(ctx context.Context, item absto.Item, opts ...provider.
 			slog.LogAttrs(ctx, slog.LevelError, "load metadata", slog.String("item", item.Pathname), slog.Any("error", err))
 		}
 
+		before = metadata
+		before.Tags = slices.Clone(metadata.Tags)
+
 		for _, opt := range opts {
 			metadata = opt(metadata)
 		}
@@ -29,8 +38,52 @@ func (s *Service) Update(ctx context.Context, item absto.Item, opts ...provider.
 			return fmt.Errorf("save metadata: %w", err)
 		}
 
+		s.updateTagIndex(ctx, item, before.Tags, metadata.Tags)
+
 		output = metadata
 
 		return nil
 	})
+	if err != nil {
+		return output, err
+	}
+
+	if exportSidecar {
+		s.syncSidecar(ctx, item, before, output)
+	}
+
+	return output, nil
+}
+
+// Edit applies manual changes to the given files, then refreshes their date and the aggregate of their directories
+func (s *Service) Edit(ctx context.Context, items []absto.Item, opts ...provider.MetadataAction) error {
+	var directories []string
+
+	for _, item := range items {
+		metadata, err := s.Update(ctx, item, opts...)
+		if err != nil {
+			return fmt.Errorf("update `%s`: %w", item.Pathname, err)
+		}
+
+		if err = s.updateDate(ctx, item, metadata); err != nil {
+			return fmt.Errorf("update date of `%s`: %w", item.Pathname, err)
+		}
+
+		if !slices.Contains(directories, item.Dir()) {
+			directories = append(directories, item.Dir())
+		}
+	}
+
+	for _, pathname := range directories {
+		dir, err := s.storage.Stat(ctx, pathname)
+		if err != nil {
+			return fmt.Errorf("get directory `%s`: %w", pathname, err)
+		}
+
+		if err = s.computeAndSaveAggregate(ctx, dir); err != nil {
+			return fmt.Errorf("compute aggregate of `%s`: %w", pathname, err)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,179 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/embedded"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const maxSidecarSize = 1 << 20
+
+// findSidecar looks for the sidecar of the item by its expected names, rather than listing the whole directory for each file
+func (s *Service) findSidecar(ctx context.Context, item absto.Item) (absto.Item, error) {
+	if !provider.CanHaveXMPSidecar(item) {
+		return absto.Item{}, absto.ErrNotExist(fmt.Errorf("no sidecar for `%s`", item.Pathname))
+	}
+
+	for _, name := range provider.XMPSidecarNames(item.Name()) {
+		for _, candidate := range []string{name, strings.TrimSuffix(name, provider.XMPExtension) + strings.ToUpper(provider.XMPExtension)} {
+			sidecar, err := s.storage.Stat(ctx, path.Join(item.Dir(), candidate))
+			if err == nil {
+				return sidecar, nil
+			}
+
+			if !absto.IsNotExist(err) {
+				return absto.Item{}, fmt.Errorf("stat sidecar: %w", err)
+			}
+		}
+	}
+
+	return absto.Item{}, absto.ErrNotExist(fmt.Errorf("no sidecar for `%s`", item.Pathname))
+}
+
+func (s *Service) readSidecar(ctx context.Context, sidecar absto.Item) ([]byte, error) {
+	reader, err := s.storage.ReadFrom(ctx, sidecar.Pathname)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+
+	defer provider.LogClose(ctx, reader, "metadata.readSidecar", sidecar.Pathname)
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxSidecarSize))
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	return content, nil
+}
+
+// importSidecar replaces tags and description of the item by the ones of its XMP sidecar, desktop tools are the reference when they write them
+func (s *Service) importSidecar(ctx context.Context, item absto.Item) error {
+	sidecar, err := s.findSidecar(ctx, item)
+	if err != nil {
+		if absto.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	content, err := s.readSidecar(ctx, sidecar)
+	if err != nil {
+		return fmt.Errorf("read sidecar: %w", err)
+	}
+
+	xmp, err := embedded.ParseXMP(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("parse sidecar `%s`: %w", sidecar.Pathname, err)
+	}
+
+	var opts []provider.MetadataAction
+
+	if len(xmp.Tags) != 0 {
+		opts = append(opts, provider.ReplaceTags(xmp.Tags))
+	}
+
+	if len(xmp.Description) != 0 {
+		opts = append(opts, provider.ReplaceDescription(xmp.Description))
+	}
+
+	if xmp.Rating != 0 {
+		opts = append(opts, provider.ReplaceRating(xmp.Rating))
+	}
+
+	if len(opts) == 0 {
+		return nil
+	}
+
+	// The sidecar is the source of the change, writing it back would only rewrite what was just read
+	if _, err = s.update(ctx, item, false, opts...); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// handleSidecarEvent imports a sidecar uploaded on its own (e.g. synced from a desktop tool) into the files it describes
+func (s *Service) handleSidecarEvent(ctx context.Context, sidecar absto.Item) error {
+	items, err := s.storage.List(ctx, sidecar.Dir())
+	if err != nil {
+		return fmt.Errorf("list siblings: %w", err)
+	}
+
+	for _, item := range items {
+		if candidate, ok := provider.XMPSidecarOf(item, items); !ok || candidate.Pathname != sidecar.Pathname {
+			continue
+		}
+
+		if err = s.importSidecar(ctx, item); err != nil {
+			return fmt.Errorf("import `%s`: %w", item.Pathname, err)
+		}
+	}
+
+	return nil
+}
+
+// exportSidecar writes tags, description and rating into the XMP sidecar of the item, creating one named after the full name of the item if needed
+func (s *Service) exportSidecar(ctx context.Context, item absto.Item, metadata provider.Metadata) error {
+	pathname := item.Pathname + provider.XMPExtension
+
+	var content []byte
+
+	sidecar, err := s.findSidecar(ctx, item)
+	switch {
+	case err == nil:
+		pathname = sidecar.Pathname
+
+		if content, err = s.readSidecar(ctx, sidecar); err != nil {
+			return fmt.Errorf("read sidecar: %w", err)
+		}
+
+	case !absto.IsNotExist(err):
+		return err
+
+	case len(metadata.Tags) == 0 && len(metadata.Description) == 0 && metadata.Rating == 0:
+		return nil
+	}
+
+	output, err := embedded.WriteXMP(content, metadata.Tags, metadata.Description, metadata.Rating)
+	if err != nil {
+		return fmt.Errorf("write xmp `%s`: %w", pathname, err)
+	}
+
+	if bytes.Equal(output, content) {
+		return nil
+	}
+
+	if err = s.storage.WriteTo(ctx, pathname, bytes.NewReader(output), absto.WriteOpts{Size: int64(len(output))}); err != nil {
+		return fmt.Errorf("write sidecar: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) shouldExportSidecar(item absto.Item, before, after provider.Metadata) bool {
+	if !s.sidecarWrite || item.IsDir() || strings.EqualFold(path.Ext(item.Pathname), provider.XMPExtension) {
+		return false
+	}
+
+	return before.Description != after.Description || before.Rating != after.Rating || !slices.Equal(before.Tags, after.Tags)
+}
+
+func (s *Service) syncSidecar(ctx context.Context, item absto.Item, before, after provider.Metadata) {
+	if !s.shouldExportSidecar(item, before, after) {
+		return
+	}
+
+	if err := s.exportSidecar(ctx, item, after); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "export sidecar", slog.String("item", item.Pathname), slog.Any("error", err))
+	}
+}
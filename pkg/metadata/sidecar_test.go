@@ -0,0 +1,125 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/mocks"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"go.uber.org/mock/gomock"
+)
+
+func TestShouldExportSidecar(t *testing.T) {
+	picture := absto.Item{Pathname: "/photos/IMG_1234.CR2", NameValue: "IMG_1234.CR2", Extension: ".cr2"}
+	before := provider.Metadata{Description: "Sunset", Tags: []string{"beach"}}
+
+	cases := map[string]struct {
+		instance Service
+		item     absto.Item
+		after    provider.Metadata
+		want     bool
+	}{
+		"disabled": {
+			Service{},
+			picture,
+			provider.Metadata{Description: "Sunrise", Tags: []string{"beach"}},
+			false,
+		},
+		"unchanged": {
+			Service{sidecarWrite: true},
+			picture,
+			provider.Metadata{Description: "Sunset", Tags: []string{"beach"}},
+			false,
+		},
+		"sidecar itself": {
+			Service{sidecarWrite: true},
+			absto.Item{Pathname: "/photos/IMG_1234.CR2.XMP", NameValue: "IMG_1234.CR2.XMP", Extension: ".xmp"},
+			provider.Metadata{Tags: []string{"sea"}},
+			false,
+		},
+		"description": {
+			Service{sidecarWrite: true},
+			picture,
+			provider.Metadata{Description: "Sunrise", Tags: []string{"beach"}},
+			true,
+		},
+		"tags": {
+			Service{sidecarWrite: true},
+			picture,
+			provider.Metadata{Description: "Sunset", Tags: []string{"beach", "sea"}},
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := tc.instance.shouldExportSidecar(tc.item, before, tc.after); got != tc.want {
+				t.Errorf("shouldExportSidecar() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFindSidecar(t *testing.T) {
+	picture := absto.Item{Pathname: "/photos/IMG_1234.CR2", NameValue: "IMG_1234.CR2", Extension: ".cr2"}
+
+	cases := map[string]struct {
+		item      absto.Item
+		existing  []string
+		wantStats int
+		want      string
+	}{
+		"not a media": {
+			absto.Item{Pathname: "/photos/notes.txt", NameValue: "notes.txt", Extension: ".txt"},
+			[]string{"/photos/notes.txt.xmp"},
+			0,
+			"",
+		},
+		"full name": {
+			picture,
+			[]string{"/photos/IMG_1234.CR2.xmp", "/photos/IMG_1234.xmp"},
+			1,
+			"/photos/IMG_1234.CR2.xmp",
+		},
+		"base name in uppercase": {
+			picture,
+			[]string{"/photos/IMG_1234.XMP"},
+			4,
+			"/photos/IMG_1234.XMP",
+		},
+		"none": {
+			picture,
+			nil,
+			4,
+			"",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			mockStorage := mocks.NewStorage(ctrl)
+			mockStorage.EXPECT().Stat(gomock.Any(), gomock.Any()).Times(tc.wantStats).DoAndReturn(func(_ context.Context, pathname string) (absto.Item, error) {
+				for _, existing := range tc.existing {
+					if existing == pathname {
+						return absto.Item{Pathname: pathname, Extension: provider.XMPExtension}, nil
+					}
+				}
+
+				return absto.Item{}, absto.ErrNotExist(errors.New("sidecar"))
+			})
+
+			got, err := (&Service{storage: mockStorage}).findSidecar(context.TODO(), tc.item)
+			if err != nil && !absto.IsNotExist(err) {
+				t.Errorf("findSidecar() = %s", err)
+			}
+
+			if got.Pathname != tc.want {
+				t.Errorf("findSidecar() = `%s`, want `%s`", got.Pathname, tc.want)
+			}
+		})
+	}
+}
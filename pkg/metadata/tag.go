@@ -0,0 +1,188 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const (
+	tagIndexFilename = provider.MetadataDirectoryName + "/tags.json"
+	tagIndexDuration = time.Minute
+)
+
+// TagIndex returns the pathnames of the files having each tag, building it on first use
+func (s *Service) TagIndex(ctx context.Context) (provider.TagIndex, error) {
+	index, err := provider.LoadJSON[provider.TagIndex](ctx, s.storage, tagIndexFilename)
+	if err == nil {
+		return index, nil
+	}
+
+	if !absto.IsNotExist(err) {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	err = s.editTagIndex(ctx, func(provider.TagIndex) bool { return false })
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.LoadJSON[provider.TagIndex](ctx, s.storage, tagIndexFilename)
+}
+
+// RenameTag replaces a tag by another one on every file, merging them if the new one already exists. It returns the number of files updated.
+func (s *Service) RenameTag(ctx context.Context, old, new string) (int, error) {
+	if old == new {
+		return 0, nil
+	}
+
+	return s.rewriteTag(ctx, old, func(metadata provider.Metadata) provider.Metadata {
+		metadata.Tags = provider.RenameTag(metadata.Tags, old, new)
+		return metadata
+	})
+}
+
+// DeleteTag removes a tag from every file. It returns the number of files updated.
+func (s *Service) DeleteTag(ctx context.Context, tag string) (int, error) {
+	return s.rewriteTag(ctx, tag, func(metadata provider.Metadata) provider.Metadata {
+		metadata.Tags = provider.RenameTag(metadata.Tags, tag, "")
+		return metadata
+	})
+}
+
+func (s *Service) rewriteTag(ctx context.Context, tag string, action provider.MetadataAction) (int, error) {
+	index, err := s.TagIndex(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get index: %w", err)
+	}
+
+	var count int
+
+	for _, pathname := range index[tag] {
+		item, err := s.storage.Stat(ctx, pathname)
+		if err != nil {
+			if absto.IsNotExist(err) {
+				slog.LogAttrs(ctx, slog.LevelWarn, "tagged file not found", slog.String("item", pathname))
+				continue
+			}
+
+			return count, fmt.Errorf("get `%s`: %w", pathname, err)
+		}
+
+		if _, err = s.Update(ctx, item, action); err != nil {
+			return count, fmt.Errorf("update `%s`: %w", pathname, err)
+		}
+
+		count++
+	}
+
+	if err = s.editTagIndex(ctx, func(index provider.TagIndex) bool {
+		_, found := index[tag]
+		delete(index, tag)
+
+		return found
+	}); err != nil {
+		return count, fmt.Errorf("clean index: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *Service) updateTagIndex(ctx context.Context, item absto.Item, before, after []string) {
+	if slices.Equal(before, after) {
+		return
+	}
+
+	if err := s.editTagIndex(ctx, func(index provider.TagIndex) bool {
+		return index.Update(item.Pathname, before, after)
+	}); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "update tag index", slog.String("item", item.Pathname), slog.Any("error", err))
+	}
+}
+
+func (s *Service) moveInTagIndex(ctx context.Context, old, new absto.Item) {
+	if err := s.editTagIndex(ctx, func(index provider.TagIndex) bool {
+		return index.Move(old.Pathname, new.Pathname)
+	}); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "move in tag index", slog.String("item", old.Pathname), slog.Any("error", err))
+	}
+}
+
+func (s *Service) editTagIndex(ctx context.Context, change func(provider.TagIndex) bool) error {
+	s.tagMutex.Lock()
+	defer s.tagMutex.Unlock()
+
+	return s.exclusive.Execute(ctx, "fibr:mutex:tags", tagIndexDuration, func(ctx context.Context) error {
+		index, err := provider.LoadJSON[provider.TagIndex](ctx, s.storage, tagIndexFilename)
+		changed := false
+
+		if err != nil {
+			if !absto.IsNotExist(err) {
+				return fmt.Errorf("load: %w", err)
+			}
+
+			if index, err = s.buildTagIndex(ctx); err != nil {
+				return fmt.Errorf("build: %w", err)
+			}
+
+			changed = true
+		}
+
+		if !change(index) && !changed {
+			return nil
+		}
+
+		if err = provider.SaveJSON(ctx, s.storage, tagIndexFilename, index); err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *Service) buildTagIndex(ctx context.Context) (provider.TagIndex, error) {
+	index := make(provider.TagIndex)
+
+	var files []absto.Item
+
+	walkStorage := s.storage.WithIgnoreFn(func(item absto.Item) bool {
+		return strings.HasPrefix(item.Pathname, provider.MetadataDirectoryName)
+	})
+
+	if err := walkStorage.Walk(ctx, "/", func(item absto.Item) error {
+		if !item.IsDir() {
+			files = append(files, item)
+		}
+
+		return nil
+	}); err != nil {
+		return index, fmt.Errorf("walk: %w", err)
+	}
+
+	metadatas, err := s.GetAllMetadataFor(ctx, files...)
+	if err != nil {
+		return index, fmt.Errorf("get metadatas: %w", err)
+	}
+
+	for _, file := range files {
+		if metadata, ok := metadatas[file.ID]; ok {
+			index.Update(file.Pathname, nil, metadata.Tags)
+		}
+	}
+
+	if _, err = s.storage.Stat(ctx, provider.MetadataDirectoryName); absto.IsNotExist(err) {
+		if err = s.storage.Mkdir(ctx, provider.MetadataDirectoryName, absto.DirectoryPerm); err != nil {
+			return index, fmt.Errorf("create dir: %w", err)
+		}
+	}
+
+	slog.LogAttrs(ctx, slog.LevelInfo, "tag index built", slog.Int("files", len(files)), slog.Int("tags", len(index)))
+
+	return index, nil
+}
@@ -0,0 +1,117 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/gpx"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func (s *Service) readTrack(ctx context.Context, item absto.Item) (gpx.Track, error) {
+	reader, err := s.storage.ReadFrom(ctx, item.Pathname)
+	if err != nil {
+		return gpx.Track{}, fmt.Errorf("open: %w", err)
+	}
+
+	defer provider.LogClose(ctx, reader, "metadata.readTrack", item.Pathname)
+
+	track, err := gpx.Parse(io.LimitReader(reader, gpx.MaxSize))
+	if err != nil {
+		return gpx.Track{}, fmt.Errorf("parse `%s`: %w", item.Pathname, err)
+	}
+
+	return track, nil
+}
+
+// Geotag sets the location of the files in the directory of the track from their capture date, offset by the camera clock drift. It returns the number of files geotagged.
+func (s *Service) Geotag(ctx context.Context, item absto.Item, offset time.Duration) (int, error) {
+	track, err := s.readTrack(ctx, item)
+	if err != nil {
+		return 0, fmt.Errorf("read track: %w", err)
+	}
+
+	items, err := s.storage.List(ctx, item.Dir())
+	if err != nil {
+		return 0, fmt.Errorf("list siblings: %w", err)
+	}
+
+	files := provider.KeepOnlyFile(items)
+
+	metadatas, err := s.GetAllMetadataFor(ctx, files...)
+	if err != nil {
+		return 0, fmt.Errorf("get metadatas: %w", err)
+	}
+
+	var count int
+
+	for _, file := range files {
+		metadata, ok := metadatas[file.ID]
+		if !ok || !metadata.CanBeGeotagged() {
+			continue
+		}
+
+		latitude, longitude, ok := track.Locate(metadata.Date.Add(-offset), s.gpxMaxGap)
+		if !ok {
+			continue
+		}
+
+		if _, err = s.Update(ctx, file, provider.ReplaceTrackLocation(latitude, longitude, item.Pathname)); err != nil {
+			return count, fmt.Errorf("update `%s`: %w", file.Pathname, err)
+		}
+
+		count++
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+
+	if err = s.aggregate(ctx, item); err != nil {
+		return count, fmt.Errorf("aggregate: %w", err)
+	}
+
+	return count, nil
+}
+
+// geotagFromTracks locates a file with the tracks already present in its directory, for files uploaded after their track
+func (s *Service) geotagFromTracks(ctx context.Context, item absto.Item, metadata provider.Metadata) (provider.Metadata, error) {
+	if !metadata.CanBeGeotagged() || len(metadata.Track) != 0 {
+		return metadata, nil
+	}
+
+	items, err := s.storage.List(ctx, item.Dir())
+	if err != nil {
+		return metadata, fmt.Errorf("list siblings: %w", err)
+	}
+
+	for _, candidate := range items {
+		if candidate.IsDir() || candidate.Extension != gpx.Extension {
+			continue
+		}
+
+		track, err := s.readTrack(ctx, candidate)
+		if err != nil {
+			slog.LogAttrs(ctx, slog.LevelWarn, "read track", slog.String("item", candidate.Pathname), slog.Any("error", err))
+			continue
+		}
+
+		latitude, longitude, ok := track.Locate(metadata.Date.Add(-s.gpxOffset), s.gpxMaxGap)
+		if !ok {
+			continue
+		}
+
+		updated, err := s.Update(ctx, item, provider.ReplaceTrackLocation(latitude, longitude, candidate.Pathname))
+		if err != nil {
+			return metadata, fmt.Errorf("update: %w", err)
+		}
+
+		return updated, nil
+	}
+
+	return metadata, nil
+}
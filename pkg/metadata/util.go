@@ -6,11 +6,20 @@ import (
 	"path/filepath"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/embedded"
 	"github.com/ViBiOh/fibr/pkg/provider"
 )
 
 func (s *Service) CanHaveExif(item absto.Item) bool {
-	return provider.ThumbnailExtensions[item.Extension] && (s.maxSize == 0 || item.Size() < s.maxSize || s.directAccess)
+	return s.canUseExas(item) || s.canUseLocal(item)
+}
+
+func (s *Service) canUseExas(item absto.Item) bool {
+	return !s.exifRequest.IsZero() && provider.ThumbnailExtensions[item.Extension] && (s.maxSize == 0 || item.Size() < s.maxSize || s.directAccess)
+}
+
+func (s *Service) canUseLocal(item absto.Item) bool {
+	return s.localEnabled() && embedded.Supported(item.Extension)
 }
 
 func Path(item absto.Item) string {
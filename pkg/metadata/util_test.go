@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/httputils/v4/pkg/request"
 )
 
 func TestGetExifPath(t *testing.T) {
@@ -43,3 +44,45 @@ func TestGetExifPath(t *testing.T) {
 		})
 	}
 }
+
+func TestCanHaveExif(t *testing.T) {
+	cases := map[string]struct {
+		instance Service
+		item     absto.Item
+		want     bool
+	}{
+		"exas": {
+			Service{exifRequest: request.New().URL("http://127.0.0.1")},
+			absto.Item{Extension: ".jpg"},
+			true,
+		},
+		"exas too large": {
+			Service{exifRequest: request.New().URL("http://127.0.0.1"), maxSize: 10},
+			absto.Item{Extension: ".jpg", SizeValue: 20},
+			false,
+		},
+		"local too large": {
+			Service{exifRequest: request.New().URL("http://127.0.0.1"), maxSize: 10, local: localFallback},
+			absto.Item{Extension: ".jpg", SizeValue: 20},
+			true,
+		},
+		"local raw": {
+			Service{local: localPrimary},
+			absto.Item{Extension: ".nef"},
+			true,
+		},
+		"local unsupported": {
+			Service{local: localPrimary},
+			absto.Item{Extension: ".mp4"},
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := tc.instance.CanHaveExif(tc.item); got != tc.want {
+				t.Errorf("CanHaveExif() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -source interfaces.go -destination ../mocks/interfaces.go -package mocks -mock_names Crud=Crud,Auth=Auth,ShareManager=ShareManager,WebhookManager=WebhookManager
+//	mockgen -source interfaces.go -destination ../mocks/interfaces.go -package mocks -mock_names Crud=Crud,Auth=Auth,ShareManager=ShareManager,WebhookManager=WebhookManager,AlbumManager=AlbumManager
 //
 
 // Package mocks is a generated GoMock package.
@@ -15,7 +15,8 @@ import (
 	reflect "reflect"
 	time "time"
 
-	model "github.com/ViBiOh/auth/v3/pkg/model"
+	model "github.com/ViBiOh/absto/pkg/model"
+	model0 "github.com/ViBiOh/auth/v3/pkg/model"
 	provider "github.com/ViBiOh/fibr/pkg/provider"
 	renderer "github.com/ViBiOh/httputils/v4/pkg/renderer"
 	gomock "go.uber.org/mock/gomock"
@@ -133,10 +134,10 @@ func (m *Auth) EXPECT() *AuthMockRecorder {
 }
 
 // GetBasicUser mocks base method.
-func (m *Auth) GetBasicUser(ctx context.Context, login, password string) (model.User, error) {
+func (m *Auth) GetBasicUser(ctx context.Context, login, password string) (model0.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetBasicUser", ctx, login, password)
-	ret0, _ := ret[0].(model.User)
+	ret0, _ := ret[0].(model0.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -148,7 +149,7 @@ func (mr *AuthMockRecorder) GetBasicUser(ctx, login, password any) *gomock.Call
 }
 
 // IsAuthorized mocks base method.
-func (m *Auth) IsAuthorized(arg0 context.Context, arg1 model.User, arg2 string) bool {
+func (m *Auth) IsAuthorized(arg0 context.Context, arg1 model0.User, arg2 string) bool {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "IsAuthorized", arg0, arg1, arg2)
 	ret0, _ := ret[0].(bool)
@@ -281,18 +282,34 @@ func (m *WebhookManager) EXPECT() *WebhookManagerMockRecorder {
 }
 
 // Create mocks base method.
-func (m *WebhookManager) Create(arg0 context.Context, arg1 string, arg2 bool, arg3 provider.WebhookKind, arg4 string, arg5 []provider.EventType) (string, error) {
+func (m *WebhookManager) Create(arg0 context.Context, arg1 provider.Webhook) (string, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create.
+func (mr *WebhookManagerMockRecorder) Create(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*WebhookManager)(nil).Create), arg0, arg1)
+}
+
+// Deliveries mocks base method.
+func (m *WebhookManager) Deliveries(arg0 context.Context) (map[string]provider.WebhookLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", arg0)
+	ret0, _ := ret[0].(map[string]provider.WebhookLog)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Create indicates an expected call of Create.
-func (mr *WebhookManagerMockRecorder) Create(arg0, arg1, arg2, arg3, arg4, arg5 any) *gomock.Call {
+// Deliveries indicates an expected call of Deliveries.
+func (mr *WebhookManagerMockRecorder) Deliveries(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*WebhookManager)(nil).Create), arg0, arg1, arg2, arg3, arg4, arg5)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*WebhookManager)(nil).Deliveries), arg0)
 }
 
 // Delete mocks base method.
@@ -323,6 +340,21 @@ func (mr *WebhookManagerMockRecorder) FindByURL(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByURL", reflect.TypeOf((*WebhookManager)(nil).FindByURL), arg0)
 }
 
+// Fire mocks base method.
+func (m *WebhookManager) Fire(arg0 context.Context, arg1 string, arg2 provider.Event) (provider.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fire", arg0, arg1, arg2)
+	ret0, _ := ret[0].(provider.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fire indicates an expected call of Fire.
+func (mr *WebhookManagerMockRecorder) Fire(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fire", reflect.TypeOf((*WebhookManager)(nil).Fire), arg0, arg1, arg2)
+}
+
 // Get mocks base method.
 func (m *WebhookManager) Get(arg0 string) provider.Webhook {
 	m.ctrl.T.Helper()
@@ -350,3 +382,182 @@ func (mr *WebhookManagerMockRecorder) List() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*WebhookManager)(nil).List))
 }
+
+// Replay mocks base method.
+func (m *WebhookManager) Replay(arg0 context.Context, arg1, arg2 string) (provider.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replay", arg0, arg1, arg2)
+	ret0, _ := ret[0].(provider.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replay indicates an expected call of Replay.
+func (mr *WebhookManagerMockRecorder) Replay(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replay", reflect.TypeOf((*WebhookManager)(nil).Replay), arg0, arg1, arg2)
+}
+
+// Rotate mocks base method.
+func (m *WebhookManager) Rotate(arg0 context.Context, arg1 string, arg2 time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *WebhookManagerMockRecorder) Rotate(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*WebhookManager)(nil).Rotate), arg0, arg1, arg2)
+}
+
+// Update mocks base method.
+func (m *WebhookManager) Update(arg0 context.Context, arg1 provider.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *WebhookManagerMockRecorder) Update(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*WebhookManager)(nil).Update), arg0, arg1)
+}
+
+// AlbumManager is a mock of AlbumManager interface.
+type AlbumManager struct {
+	ctrl     *gomock.Controller
+	recorder *AlbumManagerMockRecorder
+	isgomock struct{}
+}
+
+// AlbumManagerMockRecorder is the mock recorder for AlbumManager.
+type AlbumManagerMockRecorder struct {
+	mock *AlbumManager
+}
+
+// NewAlbumManager creates a new mock instance.
+func NewAlbumManager(ctrl *gomock.Controller) *AlbumManager {
+	mock := &AlbumManager{ctrl: ctrl}
+	mock.recorder = &AlbumManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *AlbumManager) EXPECT() *AlbumManagerMockRecorder {
+	return m.recorder
+}
+
+// AddFiles mocks base method.
+func (m *AlbumManager) AddFiles(arg0 context.Context, arg1 string, arg2 ...model.Item) (int, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "AddFiles", varargs...)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddFiles indicates an expected call of AddFiles.
+func (mr *AlbumManagerMockRecorder) AddFiles(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFiles", reflect.TypeOf((*AlbumManager)(nil).AddFiles), varargs...)
+}
+
+// Create mocks base method.
+func (m *AlbumManager) Create(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *AlbumManagerMockRecorder) Create(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*AlbumManager)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *AlbumManager) Delete(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *AlbumManagerMockRecorder) Delete(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*AlbumManager)(nil).Delete), arg0, arg1)
+}
+
+// Get mocks base method.
+func (m *AlbumManager) Get(arg0 string) provider.Album {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(provider.Album)
+	return ret0
+}
+
+// Get indicates an expected call of Get.
+func (mr *AlbumManagerMockRecorder) Get(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*AlbumManager)(nil).Get), arg0)
+}
+
+// List mocks base method.
+func (m *AlbumManager) List() []provider.Album {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]provider.Album)
+	return ret0
+}
+
+// List indicates an expected call of List.
+func (mr *AlbumManagerMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*AlbumManager)(nil).List))
+}
+
+// RemoveFiles mocks base method.
+func (m *AlbumManager) RemoveFiles(arg0 context.Context, arg1 string, arg2 ...string) (int, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveFiles", varargs...)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveFiles indicates an expected call of RemoveFiles.
+func (mr *AlbumManagerMockRecorder) RemoveFiles(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFiles", reflect.TypeOf((*AlbumManager)(nil).RemoveFiles), varargs...)
+}
+
+// UpdateName mocks base method.
+func (m *AlbumManager) UpdateName(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateName", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateName indicates an expected call of UpdateName.
+func (mr *AlbumManagerMockRecorder) UpdateName(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateName", reflect.TypeOf((*AlbumManager)(nil).UpdateName), arg0, arg1, arg2)
+}
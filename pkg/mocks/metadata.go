@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	model "github.com/ViBiOh/absto/pkg/model"
 	provider "github.com/ViBiOh/fibr/pkg/provider"
@@ -42,6 +43,69 @@ func (m *MetadataManager) EXPECT() *MetadataManagerMockRecorder {
 	return m.recorder
 }
 
+// Clusters mocks base method.
+func (m *MetadataManager) Clusters(items []model.Item, metadatas map[string]provider.Metadata) []provider.Cluster {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clusters", items, metadatas)
+	ret0, _ := ret[0].([]provider.Cluster)
+	return ret0
+}
+
+// Clusters indicates an expected call of Clusters.
+func (mr *MetadataManagerMockRecorder) Clusters(items, metadatas any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clusters", reflect.TypeOf((*MetadataManager)(nil).Clusters), items, metadatas)
+}
+
+// DeleteTag mocks base method.
+func (m *MetadataManager) DeleteTag(ctx context.Context, tag string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", ctx, tag)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MetadataManagerMockRecorder) DeleteTag(ctx, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MetadataManager)(nil).DeleteTag), ctx, tag)
+}
+
+// Edit mocks base method.
+func (m *MetadataManager) Edit(ctx context.Context, items []model.Item, opts ...provider.MetadataAction) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, items}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Edit", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Edit indicates an expected call of Edit.
+func (mr *MetadataManagerMockRecorder) Edit(ctx, items any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, items}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Edit", reflect.TypeOf((*MetadataManager)(nil).Edit), varargs...)
+}
+
+// Geotag mocks base method.
+func (m *MetadataManager) Geotag(ctx context.Context, track model.Item, offset time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Geotag", ctx, track, offset)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Geotag indicates an expected call of Geotag.
+func (mr *MetadataManagerMockRecorder) Geotag(ctx, track, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Geotag", reflect.TypeOf((*MetadataManager)(nil).Geotag), ctx, track, offset)
+}
+
 // GetAggregateFor mocks base method.
 func (m *MetadataManager) GetAggregateFor(ctx context.Context, item model.Item) (provider.Aggregate, error) {
 	m.ctrl.T.Helper()
@@ -127,6 +191,21 @@ func (mr *MetadataManagerMockRecorder) ListDir(ctx, item any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDir", reflect.TypeOf((*MetadataManager)(nil).ListDir), ctx, item)
 }
 
+// RenameTag mocks base method.
+func (m *MetadataManager) RenameTag(ctx context.Context, old, new string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameTag", ctx, old, new)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameTag indicates an expected call of RenameTag.
+func (mr *MetadataManagerMockRecorder) RenameTag(ctx, old, new any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameTag", reflect.TypeOf((*MetadataManager)(nil).RenameTag), ctx, old, new)
+}
+
 // SaveAggregateFor mocks base method.
 func (m *MetadataManager) SaveAggregateFor(ctx context.Context, item model.Item, aggregate provider.Aggregate) error {
 	m.ctrl.T.Helper()
@@ -141,6 +220,21 @@ func (mr *MetadataManagerMockRecorder) SaveAggregateFor(ctx, item, aggregate any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAggregateFor", reflect.TypeOf((*MetadataManager)(nil).SaveAggregateFor), ctx, item, aggregate)
 }
 
+// TagIndex mocks base method.
+func (m *MetadataManager) TagIndex(ctx context.Context) (provider.TagIndex, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagIndex", ctx)
+	ret0, _ := ret[0].(provider.TagIndex)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagIndex indicates an expected call of TagIndex.
+func (mr *MetadataManagerMockRecorder) TagIndex(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagIndex", reflect.TypeOf((*MetadataManager)(nil).TagIndex), ctx)
+}
+
 // Update mocks base method.
 func (m *MetadataManager) Update(ctx context.Context, item model.Item, opts ...provider.MetadataAction) (provider.Metadata, error) {
 	m.ctrl.T.Helper()
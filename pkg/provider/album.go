@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+const AlbumsPath = "/albums/"
+
+// Album is a curated collection of files, referenced by item ID, from any folder
+type Album struct {
+	Created time.Time   `json:"created"`
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Files   []AlbumFile `json:"files"`
+}
+
+type AlbumFile struct {
+	ID       string `json:"id"`
+	Pathname string `json:"pathname"`
+}
+
+func (a Album) IsZero() bool {
+	return len(a.ID) == 0
+}
+
+// Path of the virtual folder of the album
+func (a Album) Path() string {
+	return AlbumsPath + a.ID + "/"
+}
+
+func (a Album) Find(id string) (AlbumFile, bool) {
+	for _, file := range a.Files {
+		if file.ID == id {
+			return file, true
+		}
+	}
+
+	return AlbumFile{}, false
+}
+
+// Add appends the files not already in the album, it returns the number of added files
+func (a *Album) Add(items ...absto.Item) int {
+	var count int
+
+	for _, item := range items {
+		if _, ok := a.Find(item.ID); ok {
+			continue
+		}
+
+		a.Files = append(a.Files, AlbumFile{ID: item.ID, Pathname: item.Pathname})
+		count++
+	}
+
+	return count
+}
+
+// Remove deletes the files with the given IDs, it returns the number of removed files
+func (a *Album) Remove(ids ...string) int {
+	previous := len(a.Files)
+
+	a.Files = slices.DeleteFunc(a.Files, func(file AlbumFile) bool {
+		return slices.Contains(ids, file.ID)
+	})
+
+	return previous - len(a.Files)
+}
+
+// RemoveItem deletes the file, or every file under it for a directory, it returns true if the album changed
+func (a *Album) RemoveItem(item absto.Item) bool {
+	previous := len(a.Files)
+
+	a.Files = slices.DeleteFunc(a.Files, func(file AlbumFile) bool {
+		if item.IsDir() {
+			return strings.HasPrefix(file.Pathname, Dirname(item.Pathname))
+		}
+
+		return file.ID == item.ID
+	})
+
+	return previous != len(a.Files)
+}
+
+// Rename updates the reference to a renamed file, it returns true if the album changed
+func (a *Album) Rename(old, new absto.Item) bool {
+	for index, file := range a.Files {
+		if file.ID == old.ID {
+			a.Files[index] = AlbumFile{ID: new.ID, Pathname: new.Pathname}
+			return true
+		}
+	}
+
+	return false
+}
+
+// AlbumFileName is the name of a file inside the virtual folder of an album, prefixed by its ID because files come from several folders
+func AlbumFileName(item absto.Item) string {
+	return item.ID + "-" + item.Name()
+}
+
+// ParseAlbumFileName returns the item ID and the name of a file inside the virtual folder of an album
+func ParseAlbumFileName(value string) (id, name string, ok bool) {
+	return strings.Cut(value, "-")
+}
+
+// IsAlbumCompanion checks if the name is a companion or subtitle of the album file, sharing its name without extension
+func IsAlbumCompanion(file AlbumFile, name string) bool {
+	base := path.Base(file.Pathname)
+
+	return name != base && strings.HasPrefix(name, strings.TrimSuffix(base, path.Ext(base))+".")
+}
@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+func newTestAlbum() Album {
+	return Album{
+		ID:   "8000cafe",
+		Name: "Holidays",
+		Files: []AlbumFile{
+			{ID: "1", Pathname: "/travel/rome.jpg"},
+			{ID: "2", Pathname: "/travel/venice/gondola.jpg"},
+			{ID: "3", Pathname: "/pets/garfield.jpg"},
+		},
+	}
+}
+
+func TestAlbumAdd(t *testing.T) {
+	cases := map[string]struct {
+		items     []absto.Item
+		want      int
+		wantFiles int
+	}{
+		"new": {
+			[]absto.Item{{ID: "4", Pathname: "/travel/paris.jpg"}},
+			1,
+			4,
+		},
+		"already present": {
+			[]absto.Item{{ID: "1", Pathname: "/travel/rome.jpg"}, {ID: "4", Pathname: "/travel/paris.jpg"}},
+			1,
+			4,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			album := newTestAlbum()
+
+			if got := album.Add(tc.items...); got != tc.want {
+				t.Errorf("Add() = %d, want %d", got, tc.want)
+			}
+
+			if got := len(album.Files); got != tc.wantFiles {
+				t.Errorf("Add() files = %d, want %d", got, tc.wantFiles)
+			}
+		})
+	}
+}
+
+func TestAlbumRemoveItem(t *testing.T) {
+	cases := map[string]struct {
+		item  absto.Item
+		want  bool
+		files []string
+	}{
+		"file": {
+			absto.Item{ID: "1", Pathname: "/travel/rome.jpg"},
+			true,
+			[]string{"2", "3"},
+		},
+		"directory": {
+			absto.Item{Pathname: "/travel/", IsDirValue: true},
+			true,
+			[]string{"3"},
+		},
+		"directory without trailing slash": {
+			absto.Item{Pathname: "/travel/venice", IsDirValue: true},
+			true,
+			[]string{"1", "3"},
+		},
+		"sibling with the same prefix": {
+			absto.Item{Pathname: "/trav", IsDirValue: true},
+			false,
+			[]string{"1", "2", "3"},
+		},
+		"unknown": {
+			absto.Item{ID: "4", Pathname: "/travel/paris.jpg"},
+			false,
+			[]string{"1", "2", "3"},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			album := newTestAlbum()
+
+			if got := album.RemoveItem(tc.item); got != tc.want {
+				t.Errorf("RemoveItem() = %t, want %t", got, tc.want)
+			}
+
+			var files []string
+			for _, file := range album.Files {
+				files = append(files, file.ID)
+			}
+
+			if !reflect.DeepEqual(files, tc.files) {
+				t.Errorf("RemoveItem() files = %v, want %v", files, tc.files)
+			}
+		})
+	}
+}
+
+func TestAlbumRename(t *testing.T) {
+	cases := map[string]struct {
+		old  absto.Item
+		new  absto.Item
+		want bool
+	}{
+		"present": {
+			absto.Item{ID: "3", Pathname: "/pets/garfield.jpg"},
+			absto.Item{ID: "5", Pathname: "/pets/cats/garfield.jpg"},
+			true,
+		},
+		"absent": {
+			absto.Item{ID: "4", Pathname: "/pets/tom.jpg"},
+			absto.Item{ID: "6", Pathname: "/pets/cats/tom.jpg"},
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			album := newTestAlbum()
+
+			if got := album.Rename(tc.old, tc.new); got != tc.want {
+				t.Errorf("Rename() = %t, want %t", got, tc.want)
+			}
+
+			if _, ok := album.Find(tc.new.ID); ok != tc.want {
+				t.Errorf("Rename() found = %t, want %t", ok, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseAlbumFileName(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		wantID   string
+		wantName string
+		wantOk   bool
+	}{
+		"simple": {
+			"8000cafe-IMG_0001.jpg",
+			"8000cafe",
+			"IMG_0001.jpg",
+			true,
+		},
+		"dash in name": {
+			"8000cafe-my-cat.jpg",
+			"8000cafe",
+			"my-cat.jpg",
+			true,
+		},
+		"no id": {
+			"IMG_0001.jpg",
+			"IMG_0001.jpg",
+			"",
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			id, name, ok := ParseAlbumFileName(tc.value)
+			if id != tc.wantID || name != tc.wantName || ok != tc.wantOk {
+				t.Errorf("ParseAlbumFileName() = (`%s`, `%s`, %t), want (`%s`, `%s`, %t)", id, name, ok, tc.wantID, tc.wantName, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestIsAlbumCompanion(t *testing.T) {
+	file := AlbumFile{ID: "1", Pathname: "/travel/IMG_0001.HEIC"}
+
+	cases := map[string]struct {
+		name string
+		want bool
+	}{
+		"live photo": {
+			"IMG_0001.MOV",
+			true,
+		},
+		"itself": {
+			"IMG_0001.HEIC",
+			false,
+		},
+		"other": {
+			"IMG_0002.MOV",
+			false,
+		},
+		"same prefix": {
+			"IMG_00010.MOV",
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := IsAlbumCompanion(file, tc.name); got != tc.want {
+				t.Errorf("IsAlbumCompanion() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+)
+
+// Broker dispatches messages to the listeners of their key, through Redis when it's enabled so that the listeners of every instance receive them
+type Broker[T any] struct {
+	redisClient redis.Client
+	listeners   map[string]map[chan T]struct{}
+	done        chan struct{}
+	keyOf       func(T) string
+	name        string
+	channel     string
+	buffer      int
+	mutex       sync.RWMutex
+}
+
+func NewBroker[T any](name string, redisClient redis.Client, channel string, buffer int, keyOf func(T) string) *Broker[T] {
+	return &Broker[T]{
+		name:        name,
+		redisClient: redisClient,
+		channel:     channel,
+		buffer:      buffer,
+		keyOf:       keyOf,
+		listeners:   make(map[string]map[chan T]struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+func (b *Broker[T]) Done() <-chan struct{} {
+	return b.done
+}
+
+func (b *Broker[T]) Start(ctx context.Context) {
+	defer close(b.done)
+
+	redis.SubscribeFor(ctx, b.redisClient, b.channel, b.pubSubHandle)
+
+	// Without Redis, the subscription ends right away but the listeners are released on done
+	<-ctx.Done()
+}
+
+// Subscribe registers a listener of the messages of the key, until the returned func is called
+func (b *Broker[T]) Subscribe(key string) (<-chan T, func()) {
+	listener := make(chan T, b.buffer)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.listeners[key] == nil {
+		b.listeners[key] = make(map[chan T]struct{})
+	}
+
+	b.listeners[key][listener] = struct{}{}
+
+	return listener, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		delete(b.listeners[key], listener)
+		if len(b.listeners[key]) == 0 {
+			delete(b.listeners, key)
+		}
+	}
+}
+
+// Notify sends the message to the listeners of every instance when Redis is enabled, to the local ones otherwise
+func (b *Broker[T]) Notify(ctx context.Context, message T) error {
+	if !b.redisClient.Enabled() {
+		b.dispatch(message)
+		return nil
+	}
+
+	return b.redisClient.PublishJSON(ctx, b.channel, message)
+}
+
+func (b *Broker[T]) pubSubHandle(message T, err error) {
+	if err != nil {
+		slog.LogAttrs(context.Background(), slog.LevelError, "broker's PubSub", slog.String("name", b.name), slog.Any("error", err))
+		return
+	}
+
+	b.dispatch(message)
+}
+
+// dispatch never blocks: a listener too slow to read its messages misses them
+func (b *Broker[T]) dispatch(message T) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for listener := range b.listeners[b.keyOf(message)] {
+		select {
+		case listener <- message:
+		default:
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+)
+
+type brokerMessage struct {
+	Key   string
+	Value int
+}
+
+func TestBrokerNotify(t *testing.T) {
+	broker := NewBroker("test", redis.Noop{}, "test-channel", 1, func(message brokerMessage) string {
+		return message.Key
+	})
+
+	photos, unsubscribePhotos := broker.Subscribe("/photos/")
+	defer unsubscribePhotos()
+
+	archives, unsubscribeArchives := broker.Subscribe("/archives/")
+	unsubscribeArchives()
+
+	for value := range 2 {
+		if err := broker.Notify(context.Background(), brokerMessage{Key: "/photos/", Value: value}); err != nil {
+			t.Errorf("Notify() = %s", err)
+		}
+	}
+
+	if err := broker.Notify(context.Background(), brokerMessage{Key: "/archives/"}); err != nil {
+		t.Errorf("Notify() = %s", err)
+	}
+
+	if got := <-photos; got.Value != 0 {
+		t.Errorf("Notify() = %+v, want the first message", got)
+	}
+
+	select {
+	case got := <-photos:
+		t.Errorf("Notify() = %+v, want the message over the buffer to be dropped", got)
+	default:
+	}
+
+	select {
+	case got := <-archives:
+		t.Errorf("Notify() = %+v, want nothing after unsubscribing", got)
+	default:
+	}
+
+	if len(broker.listeners) != 1 {
+		t.Errorf("Subscribe() kept %d keys, want 1", len(broker.listeners))
+	}
+}
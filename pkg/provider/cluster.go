@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"strings"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+// Cluster is an event: files taken close in time and location
+type Cluster struct {
+	Start    time.Time
+	End      time.Time
+	Location string
+	Items    []absto.Item
+}
+
+func (c Cluster) ID() string {
+	if len(c.Items) == 0 {
+		return ""
+	}
+
+	return c.Items[0].ID
+}
+
+// Title describes the event by its location and its dates, e.g. "Lyon, 12–14 March 2024"
+func (c Cluster) Title() string {
+	var parts []string
+
+	if len(c.Location) != 0 {
+		parts = append(parts, c.Location)
+	}
+
+	if dates := dateRange(c.Start, c.End); len(dates) != 0 {
+		parts = append(parts, dates)
+	}
+
+	if len(parts) == 0 {
+		return "Without date"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func dateRange(start, end time.Time) string {
+	if start.IsZero() {
+		return ""
+	}
+
+	switch {
+	case start.Year() != end.Year():
+		return start.Format("2 January 2006") + " – " + end.Format("2 January 2006")
+	case start.Month() != end.Month():
+		return start.Format("2 January") + " – " + end.Format("2 January 2006")
+	case start.Day() != end.Day():
+		return start.Format("2") + "–" + end.Format("2 January 2006")
+	default:
+		return start.Format("2 January 2006")
+	}
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClusterTitle(t *testing.T) {
+	day := time.Date(2024, time.March, 12, 10, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		instance Cluster
+		want     string
+	}{
+		"empty": {
+			Cluster{},
+			"Without date",
+		},
+		"location only": {
+			Cluster{Location: "Lyon"},
+			"Lyon",
+		},
+		"same day": {
+			Cluster{Start: day, End: day.Add(time.Hour)},
+			"12 March 2024",
+		},
+		"same month": {
+			Cluster{Start: day, End: day.AddDate(0, 0, 2), Location: "Lyon"},
+			"Lyon, 12–14 March 2024",
+		},
+		"same year": {
+			Cluster{Start: day.AddDate(0, 0, 18), End: day.AddDate(0, 0, 21), Location: "Lyon"},
+			"Lyon, 30 March – 2 April 2024",
+		},
+		"different year": {
+			Cluster{Start: day.AddDate(0, 9, 19), End: day.AddDate(0, 9, 22)},
+			"31 December 2024 – 3 January 2025",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := tc.instance.Title(); got != tc.want {
+				t.Errorf("Title() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
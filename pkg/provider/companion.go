@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"strings"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+type CompanionKind string
+
+const (
+	RawCompanion  CompanionKind = "RAW"
+	LiveCompanion CompanionKind = "LIVE"
+)
+
+var (
+	RawExtensions = map[string]bool{".arw": true, ".cr2": true, ".cr3": true, ".dng": true, ".nef": true, ".orf": true, ".raf": true, ".rw2": true}
+
+	companionPrimaryExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".heic": true}
+	liveExtensions             = map[string]bool{".mov": true}
+)
+
+type Companion struct {
+	Kind CompanionKind
+	absto.Item
+}
+
+// CompanionKindOf checks if candidate is a companion of the primary item (e.g. `IMG_1234.CR3` or `IMG_1234.MOV` for `IMG_1234.JPG`) and returns its kind
+func CompanionKindOf(primary, candidate absto.Item) (CompanionKind, bool) {
+	if primary.IsDir() || candidate.IsDir() || !companionPrimaryExtensions[primary.Extension] || primary.Dir() != candidate.Dir() {
+		return "", false
+	}
+
+	if !strings.EqualFold(baseName(primary.Name()), baseName(candidate.Name())) {
+		return "", false
+	}
+
+	switch {
+	case RawExtensions[candidate.Extension]:
+		return RawCompanion, true
+	case liveExtensions[candidate.Extension]:
+		return LiveCompanion, true
+	default:
+		return "", false
+	}
+}
+
+func CompanionsOf(primary absto.Item, items []absto.Item) []Companion {
+	var output []Companion
+
+	for _, item := range items {
+		if kind, ok := CompanionKindOf(primary, item); ok {
+			output = append(output, Companion{Item: item, Kind: kind})
+		}
+	}
+
+	return output
+}
+
+// GroupCompanions removes companions from the items and returns them by ID of their primary item
+func GroupCompanions(items []absto.Item) ([]absto.Item, map[string][]Companion) {
+	primaries := make(map[string]absto.Item)
+
+	for _, item := range items {
+		if !item.IsDir() && companionPrimaryExtensions[item.Extension] {
+			primaries[strings.ToLower(item.Dir()+baseName(item.Name()))] = item
+		}
+	}
+
+	if len(primaries) == 0 {
+		return items, nil
+	}
+
+	output := make([]absto.Item, 0, len(items))
+	companions := make(map[string][]Companion)
+
+	for _, item := range items {
+		primary, ok := primaries[strings.ToLower(item.Dir()+baseName(item.Name()))]
+		if !ok {
+			output = append(output, item)
+			continue
+		}
+
+		kind, ok := CompanionKindOf(primary, item)
+		if !ok {
+			output = append(output, item)
+			continue
+		}
+
+		companions[primary.ID] = append(companions[primary.ID], Companion{Item: item, Kind: kind})
+	}
+
+	return output, companions
+}
+
+// CompanionName gives the name of the companion for the given primary name, keeping its extension
+func CompanionName(primaryName string, companion Companion) string {
+	return baseName(primaryName) + companion.Name()[len(baseName(companion.Name())):]
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestCompanionKindOf(t *testing.T) {
+	primary := absto.Item{Pathname: "/photos/IMG_1234.JPG", NameValue: "IMG_1234.JPG", Extension: ".jpg"}
+
+	cases := map[string]struct {
+		primary   absto.Item
+		candidate absto.Item
+		want      CompanionKind
+		wantOk    bool
+	}{
+		"not a primary": {
+			absto.Item{Pathname: "/photos/IMG_1234.PNG", NameValue: "IMG_1234.PNG", Extension: ".png"},
+			absto.Item{Pathname: "/photos/IMG_1234.CR3", NameValue: "IMG_1234.CR3", Extension: ".cr3"},
+			"",
+			false,
+		},
+		"another name": {
+			primary,
+			absto.Item{Pathname: "/photos/IMG_1235.CR3", NameValue: "IMG_1235.CR3", Extension: ".cr3"},
+			"",
+			false,
+		},
+		"another directory": {
+			primary,
+			absto.Item{Pathname: "/raw/IMG_1234.CR3", NameValue: "IMG_1234.CR3", Extension: ".cr3"},
+			"",
+			false,
+		},
+		"raw": {
+			primary,
+			absto.Item{Pathname: "/photos/img_1234.cr3", NameValue: "img_1234.cr3", Extension: ".cr3"},
+			RawCompanion,
+			true,
+		},
+		"live": {
+			absto.Item{Pathname: "/photos/IMG_1234.HEIC", NameValue: "IMG_1234.HEIC", Extension: ".heic"},
+			absto.Item{Pathname: "/photos/IMG_1234.MOV", NameValue: "IMG_1234.MOV", Extension: ".mov"},
+			LiveCompanion,
+			true,
+		},
+		"unrelated extension": {
+			primary,
+			absto.Item{Pathname: "/photos/IMG_1234.txt", NameValue: "IMG_1234.txt", Extension: ".txt"},
+			"",
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotOk := CompanionKindOf(tc.primary, tc.candidate)
+			if got != tc.want || gotOk != tc.wantOk {
+				t.Errorf("CompanionKindOf() = (`%s`, %t), want (`%s`, %t)", got, gotOk, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestGroupCompanions(t *testing.T) {
+	primary := absto.Item{ID: "1234", Pathname: "/photos/IMG_1234.HEIC", NameValue: "IMG_1234.HEIC", Extension: ".heic"}
+	raw := absto.Item{ID: "2345", Pathname: "/photos/IMG_1234.DNG", NameValue: "IMG_1234.DNG", Extension: ".dng"}
+	live := absto.Item{ID: "3456", Pathname: "/photos/IMG_1234.MOV", NameValue: "IMG_1234.MOV", Extension: ".mov"}
+	alone := absto.Item{ID: "4567", Pathname: "/photos/IMG_1235.MOV", NameValue: "IMG_1235.MOV", Extension: ".mov"}
+
+	cases := map[string]struct {
+		input          []absto.Item
+		want           []absto.Item
+		wantCompanions map[string][]Companion
+	}{
+		"no primary": {
+			[]absto.Item{raw, alone},
+			[]absto.Item{raw, alone},
+			nil,
+		},
+		"grouped": {
+			[]absto.Item{raw, primary, live, alone},
+			[]absto.Item{primary, alone},
+			map[string][]Companion{
+				"1234": {
+					{Item: raw, Kind: RawCompanion},
+					{Item: live, Kind: LiveCompanion},
+				},
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotCompanions := GroupCompanions(tc.input)
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("GroupCompanions() = %v, want %v", got, tc.want)
+			}
+
+			if !reflect.DeepEqual(gotCompanions, tc.wantCompanions) {
+				t.Errorf("GroupCompanions() = %v, want %v", gotCompanions, tc.wantCompanions)
+			}
+		})
+	}
+}
+
+func TestCompanionName(t *testing.T) {
+	cases := map[string]struct {
+		primaryName string
+		companion   Companion
+		want        string
+	}{
+		"keep extension case": {
+			"holidays.jpg",
+			Companion{Item: absto.Item{NameValue: "IMG_1234.CR3"}},
+			"holidays.CR3",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := CompanionName(tc.primaryName, tc.companion); got != tc.want {
+				t.Errorf("CompanionName() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,9 +36,11 @@ const (
 	StartEvent
 	AccessEvent
 	DescriptionEvent
+	MemoryEvent
+	ThumbnailEvent
 )
 
-var eventTypeValues = []string{"upload", "create", "rename", "delete", "start", "access", "description"}
+var eventTypeValues = []string{"upload", "create", "rename", "delete", "start", "access", "description", "memory", "thumbnail"}
 
 func ParseEventType(value string) (EventType, error) {
 	for i, eType := range eventTypeValues {
@@ -82,11 +85,28 @@ type Event struct {
 	Metadata     map[string]string `json:"metadata,omitempty"`
 	URL          string            `json:"url,omitempty"`
 	ShareableURL string            `json:"shareable_url,omitempty"`
+	Actor        string            `json:"actor,omitempty"`
 	TraceLink    trace.Link        `json:"-"`
 	Item         absto.Item        `json:"item"`
 	Type         EventType         `json:"type"`
 }
 
+type actorKey struct{}
+
+// WithActor stores who is acting in the context, for the events it creates
+func WithActor(ctx context.Context, actor string) context.Context {
+	if len(actor) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
 func (e Event) IsForcedFor(key string) bool {
 	force := e.GetMetadata("force")
 
@@ -175,6 +195,7 @@ func NewUploadEvent(ctx context.Context, request Request, item absto.Item, share
 		Time:         time.Now(),
 		Type:         UploadEvent,
 		Item:         item,
+		Actor:        ActorFrom(ctx),
 		TraceLink:    trace.LinkFromContext(ctx),
 		URL:          rendererService.PublicURL(request.AbsoluteURL(item.Name())),
 		ShareableURL: shareableURL,
@@ -193,6 +214,7 @@ func NewRenameEvent(ctx context.Context, old, new absto.Item, shareableURL strin
 		Time:         time.Now(),
 		Type:         RenameEvent,
 		Item:         old,
+		Actor:        ActorFrom(ctx),
 		TraceLink:    trace.LinkFromContext(ctx),
 		New:          &new,
 		URL:          rendererService.PublicURL(new.Pathname),
@@ -209,6 +231,7 @@ func NewDescriptionEvent(ctx context.Context, item absto.Item, shareableURL, des
 		Time:         time.Now(),
 		Type:         DescriptionEvent,
 		Item:         item,
+		Actor:        ActorFrom(ctx),
 		TraceLink:    trace.LinkFromContext(ctx),
 		URL:          rendererService.PublicURL(item.Pathname),
 		ShareableURL: shareableURL,
@@ -218,14 +241,64 @@ func NewDescriptionEvent(ctx context.Context, item absto.Item, shareableURL, des
 	}
 }
 
+// NewMemoryEvent summarizes the files of the folder taken on this day in previous years, the cover is the public thumbnail URL of the most remarkable one
+func NewMemoryEvent(ctx context.Context, item absto.Item, recursive bool, shareableURL string, count int, years, coverURL string, rendererService *renderer.Service) Event {
+	suffix := "?memories"
+	if !recursive {
+		suffix += "&flat"
+	}
+
+	if len(shareableURL) != 0 {
+		shareableURL = rendererService.PublicURL(shareableURL + suffix)
+	}
+
+	return Event{
+		Time:         time.Now(),
+		Type:         MemoryEvent,
+		Item:         item,
+		TraceLink:    trace.LinkFromContext(ctx),
+		URL:          rendererService.PublicURL(item.Pathname + suffix),
+		ShareableURL: shareableURL,
+		Metadata: map[string]string{
+			"recursive": strconv.FormatBool(recursive),
+			"count":     strconv.Itoa(count),
+			"years":     years,
+			"cover":     coverURL,
+		},
+	}
+}
+
 func NewDeleteEvent(ctx context.Context, request Request, item absto.Item, rendererService *renderer.Service) Event {
 	return Event{
 		Time:      time.Now(),
 		Type:      DeleteEvent,
 		Item:      item,
+		Actor:     ActorFrom(ctx),
+		TraceLink: trace.LinkFromContext(ctx),
+		URL:       rendererService.PublicURL(request.AbsoluteURL("")),
+	}
+}
+
+// NewTestEvent is a sample event on the folder, for checking that a webhook is working
+func NewTestEvent(ctx context.Context, request Request, item absto.Item, eventType EventType, rendererService *renderer.Service) Event {
+	event := Event{
+		Time:      time.Now(),
+		Type:      eventType,
+		Item:      item,
+		Actor:     ActorFrom(ctx),
 		TraceLink: trace.LinkFromContext(ctx),
 		URL:       rendererService.PublicURL(request.AbsoluteURL("")),
+		Metadata: map[string]string{
+			"test": "true",
+		},
 	}
+
+	if eventType == RenameEvent {
+		// Renderers expect the destination of a rename, the folder stands for it
+		event.New = &item
+	}
+
+	return event
 }
 
 func NewStartEvent(ctx context.Context, item absto.Item) Event {
@@ -249,6 +322,16 @@ func NewRestartEvent(ctx context.Context, item absto.Item, subset string) Event
 	}
 }
 
+// NewThumbnailEvent signals that the small thumbnail of the item is ready to be displayed
+func NewThumbnailEvent(ctx context.Context, item absto.Item) Event {
+	return Event{
+		Time:      time.Now(),
+		Type:      ThumbnailEvent,
+		Item:      item,
+		TraceLink: trace.LinkFromContext(ctx),
+	}
+}
+
 func NewAccessEvent(ctx context.Context, item absto.Item, r *http.Request) Event {
 	metadata := make(map[string]string)
 	for key, values := range r.Header {
@@ -266,6 +349,7 @@ func NewAccessEvent(ctx context.Context, item absto.Item, r *http.Request) Event
 		Time:      time.Now(),
 		Type:      AccessEvent,
 		Item:      item,
+		Actor:     ActorFrom(ctx),
 		TraceLink: trace.LinkFromContext(ctx),
 		Metadata:  metadata,
 		URL:       r.URL.String(),
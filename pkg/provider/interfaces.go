@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	absto "github.com/ViBiOh/absto/pkg/model"
 	"github.com/ViBiOh/auth/v3/pkg/model"
 	"github.com/ViBiOh/httputils/v4/pkg/renderer"
 )
@@ -12,7 +13,7 @@ import (
 //go:generate go tool "go.uber.org/mock/mockgen" -destination ../mocks/storage.go -package mocks -mock_names Storage=Storage github.com/ViBiOh/absto/pkg/model Storage
 //go:generate go tool "go.uber.org/mock/mockgen" -destination ../mocks/redis_client.go -package mocks -mock_names Client=RedisClient github.com/ViBiOh/httputils/v4/pkg/redis Client
 
-//go:generate go tool "go.uber.org/mock/mockgen" -source $GOFILE -destination ../mocks/$GOFILE -package mocks -mock_names Crud=Crud,Auth=Auth,ShareManager=ShareManager,WebhookManager=WebhookManager
+//go:generate go tool "go.uber.org/mock/mockgen" -source $GOFILE -destination ../mocks/$GOFILE -package mocks -mock_names Crud=Crud,Auth=Auth,ShareManager=ShareManager,WebhookManager=WebhookManager,AlbumManager=AlbumManager
 
 type Crud interface {
 	Get(http.ResponseWriter, *http.Request, Request) (renderer.Page, error)
@@ -39,6 +40,21 @@ type WebhookManager interface {
 	List() []Webhook
 	Get(string) Webhook
 	FindByURL(string) []Webhook
-	Create(context.Context, string, bool, WebhookKind, string, []EventType) (string, error)
+	Create(context.Context, Webhook) (string, string, error)
+	Rotate(context.Context, string, time.Duration) (string, error)
+	Update(context.Context, Webhook) error
 	Delete(context.Context, string) error
+	Deliveries(context.Context) (map[string]WebhookLog, error)
+	Fire(context.Context, string, Event) (WebhookDelivery, error)
+	Replay(context.Context, string, string) (WebhookDelivery, error)
+}
+
+type AlbumManager interface {
+	List() []Album
+	Get(string) Album
+	Create(context.Context, string) (string, error)
+	UpdateName(context.Context, string, string) error
+	Delete(context.Context, string) error
+	AddFiles(context.Context, string, ...absto.Item) (int, error)
+	RemoveFiles(context.Context, string, ...string) (int, error)
 }
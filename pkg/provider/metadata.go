@@ -2,12 +2,18 @@ package provider
 
 import (
 	"context"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
 	"time"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
 	exas "github.com/ViBiOh/exas/pkg/model"
 )
 
+const MaxRating = 5
+
 //go:generate go tool "go.uber.org/mock/mockgen" -source $GOFILE -destination ../mocks/$GOFILE -package mocks -mock_names MetadataManager=MetadataManager
 
 type ExifResponse struct {
@@ -18,26 +24,114 @@ type ExifResponse struct {
 type Metadata struct {
 	Description string   `json:"description,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
+	Rating      int      `json:"rating,omitempty"`
+	Favorite    bool     `json:"favorite,omitempty"`
 	exas.Exif
+	ManualDate     bool   `json:"manualDate,omitempty"`
+	ManualLocation bool   `json:"manualLocation,omitempty"`
+	Track          string `json:"track,omitempty"`
+	PerceptualHash uint64 `json:"phash,omitempty"`
 }
 
+// MonthLayout formats the months of Aggregate.Months
+const MonthLayout = "2006-01"
+
 type Aggregate struct {
-	Start    time.Time `json:"start"`
-	End      time.Time `json:"end"`
-	Location string    `json:"location,omitempty"`
-	Cover    string    `json:"cover,omitempty"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Months counts the dated photos and videos directly in the directory, by month. It's nil for aggregates computed before it existed
+	Months   map[string]int `json:"months"`
+	Location string         `json:"location,omitempty"`
+	Cover    string         `json:"cover,omitempty"`
 }
 
 type MetadataAction func(Metadata) Metadata
 
+// ReplaceExif replaces extracted values, except the date and the location when the user fixed them
 func ReplaceExif(exif exas.Exif) MetadataAction {
 	return func(instance Metadata) Metadata {
+		date, geocode := instance.Date, instance.Geocode
+
 		instance.Exif = exif
 
+		if instance.ManualDate {
+			instance.Date = date
+		}
+
+		if instance.ManualLocation {
+			instance.Geocode = geocode
+		}
+
+		return instance
+	}
+}
+
+func ReplaceDate(date time.Time) MetadataAction {
+	return func(instance Metadata) Metadata {
+		instance.Date = date
+		instance.ManualDate = true
+
+		return instance
+	}
+}
+
+// ShiftDate moves the capture date by the given offset, e.g. for a camera set on the wrong time zone. Items without date are left untouched.
+func ShiftDate(offset time.Duration) MetadataAction {
+	return func(instance Metadata) Metadata {
+		if instance.Date.IsZero() {
+			return instance
+		}
+
+		instance.Date = instance.Date.Add(offset)
+		instance.ManualDate = true
+
+		return instance
+	}
+}
+
+// ReplaceLocation sets the coordinates, the previous address doesn't match anymore so it's cleared
+func ReplaceLocation(latitude, longitude float64) MetadataAction {
+	return func(instance Metadata) Metadata {
+		instance.Geocode = exas.Geocode{
+			Latitude:  latitude,
+			Longitude: longitude,
+		}
+		instance.ManualLocation = true
+		instance.Track = ""
+
+		return instance
+	}
+}
+
+// ReplaceTrackLocation sets the coordinates found in the given GPX track
+func ReplaceTrackLocation(latitude, longitude float64, track string) MetadataAction {
+	return func(instance Metadata) Metadata {
+		instance = ReplaceLocation(latitude, longitude)(instance)
+		instance.Track = track
+
+		return instance
+	}
+}
+
+// CanBeGeotagged checks if a track can give a location, i.e. there is a date and no location other than one from a previous track
+func (m Metadata) CanBeGeotagged() bool {
+	return !m.Date.IsZero() && (!m.Geocode.HasCoordinates() || len(m.Track) != 0)
+}
+
+// ReplacePerceptualHash sets the hash of the thumbnail, used to find similar pictures
+func ReplacePerceptualHash(hash uint64) MetadataAction {
+	return func(instance Metadata) Metadata {
+		instance.PerceptualHash = hash
+
 		return instance
 	}
 }
 
+// HammingDistance counts the bits that differ between two perceptual hashes, the lower the more similar
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
 func ReplaceDescription(description string) MetadataAction {
 	return func(instance Metadata) Metadata {
 		instance.Description = description
@@ -54,6 +148,74 @@ func ReplaceTags(tags []string) MetadataAction {
 	}
 }
 
+// MergeTags adds the given tags to the existing ones, it never removes a tag set by the user
+func MergeTags(tags []string) MetadataAction {
+	return func(instance Metadata) Metadata {
+		for _, tag := range tags {
+			if !slices.Contains(instance.Tags, tag) {
+				instance.Tags = append(instance.Tags, tag)
+			}
+		}
+
+		return instance
+	}
+}
+
+// DefaultDescription sets the description only if the user didn't write one
+func DefaultDescription(description string) MetadataAction {
+	return func(instance Metadata) Metadata {
+		if len(instance.Description) == 0 {
+			instance.Description = description
+		}
+
+		return instance
+	}
+}
+
+// ReplaceRating sets the rating, bounded between 0 (unrated) and MaxRating
+func ReplaceRating(rating int) MetadataAction {
+	return func(instance Metadata) Metadata {
+		instance.Rating = min(max(rating, 0), MaxRating)
+
+		return instance
+	}
+}
+
+// DefaultRating sets the rating only if the user didn't rate the item
+func DefaultRating(rating int) MetadataAction {
+	return func(instance Metadata) Metadata {
+		if instance.Rating == 0 {
+			instance.Rating = min(max(rating, 0), MaxRating)
+		}
+
+		return instance
+	}
+}
+
+func ReplaceFavorite(favorite bool) MetadataAction {
+	return func(instance Metadata) Metadata {
+		instance.Favorite = favorite
+
+		return instance
+	}
+}
+
+// ExifRating reads the rating found by the exif extraction, 0 when there is none
+func ExifRating(exif exas.Exif) int {
+	var rating float64
+
+	switch value := exif.Data["Rating"].(type) {
+	case float64:
+		rating = value
+	case int:
+		rating = float64(value)
+	case string:
+		rating, _ = strconv.ParseFloat(value, 64)
+	}
+
+	return min(max(int(math.Round(rating)), 0), MaxRating)
+}
+
 type MetadataManager interface {
 	ListDir(ctx context.Context, item absto.Item) ([]absto.Item, error)
 
@@ -64,4 +226,11 @@ type MetadataManager interface {
 	GetMetadataFor(ctx context.Context, item absto.Item) (Metadata, error)
 	GetAllMetadataFor(ctx context.Context, items ...absto.Item) (map[string]Metadata, error)
 	Update(ctx context.Context, item absto.Item, opts ...MetadataAction) (Metadata, error)
+	Edit(ctx context.Context, items []absto.Item, opts ...MetadataAction) error
+	Geotag(ctx context.Context, track absto.Item, offset time.Duration) (int, error)
+	Clusters(items []absto.Item, metadatas map[string]Metadata) []Cluster
+
+	TagIndex(ctx context.Context) (TagIndex, error)
+	RenameTag(ctx context.Context, old, new string) (int, error)
+	DeleteTag(ctx context.Context, tag string) (int, error)
 }
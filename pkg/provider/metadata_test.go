@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	exas "github.com/ViBiOh/exas/pkg/model"
+)
+
+func TestReplaceExif(t *testing.T) {
+	extracted := exas.Exif{
+		Date:    time.Date(2024, 7, 14, 10, 0, 0, 0, time.UTC),
+		Geocode: exas.Geocode{Latitude: 48.8, Longitude: 2.3},
+		Data:    map[string]any{"Model": "Pixel"},
+	}
+
+	manualDate := time.Date(1998, 7, 12, 21, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		instance Metadata
+		want     Metadata
+	}{
+		"extracted": {
+			Metadata{Description: "Party"},
+			Metadata{Description: "Party", Exif: extracted},
+		},
+		"manual date": {
+			Metadata{Exif: exas.Exif{Date: manualDate}, ManualDate: true},
+			Metadata{Exif: exas.Exif{Date: manualDate, Geocode: extracted.Geocode, Data: extracted.Data}, ManualDate: true},
+		},
+		"perceptual hash": {
+			Metadata{PerceptualHash: 42},
+			Metadata{PerceptualHash: 42, Exif: extracted},
+		},
+		"manual location": {
+			ReplaceLocation(45.5, -73.56)(Metadata{}),
+			Metadata{Exif: exas.Exif{Date: extracted.Date, Geocode: exas.Geocode{Latitude: 45.5, Longitude: -73.56}, Data: extracted.Data}, ManualLocation: true},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := ReplaceExif(extracted)(tc.instance); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ReplaceExif() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestShiftDate(t *testing.T) {
+	date := time.Date(2024, 7, 14, 10, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		instance Metadata
+		want     Metadata
+	}{
+		"no date": {
+			Metadata{},
+			Metadata{},
+		},
+		"shift": {
+			Metadata{Exif: exas.Exif{Date: date}},
+			Metadata{Exif: exas.Exif{Date: date.Add(-2 * time.Hour)}, ManualDate: true},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := ShiftDate(-2 * time.Hour)(tc.instance); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ShiftDate() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanBeGeotagged(t *testing.T) {
+	date := time.Date(2024, 7, 14, 10, 0, 0, 0, time.UTC)
+	located := exas.Geocode{Latitude: 48.8, Longitude: 2.3}
+
+	cases := map[string]struct {
+		instance Metadata
+		want     bool
+	}{
+		"no date": {
+			Metadata{},
+			false,
+		},
+		"not located": {
+			Metadata{Exif: exas.Exif{Date: date}},
+			true,
+		},
+		"located by camera": {
+			Metadata{Exif: exas.Exif{Date: date, Geocode: located}},
+			false,
+		},
+		"located by hand": {
+			ReplaceLocation(45.5, -73.56)(Metadata{Exif: exas.Exif{Date: date}}),
+			false,
+		},
+		"located by track": {
+			ReplaceTrackLocation(45.5, -73.56, "/hike.gpx")(Metadata{Exif: exas.Exif{Date: date}}),
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := tc.instance.CanBeGeotagged(); got != tc.want {
+				t.Errorf("CanBeGeotagged() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRating(t *testing.T) {
+	cases := map[string]struct {
+		instance Metadata
+		rating   int
+		want     int
+	}{
+		"unrated": {
+			Metadata{},
+			4,
+			4,
+		},
+		"rated by user": {
+			Metadata{Rating: 2},
+			4,
+			2,
+		},
+		"out of bounds": {
+			Metadata{},
+			12,
+			MaxRating,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := DefaultRating(tc.rating)(tc.instance).Rating; got != tc.want {
+				t.Errorf("DefaultRating() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExifRating(t *testing.T) {
+	cases := map[string]struct {
+		exif exas.Exif
+		want int
+	}{
+		"none": {
+			exas.Exif{},
+			0,
+		},
+		"number": {
+			exas.Exif{Data: map[string]any{"Rating": float64(4)}},
+			4,
+		},
+		"string": {
+			exas.Exif{Data: map[string]any{"Rating": "3"}},
+			3,
+		},
+		"rejected": {
+			exas.Exif{Data: map[string]any{"Rating": float64(-1)}},
+			0,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := ExifRating(tc.exif); got != tc.want {
+				t.Errorf("ExifRating() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	cases := map[string]struct {
+		a    uint64
+		b    uint64
+		want int
+	}{
+		"same": {
+			0xf0f0,
+			0xf0f0,
+			0,
+		},
+		"one bit": {
+			0b1010,
+			0b1000,
+			1,
+		},
+		"opposite": {
+			0,
+			^uint64(0),
+			64,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := HammingDistance(tc.a, tc.b); got != tc.want {
+				t.Errorf("HammingDistance() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
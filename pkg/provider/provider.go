@@ -1,7 +1,9 @@
 package provider
 
 import (
+	"maps"
 	"path/filepath"
+	"slices"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
 )
@@ -13,15 +15,16 @@ const (
 )
 
 var (
-	ArchiveExtensions = map[string]bool{".zip": true, ".tar": true, ".gz": true, ".rar": true}
-	AudioExtensions   = map[string]bool{".mp3": true}
-	CodeExtensions    = map[string]bool{".html": true, ".css": true, ".js": true, ".jsx": true, ".json": true, ".yml": true, ".yaml": true, ".toml": true, ".md": true, ".go": true, ".py": true, ".java": true, ".xml": true}
-	ExcelExtensions   = map[string]bool{".xls": true, ".xlsx": true, ".xlsm": true}
-	ImageExtensions   = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".svg": true, ".tiff": true, ".webp": true, ".heic": true, ".dng": true}
-	PdfExtensions     = map[string]bool{".pdf": true}
-	VideoExtensions   = map[string]string{".mp4": "video/mp4", ".mov": "video/mp4", ".avi": "video/x-msvideo", ".ogg": "video/ogg", ".mkv": "video/x-matroska"}
-	StreamExtensions  = map[string]bool{".ts": true}
-	WordExtensions    = map[string]bool{".doc": true, ".docx": true, ".docm": true}
+	ArchiveExtensions  = map[string]bool{".zip": true, ".tar": true, ".gz": true, ".rar": true}
+	AudioExtensions    = map[string]bool{".mp3": true}
+	CodeExtensions     = map[string]bool{".html": true, ".css": true, ".js": true, ".jsx": true, ".json": true, ".yml": true, ".yaml": true, ".toml": true, ".md": true, ".go": true, ".py": true, ".java": true, ".xml": true}
+	ExcelExtensions    = map[string]bool{".xls": true, ".xlsx": true, ".xlsm": true}
+	ImageExtensions    = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".svg": true, ".tiff": true, ".webp": true, ".heic": true, ".dng": true}
+	PdfExtensions      = map[string]bool{".pdf": true}
+	VideoExtensions    = map[string]string{".mp4": "video/mp4", ".mov": "video/mp4", ".avi": "video/x-msvideo", ".ogg": "video/ogg", ".mkv": "video/x-matroska"}
+	StreamExtensions   = map[string]bool{".ts": true, ".m3u8": true}
+	SubtitleExtensions = map[string]bool{".srt": true, ".vtt": true}
+	WordExtensions     = map[string]bool{".doc": true, ".docx": true, ".docm": true}
 
 	// ThumbnailExtensions contains extensions of file eligible to thumbnail
 	ThumbnailExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".tiff": true, ".webp": true, ".dng": true, ".pdf": true, ".mp4": true, ".mov": true, ".avi": true, ".ogg": true, ".mkv": true, ".heic": true}
@@ -30,6 +33,35 @@ var (
 	VignetExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".tiff": true, ".webp": true, ".dng": true, ".mp4": true, ".mov": true, ".avi": true, ".ogg": true, ".mkv": true, ".heic": true}
 )
 
+// MimeFamilies are the aliases of the extensions groups, as used by search and webhook filters
+var MimeFamilies = []string{"archive", "audio", "code", "excel", "image", "pdf", "video", "stream", "word"}
+
+// MimeFamilyExtensions returns the extensions of the given family, nil if unknown
+func MimeFamilyExtensions(family string) []string {
+	switch family {
+	case "archive":
+		return slices.Collect(maps.Keys(ArchiveExtensions))
+	case "audio":
+		return slices.Collect(maps.Keys(AudioExtensions))
+	case "code":
+		return slices.Collect(maps.Keys(CodeExtensions))
+	case "excel":
+		return slices.Collect(maps.Keys(ExcelExtensions))
+	case "image":
+		return slices.Collect(maps.Keys(ImageExtensions))
+	case "pdf":
+		return slices.Collect(maps.Keys(PdfExtensions))
+	case "video":
+		return slices.Collect(maps.Keys(VideoExtensions))
+	case "stream":
+		return slices.Collect(maps.Keys(StreamExtensions))
+	case "word":
+		return slices.Collect(maps.Keys(WordExtensions))
+	default:
+		return nil
+	}
+}
+
 func MetadataDirectory(item absto.Item) string {
 	pathname := item.Pathname
 	if !item.IsDir() {
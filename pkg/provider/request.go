@@ -3,6 +3,7 @@ package provider
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -127,6 +128,9 @@ func (p Preferences) RemoveLayout(path string) Preferences {
 type Request struct {
 	Path        string
 	Item        string
+	Tag         string
+	Album       string
+	Login       string
 	Display     Display
 	Preferences Preferences
 	Share       Share
@@ -135,6 +139,19 @@ type Request struct {
 	CanWebhook  bool
 }
 
+// Actor identifies who is behind the request: `share:<id>` or `user:<login>`, empty when authentication is disabled
+func (r Request) Actor() string {
+	if !r.Share.IsZero() {
+		return "share:" + r.Share.ID
+	}
+
+	if len(r.Login) != 0 {
+		return "user:" + r.Login
+	}
+
+	return ""
+}
+
 func (r Request) String() string {
 	var output strings.Builder
 
@@ -145,6 +162,8 @@ func (r Request) String() string {
 	output.WriteString(string(r.Display))
 	output.WriteString(strconv.FormatBool(r.CanWebhook))
 	output.WriteString(r.Share.String())
+	output.WriteString(r.Tag)
+	output.WriteString(r.Album)
 
 	return output.String()
 }
@@ -166,6 +185,10 @@ func (r Request) DeletePreference(path string) Request {
 }
 
 func (r Request) RelativeURL(item absto.Item) string {
+	if len(r.Album) != 0 && !item.IsDir() {
+		return AlbumFileName(item)
+	}
+
 	pathname := item.Pathname
 
 	if !r.Share.IsZero() {
@@ -176,6 +199,10 @@ func (r Request) RelativeURL(item absto.Item) string {
 		pathname = Dirname(pathname)
 	}
 
+	if len(r.Tag) != 0 {
+		return Join("/", r.Share.ID, pathname)
+	}
+
 	return strings.TrimPrefix(pathname, r.Path)
 }
 
@@ -193,6 +220,15 @@ func (r Request) AbsoluteURL(name string) string {
 	return url
 }
 
+// TagURL is the URL of the virtual folder of a tag, or of the tag cloud if empty
+func (r Request) TagURL(tag string) string {
+	if len(tag) == 0 {
+		return Join("/", r.Share.ID, TagsPath)
+	}
+
+	return Join("/", r.Share.ID, TagsPath, url.PathEscape(tag)) + "/"
+}
+
 func (r Request) Filepath() string {
 	return r.SubPath(r.Item)
 }
@@ -116,6 +116,20 @@ func TestRelativeURL(t *testing.T) {
 			},
 			"index.html",
 		},
+		"album": {
+			Request{
+				Path:  "/albums/a1b2c3d4/",
+				Album: "a1b2c3d4",
+			},
+			args{
+				item: absto.Item{
+					ID:        "8000cafe",
+					Pathname:  "/2024/07/IMG_0001.jpg",
+					NameValue: "IMG_0001.jpg",
+				},
+			},
+			"8000cafe-IMG_0001.jpg",
+		},
 	}
 
 	for intention, tc := range cases {
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"strings"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+const XMPExtension = ".xmp"
+
+// XMPSidecarNames gives the names of the XMP sidecar of a file, with its full name (e.g. darktable) first and its base name (e.g. Lightroom) then
+func XMPSidecarNames(name string) []string {
+	return []string{name + XMPExtension, baseName(name) + XMPExtension}
+}
+
+// CanHaveXMPSidecar reports if desktop tools write a sidecar for the item, pictures, RAW and videos
+func CanHaveXMPSidecar(item absto.Item) bool {
+	return !item.IsDir() && (ImageExtensions[item.Extension] || RawExtensions[item.Extension] || len(VideoExtensions[item.Extension]) != 0)
+}
+
+func IsXMPSidecar(item, candidate absto.Item) bool {
+	if item.IsDir() || candidate.IsDir() || candidate.Extension != XMPExtension || item.Extension == XMPExtension || item.Dir() != candidate.Dir() {
+		return false
+	}
+
+	for _, name := range XMPSidecarNames(item.Name()) {
+		if strings.EqualFold(candidate.Name(), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func XMPSidecarOf(item absto.Item, items []absto.Item) (absto.Item, bool) {
+	for _, name := range XMPSidecarNames(item.Name()) {
+		for _, candidate := range items {
+			if IsXMPSidecar(item, candidate) && strings.EqualFold(candidate.Name(), name) {
+				return candidate, true
+			}
+		}
+	}
+
+	return absto.Item{}, false
+}
+
+// XMPSidecarName gives the name of the sidecar for the new name of its file, keeping its naming convention
+func XMPSidecarName(oldName, newName string, sidecar absto.Item) string {
+	extension := sidecar.Name()[len(baseName(sidecar.Name())):]
+
+	if strings.EqualFold(baseName(sidecar.Name()), oldName) {
+		return newName + extension
+	}
+
+	return baseName(newName) + extension
+}
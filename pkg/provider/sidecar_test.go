@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestXMPSidecarOf(t *testing.T) {
+	picture := absto.Item{Pathname: "/photos/IMG_1234.CR2", NameValue: "IMG_1234.CR2", Extension: ".cr2"}
+
+	cases := map[string]struct {
+		items  []absto.Item
+		want   string
+		wantOk bool
+	}{
+		"none": {
+			[]absto.Item{picture, {Pathname: "/photos/IMG_1235.xmp", NameValue: "IMG_1235.xmp", Extension: ".xmp"}},
+			"",
+			false,
+		},
+		"other directory": {
+			[]absto.Item{{Pathname: "/other/IMG_1234.CR2.xmp", NameValue: "IMG_1234.CR2.xmp", Extension: ".xmp"}},
+			"",
+			false,
+		},
+		"base name": {
+			[]absto.Item{picture, {Pathname: "/photos/IMG_1234.XMP", NameValue: "IMG_1234.XMP", Extension: ".xmp"}},
+			"/photos/IMG_1234.XMP",
+			true,
+		},
+		"full name first": {
+			[]absto.Item{{Pathname: "/photos/IMG_1234.xmp", NameValue: "IMG_1234.xmp", Extension: ".xmp"}, {Pathname: "/photos/IMG_1234.CR2.xmp", NameValue: "IMG_1234.CR2.xmp", Extension: ".xmp"}},
+			"/photos/IMG_1234.CR2.xmp",
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotOk := XMPSidecarOf(picture, tc.items)
+			if got.Pathname != tc.want || gotOk != tc.wantOk {
+				t.Errorf("XMPSidecarOf() = (%s, %t), want (%s, %t)", got.Pathname, gotOk, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestXMPSidecarName(t *testing.T) {
+	cases := map[string]struct {
+		sidecar absto.Item
+		want    string
+	}{
+		"full name": {
+			absto.Item{Pathname: "/photos/IMG_1234.CR2.xmp", NameValue: "IMG_1234.CR2.xmp"},
+			"sunset.CR2.xmp",
+		},
+		"base name": {
+			absto.Item{Pathname: "/photos/IMG_1234.XMP", NameValue: "IMG_1234.XMP"},
+			"sunset.XMP",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := XMPSidecarName("IMG_1234.CR2", "sunset.CR2", tc.sidecar); got != tc.want {
+				t.Errorf("XMPSidecarName() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
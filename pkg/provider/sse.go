@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SSEKeepAlive is the interval of the comments sent to keep idle streams open through proxies
+const SSEKeepAlive = time.Second * 30
+
+// EventStream writes server-sent events to a response that stays open
+type EventStream struct {
+	writer     http.ResponseWriter
+	controller *http.ResponseController
+}
+
+// NewEventStream sends the headers of a server-sent events response. The write deadline is lifted when the writer allows it, browsers reconnect once it's reached otherwise
+func NewEventStream(w http.ResponseWriter) (EventStream, error) {
+	controller := http.NewResponseController(w)
+
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return EventStream{}, fmt.Errorf("disable write deadline: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := EventStream{writer: w, controller: controller}
+
+	return stream, stream.flush()
+}
+
+// Send writes an event of given name, with its data encoded in JSON
+func (es EventStream) Send(name string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	if _, err = fmt.Fprintf(es.writer, "event: %s\ndata: %s\n\n", name, payload); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return es.flush()
+}
+
+// KeepAlive writes a comment, ignored by the browsers
+func (es EventStream) KeepAlive() error {
+	if _, err := fmt.Fprint(es.writer, ": keepalive\n\n"); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	return es.flush()
+}
+
+func (es EventStream) flush() error {
+	if err := es.controller.Flush(); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+
+	return nil
+}
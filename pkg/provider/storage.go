@@ -85,14 +85,18 @@ func (a ByID) Less(i, j int) bool {
 }
 
 type RenderItem struct {
-	Aggregate Aggregate
-	Tags      []string
-	URL       string
-	Path      string
+	Aggregate  Aggregate
+	Tags       []string
+	Subtitles  []Subtitle
+	Companions []Companion
+	URL        string
+	Path       string
 
 	absto.Item
+	Rating       int
 	HasThumbnail bool
 	IsCover      bool
+	Favorite     bool
 }
 
 func (r RenderItem) String() string {
@@ -102,6 +106,8 @@ func (r RenderItem) String() string {
 	output.WriteString(strconv.FormatBool(r.HasThumbnail))
 	output.WriteString(r.Path)
 	output.WriteString(strconv.FormatBool(r.IsCover))
+	output.WriteString(strconv.Itoa(r.Rating))
+	output.WriteString(strconv.FormatBool(r.Favorite))
 	output.WriteString(r.ID)
 	output.WriteString(strconv.FormatInt(r.Size(), 10))
 	output.WriteString(r.Date.String())
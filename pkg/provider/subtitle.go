@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"path"
+	"strings"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+type Subtitle struct {
+	Language string
+	absto.Item
+}
+
+func (s Subtitle) Label() string {
+	if len(s.Language) == 0 {
+		return "Default"
+	}
+
+	return s.Language
+}
+
+func baseName(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// SubtitleLanguage checks if candidate is a subtitle sidecar of the given video (e.g. `movie.srt` or `movie.fr.vtt` for `movie.mp4`) and returns its language
+func SubtitleLanguage(video, candidate absto.Item) (string, bool) {
+	if video.IsDir() || candidate.IsDir() || len(VideoExtensions[video.Extension]) == 0 || !SubtitleExtensions[candidate.Extension] || video.Dir() != candidate.Dir() {
+		return "", false
+	}
+
+	videoName := baseName(video.Name())
+	candidateName := baseName(candidate.Name())
+
+	if candidateName == videoName {
+		return "", true
+	}
+
+	language, ok := strings.CutPrefix(candidateName, videoName+".")
+	if !ok || len(language) == 0 || strings.Contains(language, ".") {
+		return "", false
+	}
+
+	return language, true
+}
+
+func SubtitlesOf(video absto.Item, items []absto.Item) []Subtitle {
+	var output []Subtitle
+
+	for _, item := range items {
+		if language, ok := SubtitleLanguage(video, item); ok {
+			output = append(output, Subtitle{Item: item, Language: language})
+		}
+	}
+
+	return output
+}
+
+// HasNamesake checks if another video of the items has the same basename (e.g. `movie.mov` for `movie.mp4`): they share their subtitles
+func HasNamesake(video absto.Item, items []absto.Item) bool {
+	for _, item := range items {
+		if item.ID != video.ID && !item.IsDir() && len(VideoExtensions[item.Extension]) != 0 && item.Dir() == video.Dir() && baseName(item.Name()) == baseName(video.Name()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GroupSubtitles removes subtitle sidecars from the items and returns them by ID of their video. Videos sharing a basename (e.g. `movie.mp4` and `movie.mov`) all get the subtitle, as in SubtitlesOf
+func GroupSubtitles(items []absto.Item) ([]absto.Item, map[string][]Subtitle) {
+	videos := make(map[string][]absto.Item)
+
+	for _, item := range items {
+		if !item.IsDir() && len(VideoExtensions[item.Extension]) != 0 {
+			key := item.Dir() + baseName(item.Name())
+			videos[key] = append(videos[key], item)
+		}
+	}
+
+	if len(videos) == 0 {
+		return items, nil
+	}
+
+	output := make([]absto.Item, 0, len(items))
+	subtitles := make(map[string][]Subtitle)
+
+	for _, item := range items {
+		if !SubtitleExtensions[item.Extension] || item.IsDir() {
+			output = append(output, item)
+			continue
+		}
+
+		name := item.Dir() + baseName(item.Name())
+		candidates, ok := videos[name]
+		if !ok {
+			candidates = videos[baseName(name)]
+		}
+
+		var grouped bool
+
+		for _, video := range candidates {
+			if language, isSubtitle := SubtitleLanguage(video, item); isSubtitle {
+				subtitles[video.ID] = append(subtitles[video.ID], Subtitle{Item: item, Language: language})
+				grouped = true
+			}
+		}
+
+		if !grouped {
+			output = append(output, item)
+		}
+	}
+
+	return output, subtitles
+}
+
+// SubtitleName gives the name of the subtitle for the given video name, keeping its language and extension
+func SubtitleName(videoName string, subtitle Subtitle) string {
+	name := baseName(videoName)
+
+	if len(subtitle.Language) != 0 {
+		name += "." + subtitle.Language
+	}
+
+	return name + path.Ext(subtitle.Name())
+}
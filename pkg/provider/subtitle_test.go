@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestSubtitleLanguage(t *testing.T) {
+	video := absto.Item{Pathname: "/videos/movie.mp4", NameValue: "movie.mp4", Extension: ".mp4"}
+
+	cases := map[string]struct {
+		video     absto.Item
+		candidate absto.Item
+		want      string
+		wantOk    bool
+	}{
+		"not a video": {
+			absto.Item{Pathname: "/videos/movie.jpg", NameValue: "movie.jpg", Extension: ".jpg"},
+			absto.Item{Pathname: "/videos/movie.srt", NameValue: "movie.srt", Extension: ".srt"},
+			"",
+			false,
+		},
+		"not a subtitle": {
+			video,
+			absto.Item{Pathname: "/videos/movie.txt", NameValue: "movie.txt", Extension: ".txt"},
+			"",
+			false,
+		},
+		"another directory": {
+			video,
+			absto.Item{Pathname: "/other/movie.srt", NameValue: "movie.srt", Extension: ".srt"},
+			"",
+			false,
+		},
+		"another video": {
+			video,
+			absto.Item{Pathname: "/videos/movie2.fr.srt", NameValue: "movie2.fr.srt", Extension: ".srt"},
+			"",
+			false,
+		},
+		"no language": {
+			video,
+			absto.Item{Pathname: "/videos/movie.vtt", NameValue: "movie.vtt", Extension: ".vtt"},
+			"",
+			true,
+		},
+		"language": {
+			video,
+			absto.Item{Pathname: "/videos/movie.fr.srt", NameValue: "movie.fr.srt", Extension: ".srt"},
+			"fr",
+			true,
+		},
+		"nested suffix": {
+			video,
+			absto.Item{Pathname: "/videos/movie.director.fr.srt", NameValue: "movie.director.fr.srt", Extension: ".srt"},
+			"",
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotOk := SubtitleLanguage(tc.video, tc.candidate)
+			if got != tc.want || gotOk != tc.wantOk {
+				t.Errorf("SubtitleLanguage() = (`%s`, %t), want (`%s`, %t)", got, gotOk, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestGroupSubtitles(t *testing.T) {
+	video := absto.Item{ID: "1234", Pathname: "/videos/movie.mp4", NameValue: "movie.mp4", Extension: ".mp4"}
+	french := absto.Item{ID: "2345", Pathname: "/videos/movie.fr.srt", NameValue: "movie.fr.srt", Extension: ".srt"}
+	english := absto.Item{ID: "3456", Pathname: "/videos/movie.en.vtt", NameValue: "movie.en.vtt", Extension: ".vtt"}
+	orphan := absto.Item{ID: "4567", Pathname: "/videos/other.srt", NameValue: "other.srt", Extension: ".srt"}
+	namesake := absto.Item{ID: "5678", Pathname: "/videos/movie.mov", NameValue: "movie.mov", Extension: ".mov"}
+
+	cases := map[string]struct {
+		input         []absto.Item
+		want          []absto.Item
+		wantSubtitles map[string][]Subtitle
+	}{
+		"no video": {
+			[]absto.Item{french, orphan},
+			[]absto.Item{french, orphan},
+			nil,
+		},
+		"grouped": {
+			[]absto.Item{english, french, video, orphan},
+			[]absto.Item{video, orphan},
+			map[string][]Subtitle{
+				"1234": {
+					{Item: english, Language: "en"},
+					{Item: french, Language: "fr"},
+				},
+			},
+		},
+		"namesakes": {
+			[]absto.Item{french, video, namesake},
+			[]absto.Item{video, namesake},
+			map[string][]Subtitle{
+				"1234": {{Item: french, Language: "fr"}},
+				"5678": {{Item: french, Language: "fr"}},
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotSubtitles := GroupSubtitles(tc.input)
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("GroupSubtitles() = %v, want %v", got, tc.want)
+			}
+
+			if !reflect.DeepEqual(gotSubtitles, tc.wantSubtitles) {
+				t.Errorf("GroupSubtitles() = %v, want %v", gotSubtitles, tc.wantSubtitles)
+			}
+		})
+	}
+}
+
+func TestHasNamesake(t *testing.T) {
+	video := absto.Item{ID: "1234", Pathname: "/videos/movie.mp4", NameValue: "movie.mp4", Extension: ".mp4"}
+
+	cases := map[string]struct {
+		items []absto.Item
+		want  bool
+	}{
+		"alone": {
+			[]absto.Item{video, {ID: "2345", Pathname: "/videos/movie.fr.srt", NameValue: "movie.fr.srt", Extension: ".srt"}},
+			false,
+		},
+		"other folder": {
+			[]absto.Item{video, {ID: "3456", Pathname: "/archives/movie.mov", NameValue: "movie.mov", Extension: ".mov"}},
+			false,
+		},
+		"namesake": {
+			[]absto.Item{video, {ID: "4567", Pathname: "/videos/movie.mov", NameValue: "movie.mov", Extension: ".mov"}},
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := HasNamesake(video, tc.items); got != tc.want {
+				t.Errorf("HasNamesake() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSubtitleName(t *testing.T) {
+	cases := map[string]struct {
+		videoName string
+		subtitle  Subtitle
+		want      string
+	}{
+		"no language": {
+			"holidays.mov",
+			Subtitle{Item: absto.Item{NameValue: "movie.srt"}},
+			"holidays.srt",
+		},
+		"language": {
+			"holidays.mov",
+			Subtitle{Item: absto.Item{NameValue: "movie.fr.vtt"}, Language: "fr"},
+			"holidays.fr.vtt",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := SubtitleName(tc.videoName, tc.subtitle); got != tc.want {
+				t.Errorf("SubtitleName() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"slices"
+	"strings"
+)
+
+const TagsPath = "/tags/"
+
+// TagIndex lists the pathnames of the files having each tag
+type TagIndex map[string][]string
+
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+func isUnder(pathname, root string) bool {
+	return root == "/" || pathname == root || strings.HasPrefix(pathname, strings.TrimSuffix(root, "/")+"/")
+}
+
+// Pathnames of the files having the tag, under the given root
+func (t TagIndex) Pathnames(tag, root string) []string {
+	var output []string
+
+	for _, pathname := range t[tag] {
+		if isUnder(pathname, root) {
+			output = append(output, pathname)
+		}
+	}
+
+	return output
+}
+
+// Counts of the tags used by files under the given root, sorted by name
+func (t TagIndex) Counts(root string) []TagCount {
+	var output []TagCount
+
+	for tag := range t {
+		if count := len(t.Pathnames(tag, root)); count != 0 {
+			output = append(output, TagCount{Name: tag, Count: count})
+		}
+	}
+
+	slices.SortFunc(output, func(a, b TagCount) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return output
+}
+
+// Names of the tags used by files under the given root, sorted
+func (t TagIndex) Names(root string) []string {
+	counts := t.Counts(root)
+
+	output := make([]string, len(counts))
+	for index, count := range counts {
+		output[index] = count.Name
+	}
+
+	return output
+}
+
+func (t TagIndex) add(tag, pathname string) {
+	if index, found := slices.BinarySearch(t[tag], pathname); !found {
+		t[tag] = slices.Insert(t[tag], index, pathname)
+	}
+}
+
+func (t TagIndex) remove(tag, pathname string) {
+	if index, found := slices.BinarySearch(t[tag], pathname); found {
+		t[tag] = slices.Delete(t[tag], index, index+1)
+	}
+
+	if len(t[tag]) == 0 {
+		delete(t, tag)
+	}
+}
+
+// Update replaces the tags of the file, it returns true if the index changed
+func (t TagIndex) Update(pathname string, before, after []string) bool {
+	var changed bool
+
+	for _, tag := range before {
+		if !slices.Contains(after, tag) && slices.Contains(t[tag], pathname) {
+			t.remove(tag, pathname)
+			changed = true
+		}
+	}
+
+	for _, tag := range after {
+		if !slices.Contains(t[tag], pathname) {
+			t.add(tag, pathname)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// Move changes the pathname of a file, or of all the files of a directory, it returns true if the index changed
+func (t TagIndex) Move(old, new string) bool {
+	old = strings.TrimSuffix(old, "/")
+	new = strings.TrimSuffix(new, "/")
+
+	var changed bool
+
+	for tag, pathnames := range t {
+		for _, pathname := range slices.Clone(pathnames) {
+			if !isUnder(pathname, old) {
+				continue
+			}
+
+			t.remove(tag, pathname)
+			t.add(tag, new+strings.TrimPrefix(pathname, old))
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// Remove deletes a file, or all the files of a directory, it returns true if the index changed
+func (t TagIndex) Remove(old string) bool {
+	var changed bool
+
+	for tag, pathnames := range t {
+		for _, pathname := range slices.Clone(pathnames) {
+			if isUnder(pathname, old) {
+				t.remove(tag, pathname)
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// RenameTag replaces a tag by another one in a list, without duplicate
+func RenameTag(tags []string, old, new string) []string {
+	output := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		if tag == old {
+			tag = new
+		}
+
+		if len(tag) != 0 && !slices.Contains(output, tag) {
+			output = append(output, tag)
+		}
+	}
+
+	return output
+}
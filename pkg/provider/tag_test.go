@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestIndex() TagIndex {
+	return TagIndex{
+		"cat":  {"/pets/garfield.jpg", "/pets/tom.jpg"},
+		"dog":  {"/pets/snoopy.jpg"},
+		"trip": {"/petsitter.jpg", "/travel/rome.jpg"},
+	}
+}
+
+func TestTagIndexCounts(t *testing.T) {
+	cases := map[string]struct {
+		root string
+		want []TagCount
+	}{
+		"root": {
+			"/",
+			[]TagCount{{"cat", 2}, {"dog", 1}, {"trip", 2}},
+		},
+		"sub directory": {
+			"/pets/",
+			[]TagCount{{"cat", 2}, {"dog", 1}},
+		},
+		"sub directory without slash": {
+			"/pets",
+			[]TagCount{{"cat", 2}, {"dog", 1}},
+		},
+		"empty": {
+			"/music/",
+			nil,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := newTestIndex().Counts(tc.root); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Counts() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTagIndexUpdate(t *testing.T) {
+	cases := map[string]struct {
+		pathname    string
+		before      []string
+		after       []string
+		want        TagIndex
+		wantChanged bool
+	}{
+		"unchanged": {
+			"/pets/snoopy.jpg",
+			[]string{"dog"},
+			[]string{"dog"},
+			newTestIndex(),
+			false,
+		},
+		"add and remove": {
+			"/pets/snoopy.jpg",
+			[]string{"dog"},
+			[]string{"cat"},
+			TagIndex{
+				"cat":  {"/pets/garfield.jpg", "/pets/snoopy.jpg", "/pets/tom.jpg"},
+				"trip": {"/petsitter.jpg", "/travel/rome.jpg"},
+			},
+			true,
+		},
+		"new tag": {
+			"/music/song.mp3",
+			nil,
+			[]string{"rock"},
+			TagIndex{
+				"cat":  {"/pets/garfield.jpg", "/pets/tom.jpg"},
+				"dog":  {"/pets/snoopy.jpg"},
+				"rock": {"/music/song.mp3"},
+				"trip": {"/petsitter.jpg", "/travel/rome.jpg"},
+			},
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			index := newTestIndex()
+
+			if got := index.Update(tc.pathname, tc.before, tc.after); got != tc.wantChanged {
+				t.Errorf("Update() = %t, want %t", got, tc.wantChanged)
+			}
+
+			if !reflect.DeepEqual(index, tc.want) {
+				t.Errorf("Update() = %+v, want %+v", index, tc.want)
+			}
+		})
+	}
+}
+
+func TestTagIndexMove(t *testing.T) {
+	cases := map[string]struct {
+		old  string
+		new  string
+		want TagIndex
+	}{
+		"file": {
+			"/pets/tom.jpg",
+			"/pets/thomas.jpg",
+			TagIndex{
+				"cat":  {"/pets/garfield.jpg", "/pets/thomas.jpg"},
+				"dog":  {"/pets/snoopy.jpg"},
+				"trip": {"/petsitter.jpg", "/travel/rome.jpg"},
+			},
+		},
+		"directory": {
+			"/pets/",
+			"/animals/",
+			TagIndex{
+				"cat":  {"/animals/garfield.jpg", "/animals/tom.jpg"},
+				"dog":  {"/animals/snoopy.jpg"},
+				"trip": {"/petsitter.jpg", "/travel/rome.jpg"},
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			index := newTestIndex()
+			index.Move(tc.old, tc.new)
+
+			if !reflect.DeepEqual(index, tc.want) {
+				t.Errorf("Move() = %+v, want %+v", index, tc.want)
+			}
+		})
+	}
+}
+
+func TestTagIndexRemove(t *testing.T) {
+	index := newTestIndex()
+	index.Remove("/pets")
+
+	want := TagIndex{
+		"trip": {"/petsitter.jpg", "/travel/rome.jpg"},
+	}
+
+	if !reflect.DeepEqual(index, want) {
+		t.Errorf("Remove() = %+v, want %+v", index, want)
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	cases := map[string]struct {
+		tags []string
+		old  string
+		new  string
+		want []string
+	}{
+		"rename": {
+			[]string{"cat", "cute"},
+			"cat",
+			"kitten",
+			[]string{"kitten", "cute"},
+		},
+		"merge": {
+			[]string{"cat", "kitten"},
+			"cat",
+			"kitten",
+			[]string{"kitten"},
+		},
+		"delete": {
+			[]string{"cat", "cute"},
+			"cat",
+			"",
+			[]string{"cute"},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := RenameTag(tc.tags, tc.old, tc.new); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("RenameTag() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -75,6 +75,11 @@ func Join(parts ...string) string {
 	return pathname
 }
 
+// IsMedia checks if the item is a photo or a video
+func IsMedia(item absto.Item) bool {
+	return ImageExtensions[item.Extension] || len(VideoExtensions[item.Extension]) != 0
+}
+
 func Dirname(name string) string {
 	if !strings.HasSuffix(name, "/") {
 		return name + "/"
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"path"
 	"slices"
 	"strings"
 	"time"
@@ -19,9 +21,21 @@ const (
 	Slack
 	Telegram
 	Push
+	Template
+	Matrix
+	Ntfy
+	Gotify
+	Mattermost
+	Email
 )
 
-var WebhookKindValues = []string{"raw", "discord", "slack", "telegram", "push"}
+var WebhookKindValues = []string{"raw", "discord", "slack", "telegram", "push", "template", "matrix", "ntfy", "gotify", "mattermost", "email"}
+
+// DefaultPushDebounce is the window during which the events are grouped in a single push notification
+const DefaultPushDebounce = time.Minute * 30
+
+// WebhookDigestValues are the frequencies of an email digest, an empty one meaning an email per event
+var WebhookDigestValues = []string{"hourly", "daily", "weekly"}
 
 func ParseWebhookKind(value string) (WebhookKind, error) {
 	for i, short := range WebhookKindValues {
@@ -61,13 +75,66 @@ func (r *WebhookKind) UnmarshalJSON(b []byte) error {
 }
 
 type Webhook struct {
-	Created   time.Time   `json:"created"`
-	ID        string      `json:"id"`
-	Pathname  string      `json:"pathname"`
-	URL       string      `json:"url"`
-	Types     []EventType `json:"types"`
-	Kind      WebhookKind `json:"kind"`
-	Recursive bool        `json:"recursive"`
+	Created   time.Time         `json:"created"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ID        string            `json:"id"`
+	Pathname  string            `json:"pathname"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method,omitempty"`
+	Template  string            `json:"template,omitempty"`
+	Digest    string            `json:"digest,omitempty"`
+	Debounce  time.Duration     `json:"debounce,omitempty"`
+	Types     []EventType       `json:"types"`
+	Secrets   []WebhookSecret   `json:"secrets,omitempty"`
+	Filter    WebhookFilter     `json:"filter,omitzero"`
+	Quiet     WebhookQuietHours `json:"quiet,omitzero"`
+	Kind      WebhookKind       `json:"kind"`
+	Recursive bool              `json:"recursive"`
+}
+
+// WebhookSecret signs the deliveries of a webhook, a rotated one still being used until it expires
+type WebhookSecret struct {
+	Expire time.Time `json:"expire,omitzero"`
+	Value  string    `json:"value"`
+}
+
+// WebhookFilter narrows the events sent to a webhook, its zero value letting everything through.
+// A glob containing a slash is matched against the pathname, otherwise against the name.
+type WebhookFilter struct {
+	Include       []string `json:"include,omitempty"`
+	Exclude       []string `json:"exclude,omitempty"`
+	Mimes         []string `json:"mimes,omitempty"`
+	IgnoredUsers  []string `json:"ignored_users,omitempty"`
+	IgnoredShares []string `json:"ignored_shares,omitempty"`
+	MinSize       int64    `json:"min_size,omitempty"`
+}
+
+// WebhookQuietHours holds the push notifications from its start to its end, in minutes after midnight in its timezone
+type WebhookQuietHours struct {
+	Timezone string `json:"timezone,omitempty"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
+
+// WebhookDelivery is the outcome of sending an event to a webhook, retries included
+type WebhookDelivery struct {
+	Time     time.Time     `json:"time"`
+	Event    Event         `json:"event"`
+	ID       string        `json:"id"`
+	Error    string        `json:"error,omitempty"`
+	Latency  time.Duration `json:"latency"`
+	Status   int           `json:"status"`
+	Attempts int           `json:"attempts"`
+}
+
+func (d WebhookDelivery) Failed() bool {
+	return len(d.Error) != 0
+}
+
+// WebhookLog keeps the last deliveries of a webhook, and the dead letters: the deliveries still failing after every retry
+type WebhookLog struct {
+	Deliveries  []WebhookDelivery `json:"deliveries,omitempty"`
+	DeadLetters []WebhookDelivery `json:"dead_letters,omitempty"`
 }
 
 func (w Webhook) Similar(other Webhook) bool {
@@ -75,6 +142,14 @@ func (w Webhook) Similar(other Webhook) bool {
 		return false
 	}
 
+	if !w.Filter.Equal(other.Filter) || w.Quiet != other.Quiet {
+		return false
+	}
+
+	if w.Method != other.Method || w.Template != other.Template || w.Digest != other.Digest || w.Debounce != other.Debounce || !maps.Equal(w.Headers, other.Headers) {
+		return false
+	}
+
 	if len(w.Types) != len(other.Types) {
 		return false
 	}
@@ -88,15 +163,58 @@ func (w Webhook) Similar(other Webhook) bool {
 	return true
 }
 
+// Signed reports if the deliveries of the webhook are signed with its own secrets, other kinds being sent to third-party services
+func (w Webhook) Signed() bool {
+	return w.Kind == Raw || w.Kind == Template
+}
+
+// ActiveSecrets returns the values of the secrets not expired at the given time, the current one first
+func (w Webhook) ActiveSecrets(now time.Time) []string {
+	var output []string
+
+	for _, secret := range w.Secrets {
+		if secret.Expire.IsZero() || secret.Expire.After(now) {
+			output = append(output, secret.Value)
+		}
+	}
+
+	return output
+}
+
+// Rotate puts the given secret first, the current ones expiring after the grace period
+func (w *Webhook) Rotate(value string, now time.Time, grace time.Duration) {
+	secrets := []WebhookSecret{{Value: value}}
+
+	for _, secret := range w.Secrets {
+		if secret.Expire.IsZero() {
+			secret.Expire = now.Add(grace)
+		}
+
+		if secret.Expire.After(now) {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	w.Secrets = secrets
+}
+
 func (w Webhook) Match(e Event) bool {
-	if !w.hasType(e.Type) {
+	if !w.HasType(e.Type) {
 		return false
 	}
 
-	return w.matchItem(e.Item) || (e.New != nil && w.matchItem(*e.New))
+	if e.Type == MemoryEvent {
+		return w.Pathname == e.Item.Pathname && w.Recursive == (e.GetMetadata("recursive") == "true")
+	}
+
+	if !w.matchItem(e.Item) && (e.New == nil || !w.matchItem(*e.New)) {
+		return false
+	}
+
+	return w.Filter.Match(e)
 }
 
-func (w Webhook) hasType(eventType EventType) bool {
+func (w Webhook) HasType(eventType EventType) bool {
 	return slices.Contains(w.Types, eventType)
 }
 
@@ -117,3 +235,143 @@ func (w Webhook) matchItem(item absto.Item) bool {
 
 	return itemDir == w.Pathname
 }
+
+func (f WebhookFilter) IsZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0 && len(f.Mimes) == 0 && len(f.IgnoredUsers) == 0 && len(f.IgnoredShares) == 0 && f.MinSize == 0
+}
+
+func (f WebhookFilter) Equal(other WebhookFilter) bool {
+	return slices.Equal(f.Include, other.Include) && slices.Equal(f.Exclude, other.Exclude) && slices.Equal(f.Mimes, other.Mimes) &&
+		slices.Equal(f.IgnoredUsers, other.IgnoredUsers) && slices.Equal(f.IgnoredShares, other.IgnoredShares) && f.MinSize == other.MinSize
+}
+
+// Match checks the actor of the event, then the item it results in: the new one for a rename
+func (f WebhookFilter) Match(e Event) bool {
+	if user, ok := strings.CutPrefix(e.Actor, "user:"); ok && slices.Contains(f.IgnoredUsers, user) {
+		return false
+	}
+
+	if share, ok := strings.CutPrefix(e.Actor, "share:"); ok && slices.Contains(f.IgnoredShares, share) {
+		return false
+	}
+
+	item := e.Item
+	if e.New != nil {
+		item = *e.New
+	}
+
+	if len(f.Include) != 0 && !matchGlobs(f.Include, item) {
+		return false
+	}
+
+	if matchGlobs(f.Exclude, item) {
+		return false
+	}
+
+	if len(f.Mimes) != 0 && (item.IsDir() || !slices.ContainsFunc(f.Mimes, func(family string) bool {
+		return slices.Contains(MimeFamilyExtensions(family), strings.ToLower(item.Extension))
+	})) {
+		return false
+	}
+
+	return item.IsDir() || item.Size() >= f.MinSize
+}
+
+// String summarizes the filter for display
+func (f WebhookFilter) String() string {
+	var parts []string
+
+	if len(f.Include) != 0 {
+		parts = append(parts, "only "+strings.Join(f.Include, ", "))
+	}
+
+	if len(f.Exclude) != 0 {
+		parts = append(parts, "except "+strings.Join(f.Exclude, ", "))
+	}
+
+	if len(f.Mimes) != 0 {
+		parts = append(parts, strings.Join(f.Mimes, ", "))
+	}
+
+	if f.MinSize != 0 {
+		parts = append(parts, fmt.Sprintf("at least %d kB", f.MinSize>>10))
+	}
+
+	if ignored := append(slices.Clone(f.IgnoredUsers), f.IgnoredShares...); len(ignored) != 0 {
+		parts = append(parts, "ignoring "+strings.Join(ignored, ", "))
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+func (q WebhookQuietHours) IsZero() bool {
+	return q.Start == q.End
+}
+
+// Until returns the end of the quiet hours the given time falls in, the zero time outside of them
+func (q WebhookQuietHours) Until(at time.Time) time.Time {
+	if q.IsZero() {
+		return time.Time{}
+	}
+
+	location := time.UTC
+	if len(q.Timezone) != 0 {
+		if zone, err := time.LoadLocation(q.Timezone); err == nil {
+			location = zone
+		}
+	}
+
+	local := at.In(location)
+	minutes := local.Hour()*60 + local.Minute()
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.End/60, q.End%60, 0, 0, location)
+
+	if q.Start < q.End {
+		if minutes >= q.Start && minutes < q.End {
+			return end
+		}
+
+		return time.Time{}
+	}
+
+	if minutes >= q.Start {
+		return end.AddDate(0, 0, 1)
+	}
+
+	if minutes < q.End {
+		return end
+	}
+
+	return time.Time{}
+}
+
+func (q WebhookQuietHours) StartTime() string {
+	return fmt.Sprintf("%02d:%02d", q.Start/60, q.Start%60)
+}
+
+func (q WebhookQuietHours) EndTime() string {
+	return fmt.Sprintf("%02d:%02d", q.End/60, q.End%60)
+}
+
+func (q WebhookQuietHours) String() string {
+	output := q.StartTime() + " to " + q.EndTime()
+	if len(q.Timezone) != 0 {
+		output += " " + q.Timezone
+	}
+
+	return output
+}
+
+func matchGlobs(patterns []string, item absto.Item) bool {
+	for _, pattern := range patterns {
+		value := item.Name()
+		if strings.Contains(pattern, "/") {
+			value = item.Pathname
+		}
+
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+
+	return false
+}
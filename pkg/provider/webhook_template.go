@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(value any) (string, error) {
+		output, err := json.Marshal(value)
+		return string(output), err
+	},
+}
+
+// WebhookTemplateData is what the body of a templated webhook has access to
+type WebhookTemplateData struct {
+	Event        Event
+	Item         absto.Item
+	Type         string
+	Name         string
+	URL          string
+	BrowserURL   string
+	StoryURL     string
+	ThumbnailURL string
+}
+
+func NewWebhookTemplateData(event Event, thumbnailURL string) WebhookTemplateData {
+	data := WebhookTemplateData{
+		Event:        event,
+		Item:         event.Item,
+		Type:         event.Type.String(),
+		Name:         event.GetName(),
+		URL:          event.GetURL(),
+		ThumbnailURL: thumbnailURL,
+	}
+
+	if strings.Contains(data.URL, "/") {
+		data.BrowserURL = event.BrowserURL()
+		data.StoryURL = event.StoryURL(event.Item.ID)
+	}
+
+	return data
+}
+
+func ParseWebhookTemplate(content string) (*template.Template, error) {
+	return template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(content)
+}
+
+func RenderWebhookTemplate(content string, data WebhookTemplateData) (string, error) {
+	tpl, err := ParseWebhookTemplate(content)
+	if err != nil {
+		return "", fmt.Errorf("parse: %w", err)
+	}
+
+	var output strings.Builder
+
+	if err = tpl.Execute(&output, data); err != nil {
+		return "", fmt.Errorf("execute: %w", err)
+	}
+
+	return output.String(), nil
+}
+
+// SampleEvent is the upload of a photo, for previewing a webhook
+func SampleEvent(url string) Event {
+	pathname := "/photos/sunset.jpg"
+
+	return Event{
+		Time: time.Now(),
+		Type: UploadEvent,
+		Item: absto.Item{
+			ID:        absto.ID(pathname),
+			Pathname:  pathname,
+			NameValue: "sunset.jpg",
+			Extension: ".jpg",
+			SizeValue: 2 << 20,
+			Date:      time.Now(),
+		},
+		URL: url,
+	}
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderWebhookTemplate(t *testing.T) {
+	data := NewWebhookTemplateData(SampleEvent("https://fibr.local/photos/sunset.jpg"), "https://fibr.local/photos/sunset.jpg?thumbnail")
+
+	cases := map[string]struct {
+		content string
+		want    string
+		wantErr string
+	}{
+		"fields": {
+			`{{ .Type }} {{ .Name }} {{ .BrowserURL }}`,
+			"upload sunset.jpg https://fibr.local/photos/sunset.jpg?browser",
+			"",
+		},
+		"story": {
+			`{{ .StoryURL }}`,
+			"https://fibr.local/photos/?d=story#" + data.Item.ID,
+			"",
+		},
+		"json": {
+			`{"name": {{ json .Item.Name }}, "size": {{ .Item.Size }}}`,
+			`{"name": "sunset.jpg", "size": 2097152}`,
+			"",
+		},
+		"invalid syntax": {
+			`{{ .Name `,
+			"",
+			"parse:",
+		},
+		"missing field": {
+			`{{ .Unknown }}`,
+			"",
+			"execute:",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got, gotErr := RenderWebhookTemplate(tc.content, data)
+
+			failed := false
+
+			if len(tc.wantErr) == 0 && gotErr != nil {
+				failed = true
+			} else if len(tc.wantErr) != 0 && (gotErr == nil || !strings.Contains(gotErr.Error(), tc.wantErr)) {
+				failed = true
+			} else if got != tc.want {
+				failed = true
+			}
+
+			if failed {
+				t.Errorf("RenderWebhookTemplate() = (`%s`, `%s`), want (`%s`, `%s`)", got, gotErr, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+)
+
+func TestWebhookMatch(t *testing.T) {
+	t.Parallel()
+
+	photo := absto.Item{Pathname: "/photos/sunset.jpg", NameValue: "sunset.jpg", Extension: ".JPG", SizeValue: 2 << 20}
+	sidecar := absto.Item{Pathname: "/photos/sunset.xmp", NameValue: "sunset.xmp", Extension: ".xmp", SizeValue: 512}
+	raw := absto.Item{Pathname: "/photos/raw/sunset.dng", NameValue: "sunset.dng", Extension: ".dng", SizeValue: 20 << 20}
+
+	webhook := func(filter WebhookFilter) Webhook {
+		return Webhook{Pathname: "/photos/", Recursive: true, Types: []EventType{UploadEvent, RenameEvent}, Filter: filter}
+	}
+
+	cases := map[string]struct {
+		webhook Webhook
+		event   Event
+		want    bool
+	}{
+		"no filter": {
+			webhook(WebhookFilter{}),
+			Event{Type: UploadEvent, Item: sidecar},
+			true,
+		},
+		"other type": {
+			webhook(WebhookFilter{}),
+			Event{Type: DeleteEvent, Item: photo},
+			false,
+		},
+		"excluded name": {
+			webhook(WebhookFilter{Exclude: []string{"*.xmp"}}),
+			Event{Type: UploadEvent, Item: sidecar},
+			false,
+		},
+		"excluded pathname": {
+			webhook(WebhookFilter{Exclude: []string{"/photos/raw/*"}}),
+			Event{Type: UploadEvent, Item: raw},
+			false,
+		},
+		"not included": {
+			webhook(WebhookFilter{Include: []string{"*.jpg", "*.dng"}}),
+			Event{Type: UploadEvent, Item: sidecar},
+			false,
+		},
+		"renamed into included": {
+			webhook(WebhookFilter{Include: []string{"*.jpg"}}),
+			Event{Type: RenameEvent, Item: sidecar, New: &photo},
+			true,
+		},
+		"mime family": {
+			webhook(WebhookFilter{Mimes: []string{"video", "image"}}),
+			Event{Type: UploadEvent, Item: photo},
+			true,
+		},
+		"other mime family": {
+			webhook(WebhookFilter{Mimes: []string{"image"}}),
+			Event{Type: UploadEvent, Item: sidecar},
+			false,
+		},
+		"too small": {
+			webhook(WebhookFilter{MinSize: 1 << 20}),
+			Event{Type: UploadEvent, Item: sidecar},
+			false,
+		},
+		"large enough": {
+			webhook(WebhookFilter{MinSize: 1 << 20}),
+			Event{Type: UploadEvent, Item: raw},
+			true,
+		},
+		"ignored user": {
+			webhook(WebhookFilter{IgnoredUsers: []string{"admin"}}),
+			Event{Type: UploadEvent, Item: photo, Actor: "user:admin"},
+			false,
+		},
+		"other user": {
+			webhook(WebhookFilter{IgnoredUsers: []string{"admin"}}),
+			Event{Type: UploadEvent, Item: photo, Actor: "user:alice"},
+			true,
+		},
+		"ignored share": {
+			webhook(WebhookFilter{IgnoredShares: []string{"a1b2c3d4"}}),
+			Event{Type: UploadEvent, Item: photo, Actor: "share:a1b2c3d4"},
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.webhook.Match(tc.event); got != tc.want {
+				t.Errorf("Match() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWebhookRotate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 7, 17, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		secrets []WebhookSecret
+		grace   time.Duration
+		want    []WebhookSecret
+		active  []string
+	}{
+		"first secret": {
+			nil,
+			time.Hour,
+			[]WebhookSecret{{Value: "new"}},
+			[]string{"new"},
+		},
+		"grace": {
+			[]WebhookSecret{{Value: "current"}},
+			time.Hour,
+			[]WebhookSecret{{Value: "new"}, {Value: "current", Expire: now.Add(time.Hour)}},
+			[]string{"new", "current"},
+		},
+		"no grace": {
+			[]WebhookSecret{{Value: "current"}},
+			0,
+			[]WebhookSecret{{Value: "new"}},
+			[]string{"new"},
+		},
+		"expired dropped": {
+			[]WebhookSecret{{Value: "current"}, {Value: "old", Expire: now.Add(-time.Minute)}, {Value: "previous", Expire: now.Add(time.Minute)}},
+			time.Hour,
+			[]WebhookSecret{{Value: "new"}, {Value: "current", Expire: now.Add(time.Hour)}, {Value: "previous", Expire: now.Add(time.Minute)}},
+			[]string{"new", "current", "previous"},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			webhook := Webhook{Kind: Raw, Secrets: tc.secrets}
+			webhook.Rotate("new", now, tc.grace)
+
+			if !reflect.DeepEqual(webhook.Secrets, tc.want) {
+				t.Errorf("Rotate() = %+v, want %+v", webhook.Secrets, tc.want)
+			}
+
+			if got := webhook.ActiveSecrets(now); !reflect.DeepEqual(got, tc.active) {
+				t.Errorf("ActiveSecrets() = %v, want %v", got, tc.active)
+			}
+		})
+	}
+}
+
+func TestWebhookQuietHoursUntil(t *testing.T) {
+	t.Parallel()
+
+	night := WebhookQuietHours{Start: 22 * 60, End: 7 * 60}
+	lunch := WebhookQuietHours{Start: 12 * 60, End: 14*60 + 30}
+
+	cases := map[string]struct {
+		quiet WebhookQuietHours
+		at    time.Time
+		want  time.Time
+	}{
+		"none": {
+			WebhookQuietHours{},
+			time.Date(2024, 7, 17, 23, 0, 0, 0, time.UTC),
+			time.Time{},
+		},
+		"before midnight": {
+			night,
+			time.Date(2024, 7, 17, 23, 0, 0, 0, time.UTC),
+			time.Date(2024, 7, 18, 7, 0, 0, 0, time.UTC),
+		},
+		"after midnight": {
+			night,
+			time.Date(2024, 7, 18, 6, 59, 0, 0, time.UTC),
+			time.Date(2024, 7, 18, 7, 0, 0, 0, time.UTC),
+		},
+		"awake": {
+			night,
+			time.Date(2024, 7, 18, 7, 0, 0, 0, time.UTC),
+			time.Time{},
+		},
+		"same day": {
+			lunch,
+			time.Date(2024, 7, 17, 13, 0, 0, 0, time.UTC),
+			time.Date(2024, 7, 17, 14, 30, 0, 0, time.UTC),
+		},
+		"timezone": {
+			WebhookQuietHours{Start: 22 * 60, End: 7 * 60, Timezone: "Europe/Paris"},
+			time.Date(2024, 7, 17, 21, 0, 0, 0, time.UTC),
+			time.Date(2024, 7, 18, 5, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.quiet.Until(tc.at); !got.Equal(tc.want) {
+				t.Errorf("Until() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
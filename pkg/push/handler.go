@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/httputils/v4/pkg/httperror"
 	"github.com/ViBiOh/httputils/v4/pkg/httpjson"
 )
@@ -27,6 +28,8 @@ func (s *Service) post(w http.ResponseWriter, r *http.Request) {
 	}
 
 	subscription.Created = time.Now()
+	subscription.Owner = provider.ActorFrom(ctx)
+	subscription.Device = deviceName(r.UserAgent())
 
 	if err := s.Add(ctx, subscription); err != nil {
 		httperror.InternalServerError(ctx, w, err)
@@ -12,6 +12,8 @@ type Subscription struct {
 	Endpoint  string    `json:"endpoint"`
 	PublicKey string    `json:"publicKey"`
 	Auth      string    `json:"auth"`
+	Owner     string    `json:"owner,omitempty"`
+	Device    string    `json:"device,omitempty"`
 }
 
 func (s Subscription) decodedPublicKey() ([]byte, error) {
@@ -26,6 +28,54 @@ func (s Subscription) Similar(other Subscription) bool {
 	return s.Auth == other.Auth && s.PublicKey == other.PublicKey
 }
 
+// VisibleBy reports if the actor manages the subscription, the ones made before owners were recorded belonging to the admins
+func (s Subscription) VisibleBy(actor string, admin bool) bool {
+	if len(s.Owner) == 0 {
+		return admin
+	}
+
+	return s.Owner == actor
+}
+
+// deviceName summarizes the browser and the operating system of the user agent
+func deviceName(userAgent string) string {
+	var browser, system string
+
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	default:
+		browser = "Browser"
+	}
+
+	switch {
+	case strings.Contains(userAgent, "Android"):
+		system = "Android"
+	case strings.Contains(userAgent, "iPhone"):
+		system = "iOS"
+	case strings.Contains(userAgent, "iPad"):
+		system = "iPadOS"
+	case strings.Contains(userAgent, "Mac OS X"):
+		system = "macOS"
+	case strings.Contains(userAgent, "Windows"):
+		system = "Windows"
+	case strings.Contains(userAgent, "Linux"):
+		system = "Linux"
+	default:
+		return browser
+	}
+
+	return browser + " on " + system
+}
+
 func decodeKey(key string) ([]byte, error) {
 	buffer := bytes.NewBufferString(key)
 	if rem := len(key) % 4; rem != 0 {
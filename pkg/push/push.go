@@ -125,6 +125,9 @@ func (s *Service) Notify(ctx context.Context, subscription Subscription, notific
 		Header("Urgency", "normal").
 		Header("Topic", hash.String(path.Dir(notification.URL))).
 		Send(ctx, io.NopCloser(bytes.NewBuffer(encrypted)))
+	if res == nil {
+		return 0, err
+	}
 
 	return res.StatusCode, err
 }
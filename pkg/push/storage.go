@@ -30,6 +30,11 @@ func (s *Service) Find(ctx context.Context, url string) (Subscription, error) {
 	return Subscription{}, ErrNotFound
 }
 
+// List returns every subscription, the visible ones being filtered by the caller
+func (s *Service) List(ctx context.Context) ([]Subscription, error) {
+	return s.get(ctx)
+}
+
 func (s *Service) Add(ctx context.Context, subscription Subscription) error {
 	return s.exclusive.Execute(ctx, "fibr:mutex:push", exclusive.Duration, func(ctx context.Context) error {
 		subscriptions, err := s.get(ctx)
@@ -37,10 +42,19 @@ func (s *Service) Add(ctx context.Context, subscription Subscription) error {
 			return fmt.Errorf("get subscriptions: %w", err)
 		}
 
-		for _, sub := range subscriptions {
-			if sub.Similar(subscription) {
+		for index, sub := range subscriptions {
+			if !sub.Similar(subscription) {
+				continue
+			}
+
+			if len(sub.Owner) != 0 || len(subscription.Owner) == 0 {
 				return nil
 			}
+
+			subscriptions[index].Owner = subscription.Owner
+			subscriptions[index].Device = subscription.Device
+
+			return s.save(ctx, subscriptions)
 		}
 
 		subscriptions = append(subscriptions, subscription)
@@ -1,6 +1,8 @@
 package search
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
 	"regexp"
 	"slices"
@@ -27,10 +29,15 @@ type search struct {
 	tags        []string
 	size        int64
 	greaterThan bool
+	ratingOrder string
+	rating      int
+	favorite    bool
 }
 
-func (s search) hasTags() bool {
-	return len(s.tags) > 0
+var errRatingOrder = errors.New("unknown rating comparison")
+
+func (s search) hasMetadata() bool {
+	return len(s.tags) > 0 || len(s.ratingOrder) != 0 || s.favorite
 }
 
 func parseSearch(params url.Values, now time.Time) (output search, err error) {
@@ -82,9 +89,62 @@ func parseSearch(params url.Values, now time.Time) (output search, err error) {
 	output.greaterThan = strings.TrimSpace(params.Get("sizeOrder")) == "gt"
 	output.mimes = computeMimes(params["types"])
 
+	output.ratingOrder, output.rating, err = parseRating(params)
+	if err != nil {
+		return output, err
+	}
+
+	if value, ok := params["favorite"]; ok && len(value[0]) != 0 {
+		output.favorite, err = strconv.ParseBool(value[0])
+		if err != nil {
+			return output, err
+		}
+	} else {
+		output.favorite = ok
+	}
+
 	return output, err
 }
 
+// parseRating reads `rating=4` as well as comparisons written in the query like `rating>=4`, that are decoded as the `rating>` key
+func parseRating(params url.Values) (string, int, error) {
+	raw := strings.TrimSpace(params.Get("rating"))
+
+	for key, values := range params {
+		if expression, ok := strings.CutPrefix(key, "rating"); ok && len(expression) != 0 && strings.IndexByte("<>=", expression[0]) != -1 {
+			raw = expression
+
+			if value := strings.TrimSpace(values[0]); len(value) != 0 {
+				raw += "=" + value
+			}
+		}
+	}
+
+	if len(raw) == 0 {
+		return "", 0, nil
+	}
+
+	value := strings.TrimLeft(raw, "<>=")
+
+	order := raw[:len(raw)-len(value)]
+	if len(order) == 0 {
+		order = ">="
+	}
+
+	switch order {
+	case ">=", "<=", ">", "<", "=", "==":
+	default:
+		return "", 0, fmt.Errorf("`%s`: %w", order, errRatingOrder)
+	}
+
+	rating, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return "", 0, fmt.Errorf("rating: %w", err)
+	}
+
+	return order, rating, nil
+}
+
 func (s search) match(item absto.Item) bool {
 	if !s.matchSize(item) {
 		return false
@@ -135,7 +195,36 @@ func (s search) matchMimes(item absto.Item) bool {
 	return false
 }
 
+func (s search) matchMetadata(metadata provider.Metadata) bool {
+	if s.favorite && !metadata.Favorite {
+		return false
+	}
+
+	return s.matchRating(metadata) && s.matchTags(metadata)
+}
+
+func (s search) matchRating(metadata provider.Metadata) bool {
+	switch s.ratingOrder {
+	case ">=":
+		return metadata.Rating >= s.rating
+	case "<=":
+		return metadata.Rating <= s.rating
+	case ">":
+		return metadata.Rating > s.rating
+	case "<":
+		return metadata.Rating < s.rating
+	case "=", "==":
+		return metadata.Rating == s.rating
+	default:
+		return true
+	}
+}
+
 func (s search) matchTags(metadata provider.Metadata) bool {
+	if len(s.tags) == 0 {
+		return true
+	}
+
 	if len(metadata.Tags) == 0 {
 		return false
 	}
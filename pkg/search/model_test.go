@@ -1,9 +1,13 @@
 package search
 
 import (
+	"errors"
+	"net/url"
 	"testing"
+	"time"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
 )
 
 func TestMatchSize(t *testing.T) {
@@ -66,3 +70,114 @@ func TestMatchSize(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRating(t *testing.T) {
+	cases := map[string]struct {
+		query     string
+		wantOrder string
+		want      int
+		wantErr   error
+	}{
+		"none": {
+			"name=beach",
+			"",
+			0,
+			nil,
+		},
+		"minimum": {
+			"rating=4",
+			">=",
+			4,
+			nil,
+		},
+		"comparison in query": {
+			"rating>=4",
+			">=",
+			4,
+			nil,
+		},
+		"strict comparison in query": {
+			"rating<3",
+			"<",
+			3,
+			nil,
+		},
+		"encoded comparison": {
+			"rating=" + url.QueryEscape("=5"),
+			"=",
+			5,
+			nil,
+		},
+		"invalid comparison": {
+			"rating=" + url.QueryEscape("<>2"),
+			"",
+			0,
+			errRatingOrder,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			params, _ := url.ParseQuery(tc.query)
+
+			gotOrder, got, gotErr := parseRating(params)
+
+			if gotOrder != tc.wantOrder || got != tc.want || !errors.Is(gotErr, tc.wantErr) {
+				t.Errorf("parseRating() = (`%s`, %d, %v), want (`%s`, %d, %v)", gotOrder, got, gotErr, tc.wantOrder, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchMetadata(t *testing.T) {
+	cases := map[string]struct {
+		query    string
+		metadata provider.Metadata
+		want     bool
+	}{
+		"rating": {
+			"rating>=4",
+			provider.Metadata{Rating: 4},
+			true,
+		},
+		"low rating": {
+			"rating>=4",
+			provider.Metadata{Rating: 3},
+			false,
+		},
+		"favorite": {
+			"favorite",
+			provider.Metadata{Favorite: true},
+			true,
+		},
+		"not favorite": {
+			"favorite=true&rating=1",
+			provider.Metadata{Rating: 5},
+			false,
+		},
+		"tags and rating": {
+			"tags=beach&rating>=2",
+			provider.Metadata{Rating: 2, Tags: []string{"beach", "sea"}},
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			params, _ := url.ParseQuery(tc.query)
+
+			criterions, err := parseSearch(params, time.Now())
+			if err != nil {
+				t.Fatalf("parseSearch() = %s", err)
+			}
+
+			if !criterions.hasMetadata() {
+				t.Errorf("hasMetadata() = false")
+			}
+
+			if got := criterions.matchMetadata(tc.metadata); got != tc.want {
+				t.Errorf("matchMetadata() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
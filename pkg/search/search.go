@@ -59,7 +59,7 @@ func (s Service) Files(r *http.Request, request provider.Request) (items []absto
 		return nil, err
 	}
 
-	if criterions.hasTags() {
+	if criterions.hasMetadata() {
 		metadatas, metaErr := s.exif.GetAllMetadataFor(ctx, items...)
 		if metaErr != nil {
 			slog.LogAttrs(ctx, slog.LevelError, "get all metadata", slog.Any("error", metaErr))
@@ -68,7 +68,7 @@ func (s Service) Files(r *http.Request, request provider.Request) (items []absto
 		filtered := items[:0]
 
 		for _, item := range items {
-			if criterions.matchTags(metadatas[item.ID]) {
+			if criterions.matchMetadata(metadatas[item.ID]) {
 				filtered = append(filtered, item)
 			}
 		}
@@ -50,38 +50,7 @@ func computeMimes(aliases []string) []string {
 	var output []string
 
 	for _, alias := range aliases {
-		switch alias {
-		case "archive":
-			return append(output, getKeysOfMap(provider.ArchiveExtensions)...)
-		case "audio":
-			return append(output, getKeysOfMap(provider.AudioExtensions)...)
-		case "code":
-			return append(output, getKeysOfMap(provider.CodeExtensions)...)
-		case "excel":
-			return append(output, getKeysOfMap(provider.ExcelExtensions)...)
-		case "image":
-			return append(output, getKeysOfMap(provider.ImageExtensions)...)
-		case "pdf":
-			return append(output, getKeysOfMap(provider.PdfExtensions)...)
-		case "video":
-			return append(output, getKeysOfMap(provider.VideoExtensions)...)
-		case "stream":
-			return append(output, getKeysOfMap(provider.StreamExtensions)...)
-		case "word":
-			return append(output, getKeysOfMap(provider.WordExtensions)...)
-		}
-	}
-
-	return output
-}
-
-func getKeysOfMap[T any](input map[string]T) []string {
-	output := make([]string, len(input))
-	var i int64
-
-	for key := range input {
-		output[i] = key
-		i++
+		output = append(output, provider.MimeFamilyExtensions(alias)...)
 	}
 
 	return output
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"slices"
 	"testing"
 	"time"
 )
@@ -92,3 +93,14 @@ func TestComputeSince(t *testing.T) {
 		})
 	}
 }
+
+func TestComputeMimes(t *testing.T) {
+	t.Parallel()
+
+	got := computeMimes([]string{"pdf", "audio", "unknown"})
+	slices.Sort(got)
+
+	if want := []string{".mp3", ".pdf"}; !slices.Equal(got, want) {
+		t.Errorf("computeMimes() = %v, want %v", got, want)
+	}
+}
@@ -20,10 +20,30 @@ func (s Service) AMQPHandler(ctx context.Context, message amqp.Delivery) (err er
 		return fmt.Errorf("decode: %w", err)
 	}
 
-	key := redisKey(s.PathForScale(absto.Item{
+	item := absto.Item{
 		ID:       absto.ID(req.Input),
 		Pathname: req.Input,
-	}, req.Scale))
+	}
+
+	if err = s.redisClient.Delete(ctx, redisKey(s.PathForScale(item, req.Scale))); err != nil {
+		return err
+	}
+
+	if req.Output == getSpritePath(item) {
+		return s.completeSprite(ctx, item)
+	}
+
+	// vignet only replies once the thumbnail is written, but only the small one is shown in the listing
+	if req.Scale != SmallSize || req.Output != s.PathForScale(item, SmallSize) {
+		return nil
+	}
+
+	if item, err = s.storage.Stat(ctx, req.Input); err != nil {
+		return fmt.Errorf("get item: %w", err)
+	}
+
+	s.updatePerceptualHash(ctx, item)
+	s.notifyReady(ctx, item)
 
-	return s.redisClient.Delete(ctx, key)
+	return nil
 }
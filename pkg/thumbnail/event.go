@@ -48,11 +48,22 @@ func (s Service) Rename(ctx context.Context, old, new absto.Item) error {
 		if err := s.redisClient.Delete(ctx, redisKey(oldFilename)); err != nil {
 			slog.LogAttrs(ctx, slog.LevelError, "delete cache", slog.Any("error", err))
 		}
+	}
 
-		if provider.VideoExtensions[old.Extension] != "" && s.HasStream(ctx, old) {
-			if err := s.renameStream(ctx, old, new); err != nil {
-				return fmt.Errorf("rename stream: %w", err)
-			}
+	if provider.VideoExtensions[old.Extension] != "" && (s.HasStream(ctx, old) || s.hasVariants(ctx, old)) {
+		if err := s.renameStream(ctx, old, new); err != nil {
+			return fmt.Errorf("rename stream: %w", err)
+		}
+
+		// The variants still transcoded are awaited under the new name
+		if s.hasPendingStream(ctx, new) {
+			go s.awaitStream(context.WithoutCancel(ctx), new)
+		}
+	}
+
+	if provider.VideoExtensions[old.Extension] != "" {
+		if err := s.renameSprite(ctx, old, new); err != nil {
+			return fmt.Errorf("rename sprite: %w", err)
 		}
 	}
 
@@ -98,16 +109,29 @@ func (s Service) generateItem(ctx context.Context, event provider.Event) {
 		}
 	}
 
+	s.updateMissingPerceptualHash(ctx, event.Item, forced || event.Type == provider.UploadEvent)
+
 	if provider.VideoExtensions[event.Item.Extension] != "" && (forced || !s.HasStream(ctx, event.Item)) {
-		s.generateStreamIfNeeded(ctx, event)
+		if !forced && s.hasPendingStream(ctx, event.Item) {
+			// A generation was requested before a restart, its variants are awaited rather than transcoded again
+			go s.awaitStream(context.WithoutCancel(ctx), event.Item)
+		} else {
+			s.generateStreamIfNeeded(ctx, event)
+		}
+	}
+
+	if provider.VideoExtensions[event.Item.Extension] != "" && s.spriteInterval > 0 && (forced || !s.HasSprite(ctx, event.Item)) {
+		if err := s.cache.EvictOnSuccess(ctx, getSpriteTrackPath(event.Item), s.generateSprite(ctx, event.Item)); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "generate sprite", slog.String("item", event.Item.Pathname), slog.Any("error", err))
+		}
 	}
 }
 
 func (s Service) generateStreamIfNeeded(ctx context.Context, event provider.Event) {
-	if needStream, err := s.shouldGenerateStream(ctx, event.Item); err != nil {
+	if bitrate, err := s.streamBitrate(ctx, event.Item); err != nil {
 		slog.LogAttrs(ctx, slog.LevelError, "determine if stream generation is possible", slog.Any("error", err))
-	} else if needStream {
-		if err = s.cache.EvictOnSuccess(ctx, getStreamPath(event.Item), s.generateStream(ctx, event.Item)); err != nil {
+	} else if bitrate != 0 && bitrate >= s.minBitrate {
+		if err = s.cache.EvictOnSuccess(ctx, getStreamPath(event.Item), s.generateStream(ctx, event.Item, bitrate)); err != nil {
 			slog.LogAttrs(ctx, slog.LevelError, "generate stream", slog.Any("error", err))
 		}
 	}
@@ -131,11 +155,15 @@ func (s Service) delete(ctx context.Context, item absto.Item) {
 		if err := s.redisClient.Delete(ctx, redisKey(filename)); err != nil {
 			slog.LogAttrs(ctx, slog.LevelError, "delete cache", slog.Any("error", err))
 		}
+	}
 
-		if provider.VideoExtensions[item.Extension] != "" && s.HasStream(ctx, item) {
-			if err := s.deleteStream(ctx, item); err != nil {
-				slog.LogAttrs(ctx, slog.LevelError, "delete stream", slog.Any("error", err))
-			}
+	if provider.VideoExtensions[item.Extension] != "" && (s.HasStream(ctx, item) || s.hasVariants(ctx, item)) {
+		if err := s.deleteStream(ctx, item); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "delete stream", slog.Any("error", err))
 		}
 	}
+
+	if provider.VideoExtensions[item.Extension] != "" {
+		s.deleteSprite(ctx, item)
+	}
 }
@@ -41,16 +41,39 @@ func (s Service) generate(ctx context.Context, item absto.Item, scale uint64) (e
 		}
 	}()
 
+	output := s.PathForScale(item, scale)
+
 	if resp.StatusCode == http.StatusNoContent {
-		return nil
-	}
+		// With a direct access, vignet writes the thumbnail itself and answers without content, even when it has nothing to write
+		if _, err = s.storage.Stat(ctx, output); err != nil {
+			if absto.IsNotExist(err) {
+				return nil
+			}
+
+			return fmt.Errorf("stat thumbnail: %w", err)
+		}
+	} else {
+		if err = provider.WriteToStorage(ctx, s.storage, output, resp.ContentLength, resp.Body); err != nil {
+			return err
+		}
 
-	err = provider.WriteToStorage(ctx, s.storage, s.PathForScale(item, scale), resp.ContentLength, resp.Body)
-	if err == nil {
 		s.increaseMetric(ctx, itemType.String(), "save")
 	}
 
-	return err
+	if scale == SmallSize {
+		s.notifyReady(ctx, item)
+	}
+
+	return nil
+}
+
+// notifyReady tells the event bus that the small thumbnail is written, from a goroutine because generation may run on the bus itself
+func (s Service) notifyReady(ctx context.Context, item absto.Item) {
+	if s.pushEvent == nil {
+		return
+	}
+
+	go s.pushEvent(context.WithoutCancel(ctx), provider.NewThumbnailEvent(ctx, item))
 }
 
 func (s Service) requestVignet(ctx context.Context, item absto.Item, itemType vignet.ItemType, scale uint64) (*http.Response, error) {
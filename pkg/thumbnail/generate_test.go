@@ -0,0 +1,116 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/mocks"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGenerate(t *testing.T) {
+	item := absto.Item{
+		ID:        "dd29ecf524b030a65261e3059c48ab9e1ecb2585",
+		Pathname:  "/path/to/file.jpg",
+		Extension: ".jpg",
+	}
+
+	cases := map[string]struct {
+		status     int
+		scale      uint64
+		statErr    error
+		wantWrite  bool
+		wantStat   bool
+		wantNotify bool
+	}{
+		"written": {
+			http.StatusOK,
+			SmallSize,
+			nil,
+			true,
+			false,
+			true,
+		},
+		"large": {
+			http.StatusOK,
+			800,
+			nil,
+			true,
+			false,
+			false,
+		},
+		"written by vignet": {
+			http.StatusNoContent,
+			SmallSize,
+			nil,
+			false,
+			true,
+			true,
+		},
+		"nothing written": {
+			http.StatusNoContent,
+			SmallSize,
+			absto.ErrNotExist(errors.New("thumbnail")),
+			false,
+			true,
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			mockStorage := mocks.NewStorage(ctrl)
+
+			if tc.wantWrite {
+				mockStorage.EXPECT().Mkdir(gomock.Any(), "/.fibr/path/to", gomock.Any()).Return(nil)
+				mockStorage.EXPECT().WriteTo(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			if tc.wantStat {
+				mockStorage.EXPECT().Stat(gomock.Any(), "/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.webp").Return(absto.Item{}, tc.statErr)
+			}
+
+			notified := make(chan provider.Event, 1)
+
+			instance := Service{
+				storage:       mockStorage,
+				vignetRequest: request.New().URL(server.URL),
+				directAccess:  true,
+				largeSize:     800,
+				pushEvent: func(_ context.Context, event provider.Event) {
+					notified <- event
+				},
+			}
+
+			if err := instance.generate(context.TODO(), item, tc.scale); err != nil {
+				t.Errorf("generate() = %s", err)
+			}
+
+			var got bool
+
+			select {
+			case <-notified:
+				got = true
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			if got != tc.wantNotify {
+				t.Errorf("generate() notified = %t, want %t", got, tc.wantNotify)
+			}
+		})
+	}
+}
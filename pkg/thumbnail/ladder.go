@@ -0,0 +1,95 @@
+package thumbnail
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	vignet "github.com/ViBiOh/vignet/pkg/model"
+)
+
+type streamRequest struct {
+	vignet.Request
+	Bitrate uint64 `json:"bitrate,omitempty"`
+}
+
+type rendition struct {
+	Height  uint64
+	Bitrate uint64
+}
+
+func (r rendition) String() string {
+	return fmt.Sprintf("%dp", r.Height)
+}
+
+func parseLadder(raw string) ([]rendition, error) {
+	if len(strings.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+
+	var output []rendition
+
+	for part := range strings.SplitSeq(raw, ",") {
+		rawHeight, rawBitrate, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid rendition `%s`, expected `height:bitrate`", part)
+		}
+
+		height, err := strconv.ParseUint(strings.TrimSuffix(rawHeight, "p"), 10, 64)
+		if err != nil || height == 0 {
+			return nil, fmt.Errorf("invalid height for rendition `%s`", part)
+		}
+
+		bitrate, err := strconv.ParseUint(rawBitrate, 10, 64)
+		if err != nil || bitrate == 0 {
+			return nil, fmt.Errorf("invalid bitrate for rendition `%s`", part)
+		}
+
+		output = append(output, rendition{Height: height, Bitrate: bitrate})
+	}
+
+	slices.SortFunc(output, func(a, b rendition) int {
+		return int(a.Height) - int(b.Height)
+	})
+
+	return output, nil
+}
+
+// renditionsFor keeps the renditions that are lighter than the source, there is no point in streaming a bigger version.
+func renditionsFor(ladder []rendition, bitrate uint64) []rendition {
+	var output []rendition
+
+	for _, rendition := range ladder {
+		if rendition.Bitrate < bitrate {
+			output = append(output, rendition)
+		}
+	}
+
+	return output
+}
+
+func masterPlaylist(id string, renditions []rendition) string {
+	var builder strings.Builder
+
+	builder.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+
+	for _, rendition := range renditions {
+		fmt.Fprintf(&builder, "#EXT-X-STREAM-INF:BANDWIDTH=%d\n%s/%s.m3u8\n", rendition.Bitrate, id, rendition)
+	}
+
+	return builder.String()
+}
+
+// variantsOf lists the variant playlists referenced by a master playlist
+func variantsOf(master string) []string {
+	var output []string
+
+	for line := range strings.Lines(master) {
+		if line = strings.TrimSpace(line); len(line) != 0 && !strings.HasPrefix(line, "#") {
+			output = append(output, line)
+		}
+	}
+
+	return output
+}
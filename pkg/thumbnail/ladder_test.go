@@ -0,0 +1,128 @@
+package thumbnail
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLadder(t *testing.T) {
+	cases := map[string]struct {
+		input   string
+		want    []rendition
+		wantErr bool
+	}{
+		"empty": {
+			"",
+			nil,
+			false,
+		},
+		"sorted": {
+			"1080:5000000, 360p:800000,720:2800000",
+			[]rendition{{360, 800000}, {720, 2800000}, {1080, 5000000}},
+			false,
+		},
+		"no bitrate": {
+			"720",
+			nil,
+			true,
+		},
+		"invalid height": {
+			"hd:2800000",
+			nil,
+			true,
+		},
+		"invalid bitrate": {
+			"720:0",
+			nil,
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			result, err := parseLadder(tc.input)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("parseLadder() error = %v, wantErr %t", err, tc.wantErr)
+			}
+
+			if !reflect.DeepEqual(result, tc.want) {
+				t.Errorf("parseLadder() = %v, want %v", result, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenditionsFor(t *testing.T) {
+	ladder := []rendition{{360, 800000}, {720, 2800000}, {1080, 5000000}}
+
+	cases := map[string]struct {
+		bitrate uint64
+		want    []rendition
+	}{
+		"none": {
+			500000,
+			nil,
+		},
+		"partial": {
+			3000000,
+			[]rendition{{360, 800000}, {720, 2800000}},
+		},
+		"all": {
+			80000000,
+			ladder,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if result := renditionsFor(ladder, tc.bitrate); !reflect.DeepEqual(result, tc.want) {
+				t.Errorf("renditionsFor() = %v, want %v", result, tc.want)
+			}
+		})
+	}
+}
+
+func TestMasterPlaylist(t *testing.T) {
+	cases := map[string]struct {
+		renditions []rendition
+		want       string
+	}{
+		"simple": {
+			[]rendition{{360, 800000}, {720, 2800000}},
+			"#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-STREAM-INF:BANDWIDTH=800000\n1234/360p.m3u8\n#EXT-X-STREAM-INF:BANDWIDTH=2800000\n1234/720p.m3u8\n",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if result := masterPlaylist("1234", tc.renditions); result != tc.want {
+				t.Errorf("masterPlaylist() = `%s`, want `%s`", result, tc.want)
+			}
+		})
+	}
+}
+
+func TestVariantsOf(t *testing.T) {
+	cases := map[string]struct {
+		master string
+		want   []string
+	}{
+		"empty": {
+			"",
+			nil,
+		},
+		"master": {
+			"#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-STREAM-INF:BANDWIDTH=800000\n1234/360p.m3u8\n#EXT-X-STREAM-INF:BANDWIDTH=2800000\n1234/720p.m3u8\n",
+			[]string{"1234/360p.m3u8", "1234/720p.m3u8"},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if result := variantsOf(tc.master); !reflect.DeepEqual(result, tc.want) {
+				t.Errorf("variantsOf() = %v, want %v", result, tc.want)
+			}
+		})
+	}
+}
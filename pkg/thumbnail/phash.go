@@ -0,0 +1,116 @@
+package thumbnail
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log/slog"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"golang.org/x/image/webp"
+)
+
+const (
+	hashWidth  = 9
+	hashHeight = 8
+)
+
+// perceptualHash computes the difference hash of the image: the image is reduced to 9x8 grey cells and each bit tells if a cell is brighter than its right neighbour
+func perceptualHash(img image.Image) uint64 {
+	bounds := img.Bounds()
+	if bounds.Dx() < hashWidth || bounds.Dy() < hashHeight {
+		return 0
+	}
+
+	var cells [hashHeight][hashWidth]float64
+
+	for row := range hashHeight {
+		minY := bounds.Min.Y + row*bounds.Dy()/hashHeight
+		maxY := bounds.Min.Y + (row+1)*bounds.Dy()/hashHeight
+
+		for column := range hashWidth {
+			minX := bounds.Min.X + column*bounds.Dx()/hashWidth
+			maxX := bounds.Min.X + (column+1)*bounds.Dx()/hashWidth
+
+			var sum float64
+
+			for y := minY; y < maxY; y++ {
+				for x := minX; x < maxX; x++ {
+					r, g, b, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+				}
+			}
+
+			cells[row][column] = sum / float64((maxX-minX)*(maxY-minY))
+		}
+	}
+
+	var hash uint64
+
+	for row := range hashHeight {
+		for column := range hashWidth - 1 {
+			hash <<= 1
+
+			if cells[row][column] > cells[row][column+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+func (s Service) perceptualHashOf(ctx context.Context, item absto.Item) (uint64, error) {
+	reader, err := s.storage.ReadFrom(ctx, s.PathForScale(item, SmallSize))
+	if err != nil {
+		return 0, fmt.Errorf("read: %w", err)
+	}
+
+	defer provider.LogClose(ctx, reader, "thumbnail.perceptualHashOf", item.Pathname)
+
+	img, err := webp.Decode(reader)
+	if err != nil {
+		return 0, fmt.Errorf("decode: %w", err)
+	}
+
+	return perceptualHash(img), nil
+}
+
+// updatePerceptualHash stores the hash of the small thumbnail in the metadata of the item
+func (s Service) updatePerceptualHash(ctx context.Context, item absto.Item) {
+	if s.metadata == nil || item.IsDir() {
+		return
+	}
+
+	hash, err := s.perceptualHashOf(ctx, item)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "compute perceptual hash", slog.String("item", item.Pathname), slog.Any("error", err))
+		return
+	}
+
+	if _, err = s.metadata.Update(ctx, item, provider.ReplacePerceptualHash(hash)); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "save perceptual hash", slog.String("item", item.Pathname), slog.Any("error", err))
+	}
+}
+
+// updateMissingPerceptualHash computes the hash of thumbnails generated before it existed
+func (s Service) updateMissingPerceptualHash(ctx context.Context, item absto.Item, forced bool) {
+	if s.metadata == nil || !s.HasThumbnail(ctx, item, SmallSize) {
+		return
+	}
+
+	if !forced {
+		metadata, err := s.metadata.GetMetadataFor(ctx, item)
+		if err != nil && !absto.IsNotExist(err) {
+			slog.LogAttrs(ctx, slog.LevelError, "load metadata", slog.String("item", item.Pathname), slog.Any("error", err))
+			return
+		}
+
+		if metadata.PerceptualHash != 0 {
+			return
+		}
+	}
+
+	s.updatePerceptualHash(ctx, item)
+}
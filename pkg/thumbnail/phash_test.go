@@ -0,0 +1,76 @@
+package thumbnail
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func gradient(width, height int, shade func(x, y int) uint8) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		for x := range width {
+			img.SetGray(x, y, color.Gray{Y: shade(x, y)})
+		}
+	}
+
+	return img
+}
+
+func TestPerceptualHash(t *testing.T) {
+	horizontal := gradient(150, 100, func(x, _ int) uint8 { return uint8(255 - x*255/150) })
+	brighter := gradient(150, 100, func(x, _ int) uint8 { return uint8(min(255, 20+255-x*255/150)) })
+	smaller := gradient(75, 50, func(x, _ int) uint8 { return uint8(255 - x*255/75) })
+	vertical := gradient(150, 100, func(_, y int) uint8 { return uint8(y * 255 / 100) })
+
+	cases := map[string]struct {
+		a       image.Image
+		b       image.Image
+		maxDist int
+		minDist int
+	}{
+		"identical": {
+			horizontal,
+			horizontal,
+			0,
+			0,
+		},
+		"brighter": {
+			horizontal,
+			brighter,
+			10,
+			0,
+		},
+		"resized": {
+			horizontal,
+			smaller,
+			10,
+			0,
+		},
+		"different": {
+			horizontal,
+			vertical,
+			64,
+			30,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got := provider.HammingDistance(perceptualHash(tc.a), perceptualHash(tc.b))
+
+			if got < tc.minDist || got > tc.maxDist {
+				t.Errorf("perceptualHash() distance = %d, want between %d and %d", got, tc.minDist, tc.maxDist)
+			}
+		})
+	}
+}
+
+func TestPerceptualHashTooSmall(t *testing.T) {
+	if got := perceptualHash(gradient(4, 4, func(x, _ int) uint8 { return uint8(x) })); got != 0 {
+		t.Errorf("perceptualHash() = %d, want 0", got)
+	}
+}
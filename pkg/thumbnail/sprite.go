@@ -0,0 +1,231 @@
+package thumbnail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/httperror"
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+	vignet "github.com/ViBiOh/vignet/pkg/model"
+)
+
+const (
+	spriteSuffix  = "_sprite.webp"
+	spriteWidth   = 160
+	spriteHeight  = 90
+	spriteColumns = 10
+)
+
+type spriteRequest struct {
+	vignet.Request
+	Interval uint64 `json:"interval"`
+	Columns  uint64 `json:"columns"`
+}
+
+func (s Service) HasSprite(ctx context.Context, item absto.Item) bool {
+	if s.spriteInterval == 0 {
+		return false
+	}
+
+	_, err := s.Info(ctx, getSpriteTrackPath(item))
+	return err == nil
+}
+
+func (s Service) Sprite(w http.ResponseWriter, r *http.Request, item absto.Item) {
+	s.serveSpriteFile(w, r, item, getSpritePath(item), "image/webp")
+}
+
+func (s Service) SpriteTrack(w http.ResponseWriter, r *http.Request, item absto.Item) {
+	s.serveSpriteFile(w, r, item, getSpriteTrackPath(item), "text/vtt")
+}
+
+func (s Service) serveSpriteFile(w http.ResponseWriter, r *http.Request, item absto.Item, pathname, contentType string) {
+	ctx := r.Context()
+
+	reader, err := s.storage.ReadFrom(ctx, pathname)
+	if err != nil {
+		if absto.IsNotExist(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		httperror.InternalServerError(ctx, w, err)
+		return
+	}
+
+	defer provider.LogClose(ctx, reader, "thumbnail.serveSpriteFile", item.Pathname)
+
+	w.Header().Add("Content-Type", contentType)
+	w.Header().Add("Cache-Control", cacheDuration)
+	http.ServeContent(w, r, pathname, item.Date, reader)
+}
+
+func (s Service) videoDuration(ctx context.Context, item absto.Item) (time.Duration, error) {
+	if !s.directAccess {
+		return 0, nil
+	}
+
+	s.increaseMetric(ctx, "sprite", "duration")
+
+	resp, err := s.vignetRequest.Method(http.MethodHead).Path("%s?type=%s", item.Pathname, typeOfItem(item)).Send(ctx, nil)
+	if err != nil {
+		s.increaseMetric(ctx, "sprite", "error")
+		return 0, fmt.Errorf("retrieve metadata: %w", err)
+	}
+
+	if err := request.DiscardBody(resp.Body); err != nil {
+		return 0, fmt.Errorf("discard body: %w", err)
+	}
+
+	rawDuration := resp.Header.Get("X-Vignet-Duration")
+	if len(rawDuration) == 0 {
+		return 0, nil
+	}
+
+	seconds, err := strconv.ParseFloat(rawDuration, 64)
+	if err != nil {
+		s.increaseMetric(ctx, "sprite", "error")
+		return 0, fmt.Errorf("parse duration: %w", err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func (s Service) generateSprite(ctx context.Context, item absto.Item) error {
+	duration, err := s.videoDuration(ctx, item)
+	if err != nil {
+		return fmt.Errorf("get duration: %w", err)
+	}
+
+	if duration == 0 {
+		return nil
+	}
+
+	input := item.Pathname
+	output := getSpritePath(item)
+	track := spriteTrack(duration, time.Duration(s.spriteInterval)*time.Second)
+
+	if s.amqpClient != nil {
+		// The sprite is generated asynchronously, the track waits aside until completeSprite publishes it
+		if err := s.writeSpriteTrack(ctx, getPendingSpriteTrackPath(item), track); err != nil {
+			return err
+		}
+
+		s.increaseMetric(ctx, "sprite", "publish")
+
+		req := spriteRequest{
+			Request:  vignet.NewRequest(input, output, vignet.TypeVideo, spriteWidth),
+			Interval: s.spriteInterval,
+			Columns:  spriteColumns,
+		}
+
+		if err := s.amqpClient.PublishJSON(ctx, req, s.amqpExchange, s.amqpSpriteRoutingKey); err != nil {
+			s.increaseMetric(ctx, "sprite", "error")
+			return fmt.Errorf("publish: %w", err)
+		}
+
+		return nil
+	}
+
+	s.increaseMetric(ctx, "sprite", "request")
+
+	resp, err := s.vignetRequest.Method(http.MethodPut).Path("%s?output=%s&type=%s&scale=%d&interval=%d&columns=%d", input, url.QueryEscape(output), vignet.TypeVideo, spriteWidth, s.spriteInterval, spriteColumns).Send(ctx, nil)
+	if err != nil {
+		s.increaseMetric(ctx, "sprite", "error")
+		return fmt.Errorf("send request: %w", err)
+	}
+
+	if err := request.DiscardBody(resp.Body); err != nil {
+		return fmt.Errorf("discard body: %w", err)
+	}
+
+	if err := s.writeSpriteTrack(ctx, getSpriteTrackPath(item), track); err != nil {
+		return err
+	}
+
+	s.increaseMetric(ctx, "sprite", "save")
+
+	return nil
+}
+
+func (s Service) writeSpriteTrack(ctx context.Context, pathname, track string) error {
+	if err := provider.WriteToStorage(ctx, s.storage, pathname, int64(len(track)), strings.NewReader(track)); err != nil {
+		return fmt.Errorf("write track: %w", err)
+	}
+
+	return nil
+}
+
+// completeSprite publishes the pending track of a sprite generated through AMQP, players only request the sprite when the track exists
+func (s Service) completeSprite(ctx context.Context, item absto.Item) error {
+	if err := s.storage.Rename(ctx, getPendingSpriteTrackPath(item), getSpriteTrackPath(item)); err != nil {
+		if absto.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("publish track: %w", err)
+	}
+
+	s.increaseMetric(ctx, "sprite", "save")
+
+	return s.redisClient.Delete(ctx, redisKey(getSpriteTrackPath(item)))
+}
+
+func (s Service) renameSprite(ctx context.Context, old, new absto.Item) error {
+	for _, pathnameOf := range []func(absto.Item) string{getSpritePath, getSpriteTrackPath, getPendingSpriteTrackPath} {
+		oldPathname := pathnameOf(old)
+
+		if err := s.storage.Rename(ctx, oldPathname, pathnameOf(new)); err != nil && !absto.IsNotExist(err) {
+			return err
+		}
+
+		if err := s.redisClient.Delete(ctx, redisKey(oldPathname)); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "delete cache", slog.Any("error", err))
+		}
+	}
+
+	return nil
+}
+
+func (s Service) deleteSprite(ctx context.Context, item absto.Item) {
+	for _, pathname := range []string{getSpritePath(item), getSpriteTrackPath(item), getPendingSpriteTrackPath(item)} {
+		if err := s.storage.RemoveAll(ctx, pathname); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "delete sprite", slog.Any("error", err))
+		}
+
+		if err := s.redisClient.Delete(ctx, redisKey(pathname)); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "delete cache", slog.Any("error", err))
+		}
+	}
+}
+
+// spriteTrack describes the sprite grid as WebVTT cues. Cues use a relative query so the track stays valid when the video is renamed or shared.
+func spriteTrack(duration, interval time.Duration) string {
+	var builder strings.Builder
+
+	builder.WriteString("WEBVTT\n")
+
+	for index := 0; time.Duration(index)*interval < duration; index++ {
+		start := time.Duration(index) * interval
+		end := min(start+interval, duration)
+
+		x := (index % spriteColumns) * spriteWidth
+		y := (index / spriteColumns) * spriteHeight
+
+		fmt.Fprintf(&builder, "\n%s --> %s\n?sprite#xywh=%d,%d,%d,%d\n", vttTimestamp(start), vttTimestamp(end), x, y, spriteWidth, spriteHeight)
+	}
+
+	return builder.String()
+}
+
+func vttTimestamp(value time.Duration) string {
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", int(value.Hours()), int(value.Minutes())%60, int(value.Seconds())%60, value.Milliseconds()%1000)
+}
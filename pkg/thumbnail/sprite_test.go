@@ -0,0 +1,220 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/mocks"
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+	"go.uber.org/mock/gomock"
+)
+
+// newVignetStandIn mimics the vignet sidecar: it answers probes with the given duration and accepts sprite generation requests.
+func newVignetStandIn(t *testing.T, duration string, requests *[]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.URL.String())
+
+		switch r.Method {
+		case http.MethodHead:
+			if len(duration) != 0 {
+				w.Header().Set("X-Vignet-Duration", duration)
+			}
+			w.WriteHeader(http.StatusOK)
+		case http.MethodPut:
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestSpriteTrack(t *testing.T) {
+	cases := map[string]struct {
+		duration time.Duration
+		interval time.Duration
+		want     string
+	}{
+		"empty": {
+			0,
+			time.Second * 10,
+			"WEBVTT\n",
+		},
+		"partial": {
+			time.Second*15 + time.Millisecond*500,
+			time.Second * 10,
+			"WEBVTT\n\n00:00:00.000 --> 00:00:10.000\n?sprite#xywh=0,0,160,90\n\n00:00:10.000 --> 00:00:15.500\n?sprite#xywh=160,0,160,90\n",
+		},
+		"new row": {
+			time.Second * 11,
+			time.Second,
+			"WEBVTT\n\n00:00:00.000 --> 00:00:01.000\n?sprite#xywh=0,0,160,90\n\n00:00:01.000 --> 00:00:02.000\n?sprite#xywh=160,0,160,90\n\n00:00:02.000 --> 00:00:03.000\n?sprite#xywh=320,0,160,90\n\n00:00:03.000 --> 00:00:04.000\n?sprite#xywh=480,0,160,90\n\n00:00:04.000 --> 00:00:05.000\n?sprite#xywh=640,0,160,90\n\n00:00:05.000 --> 00:00:06.000\n?sprite#xywh=800,0,160,90\n\n00:00:06.000 --> 00:00:07.000\n?sprite#xywh=960,0,160,90\n\n00:00:07.000 --> 00:00:08.000\n?sprite#xywh=1120,0,160,90\n\n00:00:08.000 --> 00:00:09.000\n?sprite#xywh=1280,0,160,90\n\n00:00:09.000 --> 00:00:10.000\n?sprite#xywh=1440,0,160,90\n\n00:00:10.000 --> 00:00:11.000\n?sprite#xywh=0,90,160,90\n",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if result := spriteTrack(tc.duration, tc.interval); result != tc.want {
+				t.Errorf("spriteTrack() = `%s`, want `%s`", result, tc.want)
+			}
+		})
+	}
+}
+
+func TestVttTimestamp(t *testing.T) {
+	cases := map[string]struct {
+		input time.Duration
+		want  string
+	}{
+		"zero": {
+			0,
+			"00:00:00.000",
+		},
+		"hours": {
+			time.Hour*2 + time.Minute*3 + time.Second*4 + time.Millisecond*5,
+			"02:03:04.005",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if result := vttTimestamp(tc.input); result != tc.want {
+				t.Errorf("vttTimestamp() = %s, want %s", result, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSprite(t *testing.T) {
+	item := absto.Item{
+		ID:        "dd29ecf524b030a65261e3059c48ab9e1ecb2585",
+		Pathname:  "/path/to/file.mov",
+		Extension: ".mov",
+	}
+
+	cases := map[string]struct {
+		duration     string
+		directAccess bool
+		wantRequests []string
+		wantTrack    string
+	}{
+		"no direct access": {
+			"12",
+			false,
+			nil,
+			"",
+		},
+		"no duration": {
+			"",
+			true,
+			[]string{"HEAD /path/to/file.mov?type=video"},
+			"",
+		},
+		"generate": {
+			"12",
+			true,
+			[]string{
+				"HEAD /path/to/file.mov?type=video",
+				"PUT /path/to/file.mov?output=%2F.fibr%2Fpath%2Fto%2Fdd29ecf524b030a65261e3059c48ab9e1ecb2585_sprite.webp&type=video&scale=160&interval=10&columns=10",
+			},
+			"WEBVTT\n\n00:00:00.000 --> 00:00:10.000\n?sprite#xywh=0,0,160,90\n\n00:00:10.000 --> 00:00:12.000\n?sprite#xywh=160,0,160,90\n",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			var requests []string
+			server := newVignetStandIn(t, tc.duration, &requests)
+
+			mockStorage := mocks.NewStorage(ctrl)
+
+			var track string
+			if len(tc.wantTrack) != 0 {
+				mockStorage.EXPECT().Mkdir(gomock.Any(), "/.fibr/path/to", gomock.Any()).Return(nil)
+				mockStorage.EXPECT().WriteTo(gomock.Any(), "/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.vtt", gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string, reader io.Reader, _ absto.WriteOpts) error {
+					content, err := io.ReadAll(reader)
+					track = string(content)
+					return err
+				})
+			}
+
+			instance := Service{
+				storage:        mockStorage,
+				vignetRequest:  request.New().URL(server.URL),
+				spriteInterval: 10,
+				directAccess:   tc.directAccess,
+			}
+
+			if err := instance.generateSprite(context.TODO(), item); err != nil {
+				t.Errorf("generateSprite() = %s", err)
+			}
+
+			if strings.Join(requests, "\n") != strings.Join(tc.wantRequests, "\n") {
+				t.Errorf("generateSprite() requests = %v, want %v", requests, tc.wantRequests)
+			}
+
+			if track != tc.wantTrack {
+				t.Errorf("generateSprite() track = `%s`, want `%s`", track, tc.wantTrack)
+			}
+		})
+	}
+}
+
+func TestCompleteSprite(t *testing.T) {
+	item := absto.Item{
+		ID:        "dd29ecf524b030a65261e3059c48ab9e1ecb2585",
+		Pathname:  "/path/to/file.mov",
+		Extension: ".mov",
+	}
+
+	cases := map[string]struct {
+		renameErr error
+		wantEvict bool
+	}{
+		"pending track": {
+			nil,
+			true,
+		},
+		"no pending track": {
+			absto.ErrNotExist(errors.New("pending")),
+			false,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			mockStorage := mocks.NewStorage(ctrl)
+			mockStorage.EXPECT().Rename(gomock.Any(), "/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.vtt.pending", "/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.vtt").Return(tc.renameErr)
+
+			mockRedisClient := mocks.NewRedisClient(ctrl)
+			if tc.wantEvict {
+				mockRedisClient.EXPECT().Delete(gomock.Any(), redisKey("/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.vtt")).Return(nil)
+			}
+
+			instance := Service{
+				storage:     mockStorage,
+				redisClient: mockRedisClient,
+			}
+
+			if err := instance.completeSprite(context.TODO(), item); err != nil {
+				t.Errorf("completeSprite() = %s", err)
+			}
+		})
+	}
+}
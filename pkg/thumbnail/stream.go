@@ -2,23 +2,124 @@ package thumbnail
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/httputils/v4/pkg/request"
 	"github.com/ViBiOh/vignet/pkg/model"
 )
 
+const (
+	streamCheckInterval = time.Minute
+	streamCheckTimeout  = time.Hour * 12
+)
+
 func (s Service) HasStream(ctx context.Context, item absto.Item) bool {
 	_, err := s.Info(ctx, getStreamPath(item))
 	return err == nil
 }
 
+func (s Service) hasPendingStream(ctx context.Context, item absto.Item) bool {
+	_, err := s.storage.Stat(ctx, getPendingStreamPath(item))
+	return err == nil
+}
+
+// awaitStream checks the variants on a regular basis until completeStream publishes the master playlist, vignet doesn't report the end of a stream generation
+func (s Service) awaitStream(ctx context.Context, item absto.Item) {
+	ctx, cancel := context.WithTimeout(ctx, streamCheckTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(streamCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				slog.LogAttrs(ctx, slog.LevelWarn, "stream not transcoded in time", slog.String("item", item.Pathname))
+			}
+
+			return
+
+		case <-ticker.C:
+			done, err := s.completeStream(ctx, item)
+			if err != nil {
+				slog.LogAttrs(ctx, slog.LevelError, "complete stream", slog.String("item", item.Pathname), slog.Any("error", err))
+				return
+			}
+
+			if done {
+				return
+			}
+		}
+	}
+}
+
+// completeStream publishes the pending master playlist once every variant is fully transcoded.
+// It's done once there is no pending playlist anymore, published, renamed or deleted meanwhile.
+func (s Service) completeStream(ctx context.Context, item absto.Item) (bool, error) {
+	master, err := s.readPlaylist(ctx, getPendingStreamPath(item))
+	if err != nil {
+		if absto.IsNotExist(err) {
+			return true, nil
+		}
+
+		return false, fmt.Errorf("read pending playlist: %w", err)
+	}
+
+	for _, variant := range variantsOf(master) {
+		playlist, err := s.readPlaylist(ctx, provider.MetadataDirectory(item)+variant)
+		if err != nil {
+			if absto.IsNotExist(err) {
+				return false, nil
+			}
+
+			return false, fmt.Errorf("read variant playlist: %w", err)
+		}
+
+		if !strings.Contains(playlist, "#EXT-X-ENDLIST") {
+			return false, nil
+		}
+	}
+
+	if err = s.storage.Rename(ctx, getPendingStreamPath(item), getStreamPath(item)); err != nil {
+		return false, fmt.Errorf("publish playlist: %w", err)
+	}
+
+	s.increaseMetric(ctx, "stream", "save")
+
+	if err = s.redisClient.Delete(ctx, redisKey(getStreamPath(item))); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "delete cache", slog.Any("error", err))
+	}
+
+	return true, nil
+}
+
+func (s Service) readPlaylist(ctx context.Context, pathname string) (string, error) {
+	reader, err := s.storage.ReadFrom(ctx, pathname)
+	if err != nil {
+		return "", err
+	}
+
+	defer provider.LogClose(ctx, reader, "thumbnail.readPlaylist", pathname)
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("read: %w", err)
+	}
+
+	return string(content), nil
+}
+
 func (s Service) handleVignetResponse(ctx context.Context, err error, body io.ReadCloser) error {
 	if err != nil {
 		s.increaseMetric(ctx, "stream", "error")
@@ -32,9 +133,9 @@ func (s Service) handleVignetResponse(ctx context.Context, err error, body io.Re
 	return nil
 }
 
-func (s Service) shouldGenerateStream(ctx context.Context, item absto.Item) (bool, error) {
+func (s Service) streamBitrate(ctx context.Context, item absto.Item) (uint64, error) {
 	if !s.directAccess {
-		return false, nil
+		return 0, nil
 	}
 
 	s.increaseMetric(ctx, "stream", "bitrate")
@@ -42,38 +143,62 @@ func (s Service) shouldGenerateStream(ctx context.Context, item absto.Item) (boo
 	resp, err := s.vignetRequest.Method(http.MethodHead).Path("%s?type=%s", item.Pathname, typeOfItem(item)).Send(ctx, nil)
 	if err != nil {
 		s.increaseMetric(ctx, "stream", "error")
-		return false, fmt.Errorf("retrieve metadata: %w", err)
+		return 0, fmt.Errorf("retrieve metadata: %w", err)
 	}
 
 	rawBitrate := resp.Header.Get("X-Vignet-Bitrate")
 	if len(rawBitrate) == 0 {
-		return false, nil
+		return 0, nil
 	}
 
 	bitrate, err := strconv.ParseUint(rawBitrate, 10, 64)
 	if err != nil {
 		s.increaseMetric(ctx, "stream", "error")
-		return false, fmt.Errorf("parse bitrate: %w", err)
+		return 0, fmt.Errorf("parse bitrate: %w", err)
 	}
 
 	if err := request.DiscardBody(resp.Body); err != nil {
-		return false, fmt.Errorf("discard body: %w", err)
+		return 0, fmt.Errorf("discard body: %w", err)
 	}
 
 	slog.LogAttrs(ctx, slog.LevelDebug, "Bitrate", slog.Uint64("bitrate", bitrate), slog.String("item", item.Pathname))
 
-	return bitrate >= s.minBitrate, nil
+	return bitrate, nil
 }
 
-func (s Service) generateStream(ctx context.Context, item absto.Item) error {
-	input := item.Pathname
-	output := getStreamPath(item)
+func (s Service) generateStream(ctx context.Context, item absto.Item, bitrate uint64) error {
+	renditions := renditionsFor(s.ladder, bitrate)
+	if len(renditions) == 0 {
+		return s.requestStream(ctx, item.Pathname, getStreamPath(item), SmallSize, 0)
+	}
+
+	// Variants are transcoded asynchronously, the master playlist waits aside until completeStream publishes it
+	playlist := masterPlaylist(item.ID, renditions)
+
+	if err := provider.WriteToStorage(ctx, s.storage, getPendingStreamPath(item), int64(len(playlist)), strings.NewReader(playlist)); err != nil {
+		return fmt.Errorf("write playlist: %w", err)
+	}
+
+	for _, rendition := range renditions {
+		if err := s.requestStream(ctx, item.Pathname, getVariantPath(item, rendition), rendition.Height, rendition.Bitrate); err != nil {
+			return fmt.Errorf("generate %s: %w", rendition, err)
+		}
+	}
 
-	req := model.NewRequest(input, getStreamPath(item), typeOfItem(item), SmallSize)
+	go s.awaitStream(context.WithoutCancel(ctx), item)
+
+	return nil
+}
 
+func (s Service) requestStream(ctx context.Context, input, output string, scale, bitrate uint64) error {
 	if s.amqpClient != nil {
 		s.increaseMetric(ctx, "stream", "publish")
 
+		req := streamRequest{
+			Request: model.NewRequest(input, output, model.TypeVideo, scale),
+			Bitrate: bitrate,
+		}
+
 		err := s.amqpClient.PublishJSON(ctx, req, s.amqpExchange, s.amqpStreamRoutingKey)
 		if err != nil {
 			s.increaseMetric(ctx, "stream", "error")
@@ -84,20 +209,80 @@ func (s Service) generateStream(ctx context.Context, item absto.Item) error {
 
 	s.increaseMetric(ctx, "stream", "request")
 
-	resp, err := s.vignetRequest.Method(http.MethodPut).Path("%s?output=%s", input, url.QueryEscape(output)).Send(ctx, nil)
+	req := s.vignetRequest.Method(http.MethodPut)
+	if bitrate == 0 {
+		req = req.Path("%s?output=%s", input, url.QueryEscape(output))
+	} else {
+		req = req.Path("%s?output=%s&scale=%d&bitrate=%d", input, url.QueryEscape(output), scale, bitrate)
+	}
+
+	resp, err := req.Send(ctx, nil)
 	return s.handleVignetResponse(ctx, err, resp.Body)
 }
 
+func (s Service) hasVariants(ctx context.Context, item absto.Item) bool {
+	_, err := s.storage.Stat(ctx, getVariantsPath(item))
+	return err == nil
+}
+
 func (s Service) renameStream(ctx context.Context, old, new absto.Item) error {
 	s.increaseMetric(ctx, "stream", "rename")
 
+	if s.hasVariants(ctx, old) {
+		return s.renameVariants(ctx, old, new)
+	}
+
 	resp, err := s.vignetRequest.Method(http.MethodPatch).Path("%s?to=%s&type=%s", getStreamPath(old), url.QueryEscape(getStreamPath(new)), typeOfItem(old)).Send(ctx, nil)
 	return s.handleVignetResponse(ctx, err, resp.Body)
 }
 
+func (s Service) renameVariants(ctx context.Context, old, new absto.Item) error {
+	if err := s.storage.Rename(ctx, getVariantsPath(old), getVariantsPath(new)); err != nil {
+		return fmt.Errorf("rename variants: %w", err)
+	}
+
+	for _, pathnameOf := range []func(absto.Item) string{getStreamPath, getPendingStreamPath} {
+		content, err := s.readPlaylist(ctx, pathnameOf(old))
+		if err != nil {
+			if absto.IsNotExist(err) {
+				continue
+			}
+
+			return fmt.Errorf("read playlist: %w", err)
+		}
+
+		// Variants are referenced relatively to the master playlist, under a folder named after the item ID
+		playlist := strings.ReplaceAll(content, old.ID+"/", new.ID+"/")
+
+		if err := provider.WriteToStorage(ctx, s.storage, pathnameOf(new), int64(len(playlist)), strings.NewReader(playlist)); err != nil {
+			return fmt.Errorf("write playlist: %w", err)
+		}
+
+		if err := s.storage.RemoveAll(ctx, pathnameOf(old)); err != nil {
+			return fmt.Errorf("remove playlist: %w", err)
+		}
+	}
+
+	return s.redisClient.Delete(ctx, redisKey(getStreamPath(old)))
+}
+
 func (s Service) deleteStream(ctx context.Context, item absto.Item) error {
 	s.increaseMetric(ctx, "stream", "delete")
 
+	if s.hasVariants(ctx, item) {
+		if err := s.storage.RemoveAll(ctx, getVariantsPath(item)); err != nil {
+			return fmt.Errorf("remove variants: %w", err)
+		}
+
+		for _, pathname := range []string{getStreamPath(item), getPendingStreamPath(item)} {
+			if err := s.storage.RemoveAll(ctx, pathname); err != nil {
+				return fmt.Errorf("remove playlist: %w", err)
+			}
+		}
+
+		return s.redisClient.Delete(ctx, redisKey(getStreamPath(item)))
+	}
+
 	resp, err := s.vignetRequest.Method(http.MethodDelete).Path("%s?type=%s", getStreamPath(item), typeOfItem(item)).Send(ctx, nil)
 	return s.handleVignetResponse(ctx, err, resp.Body)
 }
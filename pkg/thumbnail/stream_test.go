@@ -0,0 +1,91 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+type playlistReader struct {
+	*bytes.Reader
+}
+
+func (playlistReader) Close() error {
+	return nil
+}
+
+func TestCompleteStream(t *testing.T) {
+	item := absto.Item{
+		ID:        "dd29ecf524b030a65261e3059c48ab9e1ecb2585",
+		Pathname:  "/path/to/file.mov",
+		Extension: ".mov",
+	}
+
+	master := masterPlaylist(item.ID, []rendition{{Height: 360, Bitrate: 800_000}})
+
+	cases := map[string]struct {
+		master      string
+		variant     string
+		wantPublish bool
+		want        bool
+	}{
+		"transcoded": {
+			master,
+			"#EXTM3U\n#EXT-X-ENDLIST\n",
+			true,
+			true,
+		},
+		"transcoding": {
+			master,
+			"#EXTM3U\n",
+			false,
+			false,
+		},
+		"no pending playlist": {
+			"",
+			"",
+			false,
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			mockStorage := mocks.NewStorage(ctrl)
+			mockRedisClient := mocks.NewRedisClient(ctrl)
+
+			if len(tc.master) == 0 {
+				mockStorage.EXPECT().ReadFrom(gomock.Any(), "/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.m3u8.pending").Return(nil, absto.ErrNotExist(errors.New("pending")))
+			} else {
+				mockStorage.EXPECT().ReadFrom(gomock.Any(), "/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.m3u8.pending").Return(playlistReader{bytes.NewReader([]byte(tc.master))}, nil)
+				mockStorage.EXPECT().ReadFrom(gomock.Any(), "/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585/360p.m3u8").Return(playlistReader{bytes.NewReader([]byte(tc.variant))}, nil)
+			}
+
+			if tc.wantPublish {
+				mockStorage.EXPECT().Rename(gomock.Any(), "/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.m3u8.pending", "/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.m3u8").Return(nil)
+				mockRedisClient.EXPECT().Delete(gomock.Any(), redisKey("/.fibr/path/to/dd29ecf524b030a65261e3059c48ab9e1ecb2585.m3u8")).Return(nil)
+			}
+
+			instance := Service{
+				storage:     mockStorage,
+				redisClient: mockRedisClient,
+			}
+
+			got, err := instance.completeStream(context.TODO(), item)
+			if err != nil {
+				t.Errorf("completeStream() = %s", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("completeStream() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
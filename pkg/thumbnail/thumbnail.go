@@ -42,6 +42,8 @@ type Service struct {
 	largeStorage  absto.Storage
 	pathnameInput chan absto.Item
 	metric        metric.Int64Counter
+	metadata      provider.MetadataManager
+	pushEvent     provider.EventProducer
 
 	cache *cache.Cache[string, absto.Item]
 
@@ -49,13 +51,16 @@ type Service struct {
 	amqpThumbnailRoutingKey string
 	amqpExchange            string
 	amqpStreamRoutingKey    string
-
-	sizes         []uint64
-	vignetRequest request.Request
-	largeSize     uint64
-	maxSize       int64
-	minBitrate    uint64
-	directAccess  bool
+	amqpSpriteRoutingKey    string
+
+	sizes          []uint64
+	ladder         []rendition
+	vignetRequest  request.Request
+	largeSize      uint64
+	maxSize        int64
+	minBitrate     uint64
+	spriteInterval uint64
+	directAccess   bool
 }
 
 type Config struct {
@@ -65,11 +70,15 @@ type Config struct {
 
 	AmqpExchange            string
 	AmqpStreamRoutingKey    string
+	AmqpSpriteRoutingKey    string
 	AmqpThumbnailRoutingKey string
 
-	MaxSize      int64
-	MinBitrate   uint64
-	DirectAccess bool
+	StreamLadder string
+
+	MaxSize        int64
+	MinBitrate     uint64
+	SpriteInterval uint64
+	DirectAccess   bool
 
 	LargeSize uint64
 }
@@ -84,9 +93,12 @@ func Flags(fs *flag.FlagSet, prefix string) *Config {
 	flags.New("DirectAccess", "Use Vignet with direct access to filesystem (no large file upload, send a GET request, Basic Auth recommended)").Prefix(prefix).DocPrefix("thumbnail").BoolVar(fs, &config.DirectAccess, false, nil)
 	flags.New("MaxSize", "Maximum file size (in bytes) for generating thumbnail (0 to no limit). Not used if DirectAccess enabled.").Prefix(prefix).DocPrefix("thumbnail").Int64Var(fs, &config.MaxSize, 1024*1024*200, nil)
 	flags.New("MinBitrate", "Minimal video bitrate (in bits per second) to generate a streamable version (in HLS), if DirectAccess enabled").Prefix(prefix).DocPrefix("thumbnail").Uint64Var(fs, &config.MinBitrate, 80*1000*1000, nil)
+	flags.New("StreamLadder", "Adaptive bitrate ladder of HLS renditions, comma separated height:bitrate (e.g. 360:800000,720:2800000,1080:5000000). Empty for a single rendition").Prefix(prefix).DocPrefix("thumbnail").StringVar(fs, &config.StreamLadder, "", nil)
+	flags.New("SpriteInterval", "Interval (in seconds) between frames of the video seeking preview sprite, if DirectAccess enabled. 0 to disable").Prefix(prefix).DocPrefix("thumbnail").Uint64Var(fs, &config.SpriteInterval, 10, nil)
 
 	flags.New("AmqpExchange", "AMQP Exchange Name").Prefix(prefix).DocPrefix("thumbnail").StringVar(fs, &config.AmqpExchange, "fibr", nil)
 	flags.New("AmqpStreamRoutingKey", "AMQP Routing Key for stream").Prefix(prefix).DocPrefix("thumbnail").StringVar(fs, &config.AmqpStreamRoutingKey, "stream", nil)
+	flags.New("AmqpSpriteRoutingKey", "AMQP Routing Key for video seeking preview sprite").Prefix(prefix).DocPrefix("thumbnail").StringVar(fs, &config.AmqpSpriteRoutingKey, "sprite", nil)
 	flags.New("AmqpThumbnailRoutingKey", "AMQP Routing Key for thumbnail").Prefix(prefix).DocPrefix("thumbnail").StringVar(fs, &config.AmqpThumbnailRoutingKey, "thumbnail", nil)
 
 	flags.New("LargeSize", "Size of large thumbnail for story display (thumbnail are always squared). 0 to disable").Prefix(prefix).DocPrefix("thumbnail").Uint64Var(fs, &config.LargeSize, 800, nil)
@@ -94,7 +106,7 @@ func Flags(fs *flag.FlagSet, prefix string) *Config {
 	return &config
 }
 
-func New(ctx context.Context, config *Config, storage absto.Storage, redisClient redis.Client, meterProvider metric.MeterProvider, traceProvider trace.TracerProvider, amqpClient *amqp.Client) (Service, error) {
+func New(ctx context.Context, config *Config, storage absto.Storage, redisClient redis.Client, meterProvider metric.MeterProvider, traceProvider trace.TracerProvider, amqpClient *amqp.Client, metadataService provider.MetadataManager, eventProducer provider.EventProducer) (Service, error) {
 	var amqpExchange string
 
 	if amqpClient != nil {
@@ -105,6 +117,11 @@ func New(ctx context.Context, config *Config, storage absto.Storage, redisClient
 		}
 	}
 
+	ladder, err := parseLadder(config.StreamLadder)
+	if err != nil {
+		return Service{}, fmt.Errorf("parse stream ladder: %w", err)
+	}
+
 	var sizes []uint64
 	if config.LargeSize > 0 {
 		sizes = []uint64{SmallSize, config.LargeSize}
@@ -115,20 +132,25 @@ func New(ctx context.Context, config *Config, storage absto.Storage, redisClient
 	service := Service{
 		vignetRequest: request.New().URL(config.VignetURL).BasicAuth(config.VignetUser, config.VignetPass).WithClient(provider.SlowClient),
 
-		maxSize:      config.MaxSize,
-		minBitrate:   config.MinBitrate,
-		directAccess: config.DirectAccess,
+		maxSize:        config.MaxSize,
+		minBitrate:     config.MinBitrate,
+		ladder:         ladder,
+		spriteInterval: config.SpriteInterval,
+		directAccess:   config.DirectAccess,
 
 		redisClient: redisClient,
 		tracer:      traceProvider.Tracer("thumbnail"),
+		metadata:    metadataService,
+		pushEvent:   eventProducer,
 
 		amqpExchange:            amqpExchange,
 		amqpStreamRoutingKey:    config.AmqpStreamRoutingKey,
+		amqpSpriteRoutingKey:    config.AmqpSpriteRoutingKey,
 		amqpThumbnailRoutingKey: config.AmqpThumbnailRoutingKey,
 
 		storage: storage,
 		smallStorage: storage.WithIgnoreFn(func(item absto.Item) bool {
-			return !strings.HasSuffix(item.Name(), ".webp") || strings.HasSuffix(item.Name(), "_large.webp")
+			return !strings.HasSuffix(item.Name(), ".webp") || strings.HasSuffix(item.Name(), "_large.webp") || strings.HasSuffix(item.Name(), spriteSuffix)
 		}),
 		largeStorage: storage.WithIgnoreFn(func(item absto.Item) bool {
 			return !strings.HasSuffix(item.Name(), "_large.webp")
@@ -143,8 +165,6 @@ func New(ctx context.Context, config *Config, storage absto.Storage, redisClient
 	if meterProvider != nil {
 		meter := meterProvider.Meter("github.com/ViBiOh/fibr/pkg/thumbnail")
 
-		var err error
-
 		service.metric, err = meter.Int64Counter("fibr_thumbnail")
 		if err != nil {
 			return service, fmt.Errorf("create thumbnail counter: %w", err)
@@ -270,6 +290,9 @@ func (s Service) Save(w http.ResponseWriter, r *http.Request, fibrRequest provid
 	}
 
 	s.increaseMetric(ctx, itemType.String(), "save")
+	s.updatePerceptualHash(ctx, item)
+	s.notifyReady(ctx, item)
+
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -343,7 +366,7 @@ func (s Service) List(w http.ResponseWriter, r *http.Request, item absto.Item, i
 
 	var hash string
 
-	if query.GetBool(r, "search") {
+	if query.GetBool(r, "search") || !item.IsDir() {
 		hash = s.thumbnailHash(ctx, items)
 	} else if thumbnails, err := s.ListDir(ctx, item); err != nil {
 		slog.LogAttrs(ctx, slog.LevelError, "list thumbnails", slog.String("item", item.Pathname), slog.Any("error", err))
@@ -65,6 +65,30 @@ func getStreamPath(item absto.Item) string {
 	return getThumbnailPathForExtension(item, "m3u8")
 }
 
+func getPendingStreamPath(item absto.Item) string {
+	return getThumbnailPathForExtension(item, "m3u8.pending")
+}
+
+func getVariantsPath(item absto.Item) string {
+	return fmt.Sprintf("%s%s/", provider.MetadataDirectory(item), item.ID)
+}
+
+func getVariantPath(item absto.Item, rendition rendition) string {
+	return fmt.Sprintf("%s%s.m3u8", getVariantsPath(item), rendition)
+}
+
+func getSpritePath(item absto.Item) string {
+	return fmt.Sprintf("%s%s%s", provider.MetadataDirectory(item), item.ID, spriteSuffix)
+}
+
+func getSpriteTrackPath(item absto.Item) string {
+	return getThumbnailPathForExtension(item, "vtt")
+}
+
+func getPendingSpriteTrackPath(item absto.Item) string {
+	return getThumbnailPathForExtension(item, "vtt.pending")
+}
+
 func getThumbnailPathForExtension(item absto.Item, extension string) string {
 	return fmt.Sprintf("%s%s.%s", provider.MetadataDirectory(item), item.ID, extension)
 }
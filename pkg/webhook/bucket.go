@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/redis"
+)
+
+const scanPageSize = 50
+
+// newBucketStore keeps the buckets in Redis when it's configured, shared by every instance, or in the storage otherwise
+func newBucketStore(name string, redisClient redis.Client, storageService absto.Storage) bucketStore {
+	if redisClient != nil && redisClient.Enabled() {
+		return redisBuckets{client: redisClient, prefix: "fibr:" + name + ":"}
+	}
+
+	return storageBuckets{storage: storageService, directory: provider.MetadataDirectoryName + "/" + strings.ReplaceAll(name, "-", "_") + "/"}
+}
+
+type redisBuckets struct {
+	client redis.Client
+	prefix string
+}
+
+func (r redisBuckets) load(ctx context.Context, key string) ([]byte, error) {
+	return r.client.Load(ctx, r.prefix+key)
+}
+
+func (r redisBuckets) store(ctx context.Context, key string, content []byte) error {
+	return r.client.Store(ctx, r.prefix+key, content, 0)
+}
+
+func (r redisBuckets) delete(ctx context.Context, key string) error {
+	return r.client.Delete(ctx, r.prefix+key)
+}
+
+func (r redisBuckets) keys(ctx context.Context) ([]string, error) {
+	output := make(chan string, scanPageSize)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- r.client.Scan(ctx, r.prefix+"*", output, scanPageSize)
+	}()
+
+	var keys []string
+
+	for key := range output {
+		keys = append(keys, strings.TrimPrefix(key, r.prefix))
+	}
+
+	return keys, <-done
+}
+
+type storageBuckets struct {
+	storage   absto.Storage
+	directory string
+}
+
+func (s storageBuckets) filename(key string) string {
+	return s.directory + key + ".json"
+}
+
+func (s storageBuckets) load(ctx context.Context, key string) ([]byte, error) {
+	reader, err := s.storage.ReadFrom(ctx, s.filename(key))
+	if err != nil {
+		if absto.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	defer provider.LogClose(ctx, reader, "webhook.storageBuckets.load", key)
+
+	return io.ReadAll(reader)
+}
+
+func (s storageBuckets) store(ctx context.Context, key string, content []byte) error {
+	return provider.WriteToStorage(ctx, s.storage, s.filename(key), int64(len(content)), bytes.NewReader(content))
+}
+
+func (s storageBuckets) delete(ctx context.Context, key string) error {
+	return s.storage.RemoveAll(ctx, s.filename(key))
+}
+
+func (s storageBuckets) keys(ctx context.Context) ([]string, error) {
+	items, err := s.storage.List(ctx, s.directory)
+	if err != nil {
+		if absto.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("list: %w", err)
+	}
+
+	var keys []string
+
+	for _, item := range items {
+		if !item.IsDir() && path.Ext(item.Name()) == ".json" {
+			keys = append(keys, strings.TrimSuffix(item.Name(), ".json"))
+		}
+	}
+
+	return keys, nil
+}
@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"sort"
 	"time"
@@ -19,6 +20,10 @@ func (s *Service) generateID() (string, error) {
 	}
 }
 
+func generateSecret() string {
+	return "whsec_" + rand.Text()
+}
+
 func (s *Service) List() []provider.Webhook {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -66,24 +71,18 @@ func (s *Service) FindByURL(url string) []provider.Webhook {
 	return webhooks
 }
 
-func (s *Service) Create(ctx context.Context, pathname string, recursive bool, kind provider.WebhookKind, url string, types []provider.EventType) (string, error) {
-	var id string
+// Create saves the webhook unless a similar one exists, returning its ID and the secret of a new signed one, given only once
+func (s *Service) Create(ctx context.Context, webhook provider.Webhook) (string, string, error) {
+	var id, secret string
 
-	return id, s.Exclusive(ctx, "create", func(ctx context.Context) (err error) {
+	return id, secret, s.Exclusive(ctx, "create", func(ctx context.Context) (err error) {
 		id, err = s.generateID()
 		if err != nil {
 			return fmt.Errorf("generate id: %w", err)
 		}
 
-		webhook := provider.Webhook{
-			ID:        id,
-			Pathname:  pathname,
-			Recursive: recursive,
-			Kind:      kind,
-			URL:       url,
-			Types:     types,
-			Created:   time.Now(),
-		}
+		webhook.ID = id
+		webhook.Created = time.Now()
 
 		for existingID, existing := range s.webhooks {
 			if webhook.Similar(existing) {
@@ -92,20 +91,65 @@ func (s *Service) Create(ctx context.Context, pathname string, recursive bool, k
 			}
 		}
 
-		s.webhooks[id] = webhook
+		if webhook.Signed() {
+			secret = generateSecret()
+			webhook.Secrets = []provider.WebhookSecret{{Value: secret}}
+		}
+
+		return s.save(ctx, webhook)
+	})
+}
+
+// Rotate generates a new secret for the webhook, the previous ones still signing the deliveries during the grace period
+func (s *Service) Rotate(ctx context.Context, id string, grace time.Duration) (string, error) {
+	var secret string
 
-		if err = provider.SaveJSON(ctx, s.storage, webhookFilename, s.webhooks); err != nil {
-			return fmt.Errorf("save webhooks: %w", err)
+	return secret, s.Exclusive(ctx, id, func(ctx context.Context) error {
+		webhook, ok := s.webhooks[id]
+		if !ok {
+			return fmt.Errorf("webhook with id `%s` not found", id)
 		}
 
-		if err = s.redisClient.PublishJSON(ctx, s.pubsubChannel, webhook); err != nil {
-			return fmt.Errorf("publish webhook creation: %w", err)
+		if !webhook.Signed() {
+			return fmt.Errorf("webhook with id `%s` of kind `%s` is not signed", id, webhook.Kind)
 		}
 
-		return nil
+		secret = generateSecret()
+		webhook.Rotate(secret, time.Now(), grace)
+
+		return s.save(ctx, webhook)
+	})
+}
+
+// Update replaces the preferences of an existing webhook, its secrets being kept
+func (s *Service) Update(ctx context.Context, webhook provider.Webhook) error {
+	return s.Exclusive(ctx, webhook.ID, func(ctx context.Context) error {
+		existing, ok := s.webhooks[webhook.ID]
+		if !ok {
+			return fmt.Errorf("webhook with id `%s` not found", webhook.ID)
+		}
+
+		webhook.Created = existing.Created
+		webhook.Secrets = existing.Secrets
+
+		return s.save(ctx, webhook)
 	})
 }
 
+func (s *Service) save(ctx context.Context, webhook provider.Webhook) error {
+	s.webhooks[webhook.ID] = webhook
+
+	if err := provider.SaveJSON(ctx, s.storage, webhookFilename, s.webhooks); err != nil {
+		return fmt.Errorf("save webhooks: %w", err)
+	}
+
+	if err := s.redisClient.PublishJSON(ctx, s.pubsubChannel, webhook); err != nil {
+		return fmt.Errorf("publish webhook: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Service) Delete(ctx context.Context, id string) error {
 	return s.Exclusive(ctx, id, func(_ context.Context) error {
 		return s.delete(ctx, id)
@@ -123,5 +167,13 @@ func (s *Service) delete(ctx context.Context, id string) error {
 		return fmt.Errorf("publish webhook deletion: %w", err)
 	}
 
+	if err := s.forgetDeliveries(ctx, id); err != nil {
+		return fmt.Errorf("forget deliveries: %w", err)
+	}
+
+	if err := s.digests.Forget(ctx, id); err != nil {
+		return fmt.Errorf("forget digest: %w", err)
+	}
+
 	return nil
 }
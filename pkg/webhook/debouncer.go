@@ -2,83 +2,167 @@ package webhook
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
 	"time"
+
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+	"github.com/ViBiOh/fibr/pkg/provider"
 )
 
+const debounceInterval = time.Minute
+
+// Bucket groups the items received until its date, pushed back on each new item
 type Bucket[T any] struct {
-	date  time.Time
-	items []T
+	Date  time.Time `json:"date"`
+	Group string    `json:"group"`
+	Items []T       `json:"items"`
 }
 
-type Input[T any] struct {
-	item  T
-	group string
+// bucketStore persists the buckets of a debouncer by key, a missing one being loaded as nil
+type bucketStore interface {
+	load(ctx context.Context, key string) ([]byte, error)
+	store(ctx context.Context, key string, content []byte) error
+	delete(ctx context.Context, key string) error
+	keys(ctx context.Context) ([]string, error)
 }
 
+// GroupDebouncer calls the action with the items of a group once none has been received during its window.
+// Buckets are persisted so they survive a restart, and each one is locked so that a single instance flushes it.
 type GroupDebouncer[T any] struct {
-	done     chan struct{}
-	ch       chan Input[T]
-	state    map[string]*Bucket[T]
-	action   func(string, []T)
-	duration time.Duration
+	store     bucketStore
+	exclusive exclusive.Service
+	done      chan struct{}
+	action    func(string, []T)
+	name      string
+	mutex     sync.Mutex
 }
 
-func NewDebouncer[T any](duration time.Duration, action func(string, []T)) *GroupDebouncer[T] {
+func NewDebouncer[T any](name string, store bucketStore, exclusiveService exclusive.Service, action func(string, []T)) *GroupDebouncer[T] {
 	return &GroupDebouncer[T]{
-		duration: duration,
-		state:    make(map[string]*Bucket[T]),
-		ch:       make(chan Input[T]),
-		done:     make(chan struct{}),
-		action:   action,
+		name:      name,
+		store:     store,
+		exclusive: exclusiveService,
+		done:      make(chan struct{}),
+		action:    action,
 	}
 }
 
 func (d *GroupDebouncer[T]) Start(ctx context.Context) {
 	defer close(d.done)
 
-	ticker := time.NewTicker(d.duration)
+	ticker := time.NewTicker(debounceInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			d.scan(time.Time{})
 			return
 
 		case now := <-ticker.C:
-			d.scan(now)
-
-		case input := <-d.ch:
-			bucket := d.state[input.group]
-			if bucket == nil {
-				bucket = &Bucket[T]{}
-			}
+			d.flush(ctx, now)
+		}
+	}
+}
 
-			bucket.date = time.Now().Add(d.duration)
-			bucket.items = append(bucket.items, input.item)
+// Send adds the items to the bucket of their group, that will be flushed after the window without any new item.
+// The date of the bucket is only pushed back, a shorter window not flushing it earlier.
+func (d *GroupDebouncer[T]) Send(ctx context.Context, group string, window time.Duration, items ...T) error {
+	return d.update(ctx, provider.Hash(group), func(bucket *Bucket[T]) bool {
+		bucket.Group = group
 
-			d.state[input.group] = bucket
+		if date := time.Now().Add(window); date.After(bucket.Date) {
+			bucket.Date = date
 		}
-	}
+
+		bucket.Items = append(bucket.Items, items...)
+
+		return true
+	})
 }
 
-func (d *GroupDebouncer[T]) Send(group string, item T) {
-	d.ch <- Input[T]{
-		group: group,
-		item:  item,
-	}
+// Forget drops the pending items of the group without calling the action
+func (d *GroupDebouncer[T]) Forget(ctx context.Context, group string) error {
+	return d.update(ctx, provider.Hash(group), func(bucket *Bucket[T]) bool {
+		if len(bucket.Items) == 0 {
+			return false
+		}
+
+		bucket.Items = nil
+
+		return true
+	})
 }
 
 func (d *GroupDebouncer[T]) Done() <-chan struct{} {
 	return d.done
 }
 
-func (d *GroupDebouncer[T]) scan(now time.Time) {
-	for group, bucket := range d.state {
-		if now.IsZero() || bucket.date.Before(now) {
-			d.action(group, bucket.items)
+// flush pops every bucket due at the given time and calls the action outside the lock
+func (d *GroupDebouncer[T]) flush(ctx context.Context, now time.Time) {
+	keys, err := d.store.keys(ctx)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "list debounced buckets", slog.String("name", d.name), slog.Any("error", err))
+		return
+	}
+
+	for _, key := range keys {
+		var due Bucket[T]
+
+		err := d.update(ctx, key, func(bucket *Bucket[T]) bool {
+			if len(bucket.Items) == 0 || bucket.Date.After(now) {
+				return false
+			}
+
+			due = *bucket
+			bucket.Items = nil
+
+			return true
+		})
+		if err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "pop debounced bucket", slog.String("name", d.name), slog.String("key", key), slog.Any("error", err))
+			continue
+		}
 
-			delete(d.state, group)
+		if len(due.Items) != 0 {
+			d.action(due.Group, due.Items)
 		}
 	}
 }
+
+// update changes the bucket of the key under its lock, saving it if asked to and deleting it once empty
+func (d *GroupDebouncer[T]) update(ctx context.Context, key string, change func(*Bucket[T]) bool) error {
+	return d.exclusive.Execute(ctx, fmt.Sprintf("fibr:mutex:%s:%s", d.name, key), exclusive.Duration, func(ctx context.Context) error {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+
+		var bucket Bucket[T]
+
+		content, err := d.store.load(ctx, key)
+		if err != nil {
+			return fmt.Errorf("load: %w", err)
+		}
+
+		if len(content) != 0 {
+			if err = json.Unmarshal(content, &bucket); err != nil {
+				return fmt.Errorf("unmarshal: %w", err)
+			}
+		}
+
+		if !change(&bucket) {
+			return nil
+		}
+
+		if len(bucket.Items) == 0 {
+			return d.store.delete(ctx, key)
+		}
+
+		if content, err = json.Marshal(bucket); err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+
+		return d.store.store(ctx, key, content)
+	})
+}
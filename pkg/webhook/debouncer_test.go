@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+)
+
+type memoryBuckets struct {
+	content map[string][]byte
+	mutex   sync.Mutex
+}
+
+func (m *memoryBuckets) load(_ context.Context, key string) ([]byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.content[key], nil
+}
+
+func (m *memoryBuckets) store(_ context.Context, key string, content []byte) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.content[key] = content
+
+	return nil
+}
+
+func (m *memoryBuckets) delete(_ context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.content, key)
+
+	return nil
+}
+
+func (m *memoryBuckets) keys(_ context.Context) ([]string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var keys []string
+	for key := range m.content {
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func TestGroupDebouncer(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryBuckets{content: make(map[string][]byte)}
+
+	flushed := make(map[string][]string)
+	action := func(group string, items []string) {
+		flushed[group] = append(flushed[group], items...)
+	}
+
+	instance := NewDebouncer("test", store, exclusive.New(nil), action)
+
+	for _, item := range []string{"sunset.jpg", "sunrise.jpg"} {
+		if err := instance.Send(ctx, "alice", time.Minute, item); err != nil {
+			t.Fatalf("Send() = %s", err)
+		}
+	}
+
+	if err := instance.Send(ctx, "bob", time.Hour, "beach.jpg"); err != nil {
+		t.Fatalf("Send() = %s", err)
+	}
+
+	// a shorter window doesn't bring the bucket forward
+	if err := instance.Send(ctx, "bob", time.Minute, "dunes.jpg"); err != nil {
+		t.Fatalf("Send() = %s", err)
+	}
+
+	// a forgotten group is never flushed
+	if err := instance.Send(ctx, "carol", time.Minute, "snow.jpg", "ski.jpg"); err != nil {
+		t.Fatalf("Send() = %s", err)
+	}
+
+	if err := instance.Forget(ctx, "carol"); err != nil {
+		t.Fatalf("Forget() = %s", err)
+	}
+
+	// a restarted instance finds the buckets in the store
+	restarted := NewDebouncer("test", store, exclusive.New(nil), action)
+
+	restarted.flush(ctx, time.Now())
+
+	if len(flushed) != 0 {
+		t.Errorf("flush() = %v, want nothing before the window", flushed)
+	}
+
+	restarted.flush(ctx, time.Now().Add(time.Minute*2))
+
+	if want := map[string][]string{"alice": {"sunset.jpg", "sunrise.jpg"}}; !reflect.DeepEqual(flushed, want) {
+		t.Errorf("flush() = %v, want %v", flushed, want)
+	}
+
+	restarted.flush(ctx, time.Now().Add(time.Hour*2))
+
+	if want := map[string][]string{"alice": {"sunset.jpg", "sunrise.jpg"}, "bob": {"beach.jpg", "dunes.jpg"}}; !reflect.DeepEqual(flushed, want) {
+		t.Errorf("flush() = %v, want %v", flushed, want)
+	}
+
+	if len(store.content) != 0 {
+		t.Errorf("flush() left %d bucket(s) in the store", len(store.content))
+	}
+}
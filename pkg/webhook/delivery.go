@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const (
+	maxDeliveries  = 20
+	maxDeadLetters = 50
+)
+
+var (
+	deliveriesDirectory = provider.MetadataDirectoryName + "/webhook_deliveries/"
+
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+
+	errPermanent = errors.New("not retryable")
+)
+
+// deliver sends the event to the webhook, retrying with an exponential backoff on network errors and server errors
+func (s *Service) deliver(ctx context.Context, webhook provider.Webhook, event provider.Event) provider.WebhookDelivery {
+	delivery := provider.WebhookDelivery{
+		ID:    provider.Hash(provider.Identifier())[:8],
+		Time:  time.Now(),
+		Event: event,
+	}
+
+	delay := s.retryDelay
+
+	for {
+		delivery.Attempts++
+
+		start := time.Now()
+		statusCode, err := s.handle(ctx, webhook, event)
+
+		delivery.Latency = time.Since(start)
+		delivery.Status = statusCode
+		delivery.Error = ""
+
+		s.increaseMetric(ctx, strconv.Itoa(statusCode))
+
+		if err == nil {
+			return delivery
+		}
+
+		delivery.Error = err.Error()
+
+		if !retryable(statusCode, err) || delivery.Attempts > s.retries {
+			return delivery
+		}
+
+		select {
+		case <-ctx.Done():
+			return delivery
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func retryable(statusCode int, err error) bool {
+	if errors.Is(err, errPermanent) {
+		return false
+	}
+
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// record adds the delivery to the log of the webhook, in the dead letters too if it failed
+func (s *Service) record(ctx context.Context, id string, delivery provider.WebhookDelivery) error {
+	return s.updateDeliveries(ctx, id, func(log provider.WebhookLog) provider.WebhookLog {
+		log.Deliveries = prepend(log.Deliveries, delivery, maxDeliveries)
+		if delivery.Failed() {
+			log.DeadLetters = prepend(log.DeadLetters, delivery, maxDeadLetters)
+		}
+
+		return log
+	})
+}
+
+func (s *Service) forgetDeliveries(ctx context.Context, id string) error {
+	return s.exclusiveService.Execute(ctx, deliveriesMutex(id), exclusive.Duration, func(ctx context.Context) error {
+		s.logsMutex.Lock()
+		defer s.logsMutex.Unlock()
+
+		return s.storage.RemoveAll(ctx, deliveriesFilename(id))
+	})
+}
+
+// updateDeliveries rewrites the log of a single webhook, under its own lock, so deliveries of different webhooks don't wait for each other across instances.
+// The exclusive lock does nothing without Redis, the local one keeps the deliveries of this instance from overwriting each other.
+func (s *Service) updateDeliveries(ctx context.Context, id string, update func(provider.WebhookLog) provider.WebhookLog) error {
+	return s.exclusiveService.Execute(ctx, deliveriesMutex(id), exclusive.Duration, func(ctx context.Context) error {
+		s.logsMutex.Lock()
+		defer s.logsMutex.Unlock()
+
+		log, err := s.loadDeliveries(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err = s.storage.Mkdir(ctx, deliveriesDirectory, absto.DirectoryPerm); err != nil {
+			return fmt.Errorf("create dir: %w", err)
+		}
+
+		if err = provider.SaveJSON(ctx, s.storage, deliveriesFilename(id), update(log)); err != nil {
+			return fmt.Errorf("save deliveries: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *Service) loadDeliveries(ctx context.Context, id string) (provider.WebhookLog, error) {
+	log, err := provider.LoadJSON[provider.WebhookLog](ctx, s.storage, deliveriesFilename(id))
+	if err != nil && !absto.IsNotExist(err) {
+		return log, fmt.Errorf("load deliveries: %w", err)
+	}
+
+	return log, nil
+}
+
+// Deliveries returns the log of every webhook, keyed by webhook's ID
+func (s *Service) Deliveries(ctx context.Context) (map[string]provider.WebhookLog, error) {
+	logs := make(map[string]provider.WebhookLog)
+
+	for _, webhook := range s.List() {
+		log, err := s.loadDeliveries(ctx, webhook.ID)
+		if err != nil {
+			return logs, fmt.Errorf("webhook `%s`: %w", webhook.ID, err)
+		}
+
+		logs[webhook.ID] = log
+	}
+
+	return logs, nil
+}
+
+func deliveriesFilename(id string) string {
+	return deliveriesDirectory + id + ".json"
+}
+
+func deliveriesMutex(id string) string {
+	return "fibr:mutex:webhook-deliveries:" + id
+}
+
+// Fire sends the event to the webhook right away, e.g. for testing it
+func (s *Service) Fire(ctx context.Context, id string, event provider.Event) (provider.WebhookDelivery, error) {
+	webhook := s.Get(id)
+	if len(webhook.ID) == 0 {
+		return provider.WebhookDelivery{}, ErrWebhookNotFound
+	}
+
+	delivery := s.deliver(ctx, webhook, event)
+
+	return delivery, s.record(ctx, id, delivery)
+}
+
+// Replay sends again the event of a past delivery, it leaves the dead letters if it succeeds
+func (s *Service) Replay(ctx context.Context, id, deliveryID string) (provider.WebhookDelivery, error) {
+	webhook := s.Get(id)
+	if len(webhook.ID) == 0 {
+		return provider.WebhookDelivery{}, ErrWebhookNotFound
+	}
+
+	log, err := s.loadDeliveries(ctx, id)
+	if err != nil {
+		return provider.WebhookDelivery{}, err
+	}
+
+	previous, ok := findDelivery(log, deliveryID)
+	if !ok {
+		return provider.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+
+	delivery := s.deliver(ctx, webhook, previous.Event)
+
+	return delivery, s.updateDeliveries(ctx, id, func(log provider.WebhookLog) provider.WebhookLog {
+		log.Deliveries = prepend(log.Deliveries, delivery, maxDeliveries)
+		log.DeadLetters = slices.DeleteFunc(log.DeadLetters, func(item provider.WebhookDelivery) bool {
+			return item.ID == deliveryID
+		})
+
+		if delivery.Failed() {
+			log.DeadLetters = prepend(log.DeadLetters, delivery, maxDeadLetters)
+		}
+
+		return log
+	})
+}
+
+func findDelivery(log provider.WebhookLog, id string) (provider.WebhookDelivery, bool) {
+	for _, delivery := range slices.Concat(log.DeadLetters, log.Deliveries) {
+		if delivery.ID == id {
+			return delivery, true
+		}
+	}
+
+	return provider.WebhookDelivery{}, false
+}
+
+func prepend(deliveries []provider.WebhookDelivery, delivery provider.WebhookDelivery, limit int) []provider.WebhookDelivery {
+	output := append([]provider.WebhookDelivery{delivery}, deliveries...)
+
+	return output[:min(len(output), limit)]
+}
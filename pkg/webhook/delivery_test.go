@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/filesystem"
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestDeliver(t *testing.T) {
+	cases := map[string]struct {
+		statuses     []int
+		wantStatus   int
+		wantAttempts int
+		wantFailed   bool
+	}{
+		"success": {
+			[]int{http.StatusNoContent},
+			http.StatusNoContent,
+			1,
+			false,
+		},
+		"retried": {
+			[]int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			http.StatusOK,
+			3,
+			false,
+		},
+		"client error": {
+			[]int{http.StatusNotFound, http.StatusOK},
+			http.StatusNotFound,
+			1,
+			true,
+		},
+		"exhausted": {
+			[]int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			http.StatusInternalServerError,
+			3,
+			true,
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			var calls atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.statuses[min(int(calls.Add(1))-1, len(tc.statuses)-1)])
+			}))
+			defer server.Close()
+
+			instance := &Service{retries: 2, retryDelay: time.Millisecond}
+
+			got := instance.deliver(context.Background(), provider.Webhook{ID: "abc", URL: server.URL, Kind: provider.Raw}, provider.Event{Type: provider.UploadEvent})
+
+			if got.Status != tc.wantStatus || got.Attempts != tc.wantAttempts || got.Failed() != tc.wantFailed {
+				t.Errorf("deliver() = status %d after %d attempt(s), failed %t, want status %d after %d attempt(s), failed %t", got.Status, got.Attempts, got.Failed(), tc.wantStatus, tc.wantAttempts, tc.wantFailed)
+			}
+		})
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	deliveries := []provider.WebhookDelivery{{ID: "2"}, {ID: "1"}}
+
+	got := prepend(deliveries, provider.WebhookDelivery{ID: "3"}, 2)
+
+	if len(got) != 2 || got[0].ID != "3" || got[1].ID != "2" {
+		t.Errorf("prepend() = %+v, want the 2 most recent", got)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	storageService, err := filesystem.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("filesystem.New() = %s", err)
+	}
+
+	instance := &Service{storage: storageService, exclusiveService: exclusive.New(nil)}
+
+	var wg sync.WaitGroup
+
+	for index := range maxDeliveries {
+		wg.Go(func() {
+			if err := instance.record(context.Background(), "abc", provider.WebhookDelivery{ID: strconv.Itoa(index)}); err != nil {
+				t.Errorf("record() = %s", err)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	got, err := instance.loadDeliveries(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("loadDeliveries() = %s", err)
+	}
+
+	if len(got.Deliveries) != maxDeliveries {
+		t.Errorf("record() kept %d deliveries, want %d", len(got.Deliveries), maxDeliveries)
+	}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/email"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const maxDigestEvents = 100
+
+// nextDigest returns when the digest started at since is due: at the next hour, or the next day or monday at the given hour
+func nextDigest(frequency string, since time.Time, hour int) time.Time {
+	if frequency == "hourly" {
+		return since.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	next := time.Date(since.Year(), since.Month(), since.Day(), hour, 0, 0, 0, since.Location())
+	if !next.After(since) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	if frequency == "weekly" {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+
+	return next
+}
+
+// queueDigest adds the event to the digest of the webhook, a bucket of the digests' debouncer due at the next sending.
+// Every event until then is due at the same time, so the bucket is flushed once.
+func (s *Service) queueDigest(ctx context.Context, webhook provider.Webhook, event provider.Event) error {
+	if !s.email.Enabled() {
+		return fmt.Errorf("queue digest for webhook with id `%s`: %w", webhook.ID, email.ErrNoConfig)
+	}
+
+	now := time.Now()
+
+	return s.digests.Send(ctx, webhook.ID, nextDigest(webhook.Digest, now, s.digestHour).Sub(now), event)
+}
+
+// sendDigest sends the events debounced for a webhook, dropped if it has been deleted or isn't a digest anymore.
+// A failed one is queued back, to be retried on the next flush.
+func (s *Service) sendDigest(group string, events []provider.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	webhook := s.Get(group)
+	if len(webhook.ID) == 0 || webhook.Kind != provider.Email || len(webhook.Digest) == 0 {
+		return
+	}
+
+	title := fmt.Sprintf("%s digest of %s: %d event(s)", webhook.Digest, webhook.Pathname, len(events))
+
+	if err := s.email.Send(ctx, s.emailMessage(ctx, webhook, title, events[:min(len(events), maxDigestEvents)], len(events))); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "send digest", slog.String("id", webhook.ID), slog.Any("error", err))
+
+		if err = s.digests.Send(ctx, webhook.ID, 0, events...); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "requeue digest", slog.String("id", webhook.ID), slog.Any("error", err))
+		}
+
+		return
+	}
+
+	s.increaseMetric(ctx, "digest")
+}
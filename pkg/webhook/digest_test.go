@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestNextDigest(t *testing.T) {
+	// Wednesday
+	since := time.Date(2024, 7, 17, 9, 42, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		frequency string
+		since     time.Time
+		want      time.Time
+	}{
+		"hourly": {
+			"hourly",
+			since,
+			time.Date(2024, 7, 17, 10, 0, 0, 0, time.UTC),
+		},
+		"daily after the hour": {
+			"daily",
+			since,
+			time.Date(2024, 7, 18, 8, 0, 0, 0, time.UTC),
+		},
+		"daily before the hour": {
+			"daily",
+			time.Date(2024, 7, 17, 7, 59, 0, 0, time.UTC),
+			time.Date(2024, 7, 17, 8, 0, 0, 0, time.UTC),
+		},
+		"weekly": {
+			"weekly",
+			since,
+			time.Date(2024, 7, 22, 8, 0, 0, 0, time.UTC),
+		},
+		"weekly on monday morning": {
+			"weekly",
+			time.Date(2024, 7, 22, 6, 0, 0, 0, time.UTC),
+			time.Date(2024, 7, 22, 8, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := nextDigest(tc.frequency, tc.since, 8); !got.Equal(tc.want) {
+				t.Errorf("nextDigest() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	upload := provider.Event{
+		Time: time.Date(2024, 7, 14, 12, 0, 0, 0, time.UTC),
+		Type: provider.UploadEvent,
+		URL:  "https://fibr.local/photos/sunset.jpg",
+		Item: absto.Item{ID: "8a3b1f", Pathname: "/photos/sunset.jpg", NameValue: "sunset.jpg", Extension: ".jpg"},
+	}
+
+	deletion := provider.Event{
+		Type: provider.DeleteEvent,
+		URL:  "https://fibr.local/photos/old.txt",
+		Item: absto.Item{Pathname: "/photos/old.txt", NameValue: "old.txt", Extension: ".txt"},
+	}
+
+	cases := map[string]struct {
+		title       string
+		events      []provider.Event
+		count       int
+		wantSubject string
+		wantText    string
+		wantHTML    []string
+	}{
+		"single": {
+			"",
+			[]provider.Event{upload},
+			1,
+			"[fibr] photos: 💾 A file has been uploaded",
+			"💾 A file has been uploaded: https://fibr.local/photos/sunset.jpg?browser\n",
+			[]string{`<a href="https://fibr.local/photos/sunset.jpg?browser">photos</a>`, "<strong>item</strong>: sunset.jpg"},
+		},
+		"digest": {
+			"daily digest of /photos/: 3 event(s)",
+			[]provider.Event{upload, deletion},
+			3,
+			"[fibr] daily digest of /photos/: 3 event(s)",
+			"💾 A file has been uploaded: https://fibr.local/photos/sunset.jpg?browser\n❌ `old.txt` has been deleted : https://fibr.local/photos/old.txt\nAnd 1 more event(s).\n",
+			[]string{"<h2>daily digest of /photos/: 3 event(s)</h2>", "❌ `old.txt` has been deleted", "And 1 more event(s)."},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got := (&Service{}).emailMessage(context.Background(), provider.Webhook{URL: "alice@example.com"}, tc.title, tc.events, tc.count)
+
+			if len(got.To) != 1 || got.To[0] != "alice@example.com" {
+				t.Errorf("emailMessage() to = %v, want alice@example.com", got.To)
+			}
+
+			if got.Subject != tc.wantSubject {
+				t.Errorf("emailMessage() subject = `%s`, want `%s`", got.Subject, tc.wantSubject)
+			}
+
+			if got.Text != tc.wantText {
+				t.Errorf("emailMessage() text = `%s`, want `%s`", got.Text, tc.wantText)
+			}
+
+			for _, want := range tc.wantHTML {
+				if !strings.Contains(got.HTML, want) {
+					t.Errorf("emailMessage() html = `%s`, want `%s` in it", got.HTML, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"net/textproto"
+	"strings"
+
+	"github.com/ViBiOh/fibr/pkg/email"
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/fibr/pkg/thumbnail"
+)
+
+const (
+	smtpOK              = 250 // status of a delivery accepted by the SMTP server
+	maxInlineThumbnails = 24
+)
+
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+  <body style="font-family: sans-serif; color: #222;">
+    <h2>{{ .Title }}</h2>
+    {{ range .Items }}
+      <div style="border-top: 1px solid #ddd; padding: 12px 0;">
+        {{ if .ContentID }}
+          <a href="{{ .URL }}"><img src="cid:{{ .ContentID }}" alt="Thumbnail" style="float: right; max-height: 120px; margin-left: 12px;"></a>
+        {{ end }}
+        {{ if .Rich }}
+          <h3 style="margin: 0 0 6px;"><a href="{{ .URL }}">{{ .Notification.Title }}</a></h3>
+          <p style="margin: 0 0 6px;">{{ .Notification.Description }}</p>
+          {{ range .Notification.Fields }}
+            <p style="margin: 0;"><strong>{{ .Name }}</strong>: {{ .Value }}</p>
+          {{ end }}
+        {{ else }}
+          <p style="margin: 0;">{{ .Text }}</p>
+        {{ end }}
+        <p style="clear: both; margin: 0; color: #888; font-size: small;">{{ .Time }}</p>
+      </div>
+    {{ end }}
+    {{ if .Remaining }}
+      <p>And {{ .Remaining }} more event(s).</p>
+    {{ end }}
+  </body>
+</html>`))
+
+type emailItem struct {
+	Notification notification
+	Text         string
+	URL          string
+	ContentID    string
+	Time         string
+	Rich         bool
+}
+
+type emailContent struct {
+	Title     string
+	Items     []emailItem
+	Remaining int
+}
+
+// emailHandle sends an email for the event, a rejection of the SMTP server with a 5xx code being permanent
+func (s *Service) emailHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	if !s.email.Enabled() {
+		return 0, fmt.Errorf("send email for webhook with id `%s`: %w: %w", webhook.ID, email.ErrNoConfig, errPermanent)
+	}
+
+	if err := s.email.Send(ctx, s.emailMessage(ctx, webhook, "", []provider.Event{event}, 1)); err != nil {
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+			return smtpErr.Code, fmt.Errorf("send email for webhook with id `%s`: %w: %w", webhook.ID, err, errPermanent)
+		}
+
+		return 0, fmt.Errorf("send email for webhook with id `%s`: %w", webhook.ID, err)
+	}
+
+	return smtpOK, nil
+}
+
+// emailMessage renders the events in a text and an HTML body, the thumbnails being inlined; count is the number of events before the digest was capped
+func (s *Service) emailMessage(ctx context.Context, webhook provider.Webhook, title string, events []provider.Event, count int) email.Message {
+	message := email.Message{
+		To: []string{webhook.URL},
+	}
+
+	content := emailContent{
+		Title:     title,
+		Remaining: count - len(events),
+	}
+
+	var text strings.Builder
+	inlined := make(map[string]string)
+
+	for _, event := range events {
+		item := emailItem{
+			Text: s.eventText(event),
+			URL:  event.GetURL(),
+			Time: event.Time.Format("Mon 02 Jan 2006 15:04"),
+		}
+
+		fmt.Fprintf(&text, "%s\n", item.Text)
+
+		if rich, ok := s.notificationOf(event); ok {
+			item.Notification = rich
+			item.URL = rich.URL
+			item.Rich = true
+		}
+
+		if contentID, ok := inlined[event.Item.ID]; ok {
+			item.ContentID = contentID
+		} else if len(message.Inlines) < maxInlineThumbnails {
+			if inline, ok := s.inlineThumbnail(ctx, event); ok {
+				item.ContentID = inline.ContentID
+				inlined[event.Item.ID] = inline.ContentID
+				message.Inlines = append(message.Inlines, inline)
+			}
+		}
+
+		content.Items = append(content.Items, item)
+	}
+
+	if content.Remaining > 0 {
+		fmt.Fprintf(&text, "And %d more event(s).\n", content.Remaining)
+	}
+
+	if len(content.Title) == 0 && len(content.Items) != 0 {
+		if first := content.Items[0]; first.Rich {
+			content.Title = fmt.Sprintf("%s: %s", first.Notification.Title, first.Notification.Description)
+		} else {
+			content.Title = first.Text
+		}
+	}
+
+	message.Subject = "[fibr] " + content.Title
+	message.Text = text.String()
+
+	var output strings.Builder
+	if err := emailTemplate.Execute(&output, content); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "render email", slog.String("id", webhook.ID), slog.Any("error", err))
+	}
+
+	message.HTML = output.String()
+
+	return message
+}
+
+func (s *Service) inlineThumbnail(ctx context.Context, event provider.Event) (email.Inline, bool) {
+	if s.storage == nil || !s.thumbnail.CanHaveThumbnail(event.Item) || !s.thumbnail.HasThumbnail(ctx, event.Item, thumbnail.SmallSize) {
+		return email.Inline{}, false
+	}
+
+	reader, err := s.storage.ReadFrom(ctx, s.thumbnail.PathForScale(event.Item, thumbnail.SmallSize))
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelWarn, "read thumbnail for email", slog.String("item", event.Item.Pathname), slog.Any("error", err))
+		return email.Inline{}, false
+	}
+
+	defer provider.LogClose(ctx, reader, "webhook.inlineThumbnail", event.Item.Pathname)
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelWarn, "read thumbnail for email", slog.String("item", event.Item.Pathname), slog.Any("error", err))
+		return email.Inline{}, false
+	}
+
+	return email.Inline{
+		ContentID:   fmt.Sprintf("thumbnail-%s@fibr", event.Item.ID),
+		ContentType: "image/webp",
+		Content:     content,
+	}, true
+}
@@ -1,12 +1,16 @@
 package webhook
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 	"net/url"
 	"path"
-	"strconv"
 	"strings"
 	"time"
 
@@ -19,45 +23,56 @@ import (
 )
 
 func (s *Service) EventConsumer(ctx context.Context, event provider.Event) {
-	matching := s.matchingWebhooks(event)
+	for _, webhook := range s.matchingWebhooks(event) {
+		s.enqueue(ctx, webhook, event)
+	}
 
-	for _, webhook := range matching {
-		var statusCode int
-		var err error
+	if event.Type == provider.DeleteEvent {
+		go func() {
+			if err := s.deleteItem(ctx, event.Item); err != nil {
+				slog.LogAttrs(ctx, slog.LevelError, "delete webhooks for item", slog.Any("error", err))
+			}
+		}()
+	}
+}
 
-		switch webhook.Kind {
-		case provider.Raw:
-			statusCode, err = s.rawHandle(ctx, webhook, event)
+func (s *Service) handle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	switch webhook.Kind {
+	case provider.Raw:
+		return s.rawHandle(ctx, webhook, event)
 
-		case provider.Discord:
-			statusCode, err = s.discordHandle(ctx, webhook, event)
+	case provider.Discord:
+		return s.discordHandle(ctx, webhook, event)
 
-		case provider.Slack:
-			statusCode, err = s.slackHandle(ctx, webhook, event)
+	case provider.Slack:
+		return s.slackHandle(ctx, webhook, event)
 
-		case provider.Telegram:
-			statusCode, err = s.telegramHandle(ctx, webhook, event)
+	case provider.Telegram:
+		return s.telegramHandle(ctx, webhook, event)
 
-		case provider.Push:
-			statusCode, err = s.pushHandle(webhook, event)
+	case provider.Push:
+		return s.pushHandle(ctx, webhook, event)
 
-		default:
-			slog.LogAttrs(ctx, slog.LevelWarn, "unknown kind for webhook", slog.String("kind", webhook.Kind.String()))
-		}
+	case provider.Template:
+		return s.templateHandle(ctx, webhook, event)
 
-		s.increaseMetric(ctx, strconv.Itoa(statusCode))
+	case provider.Matrix:
+		return s.matrixHandle(ctx, webhook, event)
 
-		if err != nil {
-			slog.LogAttrs(ctx, slog.LevelError, "error while sending webhook", slog.Any("error", err))
-		}
-	}
+	case provider.Ntfy:
+		return s.ntfyHandle(ctx, webhook, event)
 
-	if event.Type == provider.DeleteEvent {
-		go func() {
-			if err := s.deleteItem(ctx, event.Item); err != nil {
-				slog.LogAttrs(ctx, slog.LevelError, "delete webhooks for item", slog.Any("error", err))
-			}
-		}()
+	case provider.Gotify:
+		return s.gotifyHandle(ctx, webhook, event)
+
+	case provider.Mattermost:
+		return s.mattermostHandle(ctx, webhook, event)
+
+	case provider.Email:
+		return s.emailHandle(ctx, webhook, event)
+
+	default:
+		return 0, fmt.Errorf("unknown kind `%s` for webhook: %w", webhook.Kind, errPermanent)
 	}
 }
 
@@ -78,8 +93,17 @@ func (s *Service) matchingWebhooks(event provider.Event) []provider.Webhook {
 
 func send(ctx context.Context, id string, req request.Request, payload any) (int, error) {
 	resp, err := req.JSON(ctx, payload)
+
+	return handleResponse(id, resp, err)
+}
+
+func handleResponse(id string, resp *http.Response, err error) (int, error) {
 	if err != nil {
-		return 0, fmt.Errorf("send webhook with id `%s`: %w", id, err)
+		if resp == nil {
+			return 0, fmt.Errorf("send webhook with id `%s`: %w", id, err)
+		}
+
+		return resp.StatusCode, fmt.Errorf("send webhook with id `%s`: %w", id, err)
 	}
 
 	if err = request.DiscardBody(resp.Body); err != nil {
@@ -90,10 +114,73 @@ func send(ctx context.Context, id string, req request.Request, payload any) (int
 }
 
 func (s *Service) rawHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
-	return send(ctx, webhook.ID, request.Post(webhook.URL).Header("User-Agent", "fibr-webhook").WithSignatureAuthorization("fibr", s.hmacSecret), event)
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("marshal event for webhook with id `%s`: %w: %w", webhook.ID, err, errPermanent)
+	}
+
+	resp, err := s.signed(request.Post(webhook.URL), webhook, body).ContentJSON().ContentLength(int64(len(body))).Send(ctx, io.NopCloser(bytes.NewReader(body)))
+
+	return handleResponse(webhook.ID, resp, err)
+}
+
+func (s *Service) templateHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	body, err := provider.RenderWebhookTemplate(webhook.Template, provider.NewWebhookTemplateData(event, s.thumbnailURL(event)))
+	if err != nil {
+		return 0, fmt.Errorf("render template for webhook with id `%s`: %w: %w", webhook.ID, err, errPermanent)
+	}
+
+	req := s.signed(request.New().Method(webhook.Method).URL(webhook.URL), webhook, []byte(body))
+
+	if !hasHeader(webhook.Headers, "Content-Type") {
+		req = req.ContentJSON()
+	}
+
+	resp, err := withHeaders(req, webhook.Headers).ContentLength(int64(len(body))).Send(ctx, io.NopCloser(strings.NewReader(body)))
+
+	return handleResponse(webhook.ID, resp, err)
+}
+
+// hasHeader looks for the header whatever its case, as it has been typed by the user
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Service) thumbnailURL(event provider.Event) string {
+	if !s.thumbnail.CanHaveThumbnail(event.Item) {
+		return ""
+	}
+
+	thumbnailURL := event.GetURL() + "?thumbnail"
+
+	if _, ok := provider.VideoExtensions[event.Item.Extension]; ok {
+		thumbnailURL += "&scale=large"
+	}
+
+	return thumbnailURL
 }
 
 func (s *Service) discordHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	if event.Type == provider.MemoryEvent {
+		embed := discord.Embed{
+			Title:       memoryTitle(event),
+			Description: memoryDescription(event),
+			URL:         event.GetURL(),
+		}
+
+		if cover := event.GetMetadata("cover"); len(cover) != 0 {
+			embed.Thumbnail = discord.NewImage(cover)
+		}
+
+		return send(ctx, webhook.ID, request.Post(webhook.URL), discord.NewDataResponse("").AddEmbed(embed))
+	}
+
 	if event.Type != provider.UploadEvent && event.Type != provider.RenameEvent && event.Type != provider.DescriptionEvent {
 		return send(ctx, webhook.ID, request.Post(webhook.URL), discord.NewDataResponse(s.eventText(event)))
 	}
@@ -112,7 +199,9 @@ func (s *Service) discordHandle(ctx context.Context, webhook provider.Webhook, e
 		contentURL = event.BrowserURL()
 	case provider.RenameEvent:
 		description = "✏️ An item has been renamed"
-		fields = append(fields, discord.NewField("to", event.GetTo()))
+		if event.New != nil {
+			fields = append(fields, discord.NewField("to", event.GetTo()))
+		}
 		contentURL = event.BrowserURL()
 	case provider.DescriptionEvent:
 		description = "💬 " + event.Metadata["description"]
@@ -126,19 +215,14 @@ func (s *Service) discordHandle(ctx context.Context, webhook provider.Webhook, e
 		Fields:      fields,
 	}
 
-	if s.thumbnail.CanHaveThumbnail(event.Item) {
-		thumbnailURL := event.GetURL() + "?thumbnail"
-
-		if _, ok := provider.VideoExtensions[event.Item.Extension]; ok {
-			thumbnailURL += "&scale=large"
-		}
-
+	if thumbnailURL := s.thumbnailURL(event); len(thumbnailURL) != 0 {
 		embed.Thumbnail = discord.NewImage(thumbnailURL)
 	}
 
 	return send(ctx, webhook.ID, request.Post(webhook.URL), discord.NewDataResponse("").AddEmbed(embed))
 }
 
+// asyncPushNotification sends the events debounced for a webhook, dropped if it has been deleted since
 func (s *Service) asyncPushNotification(group string, events []provider.Event) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
@@ -147,60 +231,217 @@ func (s *Service) asyncPushNotification(group string, events []provider.Event) {
 		return
 	}
 
-	subscription, err := s.push.Find(ctx, group)
-	if err != nil {
-		slog.Error("Unable to find subscription", slog.String("url", group), slog.Any("error", err))
+	webhook := s.Get(group)
+	if len(webhook.ID) == 0 {
+		return
+	}
+
+	subscription, ok := s.findSubscription(ctx, webhook.URL)
+	if !ok {
+		return
+	}
+
+	var grouped []provider.Event
+
+	for _, event := range events {
+		if event.Type == provider.MemoryEvent {
+			s.notifyPush(ctx, subscription, memoryNotification(event))
+		} else {
+			grouped = append(grouped, event)
+		}
+	}
+
+	if len(grouped) == 0 {
 		return
 	}
 
 	notification := push.Notification{
-		Title: path.Base(path.Dir(events[0].Item.Pathname)),
+		Title: path.Base(path.Dir(grouped[0].Item.Pathname)),
 	}
 
 	if notification.Title == "/" {
 		notification.Title = "fibr"
 	}
 
-	var uploadCount int
+	notification.Description, notification.URL = pushSummary(grouped)
+
+	for _, event := range grouped {
+		if len(notification.Image) == 0 && event.Type != provider.DeleteEvent {
+			notification.Image = s.thumbnailURL(event)
+		}
+	}
+
+	s.notifyPush(ctx, subscription, notification)
+}
+
+func (s *Service) asyncMemoryNotification(group string, event provider.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	if subscription, ok := s.findSubscription(ctx, group); ok {
+		s.notifyPush(ctx, subscription, memoryNotification(event))
+	}
+}
+
+func memoryNotification(event provider.Event) push.Notification {
+	return push.Notification{
+		Title:       memoryTitle(event),
+		Description: memoryDescription(event),
+		URL:         event.GetURL(),
+		Image:       event.GetMetadata("cover"),
+	}
+}
+
+// pushSummary describes the grouped events, by type in the order they occurred, and links to the first one
+func pushSummary(events []provider.Event) (string, string) {
+	var types []provider.EventType
+	counts := make(map[provider.EventType]int)
 
 	for _, event := range events {
-		uploadCount++
+		if counts[event.Type] == 0 {
+			types = append(types, event.Type)
+		}
 
-		if uploadCount > 1 {
-			notification.Description = "💾 Multiples files have been uploaded"
-		} else {
-			notification.Description = "💾 A file has been uploaded"
-			notification.URL = event.BrowserURL()
+		counts[event.Type]++
+	}
+
+	lines := make([]string, len(types))
+
+	for index, eventType := range types {
+		count := counts[eventType]
+
+		switch eventType {
+		case provider.UploadEvent:
+			lines[index] = pluralize(count, "💾 A file has been uploaded", "💾 %d files have been uploaded")
+		case provider.CreateDir:
+			lines[index] = pluralize(count, "🗂 A directory has been created", "🗂 %d directories have been created")
+		case provider.RenameEvent:
+			lines[index] = pluralize(count, "✏️ An item has been renamed", "✏️ %d items have been renamed")
+		case provider.DeleteEvent:
+			lines[index] = pluralize(count, "❌ An item has been deleted", "❌ %d items have been deleted")
+		case provider.DescriptionEvent:
+			lines[index] = pluralize(count, "💬 A description has been added", "💬 %d descriptions have been added")
+		default:
+			lines[index] = pluralize(count, fmt.Sprintf("🙄 An event `%s` occurred", eventType), "🙄 %d events `"+eventType.String()+"` occurred")
 		}
+	}
 
-		if len(notification.Image) == 0 && s.thumbnail.CanHaveThumbnail(event.Item) {
-			thumbnailURL := event.GetURL() + "?thumbnail"
+	if len(events) == 1 && events[0].Type == provider.DescriptionEvent {
+		lines[0] = "💬 " + events[0].Metadata["description"]
+	}
 
-			if _, ok := provider.VideoExtensions[event.Item.Extension]; ok {
-				thumbnailURL += "&scale=large"
-			}
+	first := events[0]
 
-			notification.Image = thumbnailURL
-		}
+	switch first.Type {
+	case provider.UploadEvent, provider.RenameEvent:
+		return strings.Join(lines, "\n"), first.BrowserURL()
+	case provider.DescriptionEvent:
+		return strings.Join(lines, "\n"), first.StoryURL(first.Item.ID)
+	default:
+		return strings.Join(lines, "\n"), first.GetURL()
 	}
+}
+
+func pluralize(count int, single, multiple string) string {
+	if count == 1 {
+		return single
+	}
+
+	return fmt.Sprintf(multiple, count)
+}
 
-	if _, err := s.push.Notify(ctx, subscription, notification); err != nil {
-		slog.Error("Unable to send push notification", slog.String("url", group), slog.Any("error", err))
+// findSubscription loads the subscription of the endpoint, pruning its webhooks if it's gone
+func (s *Service) findSubscription(ctx context.Context, endpoint string) (push.Subscription, bool) {
+	subscription, err := s.push.Find(ctx, endpoint)
+	if err == nil {
+		return subscription, true
+	}
+
+	if errors.Is(err, push.ErrNotFound) {
+		s.prunePush(ctx, endpoint)
+	} else {
+		slog.LogAttrs(ctx, slog.LevelError, "find push subscription", slog.String("url", endpoint), slog.Any("error", err))
+	}
+
+	return subscription, false
+}
+
+func (s *Service) notifyPush(ctx context.Context, subscription push.Subscription, notification push.Notification) {
+	statusCode, err := s.push.Notify(ctx, subscription, notification)
+	if statusCode == http.StatusNotFound || statusCode == http.StatusGone {
+		s.prunePush(ctx, subscription.Endpoint)
 		return
 	}
+
+	if err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "send push notification", slog.String("url", subscription.Endpoint), slog.Any("error", err))
+	}
 }
 
-func (s *Service) pushHandle(webhook provider.Webhook, event provider.Event) (int, error) {
-	switch event.Type {
-	case provider.UploadEvent:
-		s.debouncer.Send(webhook.URL, event)
-	default:
+// prunePush forgets a subscription the push service doesn't know anymore, and the webhooks notifying it
+func (s *Service) prunePush(ctx context.Context, endpoint string) {
+	slog.LogAttrs(ctx, slog.LevelInfo, "pruning expired push subscription", slog.String("url", endpoint))
+
+	if err := s.push.Delete(ctx, endpoint); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "delete push subscription", slog.String("url", endpoint), slog.Any("error", err))
+	}
+
+	for _, webhook := range s.FindByURL(endpoint) {
+		if webhook.Kind != provider.Push {
+			continue
+		}
+
+		if err := s.Delete(ctx, webhook.ID); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "delete push webhook", slog.String("id", webhook.ID), slog.Any("error", err))
+		}
+	}
+}
+
+// pushHandle groups the events of the webhook in a single notification sent after the debounce window, or at the end of the quiet hours.
+// Memories are sent right away, outside of the quiet hours.
+func (s *Service) pushHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	if s.push == nil {
+		return 0, fmt.Errorf("push notification for webhook with id `%s`: %w: %w", webhook.ID, push.ErrNoConfig, errPermanent)
+	}
+
+	now := time.Now()
+
+	window := webhook.Debounce
+	if window == 0 {
+		window = provider.DefaultPushDebounce
+	}
+
+	if event.Type == provider.MemoryEvent {
+		window = 0
+	}
+
+	if until := webhook.Quiet.Until(now.Add(window)); !until.IsZero() {
+		window = until.Sub(now)
+	}
+
+	if window == 0 {
+		go s.asyncMemoryNotification(webhook.URL, event)
+		return 0, nil
+	}
+
+	// Buckets are kept per webhook, the quiet hours of a folder not holding back the events of the other ones
+	if err := s.debouncer.Send(ctx, webhook.ID, window, event); err != nil {
+		return 0, fmt.Errorf("debounce push notification for webhook with id `%s`: %w", webhook.ID, err)
 	}
 
 	return 0, nil
 }
 
 func (s *Service) slackHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	if event.Type == provider.MemoryEvent {
+		section := slack.NewSection(slack.NewText(memoryDescription(event)))
+		if cover := event.GetMetadata("cover"); len(cover) != 0 {
+			section.Accessory = slack.NewAccessory(cover, "Memory of "+memoryTitle(event))
+		}
+
+		return send(ctx, webhook.ID, request.Post(webhook.URL), slack.NewResponse(s.eventText(event)).AddBlock(slack.NewSection(slack.NewText(fmt.Sprintf("*<%s|%s>*", event.GetURL(), memoryTitle(event))))).AddBlock(section))
+	}
+
 	if event.Type != provider.UploadEvent && event.Type != provider.RenameEvent && event.Type != provider.DescriptionEvent {
 		return send(ctx, webhook.ID, request.Post(webhook.URL), slack.NewResponse(s.eventText(event)))
 	}
@@ -219,7 +460,9 @@ func (s *Service) slackHandle(ctx context.Context, webhook provider.Webhook, eve
 		contentURL = event.BrowserURL()
 	case provider.RenameEvent:
 		description = "✏️ An item has been renamed"
-		extraField = slack.NewText(fmt.Sprintf("*to*\n%s", event.GetTo()))
+		if event.New != nil {
+			extraField = slack.NewText(fmt.Sprintf("*to*\n%s", event.GetTo()))
+		}
 		contentURL = event.BrowserURL()
 	case provider.DescriptionEvent:
 		description = "💬 " + event.Metadata["description"]
@@ -251,12 +494,18 @@ func (s *Service) eventText(event provider.Event) string {
 	case provider.UploadEvent:
 		return fmt.Sprintf("💾 A file has been uploaded: %s?browser", event.GetURL())
 	case provider.RenameEvent:
+		if event.New == nil {
+			return fmt.Sprintf("✏️ `%s` has been renamed: %s?browser", event.Item.Pathname, event.GetURL())
+		}
+
 		return fmt.Sprintf("✏️ `%s` has been renamed to `%s`: %s?browser", event.Item.Pathname, event.New.Pathname, event.GetURL())
 	case provider.DeleteEvent:
 		return fmt.Sprintf("❌ `%s` has been deleted : %s", event.Item.Name(), event.GetURL())
 	case provider.DescriptionEvent:
 		contentURL := event.GetURL()
 		return fmt.Sprintf("💬 %s %s", event.Metadata["description"], fmt.Sprintf("%s/?d=story#%s", contentURL[:strings.LastIndex(contentURL, "/")], event.Item.ID))
+	case provider.MemoryEvent:
+		return fmt.Sprintf("%s: %s", memoryDescription(event), event.GetURL())
 	case provider.StartEvent:
 		return fmt.Sprintf("🚀 Fibr starts routine for path `%s`", event.Item.Pathname)
 	default:
@@ -264,6 +513,22 @@ func (s *Service) eventText(event provider.Event) string {
 	}
 }
 
+func memoryTitle(event provider.Event) string {
+	if name := strings.Trim(event.GetName(), "/"); len(name) != 0 {
+		return name
+	}
+
+	return "fibr"
+}
+
+func memoryDescription(event provider.Event) string {
+	if event.GetMetadata("count") == "1" {
+		return fmt.Sprintf("📅 On this day: a memory from %s", event.GetMetadata("years"))
+	}
+
+	return fmt.Sprintf("📅 On this day: %s memories from %s", event.GetMetadata("count"), event.GetMetadata("years"))
+}
+
 func (s *Service) accessEvent(event provider.Event) string {
 	content := strings.Builder{}
 	fmt.Fprintf(&content, "💻 Someone connected to Fibr from %s at %s", s.rendererService.PublicURL(event.URL), event.Time.Format(time.RFC3339))
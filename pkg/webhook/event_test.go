@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestPushSummary(t *testing.T) {
+	t.Parallel()
+
+	upload := provider.Event{Type: provider.UploadEvent, URL: "https://fibr.local/photos/sunset.jpg", Item: absto.Item{Pathname: "/photos/sunset.jpg", NameValue: "sunset.jpg"}}
+	deletion := provider.Event{Type: provider.DeleteEvent, URL: "https://fibr.local/photos/", Item: absto.Item{Pathname: "/photos/old.txt", NameValue: "old.txt"}}
+	description := provider.Event{Type: provider.DescriptionEvent, URL: "https://fibr.local/photos/sunset.jpg", Item: absto.Item{ID: "8a3b1f", Pathname: "/photos/sunset.jpg"}, Metadata: map[string]string{"description": "Golden hour"}}
+
+	cases := map[string]struct {
+		events          []provider.Event
+		wantDescription string
+		wantURL         string
+	}{
+		"upload": {
+			[]provider.Event{upload},
+			"💾 A file has been uploaded",
+			"https://fibr.local/photos/sunset.jpg?browser",
+		},
+		"uploads": {
+			[]provider.Event{upload, upload, upload},
+			"💾 3 files have been uploaded",
+			"https://fibr.local/photos/sunset.jpg?browser",
+		},
+		"description": {
+			[]provider.Event{description},
+			"💬 Golden hour",
+			"https://fibr.local/photos/?d=story#8a3b1f",
+		},
+		"mixed": {
+			[]provider.Event{deletion, upload, deletion},
+			"❌ 2 items have been deleted\n💾 A file has been uploaded",
+			"https://fibr.local/photos/",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			description, url := pushSummary(tc.events)
+
+			if description != tc.wantDescription {
+				t.Errorf("pushSummary() = `%s`, want `%s`", description, tc.wantDescription)
+			}
+
+			if url != tc.wantURL {
+				t.Errorf("pushSummary() = `%s`, want `%s`", url, tc.wantURL)
+			}
+		})
+	}
+}
+
+func TestTemplateHandleContentType(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		headers map[string]string
+		want    []string
+	}{
+		"default": {
+			nil,
+			[]string{"application/json"},
+		},
+		"lowercase header": {
+			map[string]string{"content-type": "text/plain"},
+			[]string{"text/plain"},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			received := make(chan []string, 1)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received <- r.Header.Values("Content-Type")
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			webhook := provider.Webhook{ID: "abc", Kind: provider.Template, Method: http.MethodPost, URL: server.URL, Template: "fibr", Headers: tc.headers}
+
+			if _, err := (&Service{}).templateHandle(context.Background(), webhook, provider.Event{Type: provider.UploadEvent}); err != nil {
+				t.Fatalf("templateHandle() = %s", err)
+			}
+
+			if got := <-received; !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("templateHandle() Content-Type = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
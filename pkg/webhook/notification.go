@@ -0,0 +1,257 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+)
+
+type notificationField struct {
+	Name  string
+	Value string
+}
+
+// notification is the rich content shared by the self-hosted services
+type notification struct {
+	Title       string
+	Description string
+	URL         string
+	Thumbnail   string
+	Fields      []notificationField
+}
+
+// notificationOf returns the rich content of the event, false if the event only has a plain text
+func (s *Service) notificationOf(event provider.Event) (notification, bool) {
+	if event.Type == provider.MemoryEvent {
+		return notification{
+			Title:       memoryTitle(event),
+			Description: memoryDescription(event),
+			URL:         event.GetURL(),
+			Thumbnail:   event.GetMetadata("cover"),
+		}, true
+	}
+
+	if event.Type != provider.UploadEvent && event.Type != provider.RenameEvent && event.Type != provider.DescriptionEvent {
+		return notification{}, false
+	}
+
+	content := notification{
+		Title:     path.Base(path.Dir(event.Item.Pathname)),
+		Thumbnail: s.thumbnailURL(event),
+		Fields:    []notificationField{{Name: "item", Value: event.GetName()}},
+	}
+
+	if content.Title == "/" {
+		content.Title = "fibr"
+	}
+
+	switch event.Type {
+	case provider.UploadEvent:
+		content.Description = "💾 A file has been uploaded"
+		content.URL = event.BrowserURL()
+	case provider.RenameEvent:
+		content.Description = "✏️ An item has been renamed"
+		if event.New != nil {
+			content.Fields = append(content.Fields, notificationField{Name: "to", Value: event.GetTo()})
+		}
+		content.URL = event.BrowserURL()
+	case provider.DescriptionEvent:
+		content.Description = "💬 " + event.Metadata["description"]
+		content.URL = event.StoryURL(event.Item.ID)
+	}
+
+	return content, true
+}
+
+func withHeaders(req request.Request, headers map[string]string) request.Request {
+	for name, value := range headers {
+		req = req.Header(name, value)
+	}
+
+	return req
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// matrixHandle sends a message to the room, the transaction ID being derived from the event so a retry isn't posted twice
+func (s *Service) matrixHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	message := matrixMessage{
+		MsgType: "m.text",
+		Body:    s.eventText(event),
+	}
+
+	if content, ok := s.notificationOf(event); ok {
+		var plain, formatted strings.Builder
+
+		fmt.Fprintf(&plain, "%s\n%s\n", content.Title, content.Description)
+		fmt.Fprintf(&formatted, `<h4><a href="%s">%s</a></h4><p>%s</p>`, html.EscapeString(content.URL), html.EscapeString(content.Title), html.EscapeString(content.Description))
+
+		if len(content.Fields) != 0 {
+			formatted.WriteString("<ul>")
+
+			for _, field := range content.Fields {
+				fmt.Fprintf(&plain, "%s: %s\n", field.Name, field.Value)
+				fmt.Fprintf(&formatted, "<li><strong>%s</strong>: %s</li>", html.EscapeString(field.Name), html.EscapeString(field.Value))
+			}
+
+			formatted.WriteString("</ul>")
+		}
+
+		plain.WriteString(content.URL)
+
+		if len(content.Thumbnail) != 0 {
+			fmt.Fprintf(&formatted, `<p><a href="%s">🖼 Thumbnail</a></p>`, html.EscapeString(content.Thumbnail))
+		}
+
+		message.Body = plain.String()
+		message.Format = "org.matrix.custom.html"
+		message.FormattedBody = formatted.String()
+	}
+
+	transactionID := provider.Hash(fmt.Sprintf("%s:%s:%s:%s", webhook.ID, event.Type, event.Item.Pathname, event.Time.Format(time.RFC3339Nano)))
+
+	return send(ctx, webhook.ID, withHeaders(request.Put(webhook.URL+"/"+transactionID), webhook.Headers), message)
+}
+
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title,omitempty"`
+	Message  string   `json:"message"`
+	Click    string   `json:"click,omitempty"`
+	Attach   string   `json:"attach,omitempty"`
+	Filename string   `json:"filename,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// ntfyHandle publishes to the topic, the URL of the webhook being the topic URL and the JSON being posted on the server's root
+func (s *Service) ntfyHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	index := strings.LastIndex(webhook.URL, "/")
+	if index == -1 {
+		return 0, fmt.Errorf("no topic in url `%s`: %w", webhook.URL, errPermanent)
+	}
+
+	message := ntfyMessage{
+		Topic:   webhook.URL[index+1:],
+		Message: s.eventText(event),
+		Tags:    []string{"fibr", event.Type.String()},
+	}
+
+	if content, ok := s.notificationOf(event); ok {
+		var body strings.Builder
+		body.WriteString(content.Description)
+
+		for _, field := range content.Fields {
+			fmt.Fprintf(&body, "\n%s: %s", field.Name, field.Value)
+		}
+
+		message.Title = content.Title
+		message.Message = body.String()
+		message.Click = content.URL
+
+		if len(content.Thumbnail) != 0 {
+			message.Attach = content.Thumbnail
+			message.Filename = "thumbnail.webp"
+		}
+	}
+
+	return send(ctx, webhook.ID, withHeaders(request.Post(webhook.URL[:index]), webhook.Headers), message)
+}
+
+type gotifyMessage struct {
+	Extras   map[string]any `json:"extras,omitempty"`
+	Title    string         `json:"title,omitempty"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+}
+
+func (s *Service) gotifyHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	message := gotifyMessage{
+		Message:  s.eventText(event),
+		Priority: 5,
+	}
+
+	if content, ok := s.notificationOf(event); ok {
+		var body strings.Builder
+		body.WriteString(content.Description)
+
+		for _, field := range content.Fields {
+			fmt.Fprintf(&body, "\n\n**%s**: %s", field.Name, field.Value)
+		}
+
+		notificationExtras := map[string]any{
+			"click": map[string]string{"url": content.URL},
+		}
+
+		if len(content.Thumbnail) != 0 {
+			fmt.Fprintf(&body, "\n\n![thumbnail](%s)", content.Thumbnail)
+			notificationExtras["bigImageUrl"] = content.Thumbnail
+		}
+
+		message.Title = content.Title
+		message.Message = body.String()
+		message.Extras = map[string]any{
+			"client::display":      map[string]string{"contentType": "text/markdown"},
+			"client::notification": notificationExtras,
+		}
+	}
+
+	return send(ctx, webhook.ID, withHeaders(request.Post(webhook.URL), webhook.Headers), message)
+}
+
+type mattermostField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type mattermostAttachment struct {
+	Fallback  string            `json:"fallback"`
+	Title     string            `json:"title"`
+	TitleLink string            `json:"title_link,omitempty"`
+	Text      string            `json:"text,omitempty"`
+	ThumbURL  string            `json:"thumb_url,omitempty"`
+	Fields    []mattermostField `json:"fields,omitempty"`
+}
+
+type mattermostMessage struct {
+	Username    string                 `json:"username"`
+	Text        string                 `json:"text,omitempty"`
+	Attachments []mattermostAttachment `json:"attachments,omitempty"`
+}
+
+func (s *Service) mattermostHandle(ctx context.Context, webhook provider.Webhook, event provider.Event) (int, error) {
+	message := mattermostMessage{
+		Username: "fibr",
+		Text:     s.eventText(event),
+	}
+
+	if content, ok := s.notificationOf(event); ok {
+		attachment := mattermostAttachment{
+			Fallback:  message.Text,
+			Title:     content.Title,
+			TitleLink: content.URL,
+			Text:      content.Description,
+			ThumbURL:  content.Thumbnail,
+		}
+
+		for _, field := range content.Fields {
+			attachment.Fields = append(attachment.Fields, mattermostField{Title: field.Name, Value: field.Value, Short: true})
+		}
+
+		message.Text = ""
+		message.Attachments = []mattermostAttachment{attachment}
+	}
+
+	return send(ctx, webhook.ID, withHeaders(request.Post(webhook.URL), webhook.Headers), message)
+}
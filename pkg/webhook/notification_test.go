@@ -0,0 +1,232 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+type stubRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   map[string]any
+}
+
+func TestNotificationHandlers(t *testing.T) {
+	upload := provider.Event{
+		Time: time.Date(2024, 7, 14, 12, 0, 0, 0, time.UTC),
+		Type: provider.UploadEvent,
+		URL:  "https://fibr.local/photos/sunset.jpg",
+		Item: absto.Item{
+			ID:        "8a3b1f",
+			Pathname:  "/photos/sunset.jpg",
+			NameValue: "sunset.jpg",
+			Extension: ".jpg",
+		},
+	}
+
+	deletion := provider.Event{
+		Type: provider.DeleteEvent,
+		URL:  "https://fibr.local/photos/old.txt",
+		Item: absto.Item{
+			Pathname:  "/photos/old.txt",
+			NameValue: "old.txt",
+			Extension: ".txt",
+		},
+	}
+
+	rename := provider.Event{
+		Type: provider.RenameEvent,
+		URL:  "https://fibr.local/photos/",
+		Item: absto.Item{
+			Pathname:   "/photos/",
+			NameValue:  "photos",
+			IsDirValue: true,
+		},
+	}
+
+	cases := map[string]struct {
+		webhook     provider.Webhook
+		event       provider.Event
+		wantMethod  string
+		wantPath    string
+		wantHeaders map[string]string
+		want        map[string]any
+	}{
+		"matrix": {
+			provider.Webhook{Kind: provider.Matrix, URL: "/_matrix/client/v3/rooms/!room:local/send/m.room.message", Headers: map[string]string{"Authorization": "Bearer secret"}},
+			upload,
+			http.MethodPut,
+			"/_matrix/client/v3/rooms/!room:local/send/m.room.message/",
+			map[string]string{"Authorization": "Bearer secret"},
+			map[string]any{
+				"msgtype":        "m.text",
+				"body":           "photos\n💾 A file has been uploaded\nitem: sunset.jpg\nhttps://fibr.local/photos/sunset.jpg?browser",
+				"format":         "org.matrix.custom.html",
+				"formatted_body": `<h4><a href="https://fibr.local/photos/sunset.jpg?browser">photos</a></h4><p>💾 A file has been uploaded</p><ul><li><strong>item</strong>: sunset.jpg</li></ul><p><a href="https://fibr.local/photos/sunset.jpg?thumbnail">🖼 Thumbnail</a></p>`,
+			},
+		},
+		"matrix plain": {
+			provider.Webhook{Kind: provider.Matrix, URL: "/_matrix/client/v3/rooms/!room:local/send/m.room.message"},
+			deletion,
+			http.MethodPut,
+			"/_matrix/client/v3/rooms/!room:local/send/m.room.message/",
+			nil,
+			map[string]any{
+				"msgtype": "m.text",
+				"body":    "❌ `old.txt` has been deleted : https://fibr.local/photos/old.txt",
+			},
+		},
+		"ntfy": {
+			provider.Webhook{Kind: provider.Ntfy, URL: "/fibr", Headers: map[string]string{"Authorization": "Bearer secret"}},
+			upload,
+			http.MethodPost,
+			"/",
+			map[string]string{"Authorization": "Bearer secret"},
+			map[string]any{
+				"topic":    "fibr",
+				"title":    "photos",
+				"message":  "💾 A file has been uploaded\nitem: sunset.jpg",
+				"click":    "https://fibr.local/photos/sunset.jpg?browser",
+				"attach":   "https://fibr.local/photos/sunset.jpg?thumbnail",
+				"filename": "thumbnail.webp",
+				"tags":     []any{"fibr", "upload"},
+			},
+		},
+		"gotify": {
+			provider.Webhook{Kind: provider.Gotify, URL: "/message", Headers: map[string]string{"X-Gotify-Key": "secret"}},
+			upload,
+			http.MethodPost,
+			"/message",
+			map[string]string{"X-Gotify-Key": "secret"},
+			map[string]any{
+				"title":    "photos",
+				"message":  "💾 A file has been uploaded\n\n**item**: sunset.jpg\n\n![thumbnail](https://fibr.local/photos/sunset.jpg?thumbnail)",
+				"priority": float64(5),
+				"extras": map[string]any{
+					"client::display": map[string]any{"contentType": "text/markdown"},
+					"client::notification": map[string]any{
+						"click":       map[string]any{"url": "https://fibr.local/photos/sunset.jpg?browser"},
+						"bigImageUrl": "https://fibr.local/photos/sunset.jpg?thumbnail",
+					},
+				},
+			},
+		},
+		"mattermost": {
+			provider.Webhook{Kind: provider.Mattermost, URL: "/hooks/abc", Headers: map[string]string{"Authorization": "Bearer secret"}},
+			upload,
+			http.MethodPost,
+			"/hooks/abc",
+			map[string]string{"Authorization": "Bearer secret"},
+			map[string]any{
+				"username": "fibr",
+				"attachments": []any{
+					map[string]any{
+						"fallback":   "💾 A file has been uploaded: https://fibr.local/photos/sunset.jpg?browser",
+						"title":      "photos",
+						"title_link": "https://fibr.local/photos/sunset.jpg?browser",
+						"text":       "💾 A file has been uploaded",
+						"thumb_url":  "https://fibr.local/photos/sunset.jpg?thumbnail",
+						"fields": []any{
+							map[string]any{"title": "item", "value": "sunset.jpg", "short": true},
+						},
+					},
+				},
+			},
+		},
+		"rename without destination": {
+			provider.Webhook{Kind: provider.Mattermost, URL: "/hooks/abc"},
+			rename,
+			http.MethodPost,
+			"/hooks/abc",
+			nil,
+			map[string]any{
+				"username": "fibr",
+				"attachments": []any{
+					map[string]any{
+						"fallback":   "✏️ `/photos/` has been renamed: https://fibr.local/photos/?browser",
+						"title":      "photos",
+						"title_link": "https://fibr.local/photos/?browser",
+						"text":       "✏️ An item has been renamed",
+						"fields": []any{
+							map[string]any{"title": "item", "value": "photos/", "short": true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			received := make(chan stubRequest, 1)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				payload, _ := io.ReadAll(r.Body)
+
+				var body map[string]any
+				_ = json.Unmarshal(payload, &body)
+
+				received <- stubRequest{method: r.Method, path: r.URL.Path, header: r.Header, body: body}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			tc.webhook.ID = "abc"
+			tc.webhook.URL = server.URL + tc.webhook.URL
+
+			if _, err := (&Service{}).handle(context.Background(), tc.webhook, tc.event); err != nil {
+				t.Fatalf("handle() = %s", err)
+			}
+
+			got := <-received
+
+			if got.method != tc.wantMethod || !strings.HasPrefix(got.path, tc.wantPath) {
+				t.Errorf("handle() = %s %s, want %s %s", got.method, got.path, tc.wantMethod, tc.wantPath)
+			}
+
+			for name, value := range tc.wantHeaders {
+				if got.header.Get(name) != value {
+					t.Errorf("handle() header `%s` = `%s`, want `%s`", name, got.header.Get(name), value)
+				}
+			}
+
+			if !reflect.DeepEqual(got.body, tc.want) {
+				t.Errorf("handle() = %+v, want %+v", got.body, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatrixTransactionID(t *testing.T) {
+	var paths []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := provider.Webhook{ID: "abc", Kind: provider.Matrix, URL: server.URL + "/send"}
+	event := provider.Event{Type: provider.StartEvent, Time: time.Now(), Item: absto.Item{Pathname: "/photos/"}}
+
+	for range 2 {
+		if _, err := (&Service{}).matrixHandle(context.Background(), webhook, event); err != nil {
+			t.Fatalf("matrixHandle() = %s", err)
+		}
+	}
+
+	if len(paths) != 2 || paths[0] != paths[1] {
+		t.Errorf("matrixHandle() = %v, want the same transaction for the same event", paths)
+	}
+}
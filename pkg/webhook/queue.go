@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+const maxQueuedEvents = 100
+
+type queuedEvent struct {
+	webhook provider.Webhook
+	event   provider.Event
+}
+
+// enqueue hands the event to the worker of the webhook, started on demand, so the events of a webhook are processed one after the other.
+// An event over a full queue is recorded as a dead letter, for being replayed.
+func (s *Service) enqueue(ctx context.Context, webhook provider.Webhook, event provider.Event) {
+	if s.tryEnqueue(ctx, webhook, event) {
+		return
+	}
+
+	delivery := provider.WebhookDelivery{
+		ID:    provider.Hash(provider.Identifier())[:8],
+		Time:  time.Now(),
+		Event: event,
+		Error: "too many pending deliveries",
+	}
+
+	slog.LogAttrs(ctx, slog.LevelError, "webhook queue is full", slog.String("id", webhook.ID))
+
+	if err := s.record(ctx, webhook.ID, delivery); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "record webhook delivery", slog.String("id", webhook.ID), slog.Any("error", err))
+	}
+}
+
+func (s *Service) tryEnqueue(ctx context.Context, webhook provider.Webhook, event provider.Event) bool {
+	s.queuesMutex.Lock()
+	defer s.queuesMutex.Unlock()
+
+	queue, ok := s.queues[webhook.ID]
+	if !ok {
+		queue = make(chan queuedEvent, maxQueuedEvents)
+		s.queues[webhook.ID] = queue
+
+		s.deliveries.Go(func() {
+			s.work(context.WithoutCancel(ctx), webhook.ID, queue)
+		})
+	}
+
+	select {
+	case queue <- queuedEvent{webhook: webhook, event: event}:
+		return true
+	default:
+		return false
+	}
+}
+
+// work processes the queue until it's empty, the worker being removed under the lock so that no event is left behind
+func (s *Service) work(ctx context.Context, id string, queue chan queuedEvent) {
+	for {
+		s.queuesMutex.Lock()
+
+		select {
+		case queued := <-queue:
+			s.queuesMutex.Unlock()
+
+			s.process(ctx, queued.webhook, queued.event)
+
+		default:
+			delete(s.queues, id)
+			s.queuesMutex.Unlock()
+
+			return
+		}
+	}
+}
+
+func (s *Service) process(ctx context.Context, webhook provider.Webhook, event provider.Event) {
+	if webhook.Kind == provider.Push {
+		statusCode, err := s.pushHandle(ctx, webhook, event)
+		if err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "push notification", slog.String("id", webhook.ID), slog.Any("error", err))
+		}
+
+		s.increaseMetric(ctx, strconv.Itoa(statusCode))
+
+		return
+	}
+
+	if webhook.Kind == provider.Email && len(webhook.Digest) != 0 {
+		if err := s.queueDigest(ctx, webhook, event); err != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "queue digest", slog.String("id", webhook.ID), slog.Any("error", err))
+		}
+
+		return
+	}
+
+	delivery := s.deliver(ctx, webhook, event)
+	if delivery.Failed() {
+		slog.LogAttrs(ctx, slog.LevelError, "error while sending webhook", slog.String("id", webhook.ID), slog.Int("attempts", delivery.Attempts), slog.String("error", delivery.Error))
+	}
+
+	if err := s.record(ctx, webhook.ID, delivery); err != nil {
+		slog.LogAttrs(ctx, slog.LevelError, "record webhook delivery", slog.String("id", webhook.ID), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/absto/pkg/filesystem"
+	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/exclusive"
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestEnqueue(t *testing.T) {
+	storageService, err := filesystem.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("filesystem.New() = %s", err)
+	}
+
+	var received []string
+	var mutex sync.Mutex
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var once sync.Once
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(started) })
+		<-release
+
+		var event provider.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		// the first delivery is retried, the next events wait for it
+		if len(received) == 0 && event.Item.Pathname == "/0.jpg" {
+			received = append(received, "failed")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		received = append(received, event.Item.Pathname)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	instance := &Service{
+		storage:          storageService,
+		exclusiveService: exclusive.New(nil),
+		queues:           make(map[string]chan queuedEvent),
+		retries:          1,
+		retryDelay:       time.Millisecond,
+	}
+
+	webhook := provider.Webhook{ID: "abc", URL: server.URL, Kind: provider.Raw}
+
+	var want []string
+
+	// the first event is taken by the worker, the next ones fill the queue
+	for index := range maxQueuedEvents + 2 {
+		pathname := "/" + string(rune('0'+index%10)) + ".jpg"
+
+		if index <= maxQueuedEvents {
+			want = append(want, pathname)
+		}
+
+		instance.enqueue(context.Background(), webhook, provider.Event{Type: provider.UploadEvent, Item: absto.Item{Pathname: pathname}})
+
+		if index == 0 {
+			<-started
+		}
+	}
+
+	close(release)
+	instance.deliveries.Wait()
+
+	if got := received[1:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("enqueue() delivered %d event(s) out of order, want %d in order", len(got), len(want))
+	}
+
+	log, err := instance.loadDeliveries(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("loadDeliveries() = %s", err)
+	}
+
+	if len(log.DeadLetters) != 1 || log.DeadLetters[0].Error != "too many pending deliveries" {
+		t.Errorf("enqueue() dead letters = %+v, want the event over the full queue", log.DeadLetters)
+	}
+
+	if len(instance.queues) != 0 {
+		t.Errorf("enqueue() left %d worker(s)", len(instance.queues))
+	}
+}
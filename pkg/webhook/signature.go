@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+	"github.com/ViBiOh/httputils/v4/pkg/request"
+)
+
+const signatureHeader = "X-Fibr-Signature"
+
+// signature returns `t=<timestamp>,v1=<signature>` with a signature for each secret, being the hex HMAC-SHA256 of `<timestamp>.<body>`
+func signature(secrets []string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+
+	var output strings.Builder
+	output.WriteString("t=")
+	output.WriteString(unix)
+
+	for _, secret := range secrets {
+		hash := hmac.New(sha256.New, []byte(secret))
+		hash.Write([]byte(unix))
+		hash.Write([]byte("."))
+		hash.Write(body)
+
+		output.WriteString(",v1=")
+		output.WriteString(hex.EncodeToString(hash.Sum(nil)))
+	}
+
+	return output.String()
+}
+
+// signed adds the signature of the body with the secrets of the webhook, or the legacy global one for a webhook created before them
+func (s *Service) signed(req request.Request, webhook provider.Webhook, body []byte) request.Request {
+	req = req.Header("User-Agent", "fibr-webhook")
+
+	if len(webhook.Secrets) == 0 {
+		return req.WithSignatureAuthorization("fibr", s.hmacSecret)
+	}
+
+	now := time.Now()
+
+	if secrets := webhook.ActiveSecrets(now); len(secrets) != 0 {
+		req = req.Header(signatureHeader, signature(secrets, now, body))
+	}
+
+	return req
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/fibr/pkg/provider"
+)
+
+func TestSignature(t *testing.T) {
+	timestamp := time.Date(2024, 7, 17, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"upload"}`)
+
+	cases := map[string]struct {
+		secrets []string
+		want    string
+	}{
+		"single": {
+			[]string{"whsec_current"},
+			"t=1721217600,v1=6b51d91e4dae8b07b2f55b90e9df4844f071b0490397613fc6c77fa30e177e27",
+		},
+		"rotation": {
+			[]string{"whsec_current", "whsec_previous"},
+			"t=1721217600,v1=6b51d91e4dae8b07b2f55b90e9df4844f071b0490397613fc6c77fa30e177e27,v1=18dd371f18f2837ba3d35ec90a1e0923a3a19f7c9f06b825c4d88a0eb6f6373c",
+		},
+	}
+
+	for intention, tc := range cases {
+		t.Run(intention, func(t *testing.T) {
+			if got := signature(tc.secrets, timestamp, body); got != tc.want {
+				t.Errorf("signature() = `%s`, want `%s`", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRawHandleSignature(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		received <- r
+		bodies <- body
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := provider.Webhook{
+		ID:   "a1b2c3d4",
+		Kind: provider.Raw,
+		URL:  server.URL,
+		Secrets: []provider.WebhookSecret{
+			{Value: "whsec_current"},
+			{Value: "whsec_expired", Expire: time.Now().Add(-time.Minute)},
+		},
+	}
+
+	if _, err := (&Service{hmacSecret: []byte("global")}).rawHandle(context.Background(), webhook, provider.Event{Type: provider.UploadEvent}); err != nil {
+		t.Fatalf("rawHandle() = %s", err)
+	}
+
+	request, body := <-received, <-bodies
+
+	header := request.Header.Get(signatureHeader)
+
+	timestamp, signatures, ok := strings.Cut(strings.TrimPrefix(header, "t="), ",")
+	if !ok || strings.Count(signatures, "v1=") != 1 {
+		t.Fatalf("rawHandle() signature = `%s`, want a timestamp and the current secret only", header)
+	}
+
+	hash := hmac.New(sha256.New, []byte("whsec_current"))
+	hash.Write([]byte(timestamp + "."))
+	hash.Write(body)
+
+	if want := "v1=" + hex.EncodeToString(hash.Sum(nil)); signatures != want {
+		t.Errorf("rawHandle() signature = `%s`, want `%s`", signatures, want)
+	}
+
+	if request.Header.Get("Authorization") != "" {
+		t.Errorf("rawHandle() sent the global signature to a webhook having its own secrets")
+	}
+}
+
+func TestRawHandleLegacySignature(t *testing.T) {
+	received := make(chan *http.Request, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := provider.Webhook{
+		ID:   "a1b2c3d4",
+		Kind: provider.Raw,
+		URL:  server.URL,
+	}
+
+	if _, err := (&Service{hmacSecret: []byte("global")}).rawHandle(context.Background(), webhook, provider.Event{Type: provider.UploadEvent}); err != nil {
+		t.Fatalf("rawHandle() = %s", err)
+	}
+
+	request := <-received
+
+	if !strings.HasPrefix(request.Header.Get("Authorization"), "Signature ") {
+		t.Errorf("rawHandle() Authorization = `%s`, want the global signature", request.Header.Get("Authorization"))
+	}
+
+	if request.Header.Get(signatureHeader) != "" {
+		t.Errorf("rawHandle() sent a signature without any secret")
+	}
+}
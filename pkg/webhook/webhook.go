@@ -9,6 +9,7 @@ import (
 	"time"
 
 	absto "github.com/ViBiOh/absto/pkg/model"
+	"github.com/ViBiOh/fibr/pkg/email"
 	"github.com/ViBiOh/fibr/pkg/exclusive"
 	"github.com/ViBiOh/fibr/pkg/provider"
 	"github.com/ViBiOh/fibr/pkg/push"
@@ -27,31 +28,46 @@ type Service struct {
 	redisClient      redis.Client
 	exclusiveService exclusive.Service
 	webhooks         map[string]provider.Webhook
+	queues           map[string]chan queuedEvent
 	debouncer        *GroupDebouncer[provider.Event]
+	digests          *GroupDebouncer[provider.Event]
 	rendererService  *renderer.Service
 	push             *push.Service
+	email            *email.Service
 	done             chan struct{}
 	pubsubChannel    string
 	hmacSecret       []byte
 	thumbnail        thumbnail.Service
+	deliveries       sync.WaitGroup
+	retryDelay       time.Duration
+	retries          int
+	digestHour       int
 	mutex            sync.RWMutex
+	logsMutex        sync.Mutex
+	queuesMutex      sync.Mutex
 }
 
 type Config struct {
 	HmacSecret    string
 	PubsubChannel string
+	RetryDelay    time.Duration
+	Retries       int
+	DigestHour    int
 }
 
 func Flags(fs *flag.FlagSet, prefix string) *Config {
 	var config Config
 
-	flags.New("Secret", "Secret for HMAC Signature").Prefix(prefix).DocPrefix("webhook").StringVar(fs, &config.HmacSecret, "", nil)
+	flags.New("Secret", "Global secret for the legacy HMAC Signature of webhooks without their own secret").Prefix(prefix).DocPrefix("webhook").StringVar(fs, &config.HmacSecret, "", nil)
 	flags.New("PubSubChannel", "Channel name").Prefix(prefix).DocPrefix("share").StringVar(fs, &config.PubsubChannel, "fibr:webhooks-channel", nil)
+	flags.New("Retries", "Number of retries of a delivery failing with a network error or a 5xx").Prefix(prefix).DocPrefix("webhook").IntVar(fs, &config.Retries, 3, nil)
+	flags.New("RetryDelay", "Delay before the first retry, doubled on each retry").Prefix(prefix).DocPrefix("webhook").DurationVar(fs, &config.RetryDelay, time.Second, nil)
+	flags.New("DigestHour", "Hour of the daily and weekly email digests").Prefix(prefix).DocPrefix("webhook").IntVar(fs, &config.DigestHour, 8, nil)
 
 	return &config
 }
 
-func New(config *Config, storageService absto.Storage, meterProvider metric.MeterProvider, redisClient redis.Client, rendererService *renderer.Service, pushService *push.Service, thumbnailService thumbnail.Service, exclusiveApp exclusive.Service) *Service {
+func New(config *Config, storageService absto.Storage, meterProvider metric.MeterProvider, redisClient redis.Client, rendererService *renderer.Service, pushService *push.Service, emailService *email.Service, thumbnailService thumbnail.Service, exclusiveApp exclusive.Service) *Service {
 	var counter metric.Int64Counter
 	if meterProvider != nil {
 		meter := meterProvider.Meter("github.com/ViBiOh/fibr/pkg/webhook")
@@ -70,15 +86,21 @@ func New(config *Config, storageService absto.Storage, meterProvider metric.Mete
 		rendererService:  rendererService,
 		thumbnail:        thumbnailService,
 		push:             pushService,
+		email:            emailService,
 		exclusiveService: exclusiveApp,
 		webhooks:         make(map[string]provider.Webhook),
+		queues:           make(map[string]chan queuedEvent),
 		counter:          counter,
 		hmacSecret:       []byte(config.HmacSecret),
 		redisClient:      redisClient,
 		pubsubChannel:    config.PubsubChannel,
+		retries:          config.Retries,
+		retryDelay:       config.RetryDelay,
+		digestHour:       config.DigestHour,
 	}
 
-	service.debouncer = NewDebouncer[provider.Event](time.Minute*30, service.asyncPushNotification)
+	service.debouncer = NewDebouncer("webhook-debounce", newBucketStore("webhook-debounce", redisClient, storageService), exclusiveApp, service.asyncPushNotification)
+	service.digests = NewDebouncer("webhook-digest", newBucketStore("webhook-digest", redisClient, storageService), exclusiveApp, service.sendDigest)
 
 	return service
 }
@@ -91,6 +113,8 @@ func (s *Service) Done() <-chan struct{} {
 
 		<-s.done
 		<-s.debouncer.Done()
+		<-s.digests.Done()
+		s.deliveries.Wait()
 	}()
 
 	return done
@@ -115,6 +139,7 @@ func (s *Service) Start(ctx context.Context) {
 	}
 
 	go s.debouncer.Start(ctx)
+	go s.digests.Start(ctx)
 
 	redis.SubscribeFor(ctx, s.redisClient, s.pubsubChannel, s.PubSubHandle)
 }